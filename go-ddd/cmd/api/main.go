@@ -1,28 +1,46 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"log"
+	"net"
+	"net/url"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 
+	"yiwen/go-ddd/internal/application/bus"
+	"yiwen/go-ddd/internal/application/port"
 	appservice "yiwen/go-ddd/internal/application/service"
+	"yiwen/go-ddd/internal/domain/event"
+	"yiwen/go-ddd/internal/domain/repository"
 	domainservice "yiwen/go-ddd/internal/domain/service"
+	"yiwen/go-ddd/internal/domain/valueobject"
+	"yiwen/go-ddd/internal/infrastructure/auth"
 	"yiwen/go-ddd/internal/infrastructure/config"
-	"yiwen/go-ddd/internal/infrastructure/persistence/model"
+	"yiwen/go-ddd/internal/infrastructure/imaging"
+	"yiwen/go-ddd/internal/infrastructure/mail"
+	"yiwen/go-ddd/internal/infrastructure/messaging"
+	"yiwen/go-ddd/internal/infrastructure/persistence/eventsourced"
+	"yiwen/go-ddd/internal/infrastructure/persistence/memory"
 	mysqlrepo "yiwen/go-ddd/internal/infrastructure/persistence/mysql"
+	"yiwen/go-ddd/internal/infrastructure/persistence/sqlite"
+	blobstorage "yiwen/go-ddd/internal/infrastructure/storage"
 	"yiwen/go-ddd/internal/interfaces/api/handler"
 	"yiwen/go-ddd/internal/interfaces/api/middleware"
 	"yiwen/go-ddd/internal/interfaces/api/router"
+	grpcserver "yiwen/go-ddd/internal/interfaces/grpc/server"
 )
 
 func main() {
 	// 解析命令行参数
 	configPath := flag.String("config", "config/config.yaml", "config file path")
+	storageKind := flag.String("storage", "mysql", "storage backend: memory, sqlite or mysql")
 	flag.Parse()
 
 	// 加载配置
@@ -31,37 +49,210 @@ func main() {
 		log.Fatalf("Failed to load config: %v", err)
 	}
 
+	// 子命令：api [-config 配置文件] [-storage mysql] migrate <up|down|to|status|force|unlock>
+	if flag.Arg(0) == "migrate" {
+		if err := runMigrate(cfg, *storageKind, flag.Args()[1:]); err != nil {
+			log.Fatalf("Migration failed: %v", err)
+		}
+		return
+	}
+
 	// 设置Gin模式
 	gin.SetMode(cfg.App.Mode)
 
-	// 初始化数据库
-	db, err := initDatabase(cfg)
-	if err != nil {
-		log.Fatalf("Failed to connect database: %v", err)
-	}
-
 	// 依赖注入
 	// 这是DDD中的重要实践：在应用启动时进行依赖注入
 	// 各层之间通过接口解耦，便于测试和维护
 
-	// 1. 初始化仓储层（基础设施层）
-	userRepo := mysqlrepo.NewUserRepository(db)
+	// 1. 初始化存储和仓储层（基础设施层）
+	store, err := openStorage(cfg, *storageKind)
+	if err != nil {
+		log.Fatalf("Failed to open %s storage: %v", *storageKind, err)
+	}
+	log.Printf("Using %s storage", *storageKind)
 
 	// 2. 初始化领域服务（领域层）
-	userDomainService := domainservice.NewUserDomainService(userRepo)
+	passwordService, err := initPasswordService(cfg.Password)
+	if err != nil {
+		log.Fatalf("Failed to init password policy: %v", err)
+	}
+	userDomainService := domainservice.NewUserDomainService(store.users, passwordService)
+	loginGuard := domainservice.NewLoginGuard(store.loginAttempts, loginThrottlePolicy(cfg.Login.User), loginThrottlePolicy(cfg.Login.IP))
+	mfaPolicy := domainservice.NewMFAPolicy(cfg.MFA.RequireForAdmins)
+
+	// 3. 初始化事件总线和发件箱中继（基础设施层）
+	eventBus := messaging.NewEventBus()
+	eventBus.SubscribeAll(messaging.LoggingHandler{})
+	if store.eventRecorder != nil {
+		eventBus.SubscribeAll(store.eventRecorder)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if cfg.Outbox.Enabled && store.outbox != nil {
+		relay := messaging.NewOutboxRelay(store.outbox, messaging.LogSink{}, messaging.RelayConfig{
+			PollInterval: cfg.Outbox.PollInterval,
+			BatchSize:    cfg.Outbox.BatchSize,
+			MaxAttempts:  cfg.Outbox.MaxAttempts,
+		})
+		go relay.Run(ctx)
+	}
+
+	// 4. 初始化应用服务（应用层）
+	// 命令在工作单元（事务）中执行，仓储通过上下文加入同一事务
+	userAppService := appservice.NewUserApplicationService(store.users, store.uow, userDomainService, passwordService, loginGuard, eventBus)
+
+	authAppService := appservice.NewAuthApplicationService(
+		store.refreshTokens,
+		store.revokedTokens,
+		store.uow,
+		userAppService,
+		mfaPolicy,
+		cfg.JWT.RefreshTokenTTL,
+	)
+	go purgeExpired(ctx, authAppService, loginGuard)
+
+	// 查询端：用户读模型由投影订阅领域事件维护，查询只读读模型
+	userProjection := appservice.NewUserProjection(store.users, store.userViews)
+	if !cfg.ReadModel.SkipRebuild {
+		n, err := userProjection.Rebuild(ctx)
+		if err != nil {
+			log.Fatalf("Failed to rebuild user read model: %v", err)
+		}
+		log.Printf("User read model rebuilt with %d users", n)
+	}
+	eventBus.SubscribeAll(userProjection)
+	userQueryService := appservice.NewUserQueryService(store.userViews)
+
+	// 命令总线、查询总线：接口层通过总线分发命令和查询
+	// 命令在 Transactional 开启的事务中执行，事务提交后才发布领域事件
+	commandBus := bus.NewCommandBus(bus.Logging(), bus.Validation(), bus.Transactional(store.uow, eventBus))
+	queryBus := bus.NewQueryBus(bus.Logging(), bus.Validation())
+	userAppService.RegisterCommandHandlers(commandBus)
+	userQueryService.RegisterQueryHandlers(queryBus)
+
+	// 邮箱验证和密码重置：邮件由领域事件的处理器放入后台队列，在请求之外发送
+	mailer, err := initMailer(cfg)
+	if err != nil {
+		log.Fatalf("Failed to init mailer: %v", err)
+	}
+	actionTokens := auth.NewHMACActionTokenSigner(cfg.Account.TokenSecret)
+	accountAppService := appservice.NewAccountApplicationService(
+		store.users,
+		store.refreshTokens,
+		userAppService,
+		actionTokens,
+		mailer,
+		appservice.AccountOptions{
+			VerifyURL: cfg.Account.VerifyURL,
+			ResetURL:  cfg.Account.ResetURL,
+			VerifyTTL: cfg.Account.VerifyTTL,
+			ResetTTL:  cfg.Account.ResetTTL,
+		},
+	)
+	eventBus.Subscribe("user.registered", messaging.EventHandlerFunc(accountAppService.HandleUserRegistered))
+	eventBus.Subscribe("user.password_reset_requested", messaging.EventHandlerFunc(accountAppService.HandlePasswordResetRequested))
+	go accountAppService.Run(ctx)
+
+	// 两步验证：TOTP 密钥加密保存，登录第二步的令牌与邮件令牌共用签名器
+	secretCipher, err := auth.NewAESGCMCipherFromConfig(cfg.MFA.EncryptionKey)
+	if err != nil {
+		log.Fatalf("Failed to init mfa encryption: %v", err)
+	}
+	mfaAppService := appservice.NewMFAApplicationService(
+		store.users,
+		userAppService,
+		loginGuard,
+		mfaPolicy,
+		secretCipher,
+		actionTokens,
+		appservice.MFAOptions{
+			Issuer:        cfg.MFA.Issuer,
+			ChallengeTTL:  cfg.MFA.ChallengeTTL,
+			RecoveryCodes: cfg.MFA.RecoveryCodes,
+		},
+	)
+
+	// 头像：缩略图保存到文件存储，头像被替换后删除旧文件
+	blobs, err := initBlobStorage(cfg)
+	if err != nil {
+		log.Fatalf("Failed to init blob storage: %v", err)
+	}
+	avatarAppService := appservice.NewAvatarApplicationService(
+		store.users,
+		userAppService,
+		blobs,
+		imaging.NewProcessor(cfg.Avatar.MaxPixels),
+		appservice.AvatarOptions{
+			MaxBytes: cfg.Avatar.MaxBytes,
+			Sizes:    cfg.Avatar.Sizes,
+		},
+	)
+	avatarAppService.RegisterCommandHandlers(commandBus)
+	eventBus.Subscribe("user.profile_updated", messaging.EventHandlerFunc(avatarAppService.HandleProfileUpdated))
+	eventBus.Subscribe("user.erased", messaging.EventHandlerFunc(avatarAppService.HandleUserErased))
+
+	// 个人数据：导出、申请删除账户，宽限期结束后由后台任务擦除
+	personalDataAppService := appservice.NewPersonalDataApplicationService(
+		store.users,
+		store.refreshTokens,
+		store.userEvents,
+		userAppService,
+		cfg.Erasure.GracePeriod,
+	)
+	personalDataAppService.RegisterCommandHandlers(commandBus)
+	personalDataAppService.RegisterQueryHandlers(queryBus)
+	go eraseDue(ctx, personalDataAppService, cfg.Erasure.CheckInterval)
 
-	// 3. 初始化应用服务（应用层）
-	userAppService := appservice.NewUserApplicationService(userRepo, userDomainService)
+	// 5. 初始化JWT认证
+	keys, err := initSigningKeys(ctx, cfg)
+	if err != nil {
+		log.Fatalf("Failed to load signing keys: %v", err)
+	}
+	jwtAuth := middleware.NewJWTAuth(keys, cfg.JWT.AllowedAlgorithms, cfg.JWT.AccessTokenTTL, cfg.JWT.Issuer)
+	jwtAuth.SetStatusChecker(userAppService)     // 禁用、停用、降级立即生效
+	jwtAuth.SetRevocationChecker(authAppService) // 登出后的令牌立即失效
 
-	// 4. 初始化JWT认证
-	jwtAuth := middleware.NewJWTAuth(cfg.JWT.Secret, cfg.JWT.ExpireHour, cfg.JWT.Issuer)
+	// 6. 初始化HTTP处理器（接口层）
+	userHandler := handler.NewUserHandler(userAppService, authAppService, mfaAppService, commandBus, queryBus, jwtAuth)
+	accountHandler := handler.NewAccountHandler(accountAppService)
+	mfaHandler := handler.NewMFAHandler(mfaAppService, authAppService, jwtAuth)
+	avatarHandler := handler.NewAvatarHandler(commandBus, cfg.Avatar.MaxBytes)
+	dataHandler := handler.NewPersonalDataHandler(commandBus, queryBus)
+	jwksHandler := handler.NewJWKSHandler(keys)
 
-	// 5. 初始化HTTP处理器（接口层）
-	userHandler := handler.NewUserHandler(userAppService, jwtAuth)
+	// 7. 初始化路由
+	policyEngine := domainservice.NewDefaultPolicyEngine()
+	authorizer := middleware.NewAuthorizer(policyEngine)
+	r := router.NewRouter(userHandler, accountHandler, mfaHandler, avatarHandler, dataHandler, jwksHandler, jwtAuth, authorizer)
+	engine, err := r.Setup(cfg.App.TrustedProxies)
+	if err != nil {
+		log.Fatalf("Failed to setup router: %v", err)
+	}
+	if local, ok := blobs.(*blobstorage.LocalStorage); ok {
+		// 本地存储的文件由本服务提供，路径取自 blob_storage.local.base_url
+		base, err := url.Parse(cfg.BlobStorage.Local.BaseURL)
+		if err != nil {
+			log.Fatalf("Invalid blob_storage.local.base_url: %v", err)
+		}
+		engine.Static(base.Path, local.Dir())
+	}
 
-	// 6. 初始化路由
-	r := router.NewRouter(userHandler, jwtAuth)
-	engine := r.Setup()
+	// 8. 初始化 gRPC 服务（接口层），与 HTTP 共用应用服务、总线和认证
+	if cfg.GRPC.Port > 0 {
+		userServer := grpcserver.NewUserServer(userAppService, authAppService, mfaAppService, commandBus, queryBus, jwtAuth, policyEngine)
+		grpcServer := grpcserver.New(userServer, jwtAuth, grpcserver.Options{Reflection: cfg.GRPC.Reflection})
+		lis, err := net.Listen("tcp", fmt.Sprintf(":%d", cfg.GRPC.Port))
+		if err != nil {
+			log.Fatalf("Failed to listen for gRPC: %v", err)
+		}
+		log.Printf("gRPC server starting on %s", lis.Addr())
+		go func() {
+			if err := grpcServer.Serve(lis); err != nil {
+				log.Fatalf("Failed to start gRPC server: %v", err)
+			}
+		}()
+	}
 
 	// 启动服务
 	addr := fmt.Sprintf(":%d", cfg.App.Port)
@@ -74,9 +265,84 @@ func main() {
 	}
 }
 
-// initDatabase 初始化数据库连接
-func initDatabase(cfg *config.Config) (*gorm.DB, error) {
-	// 配置GORM日志
+// storage 持久化相关的依赖
+type storage struct {
+	users         repository.UserRepository
+	uow           repository.UnitOfWork
+	refreshTokens repository.RefreshTokenRepository
+	revokedTokens repository.RevokedTokenRepository
+	loginAttempts repository.LoginAttemptRepository
+	userViews     port.UserReadModel
+	outbox        messaging.OutboxStore // 内存存储没有发件箱，为 nil
+	userEvents    port.UserEventLog
+	eventRecorder event.EventHandler // 需要订阅事件总线才能记录事件时不为 nil（内存存储）
+}
+
+// openStorage 根据 --storage 选择存储
+// memory、sqlite 不依赖外部服务，适合本地体验和测试；mysql 为生产环境使用
+func openStorage(cfg *config.Config, kind string) (*storage, error) {
+	switch kind {
+	case "memory":
+		users := memory.NewUserRepository()
+		var userRepo repository.UserRepository = users
+		if cfg.EventSourcing.Enabled {
+			// 内存仓储同时作为读模型和投影器
+			userRepo = eventsourced.NewUserRepository(memory.NewEventStore(), users, users, cfg.EventSourcing.SnapshotEvery)
+		}
+		userEvents := memory.NewUserEventLog()
+		return &storage{
+			users:         userRepo,
+			uow:           memory.NewUnitOfWork(),
+			refreshTokens: memory.NewRefreshTokenRepository(),
+			revokedTokens: memory.NewRevokedTokenRepository(),
+			loginAttempts: memory.NewLoginAttemptRepository(),
+			userViews:     memory.NewUserReadModel(),
+			userEvents:    userEvents,
+			eventRecorder: userEvents,
+		}, nil
+	case "sqlite":
+		db, err := sqlite.Open(cfg.Database.SQLitePath, &gorm.Config{Logger: gormLogger(cfg)})
+		if err != nil {
+			return nil, err
+		}
+		// SQLite 只有一个连接，不会发生死锁，无需重试
+		return newGormStorage(cfg, db, 0), nil
+	case "mysql":
+		db, err := initDatabase(cfg)
+		if err != nil {
+			return nil, err
+		}
+		if err := prepareSchema(cfg, db); err != nil {
+			return nil, err
+		}
+		return newGormStorage(cfg, db, cfg.Database.TxMaxRetries), nil
+	default:
+		return nil, fmt.Errorf("unknown storage %q, expected memory, sqlite or mysql", kind)
+	}
+}
+
+// newGormStorage 基于 GORM 的存储（MySQL、SQLite 共用同一套仓储实现）
+func newGormStorage(cfg *config.Config, db *gorm.DB, txMaxRetries int) *storage {
+	var userRepo repository.UserRepository = mysqlrepo.NewUserRepository(db)
+	if cfg.EventSourcing.Enabled {
+		// 事件溯源：事件存储是事实来源，users 表由投影器维护供查询使用
+		eventStore := mysqlrepo.NewEventStore(db, event.NewUserEventRegistry())
+		userRepo = eventsourced.NewUserRepository(eventStore, userRepo, mysqlrepo.NewUserProjector(db), cfg.EventSourcing.SnapshotEvery)
+	}
+	return &storage{
+		users:         userRepo,
+		uow:           mysqlrepo.NewUnitOfWork(db, txMaxRetries),
+		refreshTokens: mysqlrepo.NewRefreshTokenRepository(db),
+		revokedTokens: mysqlrepo.NewRevokedTokenRepository(db),
+		loginAttempts: mysqlrepo.NewLoginAttemptRepository(db),
+		userViews:     mysqlrepo.NewUserReadModel(db),
+		outbox:        mysqlrepo.NewOutboxRepository(db),
+		userEvents:    mysqlrepo.NewUserEventLog(db),
+	}
+}
+
+// gormLogger 根据运行模式配置GORM日志
+func gormLogger(cfg *config.Config) logger.Interface {
 	var logLevel logger.LogLevel
 	switch cfg.App.Mode {
 	case "debug":
@@ -86,9 +352,14 @@ func initDatabase(cfg *config.Config) (*gorm.DB, error) {
 	default:
 		logLevel = logger.Error
 	}
+	return logger.Default.LogMode(logLevel)
+}
 
+// initDatabase 初始化 MySQL 数据库连接
+func initDatabase(cfg *config.Config) (*gorm.DB, error) {
 	db, err := gorm.Open(mysql.Open(cfg.Database.DSN()), &gorm.Config{
-		Logger: logger.Default.LogMode(logLevel),
+		Logger:         gormLogger(cfg),
+		TranslateError: true, // 将唯一键冲突等驱动错误转换为 gorm.ErrDuplicatedKey
 	})
 	if err != nil {
 		return nil, err
@@ -104,12 +375,194 @@ func initDatabase(cfg *config.Config) (*gorm.DB, error) {
 	sqlDB.SetMaxIdleConns(cfg.Database.MaxIdleConns)
 	sqlDB.SetMaxOpenConns(cfg.Database.MaxOpenConns)
 
-	// 自动迁移（开发环境使用，生产环境建议使用SQL脚本）
-	if cfg.App.Mode == "debug" {
-		if err := db.AutoMigrate(&model.UserModel{}); err != nil {
-			return nil, err
+	return db, nil
+}
+
+// prepareSchema 启动前检查表结构版本，与本程序内置的迁移不一致时拒绝启动
+// database.auto_migrate 开启时先执行未执行的迁移，关闭时需要预先运行 migrate up
+func prepareSchema(cfg *config.Config, db *gorm.DB) error {
+	migrator, err := newMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	if cfg.Database.AutoMigrate {
+		applied, err := migrator.Up(ctx)
+		for _, m := range applied {
+			log.Printf("Applied migration %s", m)
+		}
+		if err != nil {
+			return fmt.Errorf("auto migrate: %w", err)
 		}
 	}
 
-	return db, nil
+	version, err := migrator.Verify(ctx)
+	if err != nil {
+		return fmt.Errorf("schema check: %w", err)
+	}
+	log.Printf("Database schema at version %d", version)
+	return nil
+}
+
+// initMailer 根据 mail.driver 选择邮件发送方式
+func initMailer(cfg *config.Config) (port.Mailer, error) {
+	switch cfg.Mail.Driver {
+	case "smtp":
+		return mail.NewSMTPMailer(mail.SMTPConfig{
+			Host:     cfg.Mail.SMTP.Host,
+			Port:     cfg.Mail.SMTP.Port,
+			Username: cfg.Mail.SMTP.Username,
+			Password: cfg.Mail.SMTP.Password,
+			From:     cfg.Mail.From,
+		}), nil
+	case "file":
+		log.Printf("Writing outgoing mail to %s", cfg.Mail.FileDir)
+		return mail.NewFileMailer(cfg.Mail.FileDir, cfg.Mail.From)
+	case "log":
+		return mail.LogMailer{}, nil
+	default:
+		return nil, fmt.Errorf("unknown mail driver %q, expected smtp, file or log", cfg.Mail.Driver)
+	}
+}
+
+// initBlobStorage 根据 blob_storage.driver 选择文件存储
+func initBlobStorage(cfg *config.Config) (port.BlobStorage, error) {
+	switch cfg.BlobStorage.Driver {
+	case "local":
+		log.Printf("Storing uploaded files in %s", cfg.BlobStorage.Local.Dir)
+		return blobstorage.NewLocalStorage(cfg.BlobStorage.Local.Dir, cfg.BlobStorage.Local.BaseURL)
+	case "s3":
+		return blobstorage.NewS3Storage(blobstorage.S3Config{
+			Endpoint:        cfg.BlobStorage.S3.Endpoint,
+			Region:          cfg.BlobStorage.S3.Region,
+			Bucket:          cfg.BlobStorage.S3.Bucket,
+			AccessKeyID:     cfg.BlobStorage.S3.AccessKeyID,
+			SecretAccessKey: cfg.BlobStorage.S3.SecretAccessKey,
+			PublicURL:       cfg.BlobStorage.S3.PublicURL,
+		})
+	default:
+		return nil, fmt.Errorf("unknown blob storage driver %q, expected local or s3", cfg.BlobStorage.Driver)
+	}
+}
+
+// signingKeys 同时提供签名/验证密钥和 JWKS
+type signingKeys interface {
+	middleware.KeyProvider
+	handler.JWKSProvider
+}
+
+// initSigningKeys 初始化JWT签名密钥
+// 配置了密钥目录时使用非对称密钥并在后台定期重新加载、轮换，否则退回共享密钥
+func initSigningKeys(ctx context.Context, cfg *config.Config) (signingKeys, error) {
+	if cfg.JWT.KeyDir == "" {
+		log.Printf("jwt.key_dir not set, signing tokens with shared secret (HS256)")
+		return auth.NewHMACKeyProvider(cfg.JWT.Secret), nil
+	}
+
+	keyManager, err := auth.NewKeyManager(auth.KeyManagerConfig{
+		Dir:              cfg.JWT.KeyDir,
+		Algorithm:        cfg.JWT.SigningAlgorithm,
+		RotationInterval: cfg.JWT.KeyRotationInterval,
+		VerifyFor:        cfg.JWT.AccessTokenTTL,
+		ReloadInterval:   cfg.JWT.KeyReloadInterval,
+	})
+	if err != nil {
+		return nil, err
+	}
+	go keyManager.Run(ctx)
+	return keyManager, nil
+}
+
+// initPasswordService 根据配置创建密码策略和哈希器
+func initPasswordService(c config.PasswordConfig) (*domainservice.PasswordService, error) {
+	policy := valueobject.PasswordPolicy{
+		MinLength:     c.MinLength,
+		MaxLength:     c.MaxLength,
+		RequireUpper:  c.RequireUpper,
+		RequireLower:  c.RequireLower,
+		RequireDigit:  c.RequireDigit,
+		RequireSymbol: c.RequireSymbol,
+		HistorySize:   c.HistorySize,
+	}
+
+	if c.CheckCommon || c.BlocklistFile != "" {
+		policy.Blocklist = valueobject.NewPasswordBlocklist()
+		if c.CheckCommon {
+			policy.Blocklist.Add(valueobject.CommonPasswords()...)
+		}
+		if c.BlocklistFile != "" {
+			words, err := auth.LoadPasswordBlocklist(c.BlocklistFile)
+			if err != nil {
+				return nil, err
+			}
+			policy.Blocklist.Add(words...)
+			log.Printf("Loaded %d blocked passwords from %s", len(words), c.BlocklistFile)
+		}
+	}
+
+	hasher, err := valueobject.NewPasswordHasher(c.Hash.Algorithm, c.Hash.BcryptCost, valueobject.Argon2Params{
+		Memory:      c.Hash.Argon2.Memory,
+		Iterations:  c.Hash.Argon2.Iterations,
+		Parallelism: c.Hash.Argon2.Parallelism,
+		SaltLength:  c.Hash.Argon2.SaltLength,
+		KeyLength:   c.Hash.Argon2.KeyLength,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return domainservice.NewPasswordService(policy, hasher), nil
+}
+
+// loginThrottlePolicy 将配置转换为领域层的登录退避策略
+func loginThrottlePolicy(c config.LoginThrottleConfig) domainservice.LoginThrottlePolicy {
+	return domainservice.LoginThrottlePolicy{
+		FreeAttempts:  c.FreeAttempts,
+		BaseDelay:     c.BaseDelay,
+		MaxDelay:      c.MaxDelay,
+		LockThreshold: c.LockThreshold,
+		LockDuration:  c.LockDuration,
+		Window:        c.Window,
+	}
+}
+
+// purgeExpired 定期清理过期的刷新令牌、吊销记录和登录失败记录
+func purgeExpired(ctx context.Context, authService *appservice.AuthApplicationService, loginGuard *domainservice.LoginGuard) {
+	ticker := time.NewTicker(time.Hour)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := authService.PurgeExpired(ctx); err != nil {
+				log.Printf("Failed to purge expired tokens: %v", err)
+			}
+			if err := loginGuard.PurgeExpired(ctx); err != nil {
+				log.Printf("Failed to purge login attempts: %v", err)
+			}
+		}
+	}
+}
+
+// eraseDue 定期擦除宽限期已结束的删除申请
+func eraseDue(ctx context.Context, personalData *appservice.PersonalDataApplicationService, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			n, err := personalData.EraseDue(ctx, time.Now())
+			if err != nil {
+				log.Printf("Failed to erase users: %v", err)
+			}
+			if n > 0 {
+				log.Printf("Erased personal data of %d users", n)
+			}
+		}
+	}
 }
@@ -0,0 +1,137 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"gorm.io/gorm"
+
+	"yiwen/go-ddd/internal/infrastructure/config"
+	"yiwen/go-ddd/internal/infrastructure/persistence/migration"
+)
+
+const migrateUsage = `usage: api [-config FILE] migrate COMMAND
+  up              apply all pending migrations
+  down [N]        revert the last N migrations (default 1)
+  to VERSION      migrate up or down to VERSION (0 reverts everything)
+  status          list migrations and their state
+  force VERSION   record VERSION as the current version without running anything
+  unlock          release a lock left behind by a crashed process`
+
+// runMigrate 执行 migrate 子命令，只适用于 MySQL 存储
+func runMigrate(cfg *config.Config, storageKind string, args []string) error {
+	if storageKind != "mysql" {
+		return fmt.Errorf("migrate only applies to mysql storage, %s storage creates its tables automatically", storageKind)
+	}
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	db, err := initDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	migrator, err := newMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up(ctx)
+		printMigrations("Applied", applied)
+		return err
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps <= 0 {
+				return fmt.Errorf("invalid step count %q", args[1])
+			}
+		}
+		reverted, err := migrator.Down(ctx, steps)
+		printMigrations("Reverted", reverted)
+		return err
+	case "to":
+		version, err := versionArg(args)
+		if err != nil {
+			return err
+		}
+		migrated, err := migrator.To(ctx, version)
+		printMigrations("Migrated", migrated)
+		return err
+	case "status":
+		return printStatus(ctx, migrator)
+	case "force":
+		version, err := versionArg(args)
+		if err != nil {
+			return err
+		}
+		if err := migrator.Force(ctx, version); err != nil {
+			return err
+		}
+		fmt.Printf("Schema version set to %d\n", version)
+		return nil
+	case "unlock":
+		if err := migrator.Unlock(ctx); err != nil {
+			return err
+		}
+		fmt.Println("Migration lock released")
+		return nil
+	default:
+		return fmt.Errorf("unknown migrate command %q\n%s", args[0], migrateUsage)
+	}
+}
+
+// newMigrator 基于内置 MySQL 迁移创建迁移器
+func newMigrator(db *gorm.DB) (*migration.Migrator, error) {
+	migrations, err := migration.MySQL()
+	if err != nil {
+		return nil, err
+	}
+	return migration.New(db, migrations, migration.Options{}), nil
+}
+
+// versionArg 解析 to、force 的版本参数
+func versionArg(args []string) (uint64, error) {
+	if len(args) < 2 {
+		return 0, fmt.Errorf("migrate %s requires a VERSION\n%s", args[0], migrateUsage)
+	}
+	version, err := strconv.ParseUint(args[1], 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid version %q", args[1])
+	}
+	return version, nil
+}
+
+func printMigrations(action string, migrations []migration.Migration) {
+	if len(migrations) == 0 {
+		fmt.Println("Nothing to do")
+		return
+	}
+	for _, m := range migrations {
+		fmt.Printf("%s %s\n", action, m)
+	}
+}
+
+func printStatus(ctx context.Context, migrator *migration.Migrator) error {
+	statuses, err := migrator.Status(ctx)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATE\tAPPLIED_AT")
+	for _, s := range statuses {
+		appliedAt := "-"
+		if s.AppliedAt != nil {
+			appliedAt = s.AppliedAt.Format(time.RFC3339)
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\n", s.Version, s.Name, s.State, appliedAt)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,151 @@
+// Package bus 命令总线和查询总线
+// 命令、查询按具体类型路由到注册的处理器，处理器外层依次包裹中间件（日志、校验、事务等）
+// 接口层只依赖总线和命令/查询结构，不直接调用应用服务的方法
+package bus
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"yiwen/go-ddd/pkg/errors"
+)
+
+var ErrNoHandler = errors.New("no handler registered")
+
+// Kind 总线的种类
+type Kind string
+
+const (
+	KindCommand Kind = "command"
+	KindQuery   Kind = "query"
+)
+
+// HandlerFunc 处理一个命令或查询
+type HandlerFunc func(ctx context.Context, msg any) (any, error)
+
+// Route 处理器的注册信息，中间件据此决定是否生效
+type Route struct {
+	Kind Kind
+	Name string // 消息类型名，如 RegisterUserCommand
+
+	// OwnTransaction 处理器自己划定事务边界（例如需要在事务外计算密码哈希），Transactional 不再包裹事务
+	OwnTransaction bool
+}
+
+// Middleware 包裹处理器，注册时按顺序组装，第一个中间件在最外层
+type Middleware func(route Route, next HandlerFunc) HandlerFunc
+
+// Option 注册处理器时的选项
+type Option func(r *Route)
+
+// OwnTransaction 声明处理器自己管理事务
+func OwnTransaction() Option {
+	return func(r *Route) { r.OwnTransaction = true }
+}
+
+// Bus 命令总线或查询总线
+// 每种消息类型只能有一个处理器；中间件需在注册处理器之前通过构造函数传入
+type Bus struct {
+	kind       Kind
+	middleware []Middleware
+
+	mu       sync.RWMutex
+	handlers map[reflect.Type]HandlerFunc
+}
+
+// NewCommandBus 创建命令总线
+func NewCommandBus(middleware ...Middleware) *Bus {
+	return newBus(KindCommand, middleware)
+}
+
+// NewQueryBus 创建查询总线
+func NewQueryBus(middleware ...Middleware) *Bus {
+	return newBus(KindQuery, middleware)
+}
+
+func newBus(kind Kind, middleware []Middleware) *Bus {
+	return &Bus{
+		kind:       kind,
+		middleware: middleware,
+		handlers:   make(map[reflect.Type]HandlerFunc),
+	}
+}
+
+// Register 注册消息类型 M 的处理器，重复注册属于编程错误，直接 panic
+func Register[M any, R any](b *Bus, handler func(ctx context.Context, msg M) (R, error), opts ...Option) {
+	typ := reflect.TypeOf((*M)(nil)).Elem()
+	route := Route{Kind: b.kind, Name: typeName(typ)}
+	for _, opt := range opts {
+		opt(&route)
+	}
+
+	h := func(ctx context.Context, msg any) (any, error) {
+		return handler(ctx, msg.(M))
+	}
+	for i := len(b.middleware) - 1; i >= 0; i-- {
+		h = b.middleware[i](route, h)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if _, ok := b.handlers[typ]; ok {
+		panic(fmt.Sprintf("bus: %s handler for %s already registered", b.kind, route.Name))
+	}
+	b.handlers[typ] = h
+}
+
+// NoResult 把只返回错误的处理器适配为 Register 需要的形式
+func NoResult[M any](handler func(ctx context.Context, msg M) error) func(ctx context.Context, msg M) (struct{}, error) {
+	return func(ctx context.Context, msg M) (struct{}, error) {
+		return struct{}{}, handler(ctx, msg)
+	}
+}
+
+// Dispatch 分发消息并返回处理结果，R 必须与注册时的结果类型一致
+func Dispatch[R any](ctx context.Context, b *Bus, msg any) (R, error) {
+	var zero R
+	result, err := b.dispatch(ctx, msg)
+	if err != nil {
+		return zero, err
+	}
+	r, ok := result.(R)
+	if !ok {
+		return zero, fmt.Errorf("bus: %s returned %T, not %T", typeName(reflect.TypeOf(msg)), result, zero)
+	}
+	return r, nil
+}
+
+// Send 分发不关心结果的消息（通常是命令）
+func Send(ctx context.Context, b *Bus, msg any) error {
+	_, err := b.dispatch(ctx, msg)
+	return err
+}
+
+func (b *Bus) dispatch(ctx context.Context, msg any) (any, error) {
+	typ := reflect.TypeOf(msg)
+	b.mu.RLock()
+	h, ok := b.handlers[typ]
+	b.mu.RUnlock()
+	if !ok {
+		return nil, errors.Wrapf(ErrNoHandler, "%s %s", b.kind, typeName(typ))
+	}
+	return h(ctx, msg)
+}
+
+// typeName 消息类型名，去掉指针和包名
+func typeName(typ reflect.Type) string {
+	if typ == nil {
+		return "<nil>"
+	}
+	for typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	name := typ.String()
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	return name
+}
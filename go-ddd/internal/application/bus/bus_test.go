@@ -0,0 +1,198 @@
+package bus_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"yiwen/go-ddd/internal/application/bus"
+	"yiwen/go-ddd/internal/domain/event"
+	"yiwen/go-ddd/internal/domain/repository"
+	"yiwen/go-ddd/internal/infrastructure/persistence/memory"
+)
+
+// recordingPublisher 记录发布的事件
+type recordingPublisher struct {
+	events []event.Event
+}
+
+func (p *recordingPublisher) Publish(events ...event.Event) error {
+	p.events = append(p.events, events...)
+	return nil
+}
+
+func (p *recordingPublisher) ids() []string {
+	ids := make([]string, len(p.events))
+	for i, e := range p.events {
+		ids[i] = e.AggregateID()
+	}
+	return ids
+}
+
+// countingUoW 统计开启的工作单元数量
+type countingUoW struct {
+	repository.UnitOfWork
+	calls int
+}
+
+func (u *countingUoW) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	u.calls++
+	return u.UnitOfWork.Do(ctx, fn)
+}
+
+// deadlockOnce 第一次执行在提交时死锁，与 mysql.UnitOfWork 一样重新执行 fn
+type deadlockOnce struct {
+	repository.UnitOfWork
+}
+
+func (u deadlockOnce) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	return u.UnitOfWork.Do(ctx, func(ctx context.Context) error {
+		fn(ctx) // 这次执行的结果随事务回滚作废
+		return fn(ctx)
+	})
+}
+
+type outerCommand struct{ FailAfterInner bool }
+type innerCommand struct{}
+type ownTxCommand struct{}
+type lookupQuery struct{}
+
+func userEvent(id string) event.Event {
+	return event.NewUserEmailVerifiedEvent(id, id+"@example.com")
+}
+
+func TestTransactional(t *testing.T) {
+	errHandler := errors.New("handler failed")
+
+	newBuses := func(uow repository.UnitOfWork, publisher event.EventPublisher) (*bus.Bus, *bus.Bus) {
+		mw := bus.Transactional(uow, publisher)
+		return bus.NewCommandBus(mw), bus.NewQueryBus(mw)
+	}
+
+	t.Run("events are published after commit", func(t *testing.T) {
+		publisher := &recordingPublisher{}
+		uow := &countingUoW{UnitOfWork: memory.NewUnitOfWork()}
+		commands, _ := newBuses(uow, publisher)
+		bus.Register(commands, bus.NoResult(func(ctx context.Context, cmd innerCommand) error {
+			if !bus.DeferEvents(ctx, userEvent("uuid-1"), userEvent("uuid-2")) {
+				t.Error("expected events to be deferred inside the transaction")
+			}
+			if len(publisher.events) != 0 {
+				t.Error("events were published before commit")
+			}
+			return nil
+		}))
+
+		if err := bus.Send(context.Background(), commands, innerCommand{}); err != nil {
+			t.Fatal(err)
+		}
+		if got := fmt.Sprint(publisher.ids()); got != "[uuid-1 uuid-2]" || uow.calls != 1 {
+			t.Fatalf("published %s in %d transactions", got, uow.calls)
+		}
+	})
+
+	t.Run("events are dropped on rollback", func(t *testing.T) {
+		publisher := &recordingPublisher{}
+		commands, _ := newBuses(memory.NewUnitOfWork(), publisher)
+		bus.Register(commands, bus.NoResult(func(ctx context.Context, cmd innerCommand) error {
+			bus.DeferEvents(ctx, userEvent("uuid-1"))
+			return errHandler
+		}))
+
+		if err := bus.Send(context.Background(), commands, innerCommand{}); !errors.Is(err, errHandler) {
+			t.Fatalf("expected handler error, got %v", err)
+		}
+		if len(publisher.events) != 0 {
+			t.Fatalf("published %v after rollback", publisher.ids())
+		}
+	})
+
+	t.Run("events of a retried attempt are dropped", func(t *testing.T) {
+		publisher := &recordingPublisher{}
+		commands, _ := newBuses(deadlockOnce{memory.NewUnitOfWork()}, publisher)
+		attempts := 0
+		bus.Register(commands, bus.NoResult(func(ctx context.Context, cmd innerCommand) error {
+			attempts++
+			bus.DeferEvents(ctx, userEvent(fmt.Sprintf("attempt-%d", attempts)))
+			return nil
+		}))
+
+		if err := bus.Send(context.Background(), commands, innerCommand{}); err != nil {
+			t.Fatal(err)
+		}
+		if got := fmt.Sprint(publisher.ids()); attempts != 2 || got != "[attempt-2]" {
+			t.Fatalf("published %s after %d attempts", got, attempts)
+		}
+	})
+
+	t.Run("nested dispatch joins the outer transaction", func(t *testing.T) {
+		for _, fail := range []bool{false, true} {
+			publisher := &recordingPublisher{}
+			uow := &countingUoW{UnitOfWork: memory.NewUnitOfWork()}
+			commands, _ := newBuses(uow, publisher)
+			bus.Register(commands, bus.NoResult(func(ctx context.Context, cmd innerCommand) error {
+				bus.DeferEvents(ctx, userEvent("inner"))
+				return nil
+			}))
+			bus.Register(commands, bus.NoResult(func(ctx context.Context, cmd outerCommand) error {
+				bus.DeferEvents(ctx, userEvent("outer"))
+				if err := bus.Send(ctx, commands, innerCommand{}); err != nil {
+					return err
+				}
+				if len(publisher.events) != 0 {
+					t.Error("inner events were published before the outer transaction committed")
+				}
+				if cmd.FailAfterInner {
+					return errHandler
+				}
+				return nil
+			}))
+
+			err := bus.Send(context.Background(), commands, outerCommand{FailAfterInner: fail})
+			if uow.calls != 1 {
+				t.Fatalf("expected a single transaction, got %d", uow.calls)
+			}
+			want := "[outer inner]"
+			if fail {
+				if !errors.Is(err, errHandler) {
+					t.Fatalf("expected handler error, got %v", err)
+				}
+				want = "[]"
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if got := fmt.Sprint(publisher.ids()); got != want {
+				t.Fatalf("fail=%v: published %s, want %s", fail, got, want)
+			}
+		}
+	})
+
+	t.Run("own transaction and queries are not wrapped", func(t *testing.T) {
+		publisher := &recordingPublisher{}
+		uow := &countingUoW{UnitOfWork: memory.NewUnitOfWork()}
+		commands, queries := newBuses(uow, publisher)
+		bus.Register(commands, bus.NoResult(func(ctx context.Context, cmd ownTxCommand) error {
+			if bus.DeferEvents(ctx, userEvent("own")) {
+				t.Error("events must not be deferred outside a Transactional transaction")
+			}
+			return nil
+		}), bus.OwnTransaction())
+		bus.Register(queries, func(ctx context.Context, q lookupQuery) (string, error) {
+			if bus.DeferEvents(ctx, userEvent("query")) {
+				t.Error("queries must not run in a transaction")
+			}
+			return "ok", nil
+		})
+
+		if err := bus.Send(context.Background(), commands, ownTxCommand{}); err != nil {
+			t.Fatal(err)
+		}
+		if result, err := bus.Dispatch[string](context.Background(), queries, lookupQuery{}); err != nil || result != "ok" {
+			t.Fatalf("query returned %q, %v", result, err)
+		}
+		if uow.calls != 0 || len(publisher.events) != 0 {
+			t.Fatalf("expected no transaction and no published events, got %d transactions and %v", uow.calls, publisher.ids())
+		}
+	})
+}
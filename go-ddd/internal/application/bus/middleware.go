@@ -0,0 +1,112 @@
+package bus
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"yiwen/go-ddd/internal/domain/event"
+	"yiwen/go-ddd/internal/domain/repository"
+)
+
+// Logging 记录每个命令、查询的名称、耗时和错误
+func Logging() Middleware {
+	return func(route Route, next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, msg any) (any, error) {
+			start := time.Now()
+			result, err := next(ctx, msg)
+			if err != nil {
+				log.Printf("[%s] %s failed in %s: %v", route.Kind, route.Name, time.Since(start), err)
+			} else {
+				log.Printf("[%s] %s ok in %s", route.Kind, route.Name, time.Since(start))
+			}
+			return result, err
+		}
+	}
+}
+
+// Validator 需要校验参数的命令、查询实现该接口
+type Validator interface {
+	Validate() error
+}
+
+// ValidationError 命令或查询的参数不合法，处理器不会被调用
+type ValidationError struct {
+	Name string
+	Err  error
+}
+
+func (e *ValidationError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ValidationError) Unwrap() error {
+	return e.Err
+}
+
+// Validation 调用处理器前校验实现了 Validator 的消息
+func Validation() Middleware {
+	return func(route Route, next HandlerFunc) HandlerFunc {
+		return func(ctx context.Context, msg any) (any, error) {
+			if v, ok := msg.(Validator); ok {
+				if err := v.Validate(); err != nil {
+					return nil, &ValidationError{Name: route.Name, Err: err}
+				}
+			}
+			return next(ctx, msg)
+		}
+	}
+}
+
+type eventBufferKey struct{}
+
+// eventBuffer 事务中产生、等待提交后发布的事件
+type eventBuffer struct {
+	events []event.Event
+}
+
+// DeferEvents 在 Transactional 开启的事务中暂存领域事件，事务提交后由中间件统一发布
+// 不在这样的事务中时返回 false，调用方应在自己的事务提交后发布
+func DeferEvents(ctx context.Context, events ...event.Event) bool {
+	buf, ok := ctx.Value(eventBufferKey{}).(*eventBuffer)
+	if !ok {
+		return false
+	}
+	buf.events = append(buf.events, events...)
+	return true
+}
+
+// Transactional 在工作单元中执行命令处理器，处理器内的仓储操作和嵌套的工作单元共享同一事务
+// 处理器产生的领域事件等事务提交后才发布，回滚时丢弃；查询和声明了 OwnTransaction 的处理器不受影响
+func Transactional(uow repository.UnitOfWork, publisher event.EventPublisher) Middleware {
+	return func(route Route, next HandlerFunc) HandlerFunc {
+		if route.Kind != KindCommand || route.OwnTransaction {
+			return next
+		}
+		return func(ctx context.Context, msg any) (any, error) {
+			// 命令处理器中再分发的命令加入外层事务
+			if _, ok := ctx.Value(eventBufferKey{}).(*eventBuffer); ok {
+				return next(ctx, msg)
+			}
+
+			var result any
+			var buf *eventBuffer
+			err := uow.Do(ctx, func(ctx context.Context) error {
+				buf = &eventBuffer{} // 死锁重试时丢弃上一次执行暂存的事件
+				var err error
+				result, err = next(context.WithValue(ctx, eventBufferKey{}, buf), msg)
+				return err
+			})
+			if err != nil {
+				return nil, err
+			}
+
+			if publisher != nil && len(buf.events) > 0 {
+				if err := publisher.Publish(buf.events...); err != nil {
+					log.Printf("failed to publish domain events: %v", err)
+				}
+			}
+			return result, nil
+		}
+	}
+}
@@ -1,5 +1,10 @@
 package command
 
+import (
+	"io"
+	"time"
+)
+
 // Command 命令模式
 // CQRS (Command Query Responsibility Segregation) 命令查询职责分离
 // 命令用于写操作，改变系统状态
@@ -39,6 +44,25 @@ func NewUpdateProfileCommand(userID uint64, nickname, avatar string) *UpdateProf
 	}
 }
 
+// UploadAvatarCommand 上传头像命令
+// ContentType 和 Size 为客户端声明的值，只用于提前拒绝，图片格式以文件内容为准
+type UploadAvatarCommand struct {
+	UserID      uint64
+	ContentType string
+	Size        int64
+	File        io.Reader
+}
+
+// NewUploadAvatarCommand 创建上传头像命令
+func NewUploadAvatarCommand(userID uint64, contentType string, size int64, file io.Reader) *UploadAvatarCommand {
+	return &UploadAvatarCommand{
+		UserID:      userID,
+		ContentType: contentType,
+		Size:        size,
+		File:        file,
+	}
+}
+
 // ChangePasswordCommand 修改密码命令
 type ChangePasswordCommand struct {
 	UserID      uint64
@@ -81,6 +105,46 @@ func NewBanUserCommand(userID uint64, reason string) *BanUserCommand {
 	}
 }
 
+// UnbanUserCommand 解除禁用命令
+type UnbanUserCommand struct {
+	UserID uint64
+	Reason string
+}
+
+// NewUnbanUserCommand 创建解除禁用命令
+func NewUnbanUserCommand(userID uint64, reason string) *UnbanUserCommand {
+	return &UnbanUserCommand{
+		UserID: userID,
+		Reason: reason,
+	}
+}
+
+// ActivateUserCommand 激活用户命令
+type ActivateUserCommand struct {
+	UserID uint64
+}
+
+// NewActivateUserCommand 创建激活用户命令
+func NewActivateUserCommand(userID uint64) *ActivateUserCommand {
+	return &ActivateUserCommand{
+		UserID: userID,
+	}
+}
+
+// DeactivateUserCommand 停用用户命令
+type DeactivateUserCommand struct {
+	UserID uint64
+	Reason string
+}
+
+// NewDeactivateUserCommand 创建停用用户命令
+func NewDeactivateUserCommand(userID uint64, reason string) *DeactivateUserCommand {
+	return &DeactivateUserCommand{
+		UserID: userID,
+		Reason: reason,
+	}
+}
+
 // PromoteUserCommand 提升用户为管理员命令
 type PromoteUserCommand struct {
 	UserID uint64
@@ -92,3 +156,219 @@ func NewPromoteUserCommand(userID uint64) *PromoteUserCommand {
 		UserID: userID,
 	}
 }
+
+// DemoteUserCommand 管理员降级命令
+type DemoteUserCommand struct {
+	UserID uint64
+	Reason string
+}
+
+// NewDemoteUserCommand 创建管理员降级命令
+func NewDemoteUserCommand(userID uint64, reason string) *DemoteUserCommand {
+	return &DemoteUserCommand{
+		UserID: userID,
+		Reason: reason,
+	}
+}
+
+// UnlockUserCommand 解除登录锁定命令
+type UnlockUserCommand struct {
+	UserID uint64
+	Reason string
+}
+
+// NewUnlockUserCommand 创建解除登录锁定命令
+func NewUnlockUserCommand(userID uint64, reason string) *UnlockUserCommand {
+	return &UnlockUserCommand{
+		UserID: userID,
+		Reason: reason,
+	}
+}
+
+// TransferAdminCommand 管理员权限转移命令
+type TransferAdminCommand struct {
+	FromUserID uint64
+	ToUserID   uint64
+	Reason     string
+}
+
+// NewTransferAdminCommand 创建管理员权限转移命令
+func NewTransferAdminCommand(fromUserID, toUserID uint64, reason string) *TransferAdminCommand {
+	return &TransferAdminCommand{
+		FromUserID: fromUserID,
+		ToUserID:   toUserID,
+		Reason:     reason,
+	}
+}
+
+// RefreshTokenCommand 刷新令牌命令
+type RefreshTokenCommand struct {
+	RefreshToken string
+}
+
+// NewRefreshTokenCommand 创建刷新令牌命令
+func NewRefreshTokenCommand(refreshToken string) *RefreshTokenCommand {
+	return &RefreshTokenCommand{
+		RefreshToken: refreshToken,
+	}
+}
+
+// LogoutCommand 登出命令
+type LogoutCommand struct {
+	UserID          uint64
+	AccessTokenID   string    // 当前访问令牌的 jti
+	AccessExpiresAt time.Time // 当前访问令牌的过期时间
+	RefreshToken    string    // 可选，提供时吊销其所在的令牌家族
+}
+
+// NewLogoutCommand 创建登出命令
+func NewLogoutCommand(userID uint64, accessTokenID string, accessExpiresAt time.Time, refreshToken string) *LogoutCommand {
+	return &LogoutCommand{
+		UserID:          userID,
+		AccessTokenID:   accessTokenID,
+		AccessExpiresAt: accessExpiresAt,
+		RefreshToken:    refreshToken,
+	}
+}
+
+// ResendVerificationCommand 重新发送验证邮件命令
+type ResendVerificationCommand struct {
+	Email string
+}
+
+// NewResendVerificationCommand 创建重新发送验证邮件命令
+func NewResendVerificationCommand(email string) *ResendVerificationCommand {
+	return &ResendVerificationCommand{
+		Email: email,
+	}
+}
+
+// VerifyEmailCommand 验证邮箱命令
+type VerifyEmailCommand struct {
+	Token string
+}
+
+// NewVerifyEmailCommand 创建验证邮箱命令
+func NewVerifyEmailCommand(token string) *VerifyEmailCommand {
+	return &VerifyEmailCommand{
+		Token: token,
+	}
+}
+
+// RequestPasswordResetCommand 申请重置密码命令
+type RequestPasswordResetCommand struct {
+	Email string
+}
+
+// NewRequestPasswordResetCommand 创建申请重置密码命令
+func NewRequestPasswordResetCommand(email string) *RequestPasswordResetCommand {
+	return &RequestPasswordResetCommand{
+		Email: email,
+	}
+}
+
+// ResetPasswordCommand 重置密码命令
+type ResetPasswordCommand struct {
+	Token       string
+	NewPassword string
+}
+
+// NewResetPasswordCommand 创建重置密码命令
+func NewResetPasswordCommand(token, newPassword string) *ResetPasswordCommand {
+	return &ResetPasswordCommand{
+		Token:       token,
+		NewPassword: newPassword,
+	}
+}
+
+// EnrollMFACommand 开始绑定两步验证命令
+type EnrollMFACommand struct {
+	UserID uint64
+}
+
+// NewEnrollMFACommand 创建开始绑定两步验证命令
+func NewEnrollMFACommand(userID uint64) *EnrollMFACommand {
+	return &EnrollMFACommand{
+		UserID: userID,
+	}
+}
+
+// ConfirmMFACommand 确认绑定两步验证命令
+type ConfirmMFACommand struct {
+	UserID uint64
+	Code   string
+}
+
+// NewConfirmMFACommand 创建确认绑定两步验证命令
+func NewConfirmMFACommand(userID uint64, code string) *ConfirmMFACommand {
+	return &ConfirmMFACommand{
+		UserID: userID,
+		Code:   code,
+	}
+}
+
+// DisableMFACommand 关闭两步验证命令，需要提供当前验证码或恢复码
+type DisableMFACommand struct {
+	UserID uint64
+	Code   string
+}
+
+// NewDisableMFACommand 创建关闭两步验证命令
+func NewDisableMFACommand(userID uint64, code string) *DisableMFACommand {
+	return &DisableMFACommand{
+		UserID: userID,
+		Code:   code,
+	}
+}
+
+// MFAChallengeCommand 使用登录时签发的两步验证令牌完成登录或绑定
+type MFAChallengeCommand struct {
+	Token    string
+	Code     string // 验证码或恢复码，开始绑定时为空
+	ClientIP string // 用于按IP统计失败次数，可以为空
+}
+
+// NewMFAChallengeCommand 创建两步验证令牌命令
+func NewMFAChallengeCommand(token, code, clientIP string) *MFAChallengeCommand {
+	return &MFAChallengeCommand{
+		Token:    token,
+		Code:     code,
+		ClientIP: clientIP,
+	}
+}
+
+// ExportUserDataCommand 导出个人数据命令（记录 user.data_exported 事件）
+type ExportUserDataCommand struct {
+	UserID uint64
+}
+
+// NewExportUserDataCommand 创建导出个人数据命令
+func NewExportUserDataCommand(userID uint64) *ExportUserDataCommand {
+	return &ExportUserDataCommand{
+		UserID: userID,
+	}
+}
+
+// RequestErasureCommand 申请删除账户命令，宽限期结束后擦除个人数据
+type RequestErasureCommand struct {
+	UserID uint64
+}
+
+// NewRequestErasureCommand 创建申请删除账户命令
+func NewRequestErasureCommand(userID uint64) *RequestErasureCommand {
+	return &RequestErasureCommand{
+		UserID: userID,
+	}
+}
+
+// CancelErasureCommand 撤销删除账户申请命令
+type CancelErasureCommand struct {
+	UserID uint64
+}
+
+// NewCancelErasureCommand 创建撤销删除账户申请命令
+func NewCancelErasureCommand(userID uint64) *CancelErasureCommand {
+	return &CancelErasureCommand{
+		UserID: userID,
+	}
+}
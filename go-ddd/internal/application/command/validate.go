@@ -0,0 +1,107 @@
+package command
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// 命令的参数校验，由命令总线的 Validation 中间件在调用处理器之前执行
+// 这里只检查命令本身是否完整，业务规则（密码策略、原因必填等）仍由领域层保证
+
+var (
+	ErrUserIDRequired   = errors.New("user id is required")
+	ErrFieldRequired    = errors.New("required field is empty")
+	ErrSameUserTransfer = errors.New("cannot transfer admin to the same user")
+)
+
+func requireUserID(id uint64) error {
+	if id == 0 {
+		return ErrUserIDRequired
+	}
+	return nil
+}
+
+// requireFields 依次检查 name, value 成对给出的字段，返回第一个为空的字段
+func requireFields(pairs ...string) error {
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if strings.TrimSpace(pairs[i+1]) == "" {
+			return fmt.Errorf("%w: %s", ErrFieldRequired, pairs[i])
+		}
+	}
+	return nil
+}
+
+// Validate 校验注册命令
+func (c *RegisterUserCommand) Validate() error {
+	return requireFields("username", c.Username, "email", c.Email, "password", c.Password)
+}
+
+// Validate 校验更新资料命令
+func (c *UpdateProfileCommand) Validate() error { return requireUserID(c.UserID) }
+
+// Validate 校验上传头像命令
+func (c *UploadAvatarCommand) Validate() error {
+	if err := requireUserID(c.UserID); err != nil {
+		return err
+	}
+	if c.File == nil {
+		return fmt.Errorf("%w: avatar", ErrFieldRequired)
+	}
+	return nil
+}
+
+// Validate 校验修改密码命令
+func (c *ChangePasswordCommand) Validate() error {
+	if err := requireUserID(c.UserID); err != nil {
+		return err
+	}
+	return requireFields("old_password", c.OldPassword, "new_password", c.NewPassword)
+}
+
+// Validate 校验删除用户命令
+func (c *DeleteUserCommand) Validate() error { return requireUserID(c.UserID) }
+
+// Validate 校验导出个人数据命令
+func (c *ExportUserDataCommand) Validate() error { return requireUserID(c.UserID) }
+
+// Validate 校验申请删除账户命令
+func (c *RequestErasureCommand) Validate() error { return requireUserID(c.UserID) }
+
+// Validate 校验撤销删除账户申请命令
+func (c *CancelErasureCommand) Validate() error { return requireUserID(c.UserID) }
+
+// Validate 校验禁用用户命令
+func (c *BanUserCommand) Validate() error { return requireUserID(c.UserID) }
+
+// Validate 校验解除禁用命令
+func (c *UnbanUserCommand) Validate() error { return requireUserID(c.UserID) }
+
+// Validate 校验激活用户命令
+func (c *ActivateUserCommand) Validate() error { return requireUserID(c.UserID) }
+
+// Validate 校验停用用户命令
+func (c *DeactivateUserCommand) Validate() error { return requireUserID(c.UserID) }
+
+// Validate 校验提升用户命令
+func (c *PromoteUserCommand) Validate() error { return requireUserID(c.UserID) }
+
+// Validate 校验降级命令
+func (c *DemoteUserCommand) Validate() error { return requireUserID(c.UserID) }
+
+// Validate 校验解除登录锁定命令
+func (c *UnlockUserCommand) Validate() error { return requireUserID(c.UserID) }
+
+// Validate 校验管理员权限转移命令
+func (c *TransferAdminCommand) Validate() error {
+	if err := requireUserID(c.FromUserID); err != nil {
+		return err
+	}
+	if err := requireUserID(c.ToUserID); err != nil {
+		return err
+	}
+	if c.FromUserID == c.ToUserID {
+		return ErrSameUserTransfer
+	}
+	return nil
+}
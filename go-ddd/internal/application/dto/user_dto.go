@@ -3,6 +3,7 @@ package dto
 import (
 	"time"
 
+	"yiwen/go-ddd/internal/application/port"
 	"yiwen/go-ddd/internal/domain/entity"
 )
 
@@ -17,7 +18,7 @@ import (
 type RegisterRequest struct {
 	Username string `json:"username" binding:"required,min=3,max=50"`
 	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=8"`
+	Password string `json:"password" binding:"required"`
 	Nickname string `json:"nickname" binding:"max=50"`
 }
 
@@ -29,9 +30,21 @@ type LoginRequest struct {
 
 // LoginResponse 登录响应
 type LoginResponse struct {
-	Token     string   `json:"token"`
-	ExpiresAt int64    `json:"expires_at"`
-	User      UserDTO  `json:"user"`
+	Token            string  `json:"token"`
+	ExpiresAt        int64   `json:"expires_at"`
+	RefreshToken     string  `json:"refresh_token"`
+	RefreshExpiresAt int64   `json:"refresh_expires_at"`
+	User             UserDTO `json:"user"`
+}
+
+// RefreshRequest 刷新令牌请求
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// LogoutRequest 登出请求，提供刷新令牌时一并吊销
+type LogoutRequest struct {
+	RefreshToken string `json:"refresh_token"`
 }
 
 // UpdateProfileRequest 更新资料请求
@@ -43,7 +56,60 @@ type UpdateProfileRequest struct {
 // ChangePasswordRequest 修改密码请求
 type ChangePasswordRequest struct {
 	OldPassword string `json:"old_password" binding:"required"`
-	NewPassword string `json:"new_password" binding:"required,min=8"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+// EmailRequest 只包含邮箱的请求（重新发送验证邮件、申请重置密码）
+type EmailRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// TokenRequest 只包含一次性令牌的请求（验证邮箱）
+type TokenRequest struct {
+	Token string `json:"token" form:"token" binding:"required"`
+}
+
+// ResetPasswordRequest 重置密码请求
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required"`
+	NewPassword string `json:"new_password" binding:"required"`
+}
+
+// MFACodeRequest 两步验证码请求（确认绑定、关闭两步验证）
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+// MFATokenRequest 使用登录时签发的两步验证令牌的请求
+type MFATokenRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+}
+
+// MFALoginRequest 登录第二步：两步验证令牌 + 验证码或恢复码
+type MFALoginRequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+// MFAChallengeDTO 密码正确但还需要第二因素时的登录响应
+// EnrollmentRequired 为 true 表示策略要求启用两步验证但用户尚未绑定，需先完成绑定
+type MFAChallengeDTO struct {
+	MFARequired        bool   `json:"mfa_required"`
+	EnrollmentRequired bool   `json:"enrollment_required"`
+	MFAToken           string `json:"mfa_token"`
+	ExpiresAt          int64  `json:"expires_at"`
+}
+
+// MFAEnrollmentDTO 开始绑定两步验证的响应，密钥和恢复码只返回这一次
+type MFAEnrollmentDTO struct {
+	Secret        string   `json:"secret"`
+	OTPAuthURI    string   `json:"otpauth_uri"`
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// ReasonRequest 管理操作请求（禁用、解禁、停用、降级需要填写原因）
+type ReasonRequest struct {
+	Reason string `json:"reason" binding:"max=255"`
 }
 
 // UserDTO 用户响应DTO
@@ -57,12 +123,74 @@ type UserDTO struct {
 	Status    int       `json:"status"`
 	Role      string    `json:"role"`
 	CreatedAt time.Time `json:"created_at"`
+
+	EmailVerified bool       `json:"email_verified"`
+	LockedUntil   *time.Time `json:"locked_until,omitempty"` // 仍在锁定期内时返回
+	MFAEnabled    bool       `json:"mfa_enabled"`
+}
+
+// AvatarDTO 上传头像的响应，Thumbnails 以边长为键，Avatar 为最大的一张
+type AvatarDTO struct {
+	Avatar     string            `json:"avatar"`
+	Thumbnails map[string]string `json:"thumbnails"`
+	User       UserDTO           `json:"user"`
+}
+
+// UserDataExportDTO 用户个人数据导出，包含账户资料和该用户的全部领域事件
+type UserDataExportDTO struct {
+	ExportedAt time.Time            `json:"exported_at"`
+	User       UserDataDTO          `json:"user"`
+	Events     []port.RecordedEvent `json:"events"`
+}
+
+// UserDataDTO 导出的账户资料，不含密码哈希、两步验证密钥等凭据
+type UserDataDTO struct {
+	UserDTO
+	UpdatedAt          time.Time  `json:"updated_at"`
+	EmailVerifiedAt    *time.Time `json:"email_verified_at,omitempty"`
+	ErasureRequestedAt *time.Time `json:"erasure_requested_at,omitempty"`
+	ErasureScheduledAt *time.Time `json:"erasure_scheduled_at,omitempty"`
+}
+
+// ErasureDTO 删除账户申请的状态
+type ErasureDTO struct {
+	UserID      uint64    `json:"user_id"`
+	UUID        string    `json:"uuid"`
+	Username    string    `json:"username"`
+	RequestedAt time.Time `json:"requested_at"`
+	ScheduledAt time.Time `json:"scheduled_at"`
+}
+
+// ToUserDataDTO 将实体转换为导出的账户资料
+func ToUserDataDTO(user *entity.User) UserDataDTO {
+	return UserDataDTO{
+		UserDTO:            ToUserDTO(user),
+		UpdatedAt:          user.UpdatedAt,
+		EmailVerifiedAt:    user.EmailVerifiedAt,
+		ErasureRequestedAt: user.ErasureRequestedAt,
+		ErasureScheduledAt: user.ErasureScheduledAt,
+	}
+}
+
+// ToErasureDTO 将等待擦除的用户转换为删除申请，未申请时返回 nil
+func ToErasureDTO(user *entity.User) *ErasureDTO {
+	if !user.IsErasurePending() {
+		return nil
+	}
+	return &ErasureDTO{
+		UserID:      user.ID,
+		UUID:        user.UUID,
+		Username:    user.Username,
+		RequestedAt: *user.ErasureRequestedAt,
+		ScheduledAt: *user.ErasureScheduledAt,
+	}
 }
 
 // UserListDTO 用户列表响应DTO
 type UserListDTO struct {
-	Total int64     `json:"total"`
-	Items []UserDTO `json:"items"`
+	Total      int64     `json:"total"`
+	Items      []UserDTO `json:"items"`
+	NextCursor string    `json:"next_cursor,omitempty"` // 还有下一页时返回，作为下次请求的 cursor
 }
 
 // ToUserDTO 将实体转换为DTO
@@ -77,9 +205,43 @@ func ToUserDTO(user *entity.User) UserDTO {
 		Status:    int(user.Status),
 		Role:      string(user.Role),
 		CreatedAt: user.CreatedAt,
+
+		EmailVerified: user.IsEmailVerified(),
+		LockedUntil:   lockedUntil(user),
+		MFAEnabled:    user.IsMFAEnabled(),
 	}
 }
 
+// lockedUntil 返回仍然有效的锁定截止时间
+func lockedUntil(user *entity.User) *time.Time {
+	if !user.IsLocked(time.Now()) {
+		return nil
+	}
+	return user.LockedUntil
+}
+
+// FromUserView 将读模型转换为DTO
+func FromUserView(v port.UserView) UserDTO {
+	dto := UserDTO{
+		ID:        v.ID,
+		UUID:      v.UUID,
+		Username:  v.Username,
+		Email:     v.Email,
+		Nickname:  v.Nickname,
+		Avatar:    v.Avatar,
+		Status:    v.Status,
+		Role:      v.Role,
+		CreatedAt: v.CreatedAt,
+
+		EmailVerified: v.EmailVerified,
+		MFAEnabled:    v.MFAEnabled,
+	}
+	if v.LockedUntil != nil && time.Now().Before(*v.LockedUntil) {
+		dto.LockedUntil = v.LockedUntil
+	}
+	return dto
+}
+
 // ToUserDTOList 将实体列表转换为DTO列表
 func ToUserDTOList(users []*entity.User) []UserDTO {
 	dtos := make([]UserDTO, len(users))
@@ -91,8 +253,8 @@ func ToUserDTOList(users []*entity.User) []UserDTO {
 
 // PaginationRequest 分页请求
 type PaginationRequest struct {
-	Page     int `form:"page" binding:"min=1"`
-	PageSize int `form:"page_size" binding:"min=1,max=100"`
+	Page     int `form:"page" binding:"omitempty,min=1"`
+	PageSize int `form:"page_size" binding:"omitempty,min=1,max=100"`
 }
 
 // GetOffset 计算偏移量
@@ -116,3 +278,25 @@ func (p *PaginationRequest) GetLimit() int {
 	}
 	return p.PageSize
 }
+
+// ListUsersRequest 用户列表请求，过滤、排序参数都可以省略
+// 时间使用 RFC 3339 格式；cursor 非空时按游标翻页，忽略 page
+type ListUsersRequest struct {
+	PaginationRequest
+	Status        int       `form:"status" binding:"omitempty,oneof=1 2 3 4"`
+	Role          string    `form:"role" binding:"omitempty,oneof=user admin"`
+	CreatedFrom   time.Time `form:"created_from" time_format:"2006-01-02T15:04:05Z07:00"`
+	CreatedBefore time.Time `form:"created_before" time_format:"2006-01-02T15:04:05Z07:00"`
+	Keyword       string    `form:"q" binding:"max=100"`
+	Sort          string    `form:"sort" binding:"omitempty,oneof=id created_at username"`
+	Order         string    `form:"order" binding:"omitempty,oneof=asc desc"`
+	Cursor        string    `form:"cursor"`
+}
+
+// IsDesc 是否倒序；未指定时用户名正序，ID 和创建时间倒序（最新的在前）
+func (r *ListUsersRequest) IsDesc() bool {
+	if r.Order == "" {
+		return r.Sort != "username"
+	}
+	return r.Order == "desc"
+}
@@ -0,0 +1,30 @@
+package port
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrInvalidActionToken 令牌格式错误、签名不匹配、用途不符或已过期
+var ErrInvalidActionToken = errors.New("invalid or expired token")
+
+// 一次性操作令牌的用途
+const (
+	PurposeVerifyEmail   = "verify_email"
+	PurposeResetPassword = "reset_password"
+	PurposeMFALogin      = "mfa_login"  // 密码已校验，等待第二因素
+	PurposeMFAEnroll     = "mfa_enroll" // 密码已校验，策略要求先绑定两步验证
+)
+
+// ActionTokenSigner 签发和校验带有效期的一次性操作令牌（邮箱验证、密码重置、两步验证登录）
+// 签名时绑定一个状态值（如邮箱、密码哈希），状态改变后令牌自动失效，无需存储令牌
+type ActionTokenSigner interface {
+	// Sign 为 subject 签发用于 purpose 的令牌
+	Sign(purpose, subject, state string, ttl time.Duration) (string, error)
+
+	// Subject 读取令牌中的 subject（不校验签名），用于查找校验所需的状态
+	Subject(token string) (string, error)
+
+	// Verify 校验签名、用途、有效期以及签名时绑定的状态
+	Verify(token, purpose, state string) error
+}
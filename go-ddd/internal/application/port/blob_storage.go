@@ -0,0 +1,19 @@
+package port
+
+import (
+	"context"
+	"io"
+)
+
+// BlobStorage 文件存储端口（本地文件系统、S3 兼容的对象存储等）
+// key 为以 / 分隔的相对路径，如 avatars/<uuid>/<id>/256.jpg
+type BlobStorage interface {
+	// Put 写入文件，已存在时覆盖
+	Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error
+	// DeletePrefix 删除 key 以 prefix 开头的所有文件，prefix 必须以 / 结尾；没有匹配的文件时不报错
+	DeletePrefix(ctx context.Context, prefix string) error
+	// URL 返回文件的公开访问地址
+	URL(key string) string
+	// KeyOf 由公开访问地址反查 key，不是本存储的地址时返回 false
+	KeyOf(url string) (string, bool)
+}
@@ -0,0 +1,26 @@
+package port
+
+import (
+	"errors"
+	"io"
+)
+
+var (
+	ErrUnsupportedImage = errors.New("unsupported image format, expected jpeg, png or gif")
+	ErrImageTooLarge    = errors.New("image is too large")
+)
+
+// ImageVariant 处理后的一张图片
+type ImageVariant struct {
+	Size        int // 正方形边长（像素）
+	ContentType string
+	Ext         string // 文件扩展名，不含点
+	Data        []byte
+}
+
+// ImageProcessor 图片处理端口
+type ImageProcessor interface {
+	// Thumbnails 按文件内容识别格式并解码，居中裁剪为正方形后按 sizes 缩放
+	// 输出重新编码，不保留 EXIF 等元数据；不是支持的格式时返回 ErrUnsupportedImage，像素过多时返回 ErrImageTooLarge
+	Thumbnails(r io.Reader, sizes []int) ([]ImageVariant, error)
+}
@@ -0,0 +1,17 @@
+// Package port 应用层依赖的外部能力（端口）
+// 应用层只依赖这里的接口，具体实现（适配器）位于基础设施层，在 main 中注入
+package port
+
+import "context"
+
+// Mail 待发送的邮件（纯文本）
+type Mail struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer 邮件发送端口
+type Mailer interface {
+	Send(ctx context.Context, mail Mail) error
+}
@@ -0,0 +1,22 @@
+package port
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// RecordedEvent 事件日志中的一条记录，Payload 为脱敏后的事件 JSON
+type RecordedEvent struct {
+	Name       string          `json:"name"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Payload    json.RawMessage `json:"payload"`
+}
+
+// UserEventLog 用户的领域事件记录，用于导出个人数据和擦除时清理历史
+type UserEventLog interface {
+	// Events 按发生顺序返回聚合的全部事件
+	Events(ctx context.Context, aggregateID string) ([]RecordedEvent, error)
+	// Erase 删除聚合的全部事件；在事务中调用时与事务一起提交
+	Erase(ctx context.Context, aggregateID string) error
+}
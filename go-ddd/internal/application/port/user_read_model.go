@@ -0,0 +1,45 @@
+package port
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"yiwen/go-ddd/internal/domain/repository"
+)
+
+var ErrUserViewNotFound = errors.New("user not found")
+
+// UserView 用户读模型的一行：查询需要的字段已经展开，不含密码哈希、两步验证密钥等敏感数据
+type UserView struct {
+	ID        uint64
+	UUID      string
+	Username  string
+	Email     string
+	Nickname  string
+	Avatar    string
+	Status    int
+	Role      string
+	CreatedAt time.Time
+	UpdatedAt time.Time
+
+	EmailVerified bool
+	LockedUntil   *time.Time
+	MFAEnabled    bool
+}
+
+// UserReadModel 用户读模型（CQRS 查询端）
+// 由事件投影维护，查询只读它，不加载聚合；与写模型之间是最终一致的
+// List 的过滤、排序和游标语义与 repository.UserRepository.List 相同
+type UserReadModel interface {
+	FindByID(ctx context.Context, id uint64) (*UserView, error)
+	FindByUUID(ctx context.Context, uuid string) (*UserView, error)
+	List(ctx context.Context, opts repository.UserListOptions) ([]UserView, int64, error)
+
+	// Upsert 按 ID 写入或覆盖一行
+	Upsert(ctx context.Context, view UserView) error
+	// Delete 删除一行，不存在时不报错
+	Delete(ctx context.Context, uuid string) error
+	// Reset 清空读模型，用于全量重建
+	Reset(ctx context.Context) error
+}
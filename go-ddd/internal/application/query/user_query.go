@@ -1,5 +1,10 @@
 package query
 
+import (
+	"errors"
+	"time"
+)
+
 // Query 查询模式
 // CQRS 中的查询部分，用于读操作
 // 查询不改变系统状态，只返回数据
@@ -41,12 +46,23 @@ func NewGetUserByUsernameQuery(username string) *GetUserByUsernameQuery {
 }
 
 // ListUsersQuery 查询用户列表
+// 过滤字段为零值时不过滤；Cursor 非空时使用键集分页，忽略 Offset
 type ListUsersQuery struct {
 	Offset int
 	Limit  int
+
+	Status        int        // 状态，0 表示不限
+	Role          string     // 角色，空表示不限
+	CreatedFrom   *time.Time // 创建时间下限（含）
+	CreatedBefore *time.Time // 创建时间上限（不含）
+	Keyword       string     // 匹配用户名、邮箱或昵称
+
+	SortBy string // id、created_at 或 username，空表示 id
+	Desc   bool
+	Cursor string // 上一页返回的 next_cursor
 }
 
-// NewListUsersQuery 创建查询用户列表查询
+// NewListUsersQuery 创建查询用户列表查询，默认按ID倒序
 func NewListUsersQuery(offset, limit int) *ListUsersQuery {
 	if limit <= 0 {
 		limit = 10
@@ -57,6 +73,8 @@ func NewListUsersQuery(offset, limit int) *ListUsersQuery {
 	return &ListUsersQuery{
 		Offset: offset,
 		Limit:  limit,
+		SortBy: "id",
+		Desc:   true,
 	}
 }
 
@@ -64,12 +82,46 @@ func NewListUsersQuery(offset, limit int) *ListUsersQuery {
 type LoginQuery struct {
 	Username string
 	Password string
+	ClientIP string // 用于按IP统计登录失败，可以为空
 }
 
 // NewLoginQuery 创建登录查询
-func NewLoginQuery(username, password string) *LoginQuery {
+func NewLoginQuery(username, password, clientIP string) *LoginQuery {
 	return &LoginQuery{
 		Username: username,
 		Password: password,
+		ClientIP: clientIP,
+	}
+}
+
+// ListPendingErasuresQuery 查询等待擦除个人数据的删除申请
+type ListPendingErasuresQuery struct{}
+
+// NewListPendingErasuresQuery 创建查询删除申请查询
+func NewListPendingErasuresQuery() *ListPendingErasuresQuery {
+	return &ListPendingErasuresQuery{}
+}
+
+// Validate 校验根据ID查询用户查询
+func (q *GetUserByIDQuery) Validate() error {
+	if q.UserID == 0 {
+		return errors.New("user id is required")
+	}
+	return nil
+}
+
+// Validate 校验根据UUID查询用户查询
+func (q *GetUserByUUIDQuery) Validate() error {
+	if q.UUID == "" {
+		return errors.New("uuid is required")
+	}
+	return nil
+}
+
+// Validate 校验查询用户列表查询
+func (q *ListUsersQuery) Validate() error {
+	if q.Offset < 0 || q.Limit <= 0 || q.Limit > 100 {
+		return errors.New("invalid pagination")
 	}
+	return nil
 }
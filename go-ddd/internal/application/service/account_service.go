@@ -0,0 +1,249 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"yiwen/go-ddd/internal/application/command"
+	"yiwen/go-ddd/internal/application/dto"
+	"yiwen/go-ddd/internal/application/port"
+	"yiwen/go-ddd/internal/domain/aggregate"
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/event"
+	"yiwen/go-ddd/internal/domain/repository"
+	"yiwen/go-ddd/pkg/errors"
+)
+
+// AccountOptions 邮箱验证和密码重置的链接、有效期
+// 链接中的 {token} 会被替换为令牌
+type AccountOptions struct {
+	VerifyURL string
+	ResetURL  string
+	VerifyTTL time.Duration
+	ResetTTL  time.Duration
+}
+
+// accountQueueSize 等待后台处理的邮件任务上限
+const accountQueueSize = 1024
+
+// AccountApplicationService 账户应用服务：邮箱验证和密码重置
+// 令牌不落库：验证令牌绑定邮箱，重置令牌绑定当前密码哈希，
+// 密码修改后旧的重置令牌随之失效，因此每个重置链接只能使用一次
+// 查找邮箱、记录申请和发送邮件都在 Run 的后台任务中执行，请求的响应时间与邮箱是否注册无关，也不等待 SMTP
+type AccountApplicationService struct {
+	userRepo      repository.UserRepository
+	refreshTokens repository.RefreshTokenRepository
+	userService   *UserApplicationService
+	signer        port.ActionTokenSigner
+	mailer        port.Mailer
+	opts          AccountOptions
+	tasks         chan accountTask
+}
+
+// accountTask 后台执行的邮件任务
+type accountTask struct {
+	name string
+	run  func(ctx context.Context) error
+}
+
+// NewAccountApplicationService 创建账户应用服务
+func NewAccountApplicationService(
+	userRepo repository.UserRepository,
+	refreshTokens repository.RefreshTokenRepository,
+	userService *UserApplicationService,
+	signer port.ActionTokenSigner,
+	mailer port.Mailer,
+	opts AccountOptions,
+) *AccountApplicationService {
+	return &AccountApplicationService{
+		userRepo:      userRepo,
+		refreshTokens: refreshTokens,
+		userService:   userService,
+		signer:        signer,
+		mailer:        mailer,
+		opts:          opts,
+		tasks:         make(chan accountTask, accountQueueSize),
+	}
+}
+
+// Run 在后台依次执行邮件任务，直到 ctx 取消
+// 失败的任务只记录日志：用户可以重新申请，不会因为邮件服务故障影响请求
+func (s *AccountApplicationService) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case task := <-s.tasks:
+			if err := task.run(ctx); err != nil {
+				log.Printf("[account] %s failed: %v", task.name, err)
+			}
+		}
+	}
+}
+
+// enqueue 将任务放入后台队列；队列已满时丢弃并记录日志，不阻塞请求
+func (s *AccountApplicationService) enqueue(name string, run func(ctx context.Context) error) {
+	select {
+	case s.tasks <- accountTask{name: name, run: run}:
+	default:
+		log.Printf("[account] queue is full, dropped %s", name)
+	}
+}
+
+// HandleUserRegistered 订阅 user.registered，在后台向新用户发送验证邮件
+func (s *AccountApplicationService) HandleUserRegistered(e event.Event) error {
+	uuid := e.AggregateID()
+	s.enqueue("verification mail", func(ctx context.Context) error {
+		user, err := s.userRepo.FindByUUID(ctx, uuid)
+		if err != nil {
+			return errors.Wrap(err, "user not found")
+		}
+		if user.IsEmailVerified() {
+			return nil
+		}
+		return s.sendVerification(ctx, user)
+	})
+	return nil
+}
+
+// HandlePasswordResetRequested 订阅 user.password_reset_requested，在后台发送重置邮件
+func (s *AccountApplicationService) HandlePasswordResetRequested(e event.Event) error {
+	uuid := e.AggregateID()
+	s.enqueue("password reset mail", func(ctx context.Context) error {
+		user, err := s.userRepo.FindByUUID(ctx, uuid)
+		if err != nil {
+			return errors.Wrap(err, "user not found")
+		}
+
+		token, err := s.signer.Sign(port.PurposeResetPassword, user.UUID, user.Password.Hash(), s.opts.ResetTTL)
+		if err != nil {
+			return errors.Wrap(err, "failed to sign reset token")
+		}
+		return s.mailer.Send(ctx, port.Mail{
+			To:      user.Email.String(),
+			Subject: "Reset your password",
+			Body: fmt.Sprintf("Hi %s,\n\nUse the link below to set a new password. It expires in %s and can be used once.\n\n%s\n\nIf you did not request a password reset, you can ignore this email.\n",
+				user.Username, s.opts.ResetTTL, link(s.opts.ResetURL, token)),
+		})
+	})
+	return nil
+}
+
+// ResendVerification 重新发送验证邮件
+// 只把查找和发送放入后台队列，邮箱不存在或已验证时同样返回成功，不泄露邮箱是否注册
+func (s *AccountApplicationService) ResendVerification(ctx context.Context, cmd *command.ResendVerificationCommand) error {
+	email := cmd.Email
+	s.enqueue("verification resend", func(ctx context.Context) error {
+		user, err := s.userRepo.FindByEmail(ctx, email)
+		if err != nil || user.IsEmailVerified() {
+			return nil
+		}
+		return s.sendVerification(ctx, user)
+	})
+	return nil
+}
+
+// VerifyEmail 使用验证令牌验证邮箱，等待验证的用户随之激活
+func (s *AccountApplicationService) VerifyEmail(ctx context.Context, cmd *command.VerifyEmailCommand) (*dto.UserDTO, error) {
+	user, err := s.userFromToken(ctx, cmd.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.userService.changeUser(ctx, user.ID, func(ctx context.Context, agg *aggregate.UserAggregate) error {
+		// 令牌绑定注册时的邮箱，邮箱变更后旧令牌失效
+		if err := s.signer.Verify(cmd.Token, port.PurposeVerifyEmail, agg.User.Email.String()); err != nil {
+			return err
+		}
+		agg.VerifyEmail()
+		return nil
+	})
+}
+
+// RequestPasswordReset 申请重置密码，重置邮件由 user.password_reset_requested 的处理器发送
+// 查找邮箱和记录申请都在后台执行，邮箱不存在时同样立即返回成功，不泄露邮箱是否注册
+func (s *AccountApplicationService) RequestPasswordReset(ctx context.Context, cmd *command.RequestPasswordResetCommand) error {
+	email := cmd.Email
+	s.enqueue("password reset request", func(ctx context.Context) error {
+		user, err := s.userRepo.FindByEmail(ctx, email)
+		if err != nil || user.IsBanned() {
+			return nil
+		}
+
+		_, err = s.userService.changeUser(ctx, user.ID, func(ctx context.Context, agg *aggregate.UserAggregate) error {
+			agg.RequestPasswordReset()
+			return nil
+		})
+		return err
+	})
+	return nil
+}
+
+// ResetPassword 使用重置令牌设置新密码，并吊销该用户的全部刷新令牌
+func (s *AccountApplicationService) ResetPassword(ctx context.Context, cmd *command.ResetPasswordCommand) error {
+	// 创建新密码（哈希计算较慢，放在事务之外）
+	newPassword, err := s.userService.passwords.NewPassword(cmd.NewPassword)
+	if err != nil {
+		return errors.Wrap(err, "invalid new password")
+	}
+
+	user, err := s.userFromToken(ctx, cmd.Token)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.userService.changeUser(ctx, user.ID, func(ctx context.Context, agg *aggregate.UserAggregate) error {
+		// 令牌绑定签发时的密码哈希，密码一旦修改，同一令牌再次使用会在这里失败
+		if err := s.signer.Verify(cmd.Token, port.PurposeResetPassword, agg.User.Password.Hash()); err != nil {
+			return err
+		}
+		if err := s.userService.passwords.EnsureNotReused(agg.User, cmd.NewPassword); err != nil {
+			return err
+		}
+		agg.ResetPassword(newPassword, s.userService.passwords.HistorySize())
+		// 能收到重置邮件说明用户控制着该邮箱
+		agg.VerifyEmail()
+
+		// 已登录的会话可能属于盗用者，与密码修改在同一事务中吊销
+		if err := s.refreshTokens.RevokeAllForUser(ctx, agg.User.ID); err != nil {
+			return errors.Wrap(err, "failed to revoke refresh tokens")
+		}
+		return nil
+	})
+	return err
+}
+
+// userFromToken 根据令牌中的 subject（用户UUID）查找用户，签名在事务中校验
+func (s *AccountApplicationService) userFromToken(ctx context.Context, token string) (*entity.User, error) {
+	subject, err := s.signer.Subject(token)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.userRepo.FindByUUID(ctx, subject)
+	if err != nil {
+		return nil, port.ErrInvalidActionToken
+	}
+	return user, nil
+}
+
+// sendVerification 签发验证令牌并发送验证邮件
+func (s *AccountApplicationService) sendVerification(ctx context.Context, user *entity.User) error {
+	token, err := s.signer.Sign(port.PurposeVerifyEmail, user.UUID, user.Email.String(), s.opts.VerifyTTL)
+	if err != nil {
+		return errors.Wrap(err, "failed to sign verification token")
+	}
+	return s.mailer.Send(ctx, port.Mail{
+		To:      user.Email.String(),
+		Subject: "Verify your email address",
+		Body: fmt.Sprintf("Hi %s,\n\nPlease confirm your email address to activate your account. The link expires in %s.\n\n%s\n",
+			user.Username, s.opts.VerifyTTL, link(s.opts.VerifyURL, token)),
+	})
+}
+
+// link 将令牌填入链接模板
+func link(template, token string) string {
+	return strings.ReplaceAll(template, "{token}", token)
+}
@@ -0,0 +1,167 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"yiwen/go-ddd/internal/application/command"
+	"yiwen/go-ddd/internal/application/port"
+	"yiwen/go-ddd/internal/application/service"
+	"yiwen/go-ddd/internal/domain/aggregate"
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/repository"
+	domainservice "yiwen/go-ddd/internal/domain/service"
+	"yiwen/go-ddd/internal/domain/valueobject"
+	"yiwen/go-ddd/internal/infrastructure/auth"
+	"yiwen/go-ddd/internal/infrastructure/messaging"
+	"yiwen/go-ddd/internal/infrastructure/persistence/memory"
+)
+
+// saveRegistered 保存一个刚注册、尚未验证邮箱的用户，deactivate 为 true 时再由管理员停用
+func saveRegistered(t *testing.T, users repository.UserRepository, name string, deactivate bool) *entity.User {
+	t.Helper()
+	ctx := context.Background()
+	email, err := valueobject.NewEmail(name + "@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	agg := aggregate.Register("uuid-"+name, name, email, valueobject.NewPasswordFromHash("hash"), name)
+	if deactivate {
+		if err := agg.Deactivate("test"); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := users.SaveAggregate(ctx, agg); err != nil {
+		t.Fatal(err)
+	}
+	user, err := users.FindByUsername(ctx, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return user
+}
+
+func TestAccountTokensActivateOnlyPendingUsers(t *testing.T) {
+	signer := auth.NewHMACActionTokenSigner("account-service-test-token-secret-0123456789")
+
+	flows := []struct {
+		name string
+		run  func(ctx context.Context, svc *service.AccountApplicationService, user *entity.User) error
+	}{
+		{"VerifyEmail", func(ctx context.Context, svc *service.AccountApplicationService, user *entity.User) error {
+			token, err := signer.Sign(port.PurposeVerifyEmail, user.UUID, user.Email.String(), time.Hour)
+			if err != nil {
+				return err
+			}
+			_, err = svc.VerifyEmail(ctx, command.NewVerifyEmailCommand(token))
+			return err
+		}},
+		{"ResetPassword", func(ctx context.Context, svc *service.AccountApplicationService, user *entity.User) error {
+			token, err := signer.Sign(port.PurposeResetPassword, user.UUID, user.Password.Hash(), time.Hour)
+			if err != nil {
+				return err
+			}
+			return svc.ResetPassword(ctx, command.NewResetPasswordCommand(token, "NewPassword1"))
+		}},
+	}
+
+	cases := []struct {
+		name       string
+		deactivate bool
+		want       entity.UserStatus
+	}{
+		{"pending user is activated", false, entity.UserStatusActive},
+		{"deactivated user stays inactive", true, entity.UserStatusInactive},
+	}
+
+	for _, flow := range flows {
+		for _, tc := range cases {
+			t.Run(flow.name+"/"+tc.name, func(t *testing.T) {
+				ctx := context.Background()
+				userService, users := newUserService(t)
+				svc := service.NewAccountApplicationService(users, memory.NewRefreshTokenRepository(), userService, signer, nil, service.AccountOptions{})
+				user := saveRegistered(t, users, "carol", tc.deactivate)
+
+				if err := flow.run(ctx, svc, user); err != nil {
+					t.Fatal(err)
+				}
+				found, err := users.FindByID(ctx, user.ID)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if !found.IsEmailVerified() {
+					t.Fatal("expected email to be verified")
+				}
+				if found.Status != tc.want {
+					t.Fatalf("expected status %d, got %d", tc.want, found.Status)
+				}
+			})
+		}
+	}
+}
+
+// recordingMailer 将每封邮件的收件人写入 sent
+type recordingMailer struct {
+	sent chan string
+}
+
+func (m recordingMailer) Send(ctx context.Context, mail port.Mail) error {
+	m.sent <- mail.To
+	return nil
+}
+
+func TestAccountMailIsSentInTheBackground(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	users := memory.NewUserRepository()
+	events := messaging.NewEventBus()
+	hasher, err := valueobject.NewPasswordHasher("bcrypt", 4, valueobject.Argon2Params{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	passwords := domainservice.NewPasswordService(valueobject.PasswordPolicy{MinLength: 8}, hasher)
+	loginGuard := domainservice.NewLoginGuard(memory.NewLoginAttemptRepository(), domainservice.LoginThrottlePolicy{}, domainservice.LoginThrottlePolicy{})
+	userService := service.NewUserApplicationService(users, memory.NewUnitOfWork(), domainservice.NewUserDomainService(users, passwords), passwords, loginGuard, events)
+
+	mailer := recordingMailer{sent: make(chan string, 10)}
+	signer := auth.NewHMACActionTokenSigner("account-service-test-token-secret-0123456789")
+	svc := service.NewAccountApplicationService(users, memory.NewRefreshTokenRepository(), userService, signer, mailer, service.AccountOptions{ResetTTL: time.Hour})
+	events.Subscribe("user.password_reset_requested", messaging.EventHandlerFunc(svc.HandlePasswordResetRequested))
+	saveRegistered(t, users, "dave", false)
+
+	// 请求只放入队列，邮箱是否注册都不在请求中查找，也不等待发送
+	for _, email := range []string{"nobody@example.com", "dave@example.com"} {
+		if err := svc.RequestPasswordReset(ctx, command.NewRequestPasswordResetCommand(email)); err != nil {
+			t.Fatal(err)
+		}
+		if err := svc.ResendVerification(ctx, command.NewResendVerificationCommand(email)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	select {
+	case to := <-mailer.sent:
+		t.Fatalf("mail to %s was sent in the request", to)
+	default:
+	}
+
+	go svc.Run(ctx)
+	want := map[string]int{"dave@example.com": 2} // 重置邮件和验证邮件
+	for i := 0; i < 2; i++ {
+		select {
+		case to := <-mailer.sent:
+			want[to]--
+		case <-time.After(5 * time.Second):
+			t.Fatal("mail was not sent in the background")
+		}
+	}
+	select {
+	case to := <-mailer.sent:
+		t.Fatalf("unexpected mail to %s", to)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if want["dave@example.com"] != 0 || len(want) != 1 {
+		t.Fatalf("unexpected recipients: %v", want)
+	}
+}
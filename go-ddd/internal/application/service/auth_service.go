@@ -0,0 +1,202 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"time"
+
+	"github.com/google/uuid"
+
+	"yiwen/go-ddd/internal/application/command"
+	"yiwen/go-ddd/internal/application/dto"
+	"yiwen/go-ddd/internal/application/query"
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/repository"
+	domainservice "yiwen/go-ddd/internal/domain/service"
+	"yiwen/go-ddd/pkg/errors"
+)
+
+var (
+	ErrInvalidRefreshToken = errors.New("invalid or expired refresh token")
+	ErrRefreshTokenReused  = errors.New("refresh token reuse detected, all sessions revoked")
+)
+
+// AuthApplicationService 认证应用服务
+// 负责刷新令牌的签发、轮换和吊销，以及访问令牌的吊销列表
+// 访问令牌（JWT）本身由接口层的 JWTAuth 签发
+type AuthApplicationService struct {
+	refreshTokens repository.RefreshTokenRepository
+	revokedTokens repository.RevokedTokenRepository
+	uow           repository.UnitOfWork
+	userService   *UserApplicationService
+	mfaPolicy     *domainservice.MFAPolicy
+	refreshTTL    time.Duration
+}
+
+// NewAuthApplicationService 创建认证应用服务
+func NewAuthApplicationService(
+	refreshTokens repository.RefreshTokenRepository,
+	revokedTokens repository.RevokedTokenRepository,
+	uow repository.UnitOfWork,
+	userService *UserApplicationService,
+	mfaPolicy *domainservice.MFAPolicy,
+	refreshTTL time.Duration,
+) *AuthApplicationService {
+	return &AuthApplicationService{
+		refreshTokens: refreshTokens,
+		revokedTokens: revokedTokens,
+		uow:           uow,
+		userService:   userService,
+		mfaPolicy:     mfaPolicy,
+		refreshTTL:    refreshTTL,
+	}
+}
+
+// IssueRefreshToken 登录成功后签发刷新令牌，开启一个新的令牌家族
+func (s *AuthApplicationService) IssueRefreshToken(ctx context.Context, userID uint64) (string, time.Time, error) {
+	raw, token, err := s.newRefreshToken(userID, uuid.New().String())
+	if err != nil {
+		return "", time.Time{}, err
+	}
+	if err := s.refreshTokens.Create(ctx, token); err != nil {
+		return "", time.Time{}, errors.Wrap(err, "failed to save refresh token")
+	}
+	return raw, token.ExpiresAt, nil
+}
+
+// Refresh 使用刷新令牌换取新的刷新令牌（轮换），并返回用户信息供签发访问令牌
+// 已被轮换或吊销的令牌再次使用时视为令牌泄露，吊销整个家族
+func (s *AuthApplicationService) Refresh(ctx context.Context, cmd *command.RefreshTokenCommand) (*dto.UserDTO, string, time.Time, error) {
+	current, err := s.refreshTokens.FindByHash(ctx, hashToken(cmd.RefreshToken))
+	if err != nil {
+		return nil, "", time.Time{}, ErrInvalidRefreshToken
+	}
+
+	if current.IsRevoked() {
+		if err := s.refreshTokens.RevokeFamily(ctx, current.FamilyID); err != nil {
+			return nil, "", time.Time{}, errors.Wrap(err, "failed to revoke token family")
+		}
+		return nil, "", time.Time{}, ErrRefreshTokenReused
+	}
+	if current.IsExpired() {
+		return nil, "", time.Time{}, ErrInvalidRefreshToken
+	}
+
+	// 被禁用、停用或删除的用户不能再刷新
+	if _, err := s.userService.CheckUserAccess(ctx, current.UserID); err != nil {
+		if revokeErr := s.refreshTokens.RevokeFamily(ctx, current.FamilyID); revokeErr != nil {
+			return nil, "", time.Time{}, errors.Wrap(revokeErr, "failed to revoke token family")
+		}
+		return nil, "", time.Time{}, err
+	}
+
+	// 会话建立后才被要求启用两步验证（如提升为管理员）的用户，需要重新登录完成绑定
+	if err := s.checkMFAPolicy(ctx, current.UserID); err != nil {
+		if revokeErr := s.refreshTokens.RevokeFamily(ctx, current.FamilyID); revokeErr != nil {
+			return nil, "", time.Time{}, errors.Wrap(revokeErr, "failed to revoke token family")
+		}
+		return nil, "", time.Time{}, err
+	}
+
+	raw, next, err := s.newRefreshToken(current.UserID, current.FamilyID)
+	if err != nil {
+		return nil, "", time.Time{}, err
+	}
+
+	// 新令牌的写入和旧令牌的轮换在同一事务中，轮换失败时新令牌一并回滚
+	err = s.uow.Do(ctx, func(ctx context.Context) error {
+		if err := s.refreshTokens.Create(ctx, next); err != nil {
+			return errors.Wrap(err, "failed to save refresh token")
+		}
+		rotated, err := s.refreshTokens.MarkRotated(ctx, current.ID, next.ID)
+		if err != nil {
+			return errors.Wrap(err, "failed to rotate refresh token")
+		}
+		if !rotated {
+			return ErrRefreshTokenReused
+		}
+		return nil
+	})
+	if errors.Is(err, ErrRefreshTokenReused) {
+		// 并发请求抢先使用了同一个令牌，家族的吊销不能随事务回滚
+		if err := s.refreshTokens.RevokeFamily(ctx, current.FamilyID); err != nil {
+			return nil, "", time.Time{}, errors.Wrap(err, "failed to revoke token family")
+		}
+		return nil, "", time.Time{}, ErrRefreshTokenReused
+	}
+	if err != nil {
+		return nil, "", time.Time{}, err
+	}
+
+	user, err := s.userService.GetUserByID(ctx, query.NewGetUserByIDQuery(current.UserID))
+	if err != nil {
+		return nil, "", time.Time{}, err
+	}
+	return user, raw, next.ExpiresAt, nil
+}
+
+// Logout 登出：吊销当前访问令牌，提供刷新令牌时一并吊销其令牌家族
+func (s *AuthApplicationService) Logout(ctx context.Context, cmd *command.LogoutCommand) error {
+	if cmd.AccessTokenID != "" {
+		if err := s.revokedTokens.Revoke(ctx, cmd.AccessTokenID, cmd.AccessExpiresAt); err != nil {
+			return errors.Wrap(err, "failed to revoke access token")
+		}
+	}
+
+	if cmd.RefreshToken == "" {
+		return nil
+	}
+	token, err := s.refreshTokens.FindByHash(ctx, hashToken(cmd.RefreshToken))
+	if err != nil || token.UserID != cmd.UserID {
+		// 不属于当前用户的令牌直接忽略，不泄露令牌是否存在
+		return nil
+	}
+	if err := s.refreshTokens.RevokeFamily(ctx, token.FamilyID); err != nil {
+		return errors.Wrap(err, "failed to revoke refresh token")
+	}
+	return nil
+}
+
+// IsRevoked 检查访问令牌是否已被吊销，供认证中间件调用
+func (s *AuthApplicationService) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	return s.revokedTokens.IsRevoked(ctx, jti)
+}
+
+// PurgeExpired 清理已过期的刷新令牌和吊销记录
+func (s *AuthApplicationService) PurgeExpired(ctx context.Context) error {
+	if err := s.refreshTokens.DeleteExpired(ctx); err != nil {
+		return err
+	}
+	return s.revokedTokens.DeleteExpired(ctx)
+}
+
+// checkMFAPolicy 策略要求启用两步验证但用户尚未启用时返回 ErrMFARequired
+func (s *AuthApplicationService) checkMFAPolicy(ctx context.Context, userID uint64) error {
+	user, err := s.userService.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return domainservice.ErrUserNotFound
+	}
+	if s.mfaPolicy.Requires(user) && !user.IsMFAEnabled() {
+		return domainservice.ErrMFARequired
+	}
+	return nil
+}
+
+// newRefreshToken 生成随机令牌，返回明文（只交给客户端）和待保存的实体（只含哈希）
+func (s *AuthApplicationService) newRefreshToken(userID uint64, familyID string) (string, *entity.RefreshToken, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", nil, errors.Wrap(err, "failed to generate refresh token")
+	}
+	raw := base64.RawURLEncoding.EncodeToString(buf)
+	return raw, entity.NewRefreshToken(userID, familyID, hashToken(raw), s.refreshTTL), nil
+}
+
+// hashToken 计算令牌的 SHA-256 哈希（十六进制）
+func hashToken(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,149 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/google/uuid"
+
+	"yiwen/go-ddd/internal/application/bus"
+	"yiwen/go-ddd/internal/application/command"
+	"yiwen/go-ddd/internal/application/dto"
+	"yiwen/go-ddd/internal/application/port"
+	"yiwen/go-ddd/internal/domain/aggregate"
+	"yiwen/go-ddd/internal/domain/event"
+	"yiwen/go-ddd/internal/domain/repository"
+	"yiwen/go-ddd/pkg/errors"
+)
+
+// avatarPrefix 头像文件的 key 前缀，完整的 key 为 avatars/<用户UUID>/<上传ID>/<边长>.<扩展名>
+const avatarPrefix = "avatars/"
+
+// AvatarOptions 头像上传配置
+type AvatarOptions struct {
+	MaxBytes int64 // 上传文件的最大字节数
+	Sizes    []int // 生成的缩略图边长，头像地址指向最大的一张
+}
+
+// AvatarApplicationService 头像应用服务：校验、生成缩略图、保存文件并更新用户资料
+// 每次上传使用新的目录，头像替换后由 user.profile_updated 的处理器删除旧目录
+type AvatarApplicationService struct {
+	userRepo    repository.UserRepository
+	userService *UserApplicationService
+	storage     port.BlobStorage
+	images      port.ImageProcessor
+	opts        AvatarOptions
+}
+
+// NewAvatarApplicationService 创建头像应用服务
+func NewAvatarApplicationService(
+	userRepo repository.UserRepository,
+	userService *UserApplicationService,
+	storage port.BlobStorage,
+	images port.ImageProcessor,
+	opts AvatarOptions,
+) *AvatarApplicationService {
+	return &AvatarApplicationService{
+		userRepo:    userRepo,
+		userService: userService,
+		storage:     storage,
+		images:      images,
+		opts:        opts,
+	}
+}
+
+// RegisterCommandHandlers 在命令总线上注册头像命令的处理器
+// 图片处理和上传文件耗时较长，在事务外完成，只有更新资料在事务中执行
+func (s *AvatarApplicationService) RegisterCommandHandlers(commands *bus.Bus) {
+	bus.Register(commands, s.UploadAvatar, bus.OwnTransaction())
+}
+
+// UploadAvatar 上传头像
+// 客户端声明的大小和类型只用于提前拒绝，实际以读取到的字节数和文件内容为准
+func (s *AvatarApplicationService) UploadAvatar(ctx context.Context, cmd *command.UploadAvatarCommand) (*dto.AvatarDTO, error) {
+	if cmd.Size > s.opts.MaxBytes {
+		return nil, fmt.Errorf("%w: %d bytes exceeds the limit of %d", port.ErrImageTooLarge, cmd.Size, s.opts.MaxBytes)
+	}
+	if cmd.ContentType != "" && !strings.HasPrefix(cmd.ContentType, "image/") {
+		return nil, fmt.Errorf("%w: declared content type %s", port.ErrUnsupportedImage, cmd.ContentType)
+	}
+	data, err := io.ReadAll(io.LimitReader(cmd.File, s.opts.MaxBytes+1))
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read avatar")
+	}
+	if int64(len(data)) > s.opts.MaxBytes {
+		return nil, fmt.Errorf("%w: exceeds the limit of %d bytes", port.ErrImageTooLarge, s.opts.MaxBytes)
+	}
+
+	user, err := s.userRepo.FindByID(ctx, cmd.UserID)
+	if err != nil {
+		return nil, errors.Wrap(err, "user not found")
+	}
+	variants, err := s.images.Thumbnails(bytes.NewReader(data), s.opts.Sizes)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := avatarPrefix + user.UUID + "/" + uuid.New().String() + "/"
+	result := &dto.AvatarDTO{Thumbnails: make(map[string]string, len(variants))}
+	largest := 0
+	for _, v := range variants {
+		key := dir + strconv.Itoa(v.Size) + "." + v.Ext
+		if err := s.storage.Put(ctx, key, bytes.NewReader(v.Data), int64(len(v.Data)), v.ContentType); err != nil {
+			s.cleanup(dir)
+			return nil, errors.Wrap(err, "failed to store avatar")
+		}
+		url := s.storage.URL(key)
+		result.Thumbnails[strconv.Itoa(v.Size)] = url
+		if v.Size > largest {
+			largest, result.Avatar = v.Size, url
+		}
+	}
+
+	userDTO, err := s.userService.changeUser(ctx, cmd.UserID, func(ctx context.Context, agg *aggregate.UserAggregate) error {
+		agg.UpdateProfile(agg.User.Nickname, result.Avatar)
+		return nil
+	})
+	if err != nil {
+		s.cleanup(dir)
+		return nil, err
+	}
+	result.User = *userDTO
+	return result, nil
+}
+
+// HandleProfileUpdated 订阅 user.profile_updated，头像被替换后删除旧头像所在的目录
+// 头像地址可以通过更新资料任意填写，只删除本存储中属于该用户的头像目录
+func (s *AvatarApplicationService) HandleProfileUpdated(e event.Event) error {
+	updated, ok := e.(*event.UserProfileUpdatedEvent)
+	if !ok || updated.OldAvatar == "" || updated.OldAvatar == updated.Avatar {
+		return nil
+	}
+	key, ok := s.storage.KeyOf(updated.OldAvatar)
+	if !ok || !strings.HasPrefix(key, avatarPrefix+e.AggregateID()+"/") {
+		return nil
+	}
+	dir := path.Dir(key) + "/"
+	if newKey, ok := s.storage.KeyOf(updated.Avatar); ok && strings.HasPrefix(newKey, dir) {
+		return nil
+	}
+	return s.storage.DeletePrefix(context.Background(), dir)
+}
+
+// HandleUserErased 订阅 user.erased，删除该用户上传过的全部头像
+func (s *AvatarApplicationService) HandleUserErased(e event.Event) error {
+	return s.storage.DeletePrefix(context.Background(), avatarPrefix+e.AggregateID()+"/")
+}
+
+// cleanup 上传失败时删除已写入的文件
+func (s *AvatarApplicationService) cleanup(dir string) {
+	if err := s.storage.DeletePrefix(context.Background(), dir); err != nil {
+		log.Printf("failed to clean up avatar %s: %v", dir, err)
+	}
+}
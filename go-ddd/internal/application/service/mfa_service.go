@@ -0,0 +1,239 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"yiwen/go-ddd/internal/application/command"
+	"yiwen/go-ddd/internal/application/dto"
+	"yiwen/go-ddd/internal/application/port"
+	"yiwen/go-ddd/internal/domain/aggregate"
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/repository"
+	domainservice "yiwen/go-ddd/internal/domain/service"
+	"yiwen/go-ddd/internal/domain/valueobject"
+	"yiwen/go-ddd/pkg/errors"
+)
+
+// MFAOptions 两步验证配置
+type MFAOptions struct {
+	Issuer        string        // 验证器 App 中显示的发行方
+	ChallengeTTL  time.Duration // 登录第二步令牌的有效期
+	RecoveryCodes int           // 每次绑定生成的恢复码数量
+}
+
+// MFAApplicationService 两步验证应用服务：TOTP 绑定、登录第二步校验和关闭
+// 启用两步验证的用户密码校验通过后只拿到短期的两步验证令牌，提交验证码后才签发访问令牌。
+// 令牌不落库：登录令牌绑定密码哈希和 TOTP 密钥，绑定令牌绑定密码哈希，任一变化后令牌失效
+type MFAApplicationService struct {
+	userRepo    repository.UserRepository
+	userService *UserApplicationService
+	loginGuard  *domainservice.LoginGuard
+	policy      *domainservice.MFAPolicy
+	cipher      valueobject.SecretCipher
+	signer      port.ActionTokenSigner
+	opts        MFAOptions
+}
+
+// NewMFAApplicationService 创建两步验证应用服务
+// 验证码错误与密码错误共用 loginGuard 的失败计数，防止暴力猜测验证码
+func NewMFAApplicationService(
+	userRepo repository.UserRepository,
+	userService *UserApplicationService,
+	loginGuard *domainservice.LoginGuard,
+	policy *domainservice.MFAPolicy,
+	cipher valueobject.SecretCipher,
+	signer port.ActionTokenSigner,
+	opts MFAOptions,
+) *MFAApplicationService {
+	return &MFAApplicationService{
+		userRepo:    userRepo,
+		userService: userService,
+		loginGuard:  loginGuard,
+		policy:      policy,
+		cipher:      cipher,
+		signer:      signer,
+		opts:        opts,
+	}
+}
+
+// LoginChallenge 密码校验通过后判断是否还需要第二因素
+// 返回 nil 表示可以直接签发访问令牌
+func (s *MFAApplicationService) LoginChallenge(ctx context.Context, userID uint64) (*dto.MFAChallengeDTO, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return nil, errors.Wrap(err, "user not found")
+	}
+
+	purpose, state := port.PurposeMFALogin, loginState(user)
+	switch {
+	case user.IsMFAEnabled():
+	case s.policy.Requires(user):
+		purpose, state = port.PurposeMFAEnroll, user.Password.Hash()
+	default:
+		return nil, nil
+	}
+
+	token, err := s.signer.Sign(purpose, user.UUID, state, s.opts.ChallengeTTL)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to sign mfa token")
+	}
+	return &dto.MFAChallengeDTO{
+		MFARequired:        true,
+		EnrollmentRequired: purpose == port.PurposeMFAEnroll,
+		MFAToken:           token,
+		ExpiresAt:          time.Now().Add(s.opts.ChallengeTTL).Unix(),
+	}, nil
+}
+
+// VerifyLogin 登录第二步：校验两步验证令牌和验证码（或恢复码）
+func (s *MFAApplicationService) VerifyLogin(ctx context.Context, cmd *command.MFAChallengeCommand) (*dto.UserDTO, error) {
+	user, err := s.userFromChallenge(ctx, cmd.Token)
+	if err != nil {
+		return nil, err
+	}
+	return s.guarded(ctx, user, cmd.ClientIP, func(ctx context.Context, agg *aggregate.UserAggregate) error {
+		if err := s.signer.Verify(cmd.Token, port.PurposeMFALogin, loginState(agg.User)); err != nil {
+			return err
+		}
+		return agg.VerifyMFA(cmd.Code, time.Now(), s.cipher)
+	})
+}
+
+// EnrollWithChallenge 策略要求绑定但尚未绑定的用户，在登录过程中使用绑定令牌开始绑定
+func (s *MFAApplicationService) EnrollWithChallenge(ctx context.Context, cmd *command.MFAChallengeCommand) (*dto.MFAEnrollmentDTO, error) {
+	user, err := s.userFromChallenge(ctx, cmd.Token)
+	if err != nil {
+		return nil, err
+	}
+	return s.enroll(ctx, user.ID, func(agg *aggregate.UserAggregate) error {
+		return s.signer.Verify(cmd.Token, port.PurposeMFAEnroll, agg.User.Password.Hash())
+	})
+}
+
+// ConfirmWithChallenge 在登录过程中确认绑定，成功后即完成登录
+func (s *MFAApplicationService) ConfirmWithChallenge(ctx context.Context, cmd *command.MFAChallengeCommand) (*dto.UserDTO, error) {
+	user, err := s.userFromChallenge(ctx, cmd.Token)
+	if err != nil {
+		return nil, err
+	}
+	return s.guarded(ctx, user, cmd.ClientIP, func(ctx context.Context, agg *aggregate.UserAggregate) error {
+		if err := s.signer.Verify(cmd.Token, port.PurposeMFAEnroll, agg.User.Password.Hash()); err != nil {
+			return err
+		}
+		return agg.ConfirmMFA(cmd.Code, time.Now(), s.cipher)
+	})
+}
+
+// Enroll 已登录用户开始绑定两步验证
+func (s *MFAApplicationService) Enroll(ctx context.Context, cmd *command.EnrollMFACommand) (*dto.MFAEnrollmentDTO, error) {
+	return s.enroll(ctx, cmd.UserID, nil)
+}
+
+// Confirm 已登录用户确认绑定，启用两步验证
+func (s *MFAApplicationService) Confirm(ctx context.Context, cmd *command.ConfirmMFACommand) (*dto.UserDTO, error) {
+	user, err := s.userRepo.FindByID(ctx, cmd.UserID)
+	if err != nil {
+		return nil, errors.Wrap(err, "user not found")
+	}
+	return s.guarded(ctx, user, "", func(ctx context.Context, agg *aggregate.UserAggregate) error {
+		return agg.ConfirmMFA(cmd.Code, time.Now(), s.cipher)
+	})
+}
+
+// Disable 关闭两步验证，需要当前验证码或恢复码；策略要求启用的用户不能关闭
+// 尚未确认的绑定可以直接放弃，不需要验证码
+func (s *MFAApplicationService) Disable(ctx context.Context, cmd *command.DisableMFACommand) (*dto.UserDTO, error) {
+	user, err := s.userRepo.FindByID(ctx, cmd.UserID)
+	if err != nil {
+		return nil, errors.Wrap(err, "user not found")
+	}
+	return s.guarded(ctx, user, "", func(ctx context.Context, agg *aggregate.UserAggregate) error {
+		if agg.User.IsMFAEnabled() {
+			if err := s.policy.EnsureCanDisable(agg.User); err != nil {
+				return err
+			}
+			if err := agg.VerifyMFA(cmd.Code, time.Now(), s.cipher); err != nil {
+				return err
+			}
+		}
+		agg.DisableMFA()
+		return nil
+	})
+}
+
+// enroll 生成新的 TOTP 密钥和恢复码并保存到聚合，authorize 用于在事务中校验令牌
+func (s *MFAApplicationService) enroll(ctx context.Context, userID uint64, authorize func(agg *aggregate.UserAggregate) error) (*dto.MFAEnrollmentDTO, error) {
+	secret, plainSecret, err := valueobject.GenerateTOTPSecret(s.cipher)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate totp secret")
+	}
+	codes, hashes, err := valueobject.GenerateRecoveryCodes(s.opts.RecoveryCodes)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to generate recovery codes")
+	}
+
+	user, err := s.userService.changeUser(ctx, userID, func(ctx context.Context, agg *aggregate.UserAggregate) error {
+		if authorize != nil {
+			if err := authorize(agg); err != nil {
+				return err
+			}
+		}
+		return agg.BeginMFAEnrollment(secret, hashes)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.MFAEnrollmentDTO{
+		Secret:        plainSecret,
+		OTPAuthURI:    valueobject.TOTPURI(s.opts.Issuer, user.Username, plainSecret),
+		RecoveryCodes: codes,
+	}, nil
+}
+
+// guarded 校验验证码的变更：先检查退避和锁定并预占本次尝试，验证码错误时保留计数，成功后清除计数
+func (s *MFAApplicationService) guarded(ctx context.Context, user *entity.User, clientIP string, change func(ctx context.Context, agg *aggregate.UserAggregate) error) (*dto.UserDTO, error) {
+	reservation, err := s.loginGuard.Reserve(ctx, user.Username, clientIP)
+	if err != nil {
+		return nil, err
+	}
+
+	result, err := s.userService.changeUser(ctx, user.ID, change)
+	if errors.Is(err, aggregate.ErrInvalidMFACode) {
+		s.userService.recordLoginFailure(ctx, user.Username, reservation)
+		return nil, err
+	}
+	if err != nil {
+		s.userService.releaseLoginAttempt(ctx, reservation)
+		return nil, err
+	}
+
+	if err := s.loginGuard.RecordSuccess(ctx, reservation); err != nil {
+		log.Printf("failed to reset login failures: %v", err)
+	}
+	return result, nil
+}
+
+// userFromChallenge 根据两步验证令牌中的 subject 查找用户，并确认用户仍然可以登录
+// 令牌签名在事务中校验
+func (s *MFAApplicationService) userFromChallenge(ctx context.Context, token string) (*entity.User, error) {
+	subject, err := s.signer.Subject(token)
+	if err != nil {
+		return nil, err
+	}
+	user, err := s.userRepo.FindByUUID(ctx, subject)
+	if err != nil {
+		return nil, port.ErrInvalidActionToken
+	}
+	if _, err := s.userService.CheckUserAccess(ctx, user.ID); err != nil {
+		return nil, err
+	}
+	return user, nil
+}
+
+// loginState 登录令牌绑定的状态：修改密码或重新绑定两步验证后，已签发的登录令牌失效
+func loginState(user *entity.User) string {
+	return user.Password.Hash() + ":" + user.TOTPSecret.Ciphertext()
+}
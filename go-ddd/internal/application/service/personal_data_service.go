@@ -0,0 +1,157 @@
+package service
+
+import (
+	"context"
+	"log"
+	"time"
+
+	"yiwen/go-ddd/internal/application/bus"
+	"yiwen/go-ddd/internal/application/command"
+	"yiwen/go-ddd/internal/application/dto"
+	"yiwen/go-ddd/internal/application/port"
+	"yiwen/go-ddd/internal/application/query"
+	"yiwen/go-ddd/internal/domain/aggregate"
+	"yiwen/go-ddd/internal/domain/repository"
+	"yiwen/go-ddd/pkg/errors"
+)
+
+// PersonalDataApplicationService 个人数据应用服务：导出个人数据、申请删除账户和到期擦除
+// 删除申请经过宽限期后才擦除，期间用户可以撤销；擦除时用户名、邮箱替换为占位值，
+// 昵称、头像、凭据清空，用户 ID 和 UUID 保留，令牌等关联数据仍然指向同一行
+type PersonalDataApplicationService struct {
+	userRepo      repository.UserRepository
+	refreshTokens repository.RefreshTokenRepository
+	eventLog      port.UserEventLog
+	userService   *UserApplicationService
+	gracePeriod   time.Duration
+}
+
+// NewPersonalDataApplicationService 创建个人数据应用服务，gracePeriod 为申请删除到擦除之间的宽限期
+func NewPersonalDataApplicationService(
+	userRepo repository.UserRepository,
+	refreshTokens repository.RefreshTokenRepository,
+	eventLog port.UserEventLog,
+	userService *UserApplicationService,
+	gracePeriod time.Duration,
+) *PersonalDataApplicationService {
+	return &PersonalDataApplicationService{
+		userRepo:      userRepo,
+		refreshTokens: refreshTokens,
+		eventLog:      eventLog,
+		userService:   userService,
+		gracePeriod:   gracePeriod,
+	}
+}
+
+// RegisterCommandHandlers 在命令总线上注册个人数据命令的处理器
+func (s *PersonalDataApplicationService) RegisterCommandHandlers(commands *bus.Bus) {
+	bus.Register(commands, s.ExportUserData)
+	bus.Register(commands, s.RequestErasure)
+	bus.Register(commands, s.CancelErasure)
+}
+
+// RegisterQueryHandlers 在查询总线上注册个人数据查询的处理器
+func (s *PersonalDataApplicationService) RegisterQueryHandlers(queries *bus.Bus) {
+	bus.Register(queries, s.ListPendingErasures)
+}
+
+// ExportUserData 导出用户的账户资料和全部领域事件，并记录一次导出
+func (s *PersonalDataApplicationService) ExportUserData(ctx context.Context, cmd *command.ExportUserDataCommand) (*dto.UserDataExportDTO, error) {
+	var export *dto.UserDataExportDTO
+	_, err := s.userService.changeUser(ctx, cmd.UserID, func(ctx context.Context, agg *aggregate.UserAggregate) error {
+		events, err := s.eventLog.Events(ctx, agg.User.UUID)
+		if err != nil {
+			return errors.Wrap(err, "failed to load user events")
+		}
+		if events == nil {
+			events = []port.RecordedEvent{}
+		}
+		export = &dto.UserDataExportDTO{
+			ExportedAt: time.Now(),
+			User:       dto.ToUserDataDTO(agg.User),
+			Events:     events,
+		}
+		agg.RecordDataExport()
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return export, nil
+}
+
+// RequestErasure 申请删除账户，重复申请时返回原来的计划
+// 唯一的激活管理员不能申请，避免擦除后系统中没有管理员
+func (s *PersonalDataApplicationService) RequestErasure(ctx context.Context, cmd *command.RequestErasureCommand) (*dto.ErasureDTO, error) {
+	var erasure *dto.ErasureDTO
+	_, err := s.userService.changeUser(ctx, cmd.UserID, func(ctx context.Context, agg *aggregate.UserAggregate) error {
+		if err := s.userService.userDomainService.EnsureNotLastAdmin(ctx, agg.User); err != nil {
+			return err
+		}
+		agg.RequestErasure(time.Now(), s.gracePeriod)
+		erasure = dto.ToErasureDTO(agg.User)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return erasure, nil
+}
+
+// CancelErasure 在宽限期内撤销删除申请
+func (s *PersonalDataApplicationService) CancelErasure(ctx context.Context, cmd *command.CancelErasureCommand) (*dto.UserDTO, error) {
+	return s.userService.changeUser(ctx, cmd.UserID, func(ctx context.Context, agg *aggregate.UserAggregate) error {
+		return agg.CancelErasure()
+	})
+}
+
+// ListPendingErasures 管理员查看等待擦除的删除申请，按擦除时间升序
+func (s *PersonalDataApplicationService) ListPendingErasures(ctx context.Context, q *query.ListPendingErasuresQuery) ([]dto.ErasureDTO, error) {
+	users, err := s.userRepo.FindPendingErasures(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list pending erasures")
+	}
+	erasures := make([]dto.ErasureDTO, 0, len(users))
+	for _, user := range users {
+		erasures = append(erasures, *dto.ToErasureDTO(user))
+	}
+	return erasures, nil
+}
+
+// EraseDue 擦除宽限期已结束的用户，返回擦除的数量
+// 每个用户在单独的事务中擦除：匿名化用户、吊销刷新令牌、删除该用户的事件记录，
+// 事务提交后发布 user.erased，由订阅者删除头像等其他位置的个人数据；
+// 单个用户失败只记录日志，下次继续重试
+func (s *PersonalDataApplicationService) EraseDue(ctx context.Context, now time.Time) (int, error) {
+	users, err := s.userRepo.FindPendingErasures(ctx)
+	if err != nil {
+		return 0, errors.Wrap(err, "failed to list pending erasures")
+	}
+
+	erased := 0
+	for _, user := range users {
+		if user.ErasureScheduledAt.After(now) {
+			break // 按擦除时间升序，之后的都未到期
+		}
+		_, err := s.userService.changeUser(ctx, user.ID, func(ctx context.Context, agg *aggregate.UserAggregate) error {
+			// 宽限期内其他管理员可能已被降级，擦除前再检查一次
+			if err := s.userService.userDomainService.EnsureNotLastAdmin(ctx, agg.User); err != nil {
+				return err
+			}
+			if err := s.refreshTokens.RevokeAllForUser(ctx, agg.User.ID); err != nil {
+				return errors.Wrap(err, "failed to revoke refresh tokens")
+			}
+			// 先删除历史事件，user.erased 随后随聚合一起写入，作为擦除的记录保留
+			if err := s.eventLog.Erase(ctx, agg.User.UUID); err != nil {
+				return errors.Wrap(err, "failed to erase user events")
+			}
+			return agg.Erase(now)
+		})
+		if err != nil {
+			log.Printf("Failed to erase user %d: %v", user.ID, err)
+			continue
+		}
+		erased++
+	}
+	return erased, nil
+}
@@ -0,0 +1,63 @@
+package service
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"yiwen/go-ddd/internal/domain/repository"
+	"yiwen/go-ddd/pkg/errors"
+)
+
+var (
+	ErrInvalidSort   = errors.New("invalid sort field")
+	ErrInvalidCursor = errors.New("invalid cursor")
+)
+
+// userCursorToken 分页游标的内容，对客户端不透明
+// 记录排序方式，游标不能用于另一种排序
+type userCursorToken struct {
+	SortBy    string     `json:"s"`
+	Desc      bool       `json:"d,omitempty"`
+	ID        uint64     `json:"i"`
+	CreatedAt *time.Time `json:"c,omitempty"`
+	Username  string     `json:"u,omitempty"`
+}
+
+// encodeUserCursor 把上一页最后一个用户的排序键编码为游标
+func encodeUserCursor(c *repository.UserCursor, sortBy repository.UserSortField, desc bool) string {
+	token := userCursorToken{SortBy: string(sortBy), Desc: desc, ID: c.ID}
+	switch sortBy {
+	case repository.UserSortByCreatedAt:
+		createdAt := c.CreatedAt
+		token.CreatedAt = &createdAt
+	case repository.UserSortByUsername:
+		token.Username = c.Username
+	}
+	data, _ := json.Marshal(token)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeUserCursor 解析游标，格式错误或排序方式不一致时返回 ErrInvalidCursor
+func decodeUserCursor(s string, sortBy repository.UserSortField, desc bool) (*repository.UserCursor, error) {
+	data, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	var token userCursorToken
+	if err := json.Unmarshal(data, &token); err != nil {
+		return nil, ErrInvalidCursor
+	}
+	if token.SortBy != string(sortBy) || token.Desc != desc {
+		return nil, errors.Wrap(ErrInvalidCursor, "cursor was issued for a different sort order")
+	}
+
+	c := &repository.UserCursor{ID: token.ID, Username: token.Username}
+	if sortBy == repository.UserSortByCreatedAt {
+		if token.CreatedAt == nil {
+			return nil, ErrInvalidCursor
+		}
+		c.CreatedAt = *token.CreatedAt
+	}
+	return c, nil
+}
@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+
+	"yiwen/go-ddd/internal/application/port"
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/event"
+	"yiwen/go-ddd/internal/domain/repository"
+	"yiwen/go-ddd/pkg/errors"
+)
+
+// rebuildBatchSize 全量重建时每批读取的用户数
+const rebuildBatchSize = 500
+
+// UserProjection 用户读模型投影
+// 订阅全部用户事件，按事件的聚合ID读取用户的最新状态并覆盖读模型中的一行，user.deleted 时删除该行
+// 投影结果只取决于用户当前的状态，与事件的到达顺序、重复投递无关
+type UserProjection struct {
+	userRepo  repository.UserRepository
+	readModel port.UserReadModel
+}
+
+// NewUserProjection 创建用户读模型投影
+func NewUserProjection(userRepo repository.UserRepository, readModel port.UserReadModel) *UserProjection {
+	return &UserProjection{userRepo: userRepo, readModel: readModel}
+}
+
+// Handle 实现 event.EventHandler，在事务提交后由事件总线调用
+func (p *UserProjection) Handle(e event.Event) error {
+	ctx := context.Background()
+	switch e.(type) {
+	case *event.UserDeletedEvent, *event.UserErasedEvent:
+		return p.readModel.Delete(ctx, e.AggregateID())
+	}
+
+	user, err := p.userRepo.FindByUUID(ctx, e.AggregateID())
+	if err != nil {
+		return errors.Wrapf(err, "project user %s", e.AggregateID())
+	}
+	return p.readModel.Upsert(ctx, userView(user))
+}
+
+// Rebuild 清空读模型并从写模型全量重建，返回写入的用户数
+// 用于首次启用读模型，或投影失败导致读模型落后时修复
+func (p *UserProjection) Rebuild(ctx context.Context) (int, error) {
+	if err := p.readModel.Reset(ctx); err != nil {
+		return 0, errors.Wrap(err, "failed to reset read model")
+	}
+
+	opts := repository.UserListOptions{SortBy: repository.UserSortByID, Limit: rebuildBatchSize}
+	count := 0
+	for {
+		users, _, err := p.userRepo.List(ctx, opts)
+		if err != nil {
+			return count, errors.Wrap(err, "failed to list users")
+		}
+		for _, user := range users {
+			if err := p.readModel.Upsert(ctx, userView(user)); err != nil {
+				return count, errors.Wrapf(err, "project user %s", user.UUID)
+			}
+		}
+		count += len(users)
+		if len(users) < opts.Limit {
+			return count, nil
+		}
+		opts.After = repository.UserCursorOf(users[len(users)-1])
+	}
+}
+
+// userView 将用户实体展开为读模型的一行
+func userView(user *entity.User) port.UserView {
+	return port.UserView{
+		ID:        user.ID,
+		UUID:      user.UUID,
+		Username:  user.Username,
+		Email:     user.Email.String(),
+		Nickname:  user.Nickname,
+		Avatar:    user.Avatar,
+		Status:    int(user.Status),
+		Role:      string(user.Role),
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+
+		EmailVerified: user.IsEmailVerified(),
+		LockedUntil:   user.LockedUntil,
+		MFAEnabled:    user.IsMFAEnabled(),
+	}
+}
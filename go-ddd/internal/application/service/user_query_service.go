@@ -0,0 +1,102 @@
+package service
+
+import (
+	"context"
+	"strings"
+
+	"yiwen/go-ddd/internal/application/bus"
+	"yiwen/go-ddd/internal/application/dto"
+	"yiwen/go-ddd/internal/application/port"
+	"yiwen/go-ddd/internal/application/query"
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/repository"
+	"yiwen/go-ddd/pkg/errors"
+)
+
+// UserQueryService 用户查询服务（CQRS 查询端）
+// 只读取由事件投影维护的读模型，不加载聚合，也不访问写模型
+type UserQueryService struct {
+	readModel port.UserReadModel
+}
+
+// NewUserQueryService 创建用户查询服务
+func NewUserQueryService(readModel port.UserReadModel) *UserQueryService {
+	return &UserQueryService{readModel: readModel}
+}
+
+// RegisterQueryHandlers 在查询总线上注册用户查询的处理器
+func (s *UserQueryService) RegisterQueryHandlers(queries *bus.Bus) {
+	bus.Register(queries, s.GetUserByID)
+	bus.Register(queries, s.GetUserByUUID)
+	bus.Register(queries, s.ListUsers)
+}
+
+// GetUserByID 根据ID获取用户
+func (s *UserQueryService) GetUserByID(ctx context.Context, q *query.GetUserByIDQuery) (*dto.UserDTO, error) {
+	view, err := s.readModel.FindByID(ctx, q.UserID)
+	if err != nil {
+		return nil, errors.Wrap(err, "user not found")
+	}
+
+	result := dto.FromUserView(*view)
+	return &result, nil
+}
+
+// GetUserByUUID 根据UUID获取用户
+func (s *UserQueryService) GetUserByUUID(ctx context.Context, q *query.GetUserByUUIDQuery) (*dto.UserDTO, error) {
+	view, err := s.readModel.FindByUUID(ctx, q.UUID)
+	if err != nil {
+		return nil, errors.Wrap(err, "user not found")
+	}
+
+	result := dto.FromUserView(*view)
+	return &result, nil
+}
+
+// ListUsers 获取用户列表
+// 还有下一页时返回 NextCursor，用它继续查询（键集分页）不受翻页期间新增、删除用户的影响
+func (s *UserQueryService) ListUsers(ctx context.Context, q *query.ListUsersQuery) (*dto.UserListDTO, error) {
+	opts := repository.UserListOptions{
+		Filter: repository.UserFilter{
+			Status:        entity.UserStatus(q.Status),
+			Role:          entity.UserRole(q.Role),
+			CreatedFrom:   q.CreatedFrom,
+			CreatedBefore: q.CreatedBefore,
+			Keyword:       strings.TrimSpace(q.Keyword),
+		},
+		SortBy: repository.UserSortField(q.SortBy),
+		Desc:   q.Desc,
+		Offset: q.Offset,
+		Limit:  q.Limit + 1, // 多取一个，判断是否还有下一页
+	}
+	if opts.SortBy == "" {
+		opts.SortBy = repository.UserSortByID
+	}
+	if !opts.SortBy.IsValid() {
+		return nil, ErrInvalidSort
+	}
+	if q.Cursor != "" {
+		after, err := decodeUserCursor(q.Cursor, opts.SortBy, opts.Desc)
+		if err != nil {
+			return nil, err
+		}
+		opts.After = after
+	}
+
+	views, total, err := s.readModel.List(ctx, opts)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to list users")
+	}
+
+	result := &dto.UserListDTO{Total: total, Items: make([]dto.UserDTO, 0, len(views))}
+	if len(views) > q.Limit {
+		views = views[:q.Limit]
+		last := views[len(views)-1]
+		cursor := &repository.UserCursor{ID: last.ID, CreatedAt: last.CreatedAt, Username: last.Username}
+		result.NextCursor = encodeUserCursor(cursor, opts.SortBy, opts.Desc)
+	}
+	for _, v := range views {
+		result.Items = append(result.Items, dto.FromUserView(v))
+	}
+	return result, nil
+}
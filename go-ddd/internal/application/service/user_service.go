@@ -2,13 +2,18 @@ package service
 
 import (
 	"context"
+	"log"
+	"time"
 
 	"github.com/google/uuid"
 
+	"yiwen/go-ddd/internal/application/bus"
 	"yiwen/go-ddd/internal/application/command"
 	"yiwen/go-ddd/internal/application/dto"
 	"yiwen/go-ddd/internal/application/query"
 	"yiwen/go-ddd/internal/domain/aggregate"
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/event"
 	"yiwen/go-ddd/internal/domain/repository"
 	domainservice "yiwen/go-ddd/internal/domain/service"
 	"yiwen/go-ddd/internal/domain/valueobject"
@@ -23,32 +28,55 @@ import (
 // 4. 不包含业务逻辑（业务逻辑在领域层）
 type UserApplicationService struct {
 	userRepo          repository.UserRepository
+	uow               repository.UnitOfWork
 	userDomainService *domainservice.UserDomainService
+	passwords         *domainservice.PasswordService
+	loginGuard        *domainservice.LoginGuard
+	eventPublisher    event.EventPublisher
 }
 
 // NewUserApplicationService 创建用户应用服务
+// uow 为命令提供事务边界，passwords 按配置的策略创建和校验密码，loginGuard 限制登录失败的频率，
+// eventPublisher 用于在事务提交后将领域事件分发给进程内的处理器
 func NewUserApplicationService(
 	userRepo repository.UserRepository,
+	uow repository.UnitOfWork,
 	userDomainService *domainservice.UserDomainService,
+	passwords *domainservice.PasswordService,
+	loginGuard *domainservice.LoginGuard,
+	eventPublisher event.EventPublisher,
 ) *UserApplicationService {
 	return &UserApplicationService{
 		userRepo:          userRepo,
+		uow:               uow,
 		userDomainService: userDomainService,
+		passwords:         passwords,
+		loginGuard:        loginGuard,
+		eventPublisher:    eventPublisher,
 	}
 }
 
+// RegisterCommandHandlers 在命令总线上注册用户命令的处理器
+// 注册、修改密码需要在事务外计算密码哈希，自己管理事务
+func (s *UserApplicationService) RegisterCommandHandlers(commands *bus.Bus) {
+	bus.Register(commands, s.Register, bus.OwnTransaction())
+	bus.Register(commands, bus.NoResult(s.ChangePassword), bus.OwnTransaction())
+	bus.Register(commands, s.UpdateProfile)
+	bus.Register(commands, bus.NoResult(s.DeleteUser))
+	bus.Register(commands, s.BanUser)
+	bus.Register(commands, s.UnbanUser)
+	bus.Register(commands, s.ActivateUser)
+	bus.Register(commands, s.DeactivateUser)
+	bus.Register(commands, s.PromoteUser)
+	bus.Register(commands, s.DemoteUser)
+	bus.Register(commands, s.UnlockUser)
+	bus.Register(commands, s.TransferAdmin)
+}
+
 // Register 注册用户
+// 唯一性检查和写入在同一事务中；并发注册同名用户时由唯一索引兜底，
+// 仓储会把索引冲突翻译为 ErrUsernameAlreadyExists / ErrEmailAlreadyExists
 func (s *UserApplicationService) Register(ctx context.Context, cmd *command.RegisterUserCommand) (*dto.UserDTO, error) {
-	// 验证用户名唯一性
-	if err := s.userDomainService.ValidateUniqueUsername(ctx, cmd.Username); err != nil {
-		return nil, err
-	}
-
-	// 验证邮箱唯一性
-	if err := s.userDomainService.ValidateUniqueEmail(ctx, cmd.Email); err != nil {
-		return nil, err
-	}
-
 	// 创建邮箱值对象
 	email, err := valueobject.NewEmail(cmd.Email)
 	if err != nil {
@@ -56,41 +84,67 @@ func (s *UserApplicationService) Register(ctx context.Context, cmd *command.Regi
 	}
 
 	// 创建密码值对象
-	password, err := valueobject.NewPassword(cmd.Password)
+	password, err := s.passwords.NewPassword(cmd.Password)
 	if err != nil {
 		return nil, errors.Wrap(err, "invalid password")
 	}
 
-	// 使用聚合根创建用户
-	userAggregate := aggregate.Register(uuid.New().String(), cmd.Username, email, password)
-	userAggregate.User.Nickname = cmd.Nickname
-
-	// 保存用户
-	if err := s.userRepo.Save(ctx, userAggregate.User); err != nil {
-		return nil, errors.Wrap(err, "failed to save user")
+	var userAggregate *aggregate.UserAggregate
+	err = s.execute(ctx, func(ctx context.Context) ([]*aggregate.UserAggregate, error) {
+		// 验证用户名唯一性
+		if err := s.userDomainService.ValidateUniqueUsername(ctx, cmd.Username); err != nil {
+			return nil, err
+		}
+
+		// 验证邮箱唯一性
+		if err := s.userDomainService.ValidateUniqueEmail(ctx, cmd.Email); err != nil {
+			return nil, err
+		}
+
+		// 使用聚合根创建用户
+		userAggregate = aggregate.Register(uuid.New().String(), cmd.Username, email, password, cmd.Nickname)
+		return []*aggregate.UserAggregate{userAggregate}, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	// TODO: 发布领域事件
-	// for _, event := range userAggregate.GetUncommittedEvents() {
-	//     eventPublisher.Publish(event)
-	// }
-
 	result := dto.ToUserDTO(userAggregate.User)
 	return &result, nil
 }
 
 // Login 用户登录
+// 校验密码前先检查退避和锁定并预占本次尝试（预占即按失败计数，并发请求无法同时通过）；
+// 密码错误时保留计数，用户名的失败次数达到阈值时锁定用户聚合（user.locked）
 func (s *UserApplicationService) Login(ctx context.Context, q *query.LoginQuery) (*dto.UserDTO, error) {
-	user, err := s.userDomainService.ValidateUserCredentials(ctx, q.Username, q.Password)
+	reservation, err := s.loginGuard.Reserve(ctx, q.Username, q.ClientIP)
+	if err != nil {
+		return nil, err
+	}
+
+	user, needsRehash, err := s.userDomainService.ValidateUserCredentials(ctx, q.Username, q.Password)
+	if errors.Is(err, domainservice.ErrInvalidCredentials) {
+		s.recordLoginFailure(ctx, q.Username, reservation)
+		return nil, err
+	}
 	if err != nil {
+		s.releaseLoginAttempt(ctx, reservation)
 		return nil, err
 	}
 
+	if err := s.loginGuard.RecordSuccess(ctx, reservation); err != nil {
+		log.Printf("failed to reset login failures: %v", err)
+	}
+	if needsRehash {
+		s.rehashPassword(ctx, user, q.Password)
+	}
+
 	result := dto.ToUserDTO(user)
 	return &result, nil
 }
 
-// GetUserByID 根据ID获取用户
+// GetUserByID 从写模型读取用户，供需要最新状态的其他应用服务使用（如刷新令牌）
+// 接口层的查询走查询总线，由 UserQueryService 从读模型返回
 func (s *UserApplicationService) GetUserByID(ctx context.Context, q *query.GetUserByIDQuery) (*dto.UserDTO, error) {
 	user, err := s.userRepo.FindByID(ctx, q.UserID)
 	if err != nil {
@@ -101,82 +155,258 @@ func (s *UserApplicationService) GetUserByID(ctx context.Context, q *query.GetUs
 	return &result, nil
 }
 
-// GetUserByUUID 根据UUID获取用户
-func (s *UserApplicationService) GetUserByUUID(ctx context.Context, q *query.GetUserByUUIDQuery) (*dto.UserDTO, error) {
-	user, err := s.userRepo.FindByUUID(ctx, q.UUID)
+// UpdateProfile 更新用户资料
+func (s *UserApplicationService) UpdateProfile(ctx context.Context, cmd *command.UpdateProfileCommand) (*dto.UserDTO, error) {
+	return s.changeUser(ctx, cmd.UserID, func(ctx context.Context, agg *aggregate.UserAggregate) error {
+		// 使用聚合根更新资料
+		agg.UpdateProfile(cmd.Nickname, cmd.Avatar)
+		return nil
+	})
+}
+
+// ChangePassword 修改密码
+func (s *UserApplicationService) ChangePassword(ctx context.Context, cmd *command.ChangePasswordCommand) error {
+	// 创建新密码（哈希计算较慢，放在事务之外）
+	newPassword, err := s.passwords.NewPassword(cmd.NewPassword)
 	if err != nil {
-		return nil, errors.Wrap(err, "user not found")
+		return errors.Wrap(err, "invalid new password")
 	}
 
-	result := dto.ToUserDTO(user)
-	return &result, nil
+	_, err = s.changeUser(ctx, cmd.UserID, func(ctx context.Context, agg *aggregate.UserAggregate) error {
+		// 验证旧密码
+		if err := agg.User.Password.Verify(cmd.OldPassword); err != nil {
+			return domainservice.ErrInvalidCredentials
+		}
+
+		// 不能重复使用当前密码和最近的旧密码
+		if err := s.passwords.EnsureNotReused(agg.User, cmd.NewPassword); err != nil {
+			return err
+		}
+
+		// 使用聚合根修改密码
+		agg.ChangePassword(newPassword, s.passwords.HistorySize())
+		return nil
+	})
+	return err
 }
 
-// ListUsers 获取用户列表
-func (s *UserApplicationService) ListUsers(ctx context.Context, q *query.ListUsersQuery) (*dto.UserListDTO, error) {
-	users, total, err := s.userRepo.List(ctx, q.Offset, q.Limit)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to list users")
-	}
+// DeleteUser 删除用户
+func (s *UserApplicationService) DeleteUser(ctx context.Context, cmd *command.DeleteUserCommand) error {
+	_, err := s.changeUser(ctx, cmd.UserID, func(ctx context.Context, agg *aggregate.UserAggregate) error {
+		if err := s.userDomainService.EnsureNotLastAdmin(ctx, agg.User); err != nil {
+			return err
+		}
+		// 使用聚合根删除用户
+		agg.Delete()
+		return nil
+	})
+	return err
+}
 
-	return &dto.UserListDTO{
-		Total: total,
-		Items: dto.ToUserDTOList(users),
-	}, nil
+// BanUser 禁用用户
+func (s *UserApplicationService) BanUser(ctx context.Context, cmd *command.BanUserCommand) (*dto.UserDTO, error) {
+	return s.changeUser(ctx, cmd.UserID, func(ctx context.Context, agg *aggregate.UserAggregate) error {
+		if err := s.userDomainService.EnsureNotLastAdmin(ctx, agg.User); err != nil {
+			return err
+		}
+		return agg.Ban(cmd.Reason)
+	})
 }
 
-// UpdateProfile 更新用户资料
-func (s *UserApplicationService) UpdateProfile(ctx context.Context, cmd *command.UpdateProfileCommand) (*dto.UserDTO, error) {
-	user, err := s.userRepo.FindByID(ctx, cmd.UserID)
+// UnbanUser 解除禁用
+func (s *UserApplicationService) UnbanUser(ctx context.Context, cmd *command.UnbanUserCommand) (*dto.UserDTO, error) {
+	return s.changeUser(ctx, cmd.UserID, func(ctx context.Context, agg *aggregate.UserAggregate) error {
+		return agg.Unban(cmd.Reason)
+	})
+}
+
+// ActivateUser 激活用户
+func (s *UserApplicationService) ActivateUser(ctx context.Context, cmd *command.ActivateUserCommand) (*dto.UserDTO, error) {
+	return s.changeUser(ctx, cmd.UserID, func(ctx context.Context, agg *aggregate.UserAggregate) error {
+		agg.Activate()
+		return nil
+	})
+}
+
+// DeactivateUser 停用用户
+func (s *UserApplicationService) DeactivateUser(ctx context.Context, cmd *command.DeactivateUserCommand) (*dto.UserDTO, error) {
+	return s.changeUser(ctx, cmd.UserID, func(ctx context.Context, agg *aggregate.UserAggregate) error {
+		if err := s.userDomainService.EnsureNotLastAdmin(ctx, agg.User); err != nil {
+			return err
+		}
+		return agg.Deactivate(cmd.Reason)
+	})
+}
+
+// PromoteUser 提升为管理员
+func (s *UserApplicationService) PromoteUser(ctx context.Context, cmd *command.PromoteUserCommand) (*dto.UserDTO, error) {
+	return s.changeUser(ctx, cmd.UserID, func(ctx context.Context, agg *aggregate.UserAggregate) error {
+		agg.PromoteToAdmin()
+		return nil
+	})
+}
+
+// DemoteUser 管理员降级为普通用户
+func (s *UserApplicationService) DemoteUser(ctx context.Context, cmd *command.DemoteUserCommand) (*dto.UserDTO, error) {
+	return s.changeUser(ctx, cmd.UserID, func(ctx context.Context, agg *aggregate.UserAggregate) error {
+		if err := s.userDomainService.EnsureNotLastAdmin(ctx, agg.User); err != nil {
+			return err
+		}
+		return agg.Demote(cmd.Reason)
+	})
+}
+
+// TransferAdmin 将管理员权限转移给目标用户，两个用户的变更在同一事务中保存
+func (s *UserApplicationService) TransferAdmin(ctx context.Context, cmd *command.TransferAdminCommand) (*dto.UserDTO, error) {
+	var to *aggregate.UserAggregate
+	err := s.execute(ctx, func(ctx context.Context) ([]*aggregate.UserAggregate, error) {
+		from, err := s.userRepo.FindAggregateByID(ctx, cmd.FromUserID)
+		if err != nil {
+			return nil, errors.Wrap(err, "user not found")
+		}
+		to, err = s.userRepo.FindAggregateByID(ctx, cmd.ToUserID)
+		if err != nil {
+			return nil, errors.Wrap(err, "user not found")
+		}
+
+		if err := s.userDomainService.TransferAdmin(ctx, from, to, cmd.Reason); err != nil {
+			return nil, err
+		}
+		return []*aggregate.UserAggregate{from, to}, nil
+	})
 	if err != nil {
-		return nil, errors.Wrap(err, "user not found")
+		return nil, err
 	}
 
-	// 使用聚合根更新资料
-	userAggregate := aggregate.NewUserAggregate(user)
-	userAggregate.UpdateProfile(cmd.Nickname, cmd.Avatar)
+	result := dto.ToUserDTO(to.User)
+	return &result, nil
+}
 
-	if err := s.userRepo.Save(ctx, userAggregate.User); err != nil {
-		return nil, errors.Wrap(err, "failed to update user")
+// UnlockUser 管理员解除登录锁定，同时清除该用户名的失败计数
+func (s *UserApplicationService) UnlockUser(ctx context.Context, cmd *command.UnlockUserCommand) (*dto.UserDTO, error) {
+	return s.changeUser(ctx, cmd.UserID, func(ctx context.Context, agg *aggregate.UserAggregate) error {
+		if err := agg.Unlock(cmd.Reason); err != nil {
+			return err
+		}
+		return s.loginGuard.Reset(ctx, agg.User.Username)
+	})
+}
+
+// recordLoginFailure 确认一次登录失败（密码或两步验证码错误），达到阈值时锁定用户
+func (s *UserApplicationService) recordLoginFailure(ctx context.Context, username string, reservation *domainservice.LoginReservation) {
+	if lockedUntil, failures := s.loginGuard.RecordFailure(reservation); lockedUntil != nil {
+		s.lockUser(ctx, username, *lockedUntil, failures)
 	}
+}
 
-	result := dto.ToUserDTO(userAggregate.User)
-	return &result, nil
+// releaseLoginAttempt 尝试因密码或验证码以外的原因结束时撤销预占
+func (s *UserApplicationService) releaseLoginAttempt(ctx context.Context, reservation *domainservice.LoginReservation) {
+	if err := s.loginGuard.Release(ctx, reservation); err != nil {
+		log.Printf("failed to release login attempt: %v", err)
+	}
 }
 
-// ChangePassword 修改密码
-func (s *UserApplicationService) ChangePassword(ctx context.Context, cmd *command.ChangePasswordCommand) error {
-	user, err := s.userRepo.FindByID(ctx, cmd.UserID)
+// rehashPassword 登录成功后将过时的密码哈希升级为当前配置，失败只记录日志
+// 事务中确认哈希未被并发修改，避免覆盖刚刚修改的密码
+func (s *UserApplicationService) rehashPassword(ctx context.Context, user *entity.User, plaintext string) {
+	password, err := s.passwords.Rehash(plaintext)
 	if err != nil {
-		return errors.Wrap(err, "user not found")
+		log.Printf("failed to rehash password for user %s: %v", user.Username, err)
+		return
 	}
-
-	// 验证旧密码
-	if err := user.Password.Verify(cmd.OldPassword); err != nil {
-		return domainservice.ErrInvalidCredentials
+	_, err = s.changeUser(ctx, user.ID, func(ctx context.Context, agg *aggregate.UserAggregate) error {
+		if agg.User.Password.Hash() == user.Password.Hash() {
+			agg.RehashPassword(password)
+		}
+		return nil
+	})
+	if err != nil {
+		log.Printf("failed to rehash password for user %s: %v", user.Username, err)
 	}
+}
 
-	// 创建新密码
-	newPassword, err := valueobject.NewPassword(cmd.NewPassword)
+// lockUser 锁定存在的用户；用户名不存在时计数照常生效，只是没有聚合可以锁定
+func (s *UserApplicationService) lockUser(ctx context.Context, username string, until time.Time, failures int) {
+	user, err := s.userRepo.FindByUsername(ctx, username)
 	if err != nil {
-		return errors.Wrap(err, "invalid new password")
+		return
+	}
+	_, err = s.changeUser(ctx, user.ID, func(ctx context.Context, agg *aggregate.UserAggregate) error {
+		agg.Lock(until, failures)
+		return nil
+	})
+	if err != nil {
+		log.Printf("failed to lock user %s: %v", username, err)
 	}
+}
 
-	// 使用聚合根修改密码
-	userAggregate := aggregate.NewUserAggregate(user)
-	userAggregate.ChangePassword(newPassword)
+// CheckUserAccess 检查用户当前是否允许访问，返回其最新角色
+// 供认证中间件在每次请求时调用，使禁用、停用、降级立即生效，而不必等待 Token 过期
+func (s *UserApplicationService) CheckUserAccess(ctx context.Context, userID uint64) (string, error) {
+	user, err := s.userRepo.FindByID(ctx, userID)
+	if err != nil {
+		return "", domainservice.ErrUserNotFound
+	}
+	if !user.IsActive() {
+		return "", domainservice.ErrUserNotActive
+	}
+	return string(user.Role), nil
+}
 
-	if err := s.userRepo.Save(ctx, userAggregate.User); err != nil {
-		return errors.Wrap(err, "failed to update password")
+// changeUser 在事务中加载用户聚合并执行变更
+func (s *UserApplicationService) changeUser(ctx context.Context, userID uint64, change func(ctx context.Context, agg *aggregate.UserAggregate) error) (*dto.UserDTO, error) {
+	var userAggregate *aggregate.UserAggregate
+	err := s.execute(ctx, func(ctx context.Context) ([]*aggregate.UserAggregate, error) {
+		var err error
+		userAggregate, err = s.userRepo.FindAggregateByID(ctx, userID)
+		if err != nil {
+			return nil, errors.Wrap(err, "user not found")
+		}
+
+		if err := change(ctx, userAggregate); err != nil {
+			return nil, err
+		}
+		return []*aggregate.UserAggregate{userAggregate}, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return nil
+	result := dto.ToUserDTO(userAggregate.User)
+	return &result, nil
 }
 
-// DeleteUser 删除用户
-func (s *UserApplicationService) DeleteUser(ctx context.Context, cmd *command.DeleteUserCommand) error {
-	if err := s.userRepo.Delete(ctx, cmd.UserID); err != nil {
-		return errors.Wrap(err, "failed to delete user")
+// execute 在工作单元中执行命令：fn 加载并修改聚合，返回需要保存的聚合，
+// 聚合及其事件（发件箱）在同一事务中保存。遇到死锁时工作单元会重新执行 fn
+// 事务提交后再发布并清除事件；由命令总线的 Transactional 开启的事务中，事件交给中间件在外层事务提交后发布
+// 进程内发布失败只记录日志，可靠投递由发件箱中继保证
+func (s *UserApplicationService) execute(ctx context.Context, fn func(ctx context.Context) ([]*aggregate.UserAggregate, error)) error {
+	var aggs []*aggregate.UserAggregate
+	err := s.uow.Do(ctx, func(ctx context.Context) error {
+		var err error
+		if aggs, err = fn(ctx); err != nil {
+			return err
+		}
+		if err := s.userRepo.SaveAggregates(ctx, aggs...); err != nil {
+			return errors.Wrap(err, "failed to save user")
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	var events []event.Event
+	for _, agg := range aggs {
+		events = append(events, agg.GetUncommittedEvents()...)
+	}
+	if s.eventPublisher != nil && len(events) > 0 && !bus.DeferEvents(ctx, events...) {
+		if err := s.eventPublisher.Publish(events...); err != nil {
+			log.Printf("failed to publish domain events: %v", err)
+		}
+	}
+	for _, agg := range aggs {
+		agg.ClearEvents()
 	}
 	return nil
 }
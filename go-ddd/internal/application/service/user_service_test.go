@@ -0,0 +1,162 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"yiwen/go-ddd/internal/application/command"
+	"yiwen/go-ddd/internal/application/query"
+	"yiwen/go-ddd/internal/application/service"
+	"yiwen/go-ddd/internal/domain/aggregate"
+	"yiwen/go-ddd/internal/domain/repository"
+	domainservice "yiwen/go-ddd/internal/domain/service"
+	"yiwen/go-ddd/internal/domain/valueobject"
+	"yiwen/go-ddd/internal/infrastructure/persistence/memory"
+)
+
+func newUserService(t *testing.T) (*service.UserApplicationService, repository.UserRepository) {
+	t.Helper()
+	hasher, err := valueobject.NewPasswordHasher("bcrypt", 4, valueobject.Argon2Params{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return newUserServiceWithHasher(t, hasher)
+}
+
+func newUserServiceWithHasher(t *testing.T, hasher *valueobject.PasswordHasher) (*service.UserApplicationService, repository.UserRepository) {
+	t.Helper()
+	users := memory.NewUserRepository()
+	passwords := domainservice.NewPasswordService(valueobject.PasswordPolicy{MinLength: 8}, hasher)
+	loginGuard := domainservice.NewLoginGuard(memory.NewLoginAttemptRepository(), domainservice.LoginThrottlePolicy{}, domainservice.LoginThrottlePolicy{})
+	svc := service.NewUserApplicationService(users, memory.NewUnitOfWork(), domainservice.NewUserDomainService(users, passwords), passwords, loginGuard, nil)
+	return svc, users
+}
+
+// saveAdmin 保存一个已验证邮箱的激活管理员
+func saveAdmin(t *testing.T, users repository.UserRepository, name string) uint64 {
+	t.Helper()
+	email, err := valueobject.NewEmail(name + "@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	agg := aggregate.Register("uuid-"+name, name, email, valueobject.NewPasswordFromHash("hash"), name)
+	agg.VerifyEmail()
+	agg.PromoteToAdmin()
+	if err := users.SaveAggregate(context.Background(), agg); err != nil {
+		t.Fatal(err)
+	}
+	found, err := users.FindByUsername(context.Background(), name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return found.ID
+}
+
+func TestLastAdminCannotBeRemoved(t *testing.T) {
+	operations := []struct {
+		name string
+		run  func(ctx context.Context, svc *service.UserApplicationService, id uint64) error
+	}{
+		{"Demote", func(ctx context.Context, svc *service.UserApplicationService, id uint64) error {
+			_, err := svc.DemoteUser(ctx, command.NewDemoteUserCommand(id, "test"))
+			return err
+		}},
+		{"Ban", func(ctx context.Context, svc *service.UserApplicationService, id uint64) error {
+			_, err := svc.BanUser(ctx, command.NewBanUserCommand(id, "test"))
+			return err
+		}},
+		{"Deactivate", func(ctx context.Context, svc *service.UserApplicationService, id uint64) error {
+			_, err := svc.DeactivateUser(ctx, command.NewDeactivateUserCommand(id, "test"))
+			return err
+		}},
+		{"Delete", func(ctx context.Context, svc *service.UserApplicationService, id uint64) error {
+			return svc.DeleteUser(ctx, command.NewDeleteUserCommand(id))
+		}},
+	}
+
+	for _, op := range operations {
+		t.Run(op.name, func(t *testing.T) {
+			ctx := context.Background()
+			svc, users := newUserService(t)
+			alice := saveAdmin(t, users, "alice")
+			bob := saveAdmin(t, users, "bob")
+
+			// 还有其他管理员时允许
+			if err := op.run(ctx, svc, alice); err != nil {
+				t.Fatalf("expected %s to succeed while another admin exists, got %v", op.name, err)
+			}
+
+			// 最后一个激活的管理员不允许
+			if err := op.run(ctx, svc, bob); !errors.Is(err, domainservice.ErrLastAdmin) {
+				t.Fatalf("expected ErrLastAdmin, got %v", err)
+			}
+			user, err := users.FindByID(ctx, bob)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !user.IsAdmin() || !user.IsActive() {
+				t.Fatalf("last admin was changed: role %s, status %d", user.Role, user.Status)
+			}
+		})
+	}
+}
+
+func TestLoginRehashesOutdatedPassword(t *testing.T) {
+	ctx := context.Background()
+	bcrypt4, err := valueobject.NewPasswordHasher("bcrypt", 4, valueobject.Argon2Params{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	argon2id, err := valueobject.NewPasswordHasher("argon2id", 0, valueobject.Argon2Params{Memory: 64, Iterations: 1, Parallelism: 1, SaltLength: 8, KeyLength: 16})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		configured *valueobject.PasswordHasher
+		password   string
+		wantErr    bool
+		wantRehash bool
+	}{
+		{"outdated algorithm is rehashed", argon2id, "Secret123", false, true},
+		{"current algorithm is kept", bcrypt4, "Secret123", false, false},
+		{"wrong password does not rehash", argon2id, "Wrong1234", true, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc, users := newUserServiceWithHasher(t, tt.configured)
+			id := saveAdmin(t, users, "alice")
+			// 以旧配置（bcrypt cost 4）计算的哈希保存密码
+			old, err := bcrypt4.Hash("Secret123")
+			if err != nil {
+				t.Fatal(err)
+			}
+			agg, err := users.FindAggregateByID(ctx, id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			agg.User.Password = old
+			if err := users.SaveAggregate(ctx, agg); err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = svc.Login(ctx, query.NewLoginQuery("alice", tt.password, "203.0.113.7"))
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("login error = %v, wantErr %v", err, tt.wantErr)
+			}
+			user, err := users.FindByID(ctx, id)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if tt.wantRehash != strings.HasPrefix(user.Password.Hash(), "$argon2id$") || !tt.wantRehash && user.Password.Hash() != old.Hash() {
+				t.Fatalf("stored hash %q, want rehash %v", user.Password.Hash(), tt.wantRehash)
+			}
+			if err := user.Password.Verify("Secret123"); err != nil {
+				t.Fatalf("stored hash no longer verifies the password: %v", err)
+			}
+		})
+	}
+}
@@ -1,11 +1,29 @@
 package aggregate
 
 import (
+	"errors"
+	"strings"
+	"time"
+
 	"yiwen/go-ddd/internal/domain/entity"
 	"yiwen/go-ddd/internal/domain/event"
 	"yiwen/go-ddd/internal/domain/valueobject"
 )
 
+// ErrReasonRequired 禁用、解禁、停用、降级等管理操作必须填写原因
+var ErrReasonRequired = errors.New("reason is required")
+
+var (
+	ErrMFAAlreadyEnabled = errors.New("two-factor authentication is already enabled")
+	ErrMFANotEnrolled    = errors.New("two-factor authentication is not enrolled")
+	ErrInvalidMFACode    = errors.New("invalid two-factor authentication code")
+)
+
+var (
+	ErrErasureNotRequested = errors.New("account deletion has not been requested")
+	ErrErasureNotDue       = errors.New("account deletion grace period has not ended")
+)
+
 // UserAggregate 用户聚合根
 // 聚合是DDD中的重要概念：
 // 1. 聚合是一组相关对象的集合
@@ -13,8 +31,9 @@ import (
 // 3. 聚合根负责维护聚合内的一致性
 // 4. 聚合根可以发布领域事件
 type UserAggregate struct {
-	User   *entity.User    // 用户实体（聚合根实体）
-	Events []event.Event   // 待发布的领域事件
+	User    *entity.User  // 用户实体（聚合根实体）
+	Events  []event.Event // 待发布的领域事件
+	Version int           // 已持久化的事件数，用于事件存储的乐观并发控制
 }
 
 // NewUserAggregate 创建用户聚合
@@ -26,31 +45,60 @@ func NewUserAggregate(user *entity.User) *UserAggregate {
 }
 
 // Register 注册新用户
-func Register(uuid, username string, email valueobject.Email, password valueobject.Password) *UserAggregate {
+// 新用户处于等待验证状态，验证邮箱后激活（见 VerifyEmail）
+func Register(uuid, username string, email valueobject.Email, password valueobject.Password, nickname string) *UserAggregate {
 	user := entity.NewUser(uuid, username, email, password)
+	user.Nickname = nickname
+	user.Status = entity.UserStatusPending
 	agg := NewUserAggregate(user)
 
 	// 发布用户注册事件
-	agg.addEvent(event.NewUserRegisteredEvent(uuid, username, email.String()))
+	agg.addEvent(event.NewUserRegisteredEvent(uuid, username, email.String(), nickname, password.Hash(), int(user.Status)))
 
 	return agg
 }
 
 // UpdateProfile 更新用户资料
 func (a *UserAggregate) UpdateProfile(nickname, avatar string) {
-	oldNickname := a.User.Nickname
+	oldNickname, oldAvatar := a.User.Nickname, a.User.Avatar
 	a.User.UpdateProfile(nickname, avatar)
 
 	// 发布资料更新事件
-	a.addEvent(event.NewUserProfileUpdatedEvent(a.User.UUID, oldNickname, nickname))
+	a.addEvent(event.NewUserProfileUpdatedEvent(a.User.UUID, oldNickname, nickname, oldAvatar, avatar))
 }
 
-// ChangePassword 修改密码
-func (a *UserAggregate) ChangePassword(newPassword valueobject.Password) {
-	a.User.ChangePassword(newPassword)
+// ChangePassword 修改密码，旧密码记入历史（最多保留 historySize 个）
+func (a *UserAggregate) ChangePassword(newPassword valueobject.Password, historySize int) {
+	a.User.ChangePassword(newPassword, historySize)
 
 	// 发布密码修改事件
-	a.addEvent(event.NewUserPasswordChangedEvent(a.User.UUID))
+	a.addEvent(event.NewUserPasswordChangedEvent(a.User.UUID, newPassword.Hash(), a.User.PasswordHistory))
+}
+
+// RehashPassword 将过时的密码哈希升级为当前配置的算法和成本，密码本身不变
+func (a *UserAggregate) RehashPassword(password valueobject.Password) {
+	a.User.RehashPassword(password)
+	a.addEvent(event.NewUserPasswordRehashedEvent(a.User.UUID, password.Hash()))
+}
+
+// VerifyEmail 验证邮箱，已验证时不重复产生事件
+func (a *UserAggregate) VerifyEmail() {
+	if a.User.IsEmailVerified() {
+		return
+	}
+	a.User.VerifyEmail()
+	a.addEvent(event.NewUserEmailVerifiedEvent(a.User.UUID, a.User.Email.String()))
+}
+
+// RequestPasswordReset 申请重置密码
+func (a *UserAggregate) RequestPasswordReset() {
+	a.addEvent(event.NewPasswordResetRequestedEvent(a.User.UUID, a.User.Email.String()))
+}
+
+// ResetPassword 通过重置令牌设置新密码（不需要旧密码），旧密码记入历史
+func (a *UserAggregate) ResetPassword(newPassword valueobject.Password, historySize int) {
+	a.User.ChangePassword(newPassword, historySize)
+	a.addEvent(event.NewUserPasswordResetEvent(a.User.UUID, newPassword.Hash(), a.User.PasswordHistory))
 }
 
 // Activate 激活用户
@@ -63,21 +111,130 @@ func (a *UserAggregate) Activate() {
 }
 
 // Deactivate 停用用户
-func (a *UserAggregate) Deactivate() {
+func (a *UserAggregate) Deactivate(reason string) error {
+	if err := requireReason(reason); err != nil {
+		return err
+	}
 	if a.User.Status == entity.UserStatusInactive {
-		return
+		return nil
 	}
 	a.User.Deactivate()
-	a.addEvent(event.NewUserDeactivatedEvent(a.User.UUID))
+	a.addEvent(event.NewUserDeactivatedEvent(a.User.UUID, reason))
+	return nil
 }
 
 // Ban 禁用用户
-func (a *UserAggregate) Ban(reason string) {
+func (a *UserAggregate) Ban(reason string) error {
+	if err := requireReason(reason); err != nil {
+		return err
+	}
 	if a.User.Status == entity.UserStatusBanned {
-		return
+		return nil
 	}
 	a.User.Ban()
 	a.addEvent(event.NewUserBannedEvent(a.User.UUID, reason))
+	return nil
+}
+
+// Unban 解除禁用，只对已禁用的用户生效
+func (a *UserAggregate) Unban(reason string) error {
+	if err := requireReason(reason); err != nil {
+		return err
+	}
+	if !a.User.IsBanned() {
+		return nil
+	}
+	a.User.Unban()
+	a.addEvent(event.NewUserUnbannedEvent(a.User.UUID, reason))
+	return nil
+}
+
+// Lock 因连续登录失败临时锁定账户
+func (a *UserAggregate) Lock(until time.Time, failedAttempts int) {
+	if a.User.LockedUntil != nil && !a.User.LockedUntil.Before(until) {
+		return
+	}
+	a.User.Lock(until)
+	a.addEvent(event.NewUserLockedEvent(a.User.UUID, until, failedAttempts))
+}
+
+// Unlock 管理员解除登录锁定，只对锁定期内的用户生效
+func (a *UserAggregate) Unlock(reason string) error {
+	if err := requireReason(reason); err != nil {
+		return err
+	}
+	if !a.User.IsLocked(time.Now()) {
+		return nil
+	}
+	a.User.Unlock()
+	a.addEvent(event.NewUserUnlockedEvent(a.User.UUID, reason))
+	return nil
+}
+
+// BeginMFAEnrollment 开始绑定两步验证：保存新密钥和恢复码哈希，确认验证码后才启用
+// 已启用时需先关闭，避免在未验证新密钥的情况下替换掉正在使用的密钥
+func (a *UserAggregate) BeginMFAEnrollment(secret valueobject.TOTPSecret, recoveryCodes []string) error {
+	if a.User.IsMFAEnabled() {
+		return ErrMFAAlreadyEnabled
+	}
+	a.User.BeginMFAEnrollment(secret, recoveryCodes)
+	a.addEvent(event.NewUserMFAEnrollmentStartedEvent(a.User.UUID, secret.Ciphertext(), recoveryCodes))
+	return nil
+}
+
+// ConfirmMFA 用验证器生成的验证码确认绑定，启用两步验证
+func (a *UserAggregate) ConfirmMFA(code string, now time.Time, cipher valueobject.SecretCipher) error {
+	if a.User.IsMFAEnabled() {
+		return ErrMFAAlreadyEnabled
+	}
+	if a.User.TOTPSecret.IsZero() {
+		return ErrMFANotEnrolled
+	}
+	step, ok, err := a.User.TOTPSecret.Verify(code, now, a.User.TOTPLastStep, cipher)
+	if err != nil {
+		return err
+	}
+	if !ok {
+		return ErrInvalidMFACode
+	}
+	a.User.EnableMFA()
+	a.User.UseTOTPStep(step)
+	a.addEvent(event.NewUserMFAEnabledEvent(a.User.UUID, step))
+	return nil
+}
+
+// VerifyMFA 登录时校验第二因素：验证器验证码或一次性恢复码
+// 验证码只能使用一次，通过后记录其时间步并产生 user.totp_used 事件；
+// 使用恢复码时将其消耗并产生 user.recovery_code_used 事件
+func (a *UserAggregate) VerifyMFA(code string, now time.Time, cipher valueobject.SecretCipher) error {
+	if !a.User.IsMFAEnabled() {
+		return ErrMFANotEnrolled
+	}
+	step, ok, err := a.User.TOTPSecret.Verify(code, now, a.User.TOTPLastStep, cipher)
+	if err != nil {
+		return err
+	}
+	if ok {
+		a.User.UseTOTPStep(step)
+		a.addEvent(event.NewUserTOTPUsedEvent(a.User.UUID, step))
+		return nil
+	}
+
+	hash := valueobject.HashRecoveryCode(code)
+	if !a.User.UseRecoveryCode(hash) {
+		return ErrInvalidMFACode
+	}
+	a.addEvent(event.NewUserRecoveryCodeUsedEvent(a.User.UUID, hash, len(a.User.RecoveryCodes)))
+	return nil
+}
+
+// DisableMFA 关闭两步验证，也用于放弃尚未确认的绑定
+func (a *UserAggregate) DisableMFA() {
+	if a.User.TOTPSecret.IsZero() && !a.User.MFAEnabled {
+		return
+	}
+	a.User.DisableMFA()
+	a.addEvent(event.NewUserMFADisabledEvent(a.User.UUID))
 }
 
 // PromoteToAdmin 提升为管理员
@@ -89,6 +246,96 @@ func (a *UserAggregate) PromoteToAdmin() {
 	a.addEvent(event.NewUserPromotedEvent(a.User.UUID))
 }
 
+// Demote 将管理员降级为普通用户
+func (a *UserAggregate) Demote(reason string) error {
+	if err := requireReason(reason); err != nil {
+		return err
+	}
+	if !a.User.IsAdmin() {
+		return nil
+	}
+	a.User.DemoteToUser()
+	a.addEvent(event.NewUserDemotedEvent(a.User.UUID, reason))
+	return nil
+}
+
+// HandOverAdmin 将管理员权限转交给目标用户：自身降级并记录转移事件
+// 目标用户的提升由调用方（领域服务）在目标聚合上执行
+func (a *UserAggregate) HandOverAdmin(toUUID, reason string) error {
+	if err := requireReason(reason); err != nil {
+		return err
+	}
+	if !a.User.IsAdmin() {
+		return nil
+	}
+	a.User.DemoteToUser()
+	a.addEvent(event.NewUserDemotedEvent(a.User.UUID, reason))
+	a.addEvent(event.NewAdminTransferredEvent(a.User.UUID, toUUID, reason))
+	return nil
+}
+
+// Delete 删除用户（软删除）
+func (a *UserAggregate) Delete() {
+	if a.User.IsDeleted() {
+		return
+	}
+	a.User.MarkDeleted()
+	a.addEvent(event.NewUserDeletedEvent(a.User.UUID))
+}
+
+// RecordDataExport 记录用户导出了个人数据，不改变状态
+func (a *UserAggregate) RecordDataExport() {
+	a.addEvent(event.NewUserDataExportedEvent(a.User.UUID))
+}
+
+// RequestErasure 申请删除账户，宽限期 grace 结束后擦除个人数据；已申请时不重复产生事件
+func (a *UserAggregate) RequestErasure(now time.Time, grace time.Duration) {
+	if a.User.IsErasurePending() {
+		return
+	}
+	a.User.ScheduleErasure(now, now.Add(grace))
+	a.addEvent(event.NewUserErasureRequestedEvent(a.User.UUID, *a.User.ErasureScheduledAt))
+}
+
+// CancelErasure 在宽限期内撤销删除申请
+func (a *UserAggregate) CancelErasure() error {
+	if !a.User.IsErasurePending() {
+		return ErrErasureNotRequested
+	}
+	a.User.CancelErasure()
+	a.addEvent(event.NewUserErasureCancelledEvent(a.User.UUID))
+	return nil
+}
+
+// Erase 宽限期结束后擦除个人数据，用户随之软删除
+func (a *UserAggregate) Erase(now time.Time) error {
+	if !a.User.IsErasurePending() {
+		return ErrErasureNotRequested
+	}
+	if now.Before(*a.User.ErasureScheduledAt) {
+		return ErrErasureNotDue
+	}
+	username, email := erasedIdentity(a.User.UUID)
+	a.User.Anonymize(username, email, now)
+	a.addEvent(event.NewUserErasedEvent(a.User.UUID, username, email.String()))
+	return nil
+}
+
+// erasedIdentity 擦除后使用的用户名和邮箱，由 UUID 生成，唯一且不含个人信息
+func erasedIdentity(uuid string) (string, valueobject.Email) {
+	id := "erased-" + strings.ToLower(strings.ReplaceAll(uuid, "-", ""))
+	email, _ := valueobject.NewEmail(id + "@erased.invalid")
+	return id, email
+}
+
+// requireReason 检查原因不为空
+func requireReason(reason string) error {
+	if strings.TrimSpace(reason) == "" {
+		return ErrReasonRequired
+	}
+	return nil
+}
+
 // addEvent 添加领域事件
 func (a *UserAggregate) addEvent(e event.Event) {
 	a.Events = append(a.Events, e)
@@ -0,0 +1,58 @@
+package aggregate_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"yiwen/go-ddd/internal/domain/aggregate"
+	"yiwen/go-ddd/internal/domain/valueobject"
+)
+
+// plainCipher 不加密，密文即明文，便于使用 RFC 6238 的测试密钥
+type plainCipher struct{}
+
+func (plainCipher) Encrypt(plaintext []byte) (string, error)  { return string(plaintext), nil }
+func (plainCipher) Decrypt(ciphertext string) ([]byte, error) { return []byte(ciphertext), nil }
+
+func TestTOTPCodeCannotBeReused(t *testing.T) {
+	email, err := valueobject.NewEmail("alice@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	agg := aggregate.Register("uuid-alice", "alice", email, valueobject.NewPasswordFromHash("hash"), "alice")
+	// RFC 6238 测试密钥：T=1111111111 的验证码为 050471，上一个时间步为 081804
+	if err := agg.BeginMFAEnrollment(valueobject.NewTOTPSecretFromCiphertext("12345678901234567890"), nil); err != nil {
+		t.Fatal(err)
+	}
+	now := time.Unix(1111111111, 0)
+
+	steps := []struct {
+		name    string
+		run     func(code string) error
+		code    string
+		wantErr error
+	}{
+		{"confirm enrollment", func(code string) error { return agg.ConfirmMFA(code, now, plainCipher{}) }, "081804", nil},
+		{"reuse confirmation code", func(code string) error { return agg.VerifyMFA(code, now, plainCipher{}) }, "081804", aggregate.ErrInvalidMFACode},
+		{"login with next code", func(code string) error { return agg.VerifyMFA(code, now, plainCipher{}) }, "050471", nil},
+		{"replay login code", func(code string) error { return agg.VerifyMFA(code, now, plainCipher{}) }, "050471", aggregate.ErrInvalidMFACode},
+	}
+	for _, s := range steps {
+		if err := s.run(s.code); !errors.Is(err, s.wantErr) {
+			t.Fatalf("%s: expected %v, got %v", s.name, s.wantErr, err)
+		}
+	}
+
+	// 事件溯源模式下从事件重建的聚合同样拒绝已使用的验证码
+	rebuilt, err := aggregate.LoadUserFromHistory(agg.GetUncommittedEvents())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rebuilt.User.TOTPLastStep != agg.User.TOTPLastStep {
+		t.Fatalf("rebuilt last step %d, want %d", rebuilt.User.TOTPLastStep, agg.User.TOTPLastStep)
+	}
+	if err := rebuilt.VerifyMFA("050471", now, plainCipher{}); !errors.Is(err, aggregate.ErrInvalidMFACode) {
+		t.Fatalf("expected replay to be rejected after rebuild, got %v", err)
+	}
+}
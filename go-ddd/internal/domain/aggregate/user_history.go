@@ -0,0 +1,268 @@
+package aggregate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/event"
+	"yiwen/go-ddd/internal/domain/valueobject"
+)
+
+// ErrEmptyHistory 事件历史为空，聚合不存在
+var ErrEmptyHistory = errors.New("aggregate has no history")
+
+// LoadUserFromHistory 通过重放事件重建用户聚合
+// 事件溯源：聚合的当前状态 = 按顺序应用全部历史事件
+func LoadUserFromHistory(history []event.Event) (*UserAggregate, error) {
+	if len(history) == 0 {
+		return nil, ErrEmptyHistory
+	}
+
+	agg := &UserAggregate{Events: make([]event.Event, 0)}
+	if err := agg.LoadFromHistory(history); err != nil {
+		return nil, err
+	}
+	return agg, nil
+}
+
+// LoadFromHistory 在当前状态（如快照）之上继续重放事件
+func (a *UserAggregate) LoadFromHistory(history []event.Event) error {
+	for _, e := range history {
+		if err := a.Apply(e); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Apply 将一个已发生的事件应用到聚合状态，并将版本加一
+// 只用于重放，不会产生新的待发布事件
+func (a *UserAggregate) Apply(e event.Event) error {
+	if a.User == nil {
+		switch e.(type) {
+		case *event.UserRegisteredEvent, *event.UserImportedEvent:
+		default:
+			return fmt.Errorf("first event must be user.registered or user.imported, got %s", e.EventName())
+		}
+	}
+
+	switch ev := e.(type) {
+	case *event.UserRegisteredEvent:
+		email, _ := valueobject.NewEmail(ev.Email)
+		a.User = entity.NewUser(ev.AggregateId, ev.Username, email, valueobject.NewPasswordFromHash(ev.PasswordHash))
+		a.User.Nickname = ev.Nickname
+		a.User.CreatedAt = ev.OccurredOn
+		if ev.Status != 0 {
+			a.User.Status = entity.UserStatus(ev.Status)
+		}
+		// 早期的注册事件用"未激活"表示等待验证，注册时不会有被管理员停用的用户
+		if a.User.Status == entity.UserStatusInactive {
+			a.User.Status = entity.UserStatusPending
+		}
+	case *event.UserImportedEvent:
+		email, _ := valueobject.NewEmail(ev.Email)
+		a.User = entity.NewUser(ev.AggregateId, ev.Username, email, valueobject.NewPasswordFromHash(ev.PasswordHash))
+		a.User.Nickname = ev.Nickname
+		a.User.Avatar = ev.Avatar
+		a.User.Status = entity.UserStatus(ev.Status)
+		a.User.Role = entity.UserRole(ev.Role)
+		a.User.CreatedAt = ev.CreatedAt
+		a.User.EmailVerifiedAt = ev.EmailVerifiedAt
+		a.User.LockedUntil = ev.LockedUntil
+		a.User.TOTPSecret = valueobject.NewTOTPSecretFromCiphertext(ev.TOTPSecret)
+		a.User.TOTPLastStep = ev.TOTPLastStep
+		a.User.MFAEnabled = ev.MFAEnabled
+		a.User.RecoveryCodes = ev.RecoveryCodes
+		a.User.PasswordHistory = ev.PasswordHistory
+		a.User.ErasureRequestedAt = ev.ErasureRequestedAt
+		a.User.ErasureScheduledAt = ev.ErasureScheduledAt
+	case *event.UserProfileUpdatedEvent:
+		a.User.Nickname = ev.NewNickname
+		a.User.Avatar = ev.Avatar
+	case *event.UserPasswordChangedEvent:
+		a.User.Password = valueobject.NewPasswordFromHash(ev.PasswordHash)
+		a.User.PasswordHistory = ev.PasswordHistory
+	case *event.UserPasswordRehashedEvent:
+		a.User.Password = valueobject.NewPasswordFromHash(ev.PasswordHash)
+	case *event.UserEmailVerifiedEvent:
+		verifiedAt := ev.OccurredOn
+		if a.User.Status == entity.UserStatusPending {
+			a.User.Status = entity.UserStatusActive
+		}
+		a.User.EmailVerifiedAt = &verifiedAt
+	case *event.UserLockedEvent:
+		a.User.Lock(ev.LockedUntil)
+	case *event.UserUnlockedEvent:
+		a.User.Unlock()
+	case *event.UserMFAEnrollmentStartedEvent:
+		a.User.BeginMFAEnrollment(valueobject.NewTOTPSecretFromCiphertext(ev.TOTPSecret), ev.RecoveryCodes)
+	case *event.UserMFAEnabledEvent:
+		a.User.EnableMFA()
+		a.User.UseTOTPStep(ev.TOTPStep)
+	case *event.UserTOTPUsedEvent:
+		a.User.UseTOTPStep(ev.Step)
+	case *event.UserMFADisabledEvent:
+		a.User.DisableMFA()
+	case *event.UserRecoveryCodeUsedEvent:
+		a.User.UseRecoveryCode(ev.CodeHash)
+	case *event.PasswordResetRequestedEvent:
+		// 只用于通知，不改变状态
+	case *event.UserPasswordResetEvent:
+		a.User.Password = valueobject.NewPasswordFromHash(ev.PasswordHash)
+		a.User.PasswordHistory = ev.PasswordHistory
+	case *event.UserActivatedEvent:
+		a.User.Status = entity.UserStatusActive
+	case *event.UserDeactivatedEvent:
+		a.User.Status = entity.UserStatusInactive
+	case *event.UserBannedEvent:
+		a.User.Status = entity.UserStatusBanned
+	case *event.UserUnbannedEvent:
+		a.User.Status = entity.UserStatusActive
+	case *event.UserPromotedEvent:
+		a.User.Role = entity.UserRoleAdmin
+	case *event.UserDemotedEvent:
+		a.User.Role = entity.UserRoleUser
+	case *event.AdminTransferredEvent:
+		// 角色变化已由同一批次的 user.demoted 事件记录
+	case *event.UserDeletedEvent:
+		deletedAt := ev.OccurredOn
+		a.User.DeletedAt = &deletedAt
+	case *event.UserDataExportedEvent:
+		// 只用于审计，不改变状态
+	case *event.UserErasureRequestedEvent:
+		a.User.ScheduleErasure(ev.OccurredOn, ev.ScheduledAt)
+	case *event.UserErasureCancelledEvent:
+		a.User.CancelErasure()
+	case *event.UserErasedEvent:
+		email, _ := valueobject.NewEmail(ev.Email)
+		a.User.Anonymize(ev.Username, email, ev.OccurredOn)
+	default:
+		return fmt.Errorf("unsupported event: %s", e.EventName())
+	}
+
+	a.User.UpdatedAt = e.OccurredAt()
+	a.Version++
+	return nil
+}
+
+// ImportUser 为没有事件历史的既有用户生成导入事件
+func ImportUser(user *entity.User) *event.UserImportedEvent {
+	e := event.NewUserImportedEvent(
+		user.UUID, user.Username, user.Email.String(), user.Nickname, user.Avatar,
+		user.Password.Hash(), int(user.Status), string(user.Role), user.CreatedAt, user.EmailVerifiedAt, user.LockedUntil,
+	)
+	e.TOTPSecret = user.TOTPSecret.Ciphertext()
+	e.TOTPLastStep = user.TOTPLastStep
+	e.MFAEnabled = user.MFAEnabled
+	e.RecoveryCodes = user.RecoveryCodes
+	e.PasswordHistory = user.PasswordHistory
+	e.ErasureRequestedAt = user.ErasureRequestedAt
+	e.ErasureScheduledAt = user.ErasureScheduledAt
+	return e
+}
+
+// userSnapshot 用户聚合快照的序列化结构
+type userSnapshot struct {
+	UUID         string     `json:"uuid"`
+	Username     string     `json:"username"`
+	Email        string     `json:"email"`
+	PasswordHash string     `json:"password_hash"`
+	Nickname     string     `json:"nickname"`
+	Avatar       string     `json:"avatar"`
+	Status       int        `json:"status"`
+	Role         string     `json:"role"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	DeletedAt    *time.Time `json:"deleted_at,omitempty"`
+
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	LockedUntil     *time.Time `json:"locked_until,omitempty"`
+
+	TOTPSecret    string   `json:"totp_secret,omitempty"`
+	TOTPLastStep  int64    `json:"totp_last_step,omitempty"`
+	MFAEnabled    bool     `json:"mfa_enabled,omitempty"`
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
+
+	PasswordHistory []string `json:"password_history,omitempty"`
+
+	ErasureRequestedAt *time.Time `json:"erasure_requested_at,omitempty"`
+	ErasureScheduledAt *time.Time `json:"erasure_scheduled_at,omitempty"`
+}
+
+// Snapshot 生成当前状态的快照，应在事件持久化后调用
+func (a *UserAggregate) Snapshot() (event.Snapshot, error) {
+	u := a.User
+	state, err := json.Marshal(userSnapshot{
+		UUID:         u.UUID,
+		Username:     u.Username,
+		Email:        u.Email.String(),
+		PasswordHash: u.Password.Hash(),
+		Nickname:     u.Nickname,
+		Avatar:       u.Avatar,
+		Status:       int(u.Status),
+		Role:         string(u.Role),
+		CreatedAt:    u.CreatedAt,
+		UpdatedAt:    u.UpdatedAt,
+		DeletedAt:    u.DeletedAt,
+
+		EmailVerifiedAt: u.EmailVerifiedAt,
+		LockedUntil:     u.LockedUntil,
+
+		TOTPSecret:    u.TOTPSecret.Ciphertext(),
+		TOTPLastStep:  u.TOTPLastStep,
+		MFAEnabled:    u.MFAEnabled,
+		RecoveryCodes: u.RecoveryCodes,
+
+		PasswordHistory: u.PasswordHistory,
+
+		ErasureRequestedAt: u.ErasureRequestedAt,
+		ErasureScheduledAt: u.ErasureScheduledAt,
+	})
+	if err != nil {
+		return event.Snapshot{}, err
+	}
+	return event.Snapshot{AggregateID: u.UUID, Version: a.Version, State: state}, nil
+}
+
+// RestoreUserFromSnapshot 从快照恢复用户聚合，之后可继续调用 LoadFromHistory
+func RestoreUserFromSnapshot(snapshot event.Snapshot) (*UserAggregate, error) {
+	var s userSnapshot
+	if err := json.Unmarshal(snapshot.State, &s); err != nil {
+		return nil, fmt.Errorf("decode snapshot: %w", err)
+	}
+
+	email, _ := valueobject.NewEmail(s.Email)
+	user := &entity.User{
+		UUID:      s.UUID,
+		Username:  s.Username,
+		Email:     email,
+		Password:  valueobject.NewPasswordFromHash(s.PasswordHash),
+		Nickname:  s.Nickname,
+		Avatar:    s.Avatar,
+		Status:    entity.UserStatus(s.Status),
+		Role:      entity.UserRole(s.Role),
+		CreatedAt: s.CreatedAt,
+		UpdatedAt: s.UpdatedAt,
+		DeletedAt: s.DeletedAt,
+
+		EmailVerifiedAt: s.EmailVerifiedAt,
+		LockedUntil:     s.LockedUntil,
+
+		TOTPSecret:    valueobject.NewTOTPSecretFromCiphertext(s.TOTPSecret),
+		TOTPLastStep:  s.TOTPLastStep,
+		MFAEnabled:    s.MFAEnabled,
+		RecoveryCodes: s.RecoveryCodes,
+
+		PasswordHistory: s.PasswordHistory,
+
+		ErasureRequestedAt: s.ErasureRequestedAt,
+		ErasureScheduledAt: s.ErasureScheduledAt,
+	}
+
+	agg := NewUserAggregate(user)
+	agg.Version = snapshot.Version
+	return agg, nil
+}
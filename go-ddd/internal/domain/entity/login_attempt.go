@@ -0,0 +1,12 @@
+package entity
+
+import (
+	"time"
+)
+
+// LoginAttempt 某个键（用户名或客户端IP）在当前统计窗口内的连续登录失败记录
+type LoginAttempt struct {
+	Key          string    // 如 "user:alice"、"ip:203.0.113.7"
+	Failures     int       // 连续失败次数
+	LastFailedAt time.Time // 最近一次失败时间
+}
@@ -0,0 +1,42 @@
+package entity
+
+import (
+	"time"
+)
+
+// RefreshToken 刷新令牌实体
+// 数据库中只保存令牌的哈希值；每次刷新都会轮换出新令牌，
+// 同一次登录产生的所有令牌属于同一个家族（FamilyID），
+// 已被轮换的令牌再次出现说明令牌可能泄露，此时整个家族都会被吊销
+type RefreshToken struct {
+	ID         uint64     // 数据库自增ID
+	UserID     uint64     // 所属用户
+	FamilyID   string     // 令牌家族（一次登录）
+	TokenHash  string     // 令牌的 SHA-256 哈希
+	ExpiresAt  time.Time  // 过期时间
+	RevokedAt  *time.Time // 吊销或被轮换的时间
+	ReplacedBy uint64     // 轮换后的新令牌ID，0 表示未被轮换
+	CreatedAt  time.Time  // 创建时间
+}
+
+// NewRefreshToken 创建刷新令牌
+func NewRefreshToken(userID uint64, familyID, tokenHash string, ttl time.Duration) *RefreshToken {
+	now := time.Now()
+	return &RefreshToken{
+		UserID:    userID,
+		FamilyID:  familyID,
+		TokenHash: tokenHash,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+}
+
+// IsExpired 检查是否已过期
+func (t *RefreshToken) IsExpired() bool {
+	return time.Now().After(t.ExpiresAt)
+}
+
+// IsRevoked 检查是否已被吊销或轮换
+func (t *RefreshToken) IsRevoked() bool {
+	return t.RevokedAt != nil
+}
@@ -11,8 +11,9 @@ type UserStatus int
 
 const (
 	UserStatusActive   UserStatus = 1 // 激活
-	UserStatusInactive UserStatus = 2 // 未激活
+	UserStatusInactive UserStatus = 2 // 未激活（管理员停用）
 	UserStatusBanned   UserStatus = 3 // 禁用
+	UserStatusPending  UserStatus = 4 // 等待验证邮箱（注册后尚未验证）
 )
 
 // UserRole 用户角色
@@ -27,17 +28,31 @@ const (
 // 实体是DDD中的核心概念，具有唯一标识（ID）
 // 实体的相等性由ID决定，而不是属性
 type User struct {
-	ID        uint64                  // 数据库自增ID
-	UUID      string                  // 业务唯一标识
-	Username  string                  // 用户名
-	Email     valueobject.Email       // 邮箱（值对象）
-	Password  valueobject.Password    // 密码（值对象）
-	Nickname  string                  // 昵称
-	Avatar    string                  // 头像URL
-	Status    UserStatus              // 状态
-	Role      UserRole                // 角色
-	CreatedAt time.Time               // 创建时间
-	UpdatedAt time.Time               // 更新时间
+	ID        uint64               // 数据库自增ID
+	UUID      string               // 业务唯一标识
+	Username  string               // 用户名
+	Email     valueobject.Email    // 邮箱（值对象）
+	Password  valueobject.Password // 密码（值对象）
+	Nickname  string               // 昵称
+	Avatar    string               // 头像URL
+	Status    UserStatus           // 状态
+	Role      UserRole             // 角色
+	CreatedAt time.Time            // 创建时间
+	UpdatedAt time.Time            // 更新时间
+	DeletedAt *time.Time           // 删除时间（nil 表示未删除）
+
+	EmailVerifiedAt *time.Time // 邮箱验证时间（nil 表示未验证）
+	LockedUntil     *time.Time // 登录锁定截止时间（nil 表示未锁定）
+
+	TOTPSecret    valueobject.TOTPSecret // 两步验证密钥（加密保存）
+	TOTPLastStep  int64                  // 最近一次通过校验的验证码时间步，防止验证码重放
+	MFAEnabled    bool                   // 是否已完成两步验证绑定
+	RecoveryCodes []string               // 未使用的恢复码哈希
+
+	PasswordHistory []string // 最近使用过的旧密码哈希，最新的在前
+
+	ErasureRequestedAt *time.Time // 申请删除账户的时间（nil 表示未申请）
+	ErasureScheduledAt *time.Time // 宽限期结束、擦除个人数据的时间
 }
 
 // NewUser 创建新用户
@@ -72,12 +87,29 @@ func (u *User) UpdateProfile(nickname, avatar string) {
 	u.UpdatedAt = time.Now()
 }
 
-// ChangePassword 修改密码
-func (u *User) ChangePassword(newPassword valueobject.Password) {
+// ChangePassword 修改密码，当前密码哈希记入历史，历史最多保留 keep 个
+func (u *User) ChangePassword(newPassword valueobject.Password, keep int) {
+	history := u.PasswordHistory
+	if keep > 0 && !u.Password.IsEmpty() {
+		history = append([]string{u.Password.Hash()}, history...)
+	}
+	if len(history) > keep {
+		history = history[:keep]
+	}
+	if len(history) == 0 {
+		history = nil
+	}
+	u.PasswordHistory = history
 	u.Password = newPassword
 	u.UpdatedAt = time.Now()
 }
 
+// RehashPassword 同一个密码换用新的哈希算法或成本，不记入历史
+func (u *User) RehashPassword(password valueobject.Password) {
+	u.Password = password
+	u.UpdatedAt = time.Now()
+}
+
 // Activate 激活用户
 func (u *User) Activate() {
 	u.Status = UserStatusActive
@@ -101,3 +133,160 @@ func (u *User) PromoteToAdmin() {
 	u.Role = UserRoleAdmin
 	u.UpdatedAt = time.Now()
 }
+
+// Unban 解除禁用，恢复为激活状态
+func (u *User) Unban() {
+	u.Status = UserStatusActive
+	u.UpdatedAt = time.Now()
+}
+
+// DemoteToUser 降级为普通用户
+func (u *User) DemoteToUser() {
+	u.Role = UserRoleUser
+	u.UpdatedAt = time.Now()
+}
+
+// IsBanned 检查用户是否被禁用
+func (u *User) IsBanned() bool {
+	return u.Status == UserStatusBanned
+}
+
+// IsEmailVerified 检查邮箱是否已验证
+func (u *User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
+}
+
+// IsPendingVerification 检查用户是否在等待验证邮箱
+func (u *User) IsPendingVerification() bool {
+	return u.Status == UserStatusPending
+}
+
+// VerifyEmail 标记邮箱已验证；只有等待验证的用户随之激活，
+// 被管理员停用、禁用的用户保持原状态，验证链接和重置密码不能撤销管理员的操作
+func (u *User) VerifyEmail() {
+	now := time.Now()
+	if u.Status == UserStatusPending {
+		u.Status = UserStatusActive
+	}
+	u.EmailVerifiedAt = &now
+	u.UpdatedAt = now
+}
+
+// IsLocked 检查用户在 now 时是否处于登录锁定期
+func (u *User) IsLocked(now time.Time) bool {
+	return u.LockedUntil != nil && now.Before(*u.LockedUntil)
+}
+
+// Lock 锁定登录直到 until
+func (u *User) Lock(until time.Time) {
+	u.LockedUntil = &until
+	u.UpdatedAt = time.Now()
+}
+
+// Unlock 解除登录锁定
+func (u *User) Unlock() {
+	u.LockedUntil = nil
+	u.UpdatedAt = time.Now()
+}
+
+// IsMFAEnabled 检查是否已启用两步验证
+func (u *User) IsMFAEnabled() bool {
+	return u.MFAEnabled && !u.TOTPSecret.IsZero()
+}
+
+// BeginMFAEnrollment 保存待确认的两步验证密钥和恢复码
+func (u *User) BeginMFAEnrollment(secret valueobject.TOTPSecret, recoveryCodes []string) {
+	u.TOTPSecret = secret
+	u.TOTPLastStep = 0
+	u.RecoveryCodes = recoveryCodes
+	u.MFAEnabled = false
+	u.UpdatedAt = time.Now()
+}
+
+// EnableMFA 确认绑定，启用两步验证
+func (u *User) EnableMFA() {
+	u.MFAEnabled = true
+	u.UpdatedAt = time.Now()
+}
+
+// DisableMFA 关闭两步验证并清除密钥和恢复码
+func (u *User) DisableMFA() {
+	u.TOTPSecret = valueobject.TOTPSecret{}
+	u.TOTPLastStep = 0
+	u.RecoveryCodes = nil
+	u.MFAEnabled = false
+	u.UpdatedAt = time.Now()
+}
+
+// UseTOTPStep 记录通过校验的验证码时间步，此后该时间步及更早的验证码失效
+func (u *User) UseTOTPStep(step int64) {
+	u.TOTPLastStep = step
+	u.UpdatedAt = time.Now()
+}
+
+// UseRecoveryCode 消耗一个恢复码，哈希不存在时返回 false
+func (u *User) UseRecoveryCode(hash string) bool {
+	for i, h := range u.RecoveryCodes {
+		if h == hash {
+			u.RecoveryCodes = append(u.RecoveryCodes[:i:i], u.RecoveryCodes[i+1:]...)
+			u.UpdatedAt = time.Now()
+			return true
+		}
+	}
+	return false
+}
+
+// IsErasurePending 检查是否已申请删除账户、等待擦除个人数据
+func (u *User) IsErasurePending() bool {
+	return u.ErasureScheduledAt != nil
+}
+
+// ScheduleErasure 申请删除账户，个人数据在 scheduledAt 之后擦除
+func (u *User) ScheduleErasure(requestedAt, scheduledAt time.Time) {
+	u.ErasureRequestedAt = &requestedAt
+	u.ErasureScheduledAt = &scheduledAt
+	u.UpdatedAt = requestedAt
+}
+
+// CancelErasure 撤销删除申请
+func (u *User) CancelErasure() {
+	u.ErasureRequestedAt = nil
+	u.ErasureScheduledAt = nil
+	u.UpdatedAt = time.Now()
+}
+
+// Anonymize 擦除个人数据并软删除
+// 用户名、邮箱替换为不含个人信息的占位值，昵称、头像、密码、两步验证等全部清除；
+// ID、UUID 保留，令牌、事件等关联数据仍然可以引用该用户
+func (u *User) Anonymize(username string, email valueobject.Email, at time.Time) {
+	u.Username = username
+	u.Email = email
+	u.Password = valueobject.Password{}
+	u.Nickname = ""
+	u.Avatar = ""
+	u.Status = UserStatusInactive
+	u.Role = UserRoleUser
+	u.EmailVerifiedAt = nil
+	u.LockedUntil = nil
+	u.TOTPSecret = valueobject.TOTPSecret{}
+	u.TOTPLastStep = 0
+	u.MFAEnabled = false
+	u.RecoveryCodes = nil
+	u.PasswordHistory = nil
+	u.ErasureRequestedAt = nil
+	u.ErasureScheduledAt = nil
+	u.DeletedAt = &at
+	u.UpdatedAt = at
+}
+
+// MarkDeleted 标记为已删除（软删除）
+func (u *User) MarkDeleted() {
+	now := time.Now()
+	u.DeletedAt = &now
+	u.UpdatedAt = now
+}
+
+// IsDeleted 检查用户是否已删除
+func (u *User) IsDeleted() bool {
+	return u.DeletedAt != nil
+}
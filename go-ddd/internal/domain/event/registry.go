@@ -0,0 +1,77 @@
+package event
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+)
+
+// Registry 事件类型注册表
+// 事件以 JSON 形式持久化，读取时根据事件名称找到具体类型再反序列化
+type Registry struct {
+	mu        sync.RWMutex
+	factories map[string]func() Event
+}
+
+// NewRegistry 创建空的事件注册表
+func NewRegistry() *Registry {
+	return &Registry{
+		factories: make(map[string]func() Event),
+	}
+}
+
+// NewUserEventRegistry 创建已注册全部用户事件的注册表
+func NewUserEventRegistry() *Registry {
+	r := NewRegistry()
+	r.Register("user.registered", func() Event { return &UserRegisteredEvent{} })
+	r.Register("user.profile_updated", func() Event { return &UserProfileUpdatedEvent{} })
+	r.Register("user.password_changed", func() Event { return &UserPasswordChangedEvent{} })
+	r.Register("user.password_rehashed", func() Event { return &UserPasswordRehashedEvent{} })
+	r.Register("user.email_verified", func() Event { return &UserEmailVerifiedEvent{} })
+	r.Register("user.password_reset_requested", func() Event { return &PasswordResetRequestedEvent{} })
+	r.Register("user.password_reset", func() Event { return &UserPasswordResetEvent{} })
+	r.Register("user.locked", func() Event { return &UserLockedEvent{} })
+	r.Register("user.unlocked", func() Event { return &UserUnlockedEvent{} })
+	r.Register("user.mfa_enrollment_started", func() Event { return &UserMFAEnrollmentStartedEvent{} })
+	r.Register("user.mfa_enabled", func() Event { return &UserMFAEnabledEvent{} })
+	r.Register("user.mfa_disabled", func() Event { return &UserMFADisabledEvent{} })
+	r.Register("user.recovery_code_used", func() Event { return &UserRecoveryCodeUsedEvent{} })
+	r.Register("user.totp_used", func() Event { return &UserTOTPUsedEvent{} })
+	r.Register("user.activated", func() Event { return &UserActivatedEvent{} })
+	r.Register("user.deactivated", func() Event { return &UserDeactivatedEvent{} })
+	r.Register("user.banned", func() Event { return &UserBannedEvent{} })
+	r.Register("user.unbanned", func() Event { return &UserUnbannedEvent{} })
+	r.Register("user.promoted", func() Event { return &UserPromotedEvent{} })
+	r.Register("user.demoted", func() Event { return &UserDemotedEvent{} })
+	r.Register("user.admin_transferred", func() Event { return &AdminTransferredEvent{} })
+	r.Register("user.deleted", func() Event { return &UserDeletedEvent{} })
+	r.Register("user.data_exported", func() Event { return &UserDataExportedEvent{} })
+	r.Register("user.erasure_requested", func() Event { return &UserErasureRequestedEvent{} })
+	r.Register("user.erasure_cancelled", func() Event { return &UserErasureCancelledEvent{} })
+	r.Register("user.erased", func() Event { return &UserErasedEvent{} })
+	r.Register("user.imported", func() Event { return &UserImportedEvent{} })
+	return r
+}
+
+// Register 注册事件类型，factory 返回该类型的零值指针
+func (r *Registry) Register(name string, factory func() Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.factories[name] = factory
+}
+
+// Decode 根据事件名称反序列化事件
+func (r *Registry) Decode(name string, payload []byte) (Event, error) {
+	r.mu.RLock()
+	factory, ok := r.factories[name]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown event type: %s", name)
+	}
+
+	e := factory()
+	if err := json.Unmarshal(payload, e); err != nil {
+		return nil, fmt.Errorf("decode event %s: %w", name, err)
+	}
+	return e, nil
+}
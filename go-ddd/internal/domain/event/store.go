@@ -0,0 +1,47 @@
+package event
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrConcurrencyConflict 追加事件时聚合版本与期望版本不一致（乐观并发冲突）
+var ErrConcurrencyConflict = errors.New("aggregate version conflict")
+
+// Snapshot 聚合快照
+// 重建聚合时先恢复快照，再重放快照版本之后的事件，避免每次都重放全部历史
+type Snapshot struct {
+	AggregateID string
+	Version     int    // 快照对应的聚合版本（已包含的事件数）
+	State       []byte // 聚合状态的序列化内容
+}
+
+// StreamAppend 一次追加到单个事件流的事件
+// Replace 为 true 时先删除该事件流已有的事件和快照，Events 作为新的事件流从版本 1 开始写入，
+// 用于擦除个人数据：历史事件中的个人数据不能继续保留在事件存储中
+type StreamAppend struct {
+	AggregateID     string
+	ExpectedVersion int
+	Events          []Event
+	Replace         bool
+}
+
+// EventStore 事件存储接口
+// 事件溯源（Event Sourcing）中事件是唯一的事实来源，聚合状态由事件重放得到
+type EventStore interface {
+	// Append 追加事件，expectedVersion 为追加前聚合的版本
+	// 当前版本不等于 expectedVersion 时返回 ErrConcurrencyConflict
+	Append(ctx context.Context, aggregateID string, expectedVersion int, events []Event) error
+
+	// AppendAll 原子地向多个聚合的事件流追加事件，任一流版本冲突时全部不写入
+	AppendAll(ctx context.Context, streams ...StreamAppend) error
+
+	// Load 按版本顺序加载 afterVersion 之后的事件
+	Load(ctx context.Context, aggregateID string, afterVersion int) ([]Event, error)
+
+	// SaveSnapshot 保存（覆盖）聚合快照
+	SaveSnapshot(ctx context.Context, snapshot Snapshot) error
+
+	// LoadSnapshot 加载最新快照，没有快照时返回 nil
+	LoadSnapshot(ctx context.Context, aggregateID string) (*Snapshot, error)
+}
@@ -38,30 +38,45 @@ func (e BaseEvent) AggregateID() string {
 // UserRegisteredEvent 用户注册事件
 type UserRegisteredEvent struct {
 	BaseEvent
-	Username string `json:"username"`
-	Email    string `json:"email"`
+	Username     string `json:"username"`
+	Email        string `json:"email"`
+	Nickname     string `json:"nickname"`
+	PasswordHash string `json:"password_hash,omitempty"` // 仅保存在事件存储中，用于重建聚合
+	Status       int    `json:"status,omitempty"`        // 注册后的状态，旧事件没有该字段，视为激活
 }
 
-func NewUserRegisteredEvent(uuid, username, email string) *UserRegisteredEvent {
+func NewUserRegisteredEvent(uuid, username, email, nickname, passwordHash string, status int) *UserRegisteredEvent {
 	return &UserRegisteredEvent{
 		BaseEvent: BaseEvent{
 			Name:        "user.registered",
 			OccurredOn:  time.Now(),
 			AggregateId: uuid,
 		},
-		Username: username,
-		Email:    email,
+		Username:     username,
+		Email:        email,
+		Nickname:     nickname,
+		PasswordHash: passwordHash,
+		Status:       status,
 	}
 }
 
+// Redacted 返回去掉密码哈希的副本
+func (e *UserRegisteredEvent) Redacted() Event {
+	c := *e
+	c.PasswordHash = ""
+	return &c
+}
+
 // UserProfileUpdatedEvent 用户资料更新事件
 type UserProfileUpdatedEvent struct {
 	BaseEvent
 	OldNickname string `json:"old_nickname"`
 	NewNickname string `json:"new_nickname"`
+	OldAvatar   string `json:"old_avatar,omitempty"` // 头像被替换时，订阅者据此清理旧文件
+	Avatar      string `json:"avatar"`
 }
 
-func NewUserProfileUpdatedEvent(uuid, oldNickname, newNickname string) *UserProfileUpdatedEvent {
+func NewUserProfileUpdatedEvent(uuid, oldNickname, newNickname, oldAvatar, avatar string) *UserProfileUpdatedEvent {
 	return &UserProfileUpdatedEvent{
 		BaseEvent: BaseEvent{
 			Name:        "user.profile_updated",
@@ -70,24 +85,124 @@ func NewUserProfileUpdatedEvent(uuid, oldNickname, newNickname string) *UserProf
 		},
 		OldNickname: oldNickname,
 		NewNickname: newNickname,
+		OldAvatar:   oldAvatar,
+		Avatar:      avatar,
 	}
 }
 
 // UserPasswordChangedEvent 用户密码修改事件
 type UserPasswordChangedEvent struct {
 	BaseEvent
+	PasswordHash    string   `json:"password_hash,omitempty"`    // 仅保存在事件存储中，用于重建聚合
+	PasswordHistory []string `json:"password_history,omitempty"` // 修改后的旧密码历史，仅保存在事件存储中
 }
 
-func NewUserPasswordChangedEvent(uuid string) *UserPasswordChangedEvent {
+func NewUserPasswordChangedEvent(uuid, passwordHash string, passwordHistory []string) *UserPasswordChangedEvent {
 	return &UserPasswordChangedEvent{
 		BaseEvent: BaseEvent{
 			Name:        "user.password_changed",
 			OccurredOn:  time.Now(),
 			AggregateId: uuid,
 		},
+		PasswordHash:    passwordHash,
+		PasswordHistory: passwordHistory,
+	}
+}
+
+// Redacted 返回去掉密码哈希的副本
+func (e *UserPasswordChangedEvent) Redacted() Event {
+	c := *e
+	c.PasswordHash = ""
+	c.PasswordHistory = nil
+	return &c
+}
+
+// UserPasswordRehashedEvent 登录时将过时的密码哈希升级为当前算法、成本的事件
+type UserPasswordRehashedEvent struct {
+	BaseEvent
+	PasswordHash string `json:"password_hash,omitempty"` // 仅保存在事件存储中，用于重建聚合
+}
+
+func NewUserPasswordRehashedEvent(uuid, passwordHash string) *UserPasswordRehashedEvent {
+	return &UserPasswordRehashedEvent{
+		BaseEvent: BaseEvent{
+			Name:        "user.password_rehashed",
+			OccurredOn:  time.Now(),
+			AggregateId: uuid,
+		},
+		PasswordHash: passwordHash,
+	}
+}
+
+// Redacted 返回去掉密码哈希的副本
+func (e *UserPasswordRehashedEvent) Redacted() Event {
+	c := *e
+	c.PasswordHash = ""
+	return &c
+}
+
+// UserEmailVerifiedEvent 用户邮箱验证事件
+type UserEmailVerifiedEvent struct {
+	BaseEvent
+	Email string `json:"email"`
+}
+
+func NewUserEmailVerifiedEvent(uuid, email string) *UserEmailVerifiedEvent {
+	return &UserEmailVerifiedEvent{
+		BaseEvent: BaseEvent{
+			Name:        "user.email_verified",
+			OccurredOn:  time.Now(),
+			AggregateId: uuid,
+		},
+		Email: email,
 	}
 }
 
+// PasswordResetRequestedEvent 用户申请重置密码事件
+// 不改变聚合状态，订阅者据此发送重置邮件
+type PasswordResetRequestedEvent struct {
+	BaseEvent
+	Email string `json:"email"`
+}
+
+func NewPasswordResetRequestedEvent(uuid, email string) *PasswordResetRequestedEvent {
+	return &PasswordResetRequestedEvent{
+		BaseEvent: BaseEvent{
+			Name:        "user.password_reset_requested",
+			OccurredOn:  time.Now(),
+			AggregateId: uuid,
+		},
+		Email: email,
+	}
+}
+
+// UserPasswordResetEvent 用户通过重置令牌设置新密码事件
+type UserPasswordResetEvent struct {
+	BaseEvent
+	PasswordHash    string   `json:"password_hash,omitempty"`    // 仅保存在事件存储中，用于重建聚合
+	PasswordHistory []string `json:"password_history,omitempty"` // 重置后的旧密码历史，仅保存在事件存储中
+}
+
+func NewUserPasswordResetEvent(uuid, passwordHash string, passwordHistory []string) *UserPasswordResetEvent {
+	return &UserPasswordResetEvent{
+		BaseEvent: BaseEvent{
+			Name:        "user.password_reset",
+			OccurredOn:  time.Now(),
+			AggregateId: uuid,
+		},
+		PasswordHash:    passwordHash,
+		PasswordHistory: passwordHistory,
+	}
+}
+
+// Redacted 返回去掉密码哈希的副本
+func (e *UserPasswordResetEvent) Redacted() Event {
+	c := *e
+	c.PasswordHash = ""
+	c.PasswordHistory = nil
+	return &c
+}
+
 // UserActivatedEvent 用户激活事件
 type UserActivatedEvent struct {
 	BaseEvent
@@ -106,15 +221,17 @@ func NewUserActivatedEvent(uuid string) *UserActivatedEvent {
 // UserDeactivatedEvent 用户停用事件
 type UserDeactivatedEvent struct {
 	BaseEvent
+	Reason string `json:"reason"`
 }
 
-func NewUserDeactivatedEvent(uuid string) *UserDeactivatedEvent {
+func NewUserDeactivatedEvent(uuid, reason string) *UserDeactivatedEvent {
 	return &UserDeactivatedEvent{
 		BaseEvent: BaseEvent{
 			Name:        "user.deactivated",
 			OccurredOn:  time.Now(),
 			AggregateId: uuid,
 		},
+		Reason: reason,
 	}
 }
 
@@ -135,6 +252,154 @@ func NewUserBannedEvent(uuid, reason string) *UserBannedEvent {
 	}
 }
 
+// UserUnbannedEvent 用户解除禁用事件
+type UserUnbannedEvent struct {
+	BaseEvent
+	Reason string `json:"reason"`
+}
+
+func NewUserUnbannedEvent(uuid, reason string) *UserUnbannedEvent {
+	return &UserUnbannedEvent{
+		BaseEvent: BaseEvent{
+			Name:        "user.unbanned",
+			OccurredOn:  time.Now(),
+			AggregateId: uuid,
+		},
+		Reason: reason,
+	}
+}
+
+// UserLockedEvent 连续登录失败导致账户被临时锁定事件
+type UserLockedEvent struct {
+	BaseEvent
+	LockedUntil    time.Time `json:"locked_until"`
+	FailedAttempts int       `json:"failed_attempts"`
+}
+
+func NewUserLockedEvent(uuid string, lockedUntil time.Time, failedAttempts int) *UserLockedEvent {
+	return &UserLockedEvent{
+		BaseEvent: BaseEvent{
+			Name:        "user.locked",
+			OccurredOn:  time.Now(),
+			AggregateId: uuid,
+		},
+		LockedUntil:    lockedUntil,
+		FailedAttempts: failedAttempts,
+	}
+}
+
+// UserUnlockedEvent 管理员解除登录锁定事件
+type UserUnlockedEvent struct {
+	BaseEvent
+	Reason string `json:"reason"`
+}
+
+func NewUserUnlockedEvent(uuid, reason string) *UserUnlockedEvent {
+	return &UserUnlockedEvent{
+		BaseEvent: BaseEvent{
+			Name:        "user.unlocked",
+			OccurredOn:  time.Now(),
+			AggregateId: uuid,
+		},
+		Reason: reason,
+	}
+}
+
+// UserMFAEnrollmentStartedEvent 开始绑定两步验证事件（生成了新密钥和恢复码，尚未启用）
+type UserMFAEnrollmentStartedEvent struct {
+	BaseEvent
+	TOTPSecret    string   `json:"totp_secret,omitempty"`    // 加密后的密钥，仅保存在事件存储中
+	RecoveryCodes []string `json:"recovery_codes,omitempty"` // 恢复码哈希，仅保存在事件存储中
+}
+
+func NewUserMFAEnrollmentStartedEvent(uuid, totpSecret string, recoveryCodes []string) *UserMFAEnrollmentStartedEvent {
+	return &UserMFAEnrollmentStartedEvent{
+		BaseEvent: BaseEvent{
+			Name:        "user.mfa_enrollment_started",
+			OccurredOn:  time.Now(),
+			AggregateId: uuid,
+		},
+		TOTPSecret:    totpSecret,
+		RecoveryCodes: recoveryCodes,
+	}
+}
+
+// Redacted 返回去掉密钥和恢复码的副本
+func (e *UserMFAEnrollmentStartedEvent) Redacted() Event {
+	c := *e
+	c.TOTPSecret = ""
+	c.RecoveryCodes = nil
+	return &c
+}
+
+// UserMFAEnabledEvent 启用两步验证事件
+type UserMFAEnabledEvent struct {
+	BaseEvent
+	TOTPStep int64 `json:"totp_step,omitempty"` // 确认绑定所用验证码的时间步
+}
+
+func NewUserMFAEnabledEvent(uuid string, totpStep int64) *UserMFAEnabledEvent {
+	return &UserMFAEnabledEvent{
+		BaseEvent: BaseEvent{
+			Name:        "user.mfa_enabled",
+			OccurredOn:  time.Now(),
+			AggregateId: uuid,
+		},
+		TOTPStep: totpStep,
+	}
+}
+
+// UserMFADisabledEvent 关闭两步验证事件
+type UserMFADisabledEvent struct {
+	BaseEvent
+}
+
+func NewUserMFADisabledEvent(uuid string) *UserMFADisabledEvent {
+	return &UserMFADisabledEvent{
+		BaseEvent: BaseEvent{
+			Name:        "user.mfa_disabled",
+			OccurredOn:  time.Now(),
+			AggregateId: uuid,
+		},
+	}
+}
+
+// UserRecoveryCodeUsedEvent 使用恢复码登录事件
+type UserRecoveryCodeUsedEvent struct {
+	BaseEvent
+	CodeHash  string `json:"code_hash"`
+	Remaining int    `json:"remaining"`
+}
+
+func NewUserRecoveryCodeUsedEvent(uuid, codeHash string, remaining int) *UserRecoveryCodeUsedEvent {
+	return &UserRecoveryCodeUsedEvent{
+		BaseEvent: BaseEvent{
+			Name:        "user.recovery_code_used",
+			OccurredOn:  time.Now(),
+			AggregateId: uuid,
+		},
+		CodeHash:  codeHash,
+		Remaining: remaining,
+	}
+}
+
+// UserTOTPUsedEvent 使用验证器验证码完成登录第二步事件，记录时间步使同一验证码不能再次使用
+type UserTOTPUsedEvent struct {
+	BaseEvent
+	Step int64 `json:"step"`
+}
+
+func NewUserTOTPUsedEvent(uuid string, step int64) *UserTOTPUsedEvent {
+	return &UserTOTPUsedEvent{
+		BaseEvent: BaseEvent{
+			Name:        "user.totp_used",
+			OccurredOn:  time.Now(),
+			AggregateId: uuid,
+		},
+		Step: step,
+	}
+}
+
 // UserPromotedEvent 用户提升为管理员事件
 type UserPromotedEvent struct {
 	BaseEvent
@@ -150,6 +415,198 @@ func NewUserPromotedEvent(uuid string) *UserPromotedEvent {
 	}
 }
 
+// UserDemotedEvent 管理员降级为普通用户事件
+type UserDemotedEvent struct {
+	BaseEvent
+	Reason string `json:"reason"`
+}
+
+func NewUserDemotedEvent(uuid, reason string) *UserDemotedEvent {
+	return &UserDemotedEvent{
+		BaseEvent: BaseEvent{
+			Name:        "user.demoted",
+			OccurredOn:  time.Now(),
+			AggregateId: uuid,
+		},
+		Reason: reason,
+	}
+}
+
+// AdminTransferredEvent 管理员权限转移事件
+// 记录在原管理员的事件流上，目标用户的提升由其自身的 user.promoted 事件记录
+type AdminTransferredEvent struct {
+	BaseEvent
+	ToUserUUID string `json:"to_user_uuid"`
+	Reason     string `json:"reason"`
+}
+
+func NewAdminTransferredEvent(fromUUID, toUUID, reason string) *AdminTransferredEvent {
+	return &AdminTransferredEvent{
+		BaseEvent: BaseEvent{
+			Name:        "user.admin_transferred",
+			OccurredOn:  time.Now(),
+			AggregateId: fromUUID,
+		},
+		ToUserUUID: toUUID,
+		Reason:     reason,
+	}
+}
+
+// UserDeletedEvent 用户删除事件
+type UserDeletedEvent struct {
+	BaseEvent
+}
+
+func NewUserDeletedEvent(uuid string) *UserDeletedEvent {
+	return &UserDeletedEvent{
+		BaseEvent: BaseEvent{
+			Name:        "user.deleted",
+			OccurredOn:  time.Now(),
+			AggregateId: uuid,
+		},
+	}
+}
+
+// UserDataExportedEvent 用户导出个人数据事件
+type UserDataExportedEvent struct {
+	BaseEvent
+}
+
+func NewUserDataExportedEvent(uuid string) *UserDataExportedEvent {
+	return &UserDataExportedEvent{
+		BaseEvent: BaseEvent{
+			Name:        "user.data_exported",
+			OccurredOn:  time.Now(),
+			AggregateId: uuid,
+		},
+	}
+}
+
+// UserErasureRequestedEvent 用户申请删除账户事件，个人数据在 ScheduledAt 之后擦除
+type UserErasureRequestedEvent struct {
+	BaseEvent
+	ScheduledAt time.Time `json:"scheduled_at"`
+}
+
+func NewUserErasureRequestedEvent(uuid string, scheduledAt time.Time) *UserErasureRequestedEvent {
+	return &UserErasureRequestedEvent{
+		BaseEvent: BaseEvent{
+			Name:        "user.erasure_requested",
+			OccurredOn:  time.Now(),
+			AggregateId: uuid,
+		},
+		ScheduledAt: scheduledAt,
+	}
+}
+
+// UserErasureCancelledEvent 用户在宽限期内撤销删除申请事件
+type UserErasureCancelledEvent struct {
+	BaseEvent
+}
+
+func NewUserErasureCancelledEvent(uuid string) *UserErasureCancelledEvent {
+	return &UserErasureCancelledEvent{
+		BaseEvent: BaseEvent{
+			Name:        "user.erasure_cancelled",
+			OccurredOn:  time.Now(),
+			AggregateId: uuid,
+		},
+	}
+}
+
+// UserErasedEvent 用户个人数据已擦除事件
+// 只携带替换后的占位用户名和邮箱，订阅者据此删除各自保存的个人数据
+type UserErasedEvent struct {
+	BaseEvent
+	Username string `json:"username"`
+	Email    string `json:"email"`
+}
+
+func NewUserErasedEvent(uuid, username, email string) *UserErasedEvent {
+	return &UserErasedEvent{
+		BaseEvent: BaseEvent{
+			Name:        "user.erased",
+			OccurredOn:  time.Now(),
+			AggregateId: uuid,
+		},
+		Username: username,
+		Email:    email,
+	}
+}
+
+// UserImportedEvent 用户导入事件
+// 启用事件溯源前已存在的用户没有事件历史，首次加载时以该事件记录当时的完整状态
+type UserImportedEvent struct {
+	BaseEvent
+	Username     string    `json:"username"`
+	Email        string    `json:"email"`
+	Nickname     string    `json:"nickname"`
+	Avatar       string    `json:"avatar"`
+	PasswordHash string    `json:"password_hash,omitempty"`
+	Status       int       `json:"status"`
+	Role         string    `json:"role"`
+	CreatedAt    time.Time `json:"created_at"`
+
+	EmailVerifiedAt *time.Time `json:"email_verified_at,omitempty"`
+	LockedUntil     *time.Time `json:"locked_until,omitempty"`
+
+	TOTPSecret    string   `json:"totp_secret,omitempty"`
+	TOTPLastStep  int64    `json:"totp_last_step,omitempty"`
+	MFAEnabled    bool     `json:"mfa_enabled,omitempty"`
+	RecoveryCodes []string `json:"recovery_codes,omitempty"`
+
+	PasswordHistory []string `json:"password_history,omitempty"`
+
+	ErasureRequestedAt *time.Time `json:"erasure_requested_at,omitempty"`
+	ErasureScheduledAt *time.Time `json:"erasure_scheduled_at,omitempty"`
+}
+
+func NewUserImportedEvent(uuid, username, email, nickname, avatar, passwordHash string, status int, role string, createdAt time.Time, emailVerifiedAt, lockedUntil *time.Time) *UserImportedEvent {
+	return &UserImportedEvent{
+		BaseEvent: BaseEvent{
+			Name:        "user.imported",
+			OccurredOn:  time.Now(),
+			AggregateId: uuid,
+		},
+		Username:     username,
+		Email:        email,
+		Nickname:     nickname,
+		Avatar:       avatar,
+		PasswordHash: passwordHash,
+		Status:       status,
+		Role:         role,
+		CreatedAt:    createdAt,
+
+		EmailVerifiedAt: emailVerifiedAt,
+		LockedUntil:     lockedUntil,
+	}
+}
+
+// Redacted 返回去掉密码哈希的副本
+func (e *UserImportedEvent) Redacted() Event {
+	c := *e
+	c.PasswordHash = ""
+	c.TOTPSecret = ""
+	c.RecoveryCodes = nil
+	c.PasswordHistory = nil
+	return &c
+}
+
+// SensitiveEvent 含敏感字段（如密码哈希）的事件
+// 事件存储保存完整内容；写入发件箱、日志等对外发布时使用 Redacted 返回的副本
+type SensitiveEvent interface {
+	Event
+	Redacted() Event
+}
+
+// Redact 如果事件含敏感字段，返回脱敏后的副本，否则原样返回
+func Redact(e Event) Event {
+	if s, ok := e.(SensitiveEvent); ok {
+		return s.Redacted()
+	}
+	return e
+}
+
 // EventHandler 事件处理器接口
 type EventHandler interface {
 	Handle(event Event) error
@@ -0,0 +1,28 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"yiwen/go-ddd/internal/domain/entity"
+)
+
+// LoginAttemptRepository 登录失败计数仓储
+type LoginAttemptRepository interface {
+	// Get 获取失败记录，没有记录时返回 Failures 为 0 的记录
+	Get(ctx context.Context, key string) (*entity.LoginAttempt, error)
+
+	// Reserve 原子地检查并预占一次尝试：allow 根据当前记录判断是否允许，
+	// 允许时立即计为一次失败并返回最新记录；不允许时不计数，返回当前记录和 false
+	// 上一次失败早于 now-window 时重新从 1 开始计数
+	Reserve(ctx context.Context, key string, now time.Time, window time.Duration, allow func(*entity.LoginAttempt) bool) (*entity.LoginAttempt, bool, error)
+
+	// Release 撤销一次预占，失败次数减一（不低于 0）
+	Release(ctx context.Context, key string) error
+
+	// Reset 清除失败记录（登录成功、管理员解锁）
+	Reset(ctx context.Context, key string) error
+
+	// DeleteBefore 清理最近一次失败早于 before 的记录
+	DeleteBefore(ctx context.Context, before time.Time) error
+}
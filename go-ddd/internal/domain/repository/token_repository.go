@@ -0,0 +1,43 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"yiwen/go-ddd/internal/domain/entity"
+)
+
+// RefreshTokenRepository 刷新令牌仓储接口
+type RefreshTokenRepository interface {
+	// Create 保存新令牌
+	Create(ctx context.Context, token *entity.RefreshToken) error
+
+	// FindByHash 根据令牌哈希查找（包括已吊销的令牌，用于检测重用）
+	FindByHash(ctx context.Context, tokenHash string) (*entity.RefreshToken, error)
+
+	// MarkRotated 将未吊销的令牌标记为已被 replacedBy 轮换
+	// 令牌已被吊销（例如并发刷新中另一方抢先）时返回 false
+	MarkRotated(ctx context.Context, id, replacedBy uint64) (bool, error)
+
+	// RevokeFamily 吊销整个令牌家族
+	RevokeFamily(ctx context.Context, familyID string) error
+
+	// RevokeAllForUser 吊销用户的全部令牌
+	RevokeAllForUser(ctx context.Context, userID uint64) error
+
+	// DeleteExpired 清理已过期的令牌
+	DeleteExpired(ctx context.Context) error
+}
+
+// RevokedTokenRepository 已吊销的访问令牌（JWT ID）列表
+// 只需保存到令牌原本的过期时间，之后令牌自然失效
+type RevokedTokenRepository interface {
+	// Revoke 吊销访问令牌
+	Revoke(ctx context.Context, jti string, expiresAt time.Time) error
+
+	// IsRevoked 检查访问令牌是否已被吊销
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+
+	// DeleteExpired 清理已过期的记录
+	DeleteExpired(ctx context.Context) error
+}
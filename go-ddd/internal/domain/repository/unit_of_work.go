@@ -0,0 +1,13 @@
+package repository
+
+import "context"
+
+// UnitOfWork 工作单元
+// 在一个事务中执行 fn，fn 内通过传入的 ctx 调用的仓储操作共享同一事务；
+// fn 返回错误时回滚，否则提交。嵌套调用时复用外层事务
+//
+// 实现可以在死锁等可重试的错误时重新执行 fn，因此 fn 应当在内部加载聚合，
+// 不要依赖在 fn 之外读取的状态
+type UnitOfWork interface {
+	Do(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -0,0 +1,84 @@
+package repository
+
+import (
+	"strings"
+	"time"
+
+	"yiwen/go-ddd/internal/domain/entity"
+)
+
+// UserSortField 用户列表的排序字段
+type UserSortField string
+
+const (
+	UserSortByID        UserSortField = "id"
+	UserSortByCreatedAt UserSortField = "created_at"
+	UserSortByUsername  UserSortField = "username"
+)
+
+// IsValid 检查是否为支持的排序字段
+func (f UserSortField) IsValid() bool {
+	switch f {
+	case UserSortByID, UserSortByCreatedAt, UserSortByUsername:
+		return true
+	}
+	return false
+}
+
+// UserFilter 用户列表的过滤条件，零值字段表示不过滤
+type UserFilter struct {
+	Status        entity.UserStatus // 状态，0 表示不限
+	Role          entity.UserRole   // 角色，空表示不限
+	CreatedFrom   *time.Time        // 创建时间下限（含）
+	CreatedBefore *time.Time        // 创建时间上限（不含）
+	Keyword       string            // 用户名、邮箱或昵称包含该关键字（不区分大小写）
+}
+
+// Matches 检查用户是否满足过滤条件，供内存实现使用，数据库实现需保持相同语义
+func (f UserFilter) Matches(u *entity.User) bool {
+	if f.Status != 0 && u.Status != f.Status {
+		return false
+	}
+	if f.Role != "" && u.Role != f.Role {
+		return false
+	}
+	if f.CreatedFrom != nil && u.CreatedAt.Before(*f.CreatedFrom) {
+		return false
+	}
+	if f.CreatedBefore != nil && !u.CreatedAt.Before(*f.CreatedBefore) {
+		return false
+	}
+	if f.Keyword != "" {
+		kw := strings.ToLower(f.Keyword)
+		if !strings.Contains(strings.ToLower(u.Username), kw) &&
+			!strings.Contains(strings.ToLower(u.Email.String()), kw) &&
+			!strings.Contains(strings.ToLower(u.Nickname), kw) {
+			return false
+		}
+	}
+	return true
+}
+
+// UserCursor 键集分页的位置，即上一页最后一个用户的排序键
+// 排序值相同时按 ID 定序，所以 ID 总是需要
+type UserCursor struct {
+	ID        uint64
+	CreatedAt time.Time
+	Username  string
+}
+
+// UserCursorOf 取用户的排序键作为下一页的起点
+func UserCursorOf(u *entity.User) *UserCursor {
+	return &UserCursor{ID: u.ID, CreatedAt: u.CreatedAt, Username: u.Username}
+}
+
+// UserListOptions 用户列表的查询选项
+// 排序值相同的用户按 ID 同方向排序；设置 After 时从该位置之后开始（键集分页），忽略 Offset
+type UserListOptions struct {
+	Filter UserFilter
+	SortBy UserSortField // 空表示按 ID
+	Desc   bool
+	Offset int
+	Limit  int
+	After  *UserCursor
+}
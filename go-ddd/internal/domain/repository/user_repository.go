@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 
+	"yiwen/go-ddd/internal/domain/aggregate"
 	"yiwen/go-ddd/internal/domain/entity"
 )
 
@@ -16,6 +17,16 @@ type UserRepository interface {
 	// Save 保存用户（创建或更新）
 	Save(ctx context.Context, user *entity.User) error
 
+	// SaveAggregate 保存聚合根，并在同一事务中将未提交的领域事件写入发件箱（outbox）
+	// 事务提交后事件由发件箱中继至少投递一次，调用方负责在发布后清除事件
+	SaveAggregate(ctx context.Context, agg *aggregate.UserAggregate) error
+
+	// SaveAggregates 在同一事务中保存多个聚合（例如管理员权限转移），要么全部成功要么全部失败
+	SaveAggregates(ctx context.Context, aggs ...*aggregate.UserAggregate) error
+
+	// FindAggregateByID 根据ID加载用户聚合（携带版本号，用于执行命令）
+	FindAggregateByID(ctx context.Context, id uint64) (*aggregate.UserAggregate, error)
+
 	// FindByID 根据ID查找用户
 	FindByID(ctx context.Context, id uint64) (*entity.User, error)
 
@@ -31,12 +42,19 @@ type UserRepository interface {
 	// Delete 删除用户（软删除）
 	Delete(ctx context.Context, id uint64) error
 
-	// List 分页查询用户列表
-	List(ctx context.Context, offset, limit int) ([]*entity.User, int64, error)
+	// List 按条件查询用户列表，返回当前页和满足过滤条件的总数（不受分页影响）
+	List(ctx context.Context, opts UserListOptions) ([]*entity.User, int64, error)
 
 	// ExistsByUsername 检查用户名是否存在
 	ExistsByUsername(ctx context.Context, username string) (bool, error)
 
 	// ExistsByEmail 检查邮箱是否存在
 	ExistsByEmail(ctx context.Context, email string) (bool, error)
+
+	// CountActiveAdmins 统计处于激活状态的管理员数量
+	// 在事务中调用时锁定这些管理员记录直到事务结束，用于"至少保留一个管理员"的检查
+	CountActiveAdmins(ctx context.Context) (int64, error)
+
+	// FindPendingErasures 查找已申请删除账户、等待擦除个人数据的用户，按擦除时间升序
+	FindPendingErasures(ctx context.Context) ([]*entity.User, error)
 }
@@ -0,0 +1,217 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/repository"
+)
+
+var ErrTooManyLoginAttempts = errors.New("too many failed login attempts, try again later")
+
+// LoginBlockedError 登录请求在校验密码之前被拒绝
+// Err 为 ErrTooManyLoginAttempts（退避中）或 ErrAccountLocked（锁定中）
+type LoginBlockedError struct {
+	Err        error
+	RetryAfter time.Duration
+}
+
+func (e *LoginBlockedError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *LoginBlockedError) Unwrap() error {
+	return e.Err
+}
+
+// LoginThrottlePolicy 登录失败的退避和锁定策略
+// 前 FreeAttempts 次失败不受限制；之后每次失败的等待时间从 BaseDelay 开始翻倍，最长 MaxDelay；
+// 失败达到 LockThreshold 次时锁定 LockDuration。距上次失败超过 Window 后重新计数
+type LoginThrottlePolicy struct {
+	FreeAttempts  int
+	BaseDelay     time.Duration
+	MaxDelay      time.Duration
+	LockThreshold int // 0 表示只退避不锁定
+	LockDuration  time.Duration
+	Window        time.Duration
+}
+
+// retryAfter 计算下一次允许尝试前还需等待的时间，locked 表示处于锁定期
+func (p LoginThrottlePolicy) retryAfter(a *entity.LoginAttempt, now time.Time) (wait time.Duration, locked bool) {
+	if a.Failures == 0 {
+		return 0, false
+	}
+	if p.LockThreshold > 0 && a.Failures >= p.LockThreshold {
+		if until := a.LastFailedAt.Add(p.LockDuration); now.Before(until) {
+			return until.Sub(now), true
+		}
+	}
+	if a.Failures < p.FreeAttempts || now.Sub(a.LastFailedAt) > p.Window {
+		return 0, false
+	}
+
+	delay := p.BaseDelay
+	for i := p.FreeAttempts; i < a.Failures && delay < p.MaxDelay; i++ {
+		delay *= 2
+	}
+	if delay > p.MaxDelay {
+		delay = p.MaxDelay
+	}
+	if until := a.LastFailedAt.Add(delay); now.Before(until) {
+		return until.Sub(now), false
+	}
+	return 0, false
+}
+
+// blocked 不允许本次尝试时返回拒绝原因和等待时间，允许时返回 nil
+func (p LoginThrottlePolicy) blocked(a *entity.LoginAttempt, now time.Time) *LoginBlockedError {
+	wait, locked := p.retryAfter(a, now)
+	if wait <= 0 {
+		return nil
+	}
+	if locked {
+		return &LoginBlockedError{Err: ErrAccountLocked, RetryAfter: wait}
+	}
+	return &LoginBlockedError{Err: ErrTooManyLoginAttempts, RetryAfter: wait}
+}
+
+// LoginGuard 登录防暴力破解：按用户名和客户端IP分别统计失败次数
+// 计数与用户是否存在无关，因此退避、锁定的响应也不会泄露用户名是否存在
+type LoginGuard struct {
+	attempts   repository.LoginAttemptRepository
+	userPolicy LoginThrottlePolicy
+	ipPolicy   LoginThrottlePolicy
+}
+
+// NewLoginGuard 创建登录防护
+func NewLoginGuard(attempts repository.LoginAttemptRepository, userPolicy, ipPolicy LoginThrottlePolicy) *LoginGuard {
+	return &LoginGuard{
+		attempts:   attempts,
+		userPolicy: userPolicy,
+		ipPolicy:   ipPolicy,
+	}
+}
+
+// LoginReservation 校验密码之前预占的一次登录尝试
+// 预占时已计为一次失败，并发的请求看到的是计入本次尝试后的次数，无法同时绕过退避
+type LoginReservation struct {
+	username string
+	at       time.Time
+	failures int      // 计入本次尝试后用户名的失败次数
+	keys     []string // 已预占的键
+}
+
+// Reserve 在校验密码之前原子地检查并预占本次尝试，不允许时返回 *LoginBlockedError 且不计数
+// 之后必须以 RecordFailure、RecordSuccess 或 Release 之一结束
+func (g *LoginGuard) Reserve(ctx context.Context, username, ip string) (*LoginReservation, error) {
+	now := time.Now()
+	r := &LoginReservation{username: username, at: now}
+	keys := g.keys(username, ip)
+	for i, k := range keys {
+		var blocked *LoginBlockedError
+		attempt, ok, err := g.attempts.Reserve(ctx, k.key, now, k.policy.Window, func(a *entity.LoginAttempt) bool {
+			blocked = k.policy.blocked(a, now)
+			return blocked == nil
+		})
+		if err != nil {
+			return nil, errors.Join(err, g.release(ctx, r.keys))
+		}
+		if !ok {
+			if err := g.release(ctx, r.keys); err != nil {
+				return nil, err
+			}
+			return nil, g.longestWait(ctx, blocked, keys[i+1:], now)
+		}
+		r.keys = append(r.keys, k.key)
+		if k.user {
+			r.failures = attempt.Failures
+		}
+	}
+	return r, nil
+}
+
+// RecordFailure 确认预占的尝试失败（密码或两步验证码错误），预占时已经计数
+// 用户名的失败次数达到锁定阈值时返回锁定截止时间，调用方据此锁定用户聚合
+func (g *LoginGuard) RecordFailure(r *LoginReservation) (lockedUntil *time.Time, failures int) {
+	if g.userPolicy.LockThreshold > 0 && r.failures >= g.userPolicy.LockThreshold {
+		until := r.at.Add(g.userPolicy.LockDuration)
+		lockedUntil = &until
+	}
+	return lockedUntil, r.failures
+}
+
+// RecordSuccess 登录成功：清除用户名的失败记录，撤销对IP的预占
+// IP 之前的计数不清除，避免攻击者用自己的账户登录成功来重置退避
+func (g *LoginGuard) RecordSuccess(ctx context.Context, r *LoginReservation) error {
+	if err := g.Reset(ctx, r.username); err != nil {
+		return err
+	}
+	return g.release(ctx, r.keys[1:])
+}
+
+// Release 撤销预占：尝试在校验密码之外的原因上结束（如密码正确但账户已锁定、存储出错），不计为失败
+func (g *LoginGuard) Release(ctx context.Context, r *LoginReservation) error {
+	return g.release(ctx, r.keys)
+}
+
+// Reset 清除用户名的失败记录（管理员解锁）
+func (g *LoginGuard) Reset(ctx context.Context, username string) error {
+	return g.attempts.Reset(ctx, userAttemptKey(username))
+}
+
+// PurgeExpired 清理已不再影响判定的失败记录
+func (g *LoginGuard) PurgeExpired(ctx context.Context) error {
+	keep := g.userPolicy.Window
+	for _, d := range []time.Duration{g.userPolicy.LockDuration, g.ipPolicy.Window, g.ipPolicy.LockDuration} {
+		if d > keep {
+			keep = d
+		}
+	}
+	return g.attempts.DeleteBefore(ctx, time.Now().Add(-keep))
+}
+
+// release 撤销已预占的键
+func (g *LoginGuard) release(ctx context.Context, keys []string) error {
+	var errs []error
+	for _, key := range keys {
+		if err := g.attempts.Release(ctx, key); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// longestWait 某个键拒绝本次尝试后，用其余键的记录取最长的等待时间返回
+func (g *LoginGuard) longestWait(ctx context.Context, blocked *LoginBlockedError, rest []attemptKey, now time.Time) error {
+	for _, k := range rest {
+		attempt, err := g.attempts.Get(ctx, k.key)
+		if err != nil {
+			return err
+		}
+		if b := k.policy.blocked(attempt, now); b != nil && b.RetryAfter > blocked.RetryAfter {
+			blocked = b
+		}
+	}
+	return blocked
+}
+
+type attemptKey struct {
+	key    string
+	policy LoginThrottlePolicy
+	user   bool // 按用户名统计的键，达到阈值时锁定用户
+}
+
+// keys 返回本次登录需要统计的键，IP 未知时只按用户名统计
+func (g *LoginGuard) keys(username, ip string) []attemptKey {
+	keys := []attemptKey{{key: userAttemptKey(username), policy: g.userPolicy, user: true}}
+	if ip != "" {
+		keys = append(keys, attemptKey{key: "ip:" + ip, policy: g.ipPolicy})
+	}
+	return keys
+}
+
+func userAttemptKey(username string) string {
+	return "user:" + username
+}
@@ -0,0 +1,281 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"yiwen/go-ddd/internal/domain/entity"
+)
+
+// attemptStore 内存中的登录失败计数，实现 repository.LoginAttemptRepository
+type attemptStore struct {
+	mu       sync.Mutex
+	attempts map[string]*entity.LoginAttempt
+}
+
+func newAttemptStore() *attemptStore {
+	return &attemptStore{attempts: make(map[string]*entity.LoginAttempt)}
+}
+
+func (s *attemptStore) Get(ctx context.Context, key string) (*entity.LoginAttempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if a, ok := s.attempts[key]; ok {
+		c := *a
+		return &c, nil
+	}
+	return &entity.LoginAttempt{Key: key}, nil
+}
+
+func (s *attemptStore) Reserve(ctx context.Context, key string, now time.Time, window time.Duration, allow func(*entity.LoginAttempt) bool) (*entity.LoginAttempt, bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, ok := s.attempts[key]
+	if !ok {
+		a = &entity.LoginAttempt{Key: key}
+	}
+	c := *a
+	if !allow(&c) {
+		return &c, false, nil
+	}
+	if now.Sub(a.LastFailedAt) > window {
+		a = &entity.LoginAttempt{Key: key}
+	}
+	a.Failures++
+	a.LastFailedAt = now
+	s.attempts[key] = a
+	c = *a
+	return &c, true, nil
+}
+
+func (s *attemptStore) Release(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if a, ok := s.attempts[key]; ok && a.Failures > 0 {
+		a.Failures--
+	}
+	return nil
+}
+
+func (s *attemptStore) Reset(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.attempts, key)
+	return nil
+}
+
+func (s *attemptStore) DeleteBefore(ctx context.Context, before time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for k, a := range s.attempts {
+		if a.LastFailedAt.Before(before) {
+			delete(s.attempts, k)
+		}
+	}
+	return nil
+}
+
+func (s *attemptStore) failures(key string) int {
+	a, _ := s.Get(context.Background(), key)
+	return a.Failures
+}
+
+var testThrottlePolicy = LoginThrottlePolicy{
+	FreeAttempts:  3,
+	BaseDelay:     time.Second,
+	MaxDelay:      8 * time.Second,
+	LockThreshold: 10,
+	LockDuration:  15 * time.Minute,
+	Window:        time.Hour,
+}
+
+func TestLoginThrottlePolicyRetryAfter(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name       string
+		failures   int
+		sinceLast  time.Duration
+		wantWait   time.Duration
+		wantLocked bool
+	}{
+		{"no failures", 0, 0, 0, false},
+		{"within free attempts", 2, 0, 0, false},
+		{"first delayed attempt", 3, 0, time.Second, false},
+		{"delay doubles", 4, 0, 2 * time.Second, false},
+		{"delay doubles again", 5, 0, 4 * time.Second, false},
+		{"delay capped at max", 8, 0, 8 * time.Second, false},
+		{"delay partly elapsed", 4, 500 * time.Millisecond, 1500 * time.Millisecond, false},
+		{"delay elapsed", 4, 2 * time.Second, 0, false},
+		{"failures outside window are ignored", 5, 2 * time.Hour, 0, false},
+		{"lock threshold reached", 10, 0, 15 * time.Minute, true},
+		{"lock partly elapsed", 10, 5 * time.Minute, 10 * time.Minute, true},
+		{"lock expired", 10, 20 * time.Minute, 0, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			attempt := &entity.LoginAttempt{Failures: tt.failures, LastFailedAt: now.Add(-tt.sinceLast)}
+			wait, locked := testThrottlePolicy.retryAfter(attempt, now)
+			if wait != tt.wantWait || locked != tt.wantLocked {
+				t.Fatalf("retryAfter = (%s, %v), want (%s, %v)", wait, locked, tt.wantWait, tt.wantLocked)
+			}
+		})
+	}
+
+	// 未配置锁定阈值时只退避不锁定
+	noLock := testThrottlePolicy
+	noLock.LockThreshold = 0
+	if wait, locked := noLock.retryAfter(&entity.LoginAttempt{Failures: 50, LastFailedAt: now}, now); locked || wait != 8*time.Second {
+		t.Fatalf("retryAfter without lock = (%s, %v)", wait, locked)
+	}
+}
+
+func TestLoginGuardLockout(t *testing.T) {
+	ctx := context.Background()
+	ipPolicy := LoginThrottlePolicy{FreeAttempts: 5, BaseDelay: time.Second, MaxDelay: time.Second, Window: time.Hour}
+	noDelay := testThrottlePolicy
+	noDelay.BaseDelay, noDelay.MaxDelay = 0, 0 // 只测试锁定，不等待退避
+	guard := NewLoginGuard(newAttemptStore(), noDelay, ipPolicy)
+
+	for i := 1; i <= testThrottlePolicy.LockThreshold; i++ {
+		reservation, err := guard.Reserve(ctx, "alice", "")
+		if err != nil {
+			t.Fatalf("attempt %d: %v", i, err)
+		}
+		lockedUntil, failures := guard.RecordFailure(reservation)
+		if failures != i {
+			t.Fatalf("failure %d counted as %d", i, failures)
+		}
+		if (lockedUntil != nil) != (i == testThrottlePolicy.LockThreshold) {
+			t.Fatalf("failure %d: lockedUntil = %v", i, lockedUntil)
+		}
+	}
+
+	var blocked *LoginBlockedError
+	_, err := guard.Reserve(ctx, "alice", "198.51.100.1")
+	if !errors.As(err, &blocked) || !errors.Is(err, ErrAccountLocked) {
+		t.Fatalf("expected the user to be locked, got %v", err)
+	}
+	if blocked.RetryAfter <= 14*time.Minute || blocked.RetryAfter > testThrottlePolicy.LockDuration {
+		t.Fatalf("unexpected retry after %s", blocked.RetryAfter)
+	}
+
+	// 管理员解锁清除用户名的计数
+	if err := guard.Reset(ctx, "alice"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := guard.Reserve(ctx, "alice", "198.51.100.1"); err != nil {
+		t.Fatalf("expected reset to clear the lock, got %v", err)
+	}
+}
+
+func TestLoginGuardIPThrottle(t *testing.T) {
+	ctx := context.Background()
+	store := newAttemptStore()
+	ipPolicy := LoginThrottlePolicy{FreeAttempts: 2, BaseDelay: time.Second, MaxDelay: time.Second, Window: time.Hour}
+	guard := NewLoginGuard(store, LoginThrottlePolicy{Window: time.Hour}, ipPolicy)
+
+	for _, username := range []string{"alice", "bob"} {
+		reservation, err := guard.Reserve(ctx, username, "203.0.113.7")
+		if err != nil {
+			t.Fatal(err)
+		}
+		guard.RecordFailure(reservation)
+	}
+
+	// IP 只退避不锁定：换一个用户名时返回按 IP 计算的等待时间，被拒绝的尝试不计数
+	var blocked *LoginBlockedError
+	_, err := guard.Reserve(ctx, "carol", "203.0.113.7")
+	if !errors.As(err, &blocked) || !errors.Is(err, ErrTooManyLoginAttempts) {
+		t.Fatalf("expected the ip to be throttled, got %v", err)
+	}
+	if blocked.RetryAfter <= 0 || blocked.RetryAfter > ipPolicy.MaxDelay {
+		t.Fatalf("unexpected ip retry after %s", blocked.RetryAfter)
+	}
+	if n := store.failures("user:carol"); n != 0 {
+		t.Fatalf("blocked attempt was counted for the username: %d", n)
+	}
+	if n := store.failures("ip:203.0.113.7"); n != 2 {
+		t.Fatalf("expected 2 ip failures, got %d", n)
+	}
+}
+
+func TestLoginGuardReservationOutcome(t *testing.T) {
+	ctx := context.Background()
+	store := newAttemptStore()
+	policy := LoginThrottlePolicy{FreeAttempts: 10, BaseDelay: time.Second, MaxDelay: time.Second, Window: time.Hour}
+	guard := NewLoginGuard(store, policy, policy)
+
+	failed, err := guard.Reserve(ctx, "alice", "203.0.113.7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	guard.RecordFailure(failed)
+
+	// 预占期间按失败计数
+	reservation, err := guard.Reserve(ctx, "alice", "203.0.113.7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if store.failures("user:alice") != 2 || store.failures("ip:203.0.113.7") != 2 {
+		t.Fatalf("expected the reservation to be counted, got %d and %d", store.failures("user:alice"), store.failures("ip:203.0.113.7"))
+	}
+
+	// 登录成功清除用户名的计数；IP 只撤销本次预占，之前的失败仍然保留
+	if err := guard.RecordSuccess(ctx, reservation); err != nil {
+		t.Fatal(err)
+	}
+	if store.failures("user:alice") != 0 || store.failures("ip:203.0.113.7") != 1 {
+		t.Fatalf("after success got %d and %d", store.failures("user:alice"), store.failures("ip:203.0.113.7"))
+	}
+
+	// 因其他原因结束时撤销全部预占
+	reservation, err = guard.Reserve(ctx, "alice", "203.0.113.7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := guard.Release(ctx, reservation); err != nil {
+		t.Fatal(err)
+	}
+	if store.failures("user:alice") != 0 || store.failures("ip:203.0.113.7") != 1 {
+		t.Fatalf("after release got %d and %d", store.failures("user:alice"), store.failures("ip:203.0.113.7"))
+	}
+}
+
+func TestLoginGuardConcurrentAttempts(t *testing.T) {
+	ctx := context.Background()
+	store := newAttemptStore()
+	guard := NewLoginGuard(store, testThrottlePolicy, LoginThrottlePolicy{Window: time.Hour})
+
+	// 同时到达的一批请求只有免退避的次数可以校验密码
+	const burst = 50
+	var wg sync.WaitGroup
+	var allowed, throttled atomic.Int32
+	for i := 0; i < burst; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			reservation, err := guard.Reserve(ctx, "alice", "203.0.113.7")
+			switch {
+			case errors.Is(err, ErrTooManyLoginAttempts):
+				throttled.Add(1)
+			case err != nil:
+				t.Error(err)
+			default:
+				allowed.Add(1)
+				guard.RecordFailure(reservation)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if int(allowed.Load()) != testThrottlePolicy.FreeAttempts || int(throttled.Load()) != burst-testThrottlePolicy.FreeAttempts {
+		t.Fatalf("allowed %d and throttled %d of %d concurrent attempts", allowed.Load(), throttled.Load(), burst)
+	}
+	if n := store.failures("user:alice"); n != testThrottlePolicy.FreeAttempts {
+		t.Fatalf("expected %d failures, got %d", testThrottlePolicy.FreeAttempts, n)
+	}
+}
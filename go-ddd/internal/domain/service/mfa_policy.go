@@ -0,0 +1,32 @@
+package service
+
+import (
+	"errors"
+
+	"yiwen/go-ddd/internal/domain/entity"
+)
+
+var ErrMFARequired = errors.New("two-factor authentication is required for this account")
+
+// MFAPolicy 两步验证策略：哪些用户必须启用第二因素
+type MFAPolicy struct {
+	requireForAdmins bool
+}
+
+// NewMFAPolicy 创建两步验证策略，requireForAdmins 为 true 时所有管理员必须启用
+func NewMFAPolicy(requireForAdmins bool) *MFAPolicy {
+	return &MFAPolicy{requireForAdmins: requireForAdmins}
+}
+
+// Requires 用户是否必须启用两步验证
+func (p *MFAPolicy) Requires(user *entity.User) bool {
+	return p.requireForAdmins && user.Role == entity.UserRoleAdmin
+}
+
+// EnsureCanDisable 必须启用两步验证的用户不能自行关闭
+func (p *MFAPolicy) EnsureCanDisable(user *entity.User) error {
+	if p.Requires(user) {
+		return ErrMFARequired
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"errors"
+	"sync"
+
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/valueobject"
+)
+
+var ErrPasswordReused = errors.New("password was used recently, choose a different one")
+
+// PasswordService 密码领域服务：按配置的策略创建密码、检查历史密码、校验并报告过时的哈希
+type PasswordService struct {
+	policy valueobject.PasswordPolicy
+	hasher *valueobject.PasswordHasher
+
+	dummyOnce sync.Once
+	dummy     valueobject.Password
+}
+
+// NewPasswordService 创建密码领域服务
+func NewPasswordService(policy valueobject.PasswordPolicy, hasher *valueobject.PasswordHasher) *PasswordService {
+	return &PasswordService{
+		policy: policy,
+		hasher: hasher,
+	}
+}
+
+// NewPassword 校验强度并计算哈希（注册、修改密码、重置密码）
+func (s *PasswordService) NewPassword(plaintext string) (valueobject.Password, error) {
+	return valueobject.NewPassword(plaintext, s.policy, s.hasher)
+}
+
+// Verify 校验密码；匹配时 needsRehash 表示哈希的算法或成本已过时，调用方应使用 Rehash 升级
+func (s *PasswordService) Verify(password valueobject.Password, plaintext string) (needsRehash bool, err error) {
+	return s.hasher.Verify(password, plaintext)
+}
+
+// Rehash 用当前配置重新计算同一密码的哈希，不做强度校验（旧密码可能不满足新策略）
+func (s *PasswordService) Rehash(plaintext string) (valueobject.Password, error) {
+	return s.hasher.Hash(plaintext)
+}
+
+// HistorySize 修改密码时保留的旧密码数量
+func (s *PasswordService) HistorySize() int {
+	return s.policy.HistorySize
+}
+
+// EnsureNotReused 新密码不能与当前密码及最近 HistorySize 个旧密码相同
+// 需要逐个校验哈希，耗时与历史数量成正比
+func (s *PasswordService) EnsureNotReused(user *entity.User, plaintext string) error {
+	if s.policy.HistorySize <= 0 {
+		return nil
+	}
+	if user.Password.Verify(plaintext) == nil {
+		return ErrPasswordReused
+	}
+	for i, hash := range user.PasswordHistory {
+		if i >= s.policy.HistorySize {
+			break
+		}
+		if valueobject.NewPasswordFromHash(hash).Verify(plaintext) == nil {
+			return ErrPasswordReused
+		}
+	}
+	return nil
+}
+
+// verifyDummy 对不存在的用户校验一个与真实密码成本相同的哈希，使响应时间一致
+func (s *PasswordService) verifyDummy(plaintext string) {
+	s.dummyOnce.Do(func() {
+		s.dummy, _ = s.hasher.Hash("Dummy-Passw0rd")
+	})
+	_ = s.dummy.Verify(plaintext)
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"errors"
+
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/valueobject"
+)
+
+var ErrPermissionDenied = errors.New("permission denied")
+
+// Grant 授权规则：某个角色在某个范围内拥有某项权限
+type Grant struct {
+	Role       entity.UserRole
+	Permission valueobject.Permission
+	Scope      valueobject.Scope
+}
+
+// PolicyEngine 授权策略引擎
+// 角色与权限的对应关系、资源级规则（例如"本人或管理员"）都集中在这里，
+// 接口层只负责描述"谁、对什么资源、做什么"
+type PolicyEngine struct {
+	grants map[entity.UserRole]map[valueobject.Permission]valueobject.Scope
+}
+
+// NewPolicyEngine 根据授权规则创建策略引擎
+// 同一角色对同一权限配置多条规则时取范围最大的一条
+func NewPolicyEngine(grants ...Grant) *PolicyEngine {
+	e := &PolicyEngine{grants: make(map[entity.UserRole]map[valueobject.Permission]valueobject.Scope)}
+	for _, g := range grants {
+		perms, ok := e.grants[g.Role]
+		if !ok {
+			perms = make(map[valueobject.Permission]valueobject.Scope)
+			e.grants[g.Role] = perms
+		}
+		if scope, ok := perms[g.Permission]; !ok || g.Scope > scope {
+			perms[g.Permission] = g.Scope
+		}
+	}
+	return e
+}
+
+// DefaultGrants 系统默认的角色权限
+func DefaultGrants() []Grant {
+	return []Grant{
+		// 普通用户：可以查看用户资料，只能修改自己的资料和密码
+		{Role: entity.UserRoleUser, Permission: valueobject.PermissionUserRead, Scope: valueobject.ScopeAny},
+		{Role: entity.UserRoleUser, Permission: valueobject.PermissionUserUpdate, Scope: valueobject.ScopeOwn},
+		{Role: entity.UserRoleUser, Permission: valueobject.PermissionUserChangePassword, Scope: valueobject.ScopeOwn},
+
+		// 管理员：可以管理任意用户，但修改密码需要旧密码，仍然只能改自己的
+		{Role: entity.UserRoleAdmin, Permission: valueobject.PermissionUserRead, Scope: valueobject.ScopeAny},
+		{Role: entity.UserRoleAdmin, Permission: valueobject.PermissionUserList, Scope: valueobject.ScopeAny},
+		{Role: entity.UserRoleAdmin, Permission: valueobject.PermissionUserUpdate, Scope: valueobject.ScopeAny},
+		{Role: entity.UserRoleAdmin, Permission: valueobject.PermissionUserChangePassword, Scope: valueobject.ScopeOwn},
+		{Role: entity.UserRoleAdmin, Permission: valueobject.PermissionUserDelete, Scope: valueobject.ScopeAny},
+		{Role: entity.UserRoleAdmin, Permission: valueobject.PermissionUserManageStatus, Scope: valueobject.ScopeAny},
+		{Role: entity.UserRoleAdmin, Permission: valueobject.PermissionUserManageRole, Scope: valueobject.ScopeAny},
+	}
+}
+
+// NewDefaultPolicyEngine 使用默认角色权限创建策略引擎
+func NewDefaultPolicyEngine() *PolicyEngine {
+	return NewPolicyEngine(DefaultGrants()...)
+}
+
+// Authorize 判断主体能否对资源执行操作，不允许时返回 ErrPermissionDenied
+func (e *PolicyEngine) Authorize(subject valueobject.Subject, permission valueobject.Permission, resource valueobject.Resource) error {
+	scope, ok := e.grants[entity.UserRole(subject.Role)][permission]
+	if !ok {
+		return ErrPermissionDenied
+	}
+
+	switch scope {
+	case valueobject.ScopeAny:
+		return nil
+	case valueobject.ScopeOwn:
+		if resource.IsOwnedBy(subject.UserID) {
+			return nil
+		}
+	}
+	return ErrPermissionDenied
+}
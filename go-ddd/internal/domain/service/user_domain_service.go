@@ -3,7 +3,9 @@ package service
 import (
 	"context"
 	"errors"
+	"time"
 
+	"yiwen/go-ddd/internal/domain/aggregate"
 	"yiwen/go-ddd/internal/domain/entity"
 	"yiwen/go-ddd/internal/domain/repository"
 )
@@ -13,20 +15,28 @@ var (
 	ErrEmailAlreadyExists    = errors.New("email already exists")
 	ErrUserNotFound          = errors.New("user not found")
 	ErrUserNotActive         = errors.New("user is not active")
+	ErrEmailNotVerified      = errors.New("email is not verified")
+	ErrAccountLocked         = errors.New("account is temporarily locked")
 	ErrInvalidCredentials    = errors.New("invalid credentials")
+	ErrNotAdmin              = errors.New("source user is not an admin")
+	ErrAlreadyAdmin          = errors.New("target user is already an admin")
+	ErrTransferToSelf        = errors.New("cannot transfer admin to yourself")
+	ErrLastAdmin             = errors.New("cannot demote, disable or delete the last active admin")
 )
 
 // UserDomainService 用户领域服务
 // 领域服务用于处理不属于单个实体的业务逻辑
 // 例如：涉及多个实体的操作、需要访问仓储的验证逻辑等
 type UserDomainService struct {
-	userRepo repository.UserRepository
+	userRepo  repository.UserRepository
+	passwords *PasswordService
 }
 
 // NewUserDomainService 创建用户领域服务
-func NewUserDomainService(userRepo repository.UserRepository) *UserDomainService {
+func NewUserDomainService(userRepo repository.UserRepository, passwords *PasswordService) *UserDomainService {
 	return &UserDomainService{
-		userRepo: userRepo,
+		userRepo:  userRepo,
+		passwords: passwords,
 	}
 }
 
@@ -55,48 +65,73 @@ func (s *UserDomainService) ValidateUniqueEmail(ctx context.Context, email strin
 }
 
 // ValidateUserCredentials 验证用户凭证（登录）
-func (s *UserDomainService) ValidateUserCredentials(ctx context.Context, username, password string) (*entity.User, error) {
-	user, err := s.userRepo.FindByUsername(ctx, username)
+// 用户不存在时同样校验一次密码哈希，使两种情况的响应和耗时一致，不泄露用户名是否存在
+// 锁定、未激活等状态只在密码正确时才返回
+// needsRehash 表示密码正确但哈希的算法或成本已过时，调用方可以用明文重新计算哈希
+func (s *UserDomainService) ValidateUserCredentials(ctx context.Context, username, password string) (user *entity.User, needsRehash bool, err error) {
+	user, err = s.userRepo.FindByUsername(ctx, username)
 	if err != nil {
-		return nil, ErrInvalidCredentials
+		s.passwords.verifyDummy(password)
+		return nil, false, ErrInvalidCredentials
 	}
 
-	if err := user.Password.Verify(password); err != nil {
-		return nil, ErrInvalidCredentials
+	needsRehash, err = s.passwords.Verify(user.Password, password)
+	if err != nil {
+		return nil, false, ErrInvalidCredentials
+	}
+
+	if user.IsLocked(time.Now()) {
+		return nil, false, ErrAccountLocked
 	}
 
 	if !user.IsActive() {
-		return nil, ErrUserNotActive
+		// 注册后尚未验证邮箱的用户给出明确提示，便于客户端引导重新发送验证邮件
+		if user.IsPendingVerification() {
+			return nil, false, ErrEmailNotVerified
+		}
+		return nil, false, ErrUserNotActive
 	}
 
-	return user, nil
+	return user, needsRehash, nil
 }
 
-// CanUserPerformAction 检查用户是否可以执行操作
-func (s *UserDomainService) CanUserPerformAction(user *entity.User, action string) bool {
-	// 管理员可以执行所有操作
-	if user.IsAdmin() {
-		return true
+// EnsureNotLastAdmin 检查管理员能否被降级、禁用、停用或删除：系统中至少要保留一个激活的管理员
+// 需要在保存变更的同一事务中调用：仓储统计时锁定激活的管理员记录，
+// 并发地对不同管理员执行这些操作时会串行检查，不会同时通过
+func (s *UserDomainService) EnsureNotLastAdmin(ctx context.Context, user *entity.User) error {
+	if !user.IsAdmin() || !user.IsActive() {
+		return nil
 	}
 
-	// 普通用户只能执行部分操作
-	allowedActions := map[string]bool{
-		"view_profile":   true,
-		"update_profile": true,
-		"change_password": true,
+	count, err := s.userRepo.CountActiveAdmins(ctx)
+	if err != nil {
+		return err
 	}
-
-	return allowedActions[action]
+	if count <= 1 {
+		return ErrLastAdmin
+	}
+	return nil
 }
 
-// TransferAdmin 转移管理员权限
-func (s *UserDomainService) TransferAdmin(ctx context.Context, fromUser, toUser *entity.User) error {
-	if !fromUser.IsAdmin() {
-		return errors.New("source user is not an admin")
+// TransferAdmin 转移管理员权限：提升目标用户，降级原管理员
+// 两个聚合都会产生事件，调用方需要在同一事务中保存（SaveAggregates）
+func (s *UserDomainService) TransferAdmin(ctx context.Context, from, to *aggregate.UserAggregate, reason string) error {
+	if from.User.UUID == to.User.UUID {
+		return ErrTransferToSelf
+	}
+	if !from.User.IsAdmin() || !from.User.IsActive() {
+		return ErrNotAdmin
+	}
+	if to.User.IsAdmin() {
+		return ErrAlreadyAdmin
+	}
+	if !to.User.IsActive() {
+		return ErrUserNotActive
 	}
 
-	// 这里可以添加更多的业务规则
-	// 例如：检查目标用户是否满足成为管理员的条件
-
+	if err := from.HandOverAdmin(to.User.UUID, reason); err != nil {
+		return err
+	}
+	to.PromoteToAdmin()
 	return nil
 }
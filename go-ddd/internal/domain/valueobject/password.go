@@ -2,14 +2,13 @@ package valueobject
 
 import (
 	"errors"
-	"unicode"
-
-	"golang.org/x/crypto/bcrypt"
 )
 
 var (
-	ErrPasswordTooShort   = errors.New("password must be at least 8 characters")
-	ErrPasswordTooWeak    = errors.New("password must contain at least one uppercase, one lowercase, and one digit")
+	ErrPasswordTooShort   = errors.New("password is too short")
+	ErrPasswordTooLong    = errors.New("password is too long")
+	ErrPasswordTooWeak    = errors.New("password does not meet the complexity requirements")
+	ErrPasswordTooCommon  = errors.New("password is too common or has appeared in a data breach")
 	ErrPasswordHashFailed = errors.New("failed to hash password")
 	ErrPasswordMismatch   = errors.New("password does not match")
 )
@@ -21,24 +20,14 @@ type Password struct {
 	hash string
 }
 
-// NewPassword 从明文创建密码值对象
-func NewPassword(plaintext string) (Password, error) {
+// NewPassword 从明文创建密码值对象：先按策略校验强度，再用 hasher 计算哈希
+func NewPassword(plaintext string, policy PasswordPolicy, hasher *PasswordHasher) (Password, error) {
 	// 验证密码强度
-	if len(plaintext) < 8 {
-		return Password{}, ErrPasswordTooShort
-	}
-
-	if !isStrongPassword(plaintext) {
-		return Password{}, ErrPasswordTooWeak
-	}
-
-	// 使用bcrypt加密
-	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(plaintext), bcrypt.DefaultCost)
-	if err != nil {
-		return Password{}, ErrPasswordHashFailed
+	if err := policy.Validate(plaintext); err != nil {
+		return Password{}, err
 	}
 
-	return Password{hash: string(hashedBytes)}, nil
+	return hasher.Hash(plaintext)
 }
 
 // NewPasswordFromHash 从哈希值创建密码值对象（用于从数据库读取）
@@ -51,10 +40,10 @@ func (p Password) Hash() string {
 	return p.hash
 }
 
-// Verify 验证明文密码是否匹配
+// Verify 验证明文密码是否匹配，根据哈希格式自动识别 bcrypt 或 argon2id
+// 需要知道哈希是否过时（算法或成本低于当前配置）时使用 PasswordHasher.Verify
 func (p Password) Verify(plaintext string) error {
-	err := bcrypt.CompareHashAndPassword([]byte(p.hash), []byte(plaintext))
-	if err != nil {
+	if !verifyHash(p.hash, plaintext) {
 		return ErrPasswordMismatch
 	}
 	return nil
@@ -64,21 +53,3 @@ func (p Password) Verify(plaintext string) error {
 func (p Password) IsEmpty() bool {
 	return p.hash == ""
 }
-
-// isStrongPassword 检查密码强度
-func isStrongPassword(password string) bool {
-	var hasUpper, hasLower, hasDigit bool
-
-	for _, char := range password {
-		switch {
-		case unicode.IsUpper(char):
-			hasUpper = true
-		case unicode.IsLower(char):
-			hasLower = true
-		case unicode.IsDigit(char):
-			hasDigit = true
-		}
-	}
-
-	return hasUpper && hasLower && hasDigit
-}
@@ -0,0 +1,167 @@
+package valueobject
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// 密码哈希算法
+const (
+	PasswordAlgorithmBcrypt   = "bcrypt"
+	PasswordAlgorithmArgon2id = "argon2id"
+)
+
+const argon2idPrefix = "$argon2id$"
+
+// Argon2Params argon2id 参数
+type Argon2Params struct {
+	Memory      uint32 // KiB
+	Iterations  uint32
+	Parallelism uint8
+	SaltLength  uint32
+	KeyLength   uint32
+}
+
+// DefaultArgon2Params 默认 argon2id 参数（64 MiB、3 次迭代、2 线程）
+func DefaultArgon2Params() Argon2Params {
+	return Argon2Params{
+		Memory:      64 * 1024,
+		Iterations:  3,
+		Parallelism: 2,
+		SaltLength:  16,
+		KeyLength:   32,
+	}
+}
+
+// PasswordHasher 按配置的算法和成本计算密码哈希
+// 校验时能识别所有支持的算法，并报告哈希是否低于当前配置（需要重新哈希）
+type PasswordHasher struct {
+	algorithm  string
+	bcryptCost int
+	argon2     Argon2Params
+}
+
+// NewPasswordHasher 创建密码哈希器
+func NewPasswordHasher(algorithm string, bcryptCost int, argon2Params Argon2Params) (*PasswordHasher, error) {
+	switch algorithm {
+	case PasswordAlgorithmBcrypt:
+		if bcryptCost < bcrypt.MinCost || bcryptCost > bcrypt.MaxCost {
+			return nil, fmt.Errorf("invalid bcrypt cost %d", bcryptCost)
+		}
+	case PasswordAlgorithmArgon2id:
+		if argon2Params.Memory == 0 || argon2Params.Iterations == 0 || argon2Params.Parallelism == 0 ||
+			argon2Params.SaltLength == 0 || argon2Params.KeyLength == 0 {
+			return nil, fmt.Errorf("invalid argon2id parameters %+v", argon2Params)
+		}
+	default:
+		return nil, fmt.Errorf("unsupported password algorithm %q", algorithm)
+	}
+	return &PasswordHasher{algorithm: algorithm, bcryptCost: bcryptCost, argon2: argon2Params}, nil
+}
+
+// DefaultPasswordHasher bcrypt DefaultCost，与引入可配置哈希之前的行为一致
+func DefaultPasswordHasher() *PasswordHasher {
+	return &PasswordHasher{algorithm: PasswordAlgorithmBcrypt, bcryptCost: bcrypt.DefaultCost, argon2: DefaultArgon2Params()}
+}
+
+// Hash 计算明文密码的哈希，不做强度校验
+func (h *PasswordHasher) Hash(plaintext string) (Password, error) {
+	if h.algorithm == PasswordAlgorithmArgon2id {
+		salt := make([]byte, h.argon2.SaltLength)
+		if _, err := rand.Read(salt); err != nil {
+			return Password{}, ErrPasswordHashFailed
+		}
+		return Password{hash: encodeArgon2id(h.argon2, salt, plaintext)}, nil
+	}
+
+	// 使用bcrypt加密
+	hashedBytes, err := bcrypt.GenerateFromPassword([]byte(plaintext), h.bcryptCost)
+	if err == bcrypt.ErrPasswordTooLong {
+		return Password{}, fmt.Errorf("%w: bcrypt accepts at most 72 bytes", ErrPasswordTooLong)
+	}
+	if err != nil {
+		return Password{}, ErrPasswordHashFailed
+	}
+	return Password{hash: string(hashedBytes)}, nil
+}
+
+// Verify 验证明文密码；匹配时 needsRehash 报告哈希的算法或成本是否已过时
+func (h *PasswordHasher) Verify(p Password, plaintext string) (needsRehash bool, err error) {
+	if err := p.Verify(plaintext); err != nil {
+		return false, err
+	}
+	return h.NeedsRehash(p), nil
+}
+
+// NeedsRehash 哈希的算法与当前配置不同，或成本参数低于当前配置
+func (h *PasswordHasher) NeedsRehash(p Password) bool {
+	if strings.HasPrefix(p.hash, argon2idPrefix) {
+		if h.algorithm != PasswordAlgorithmArgon2id {
+			return true
+		}
+		params, _, _, err := decodeArgon2id(p.hash)
+		if err != nil {
+			return false
+		}
+		return params.Memory < h.argon2.Memory || params.Iterations < h.argon2.Iterations ||
+			params.Parallelism < h.argon2.Parallelism || params.KeyLength < h.argon2.KeyLength
+	}
+
+	cost, err := bcrypt.Cost([]byte(p.hash))
+	if err != nil {
+		return false
+	}
+	return h.algorithm != PasswordAlgorithmBcrypt || cost < h.bcryptCost
+}
+
+// verifyHash 根据哈希格式选择算法校验
+func verifyHash(hash, plaintext string) bool {
+	if strings.HasPrefix(hash, argon2idPrefix) {
+		params, salt, key, err := decodeArgon2id(hash)
+		if err != nil {
+			return false
+		}
+		computed := argon2.IDKey([]byte(plaintext), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+		return subtle.ConstantTimeCompare(computed, key) == 1
+	}
+	return bcrypt.CompareHashAndPassword([]byte(hash), []byte(plaintext)) == nil
+}
+
+// encodeArgon2id 以 PHC 字符串格式编码：$argon2id$v=19$m=65536,t=3,p=2$salt$hash
+func encodeArgon2id(params Argon2Params, salt []byte, plaintext string) string {
+	key := argon2.IDKey([]byte(plaintext), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+	return fmt.Sprintf("%sv=%d$m=%d,t=%d,p=%d$%s$%s", argon2idPrefix, argon2.Version,
+		params.Memory, params.Iterations, params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt), base64.RawStdEncoding.EncodeToString(key))
+}
+
+// decodeArgon2id 解析 PHC 格式的 argon2id 哈希
+func decodeArgon2id(hash string) (params Argon2Params, salt, key []byte, err error) {
+	parts := strings.Split(hash, "$")
+	if len(parts) != 6 {
+		return params, nil, nil, fmt.Errorf("invalid argon2id hash")
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, fmt.Errorf("unsupported argon2 version")
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return params, nil, nil, fmt.Errorf("invalid argon2id parameters: %w", err)
+	}
+	if salt, err = base64.RawStdEncoding.DecodeString(parts[4]); err != nil {
+		return params, nil, nil, err
+	}
+	if key, err = base64.RawStdEncoding.DecodeString(parts[5]); err != nil {
+		return params, nil, nil, err
+	}
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+	return params, salt, key, nil
+}
@@ -0,0 +1,140 @@
+package valueobject
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testArgon2Params 测试用的低成本 argon2id 参数
+var testArgon2Params = Argon2Params{Memory: 64, Iterations: 1, Parallelism: 1, SaltLength: 8, KeyLength: 16}
+
+func newTestHasher(t *testing.T, algorithm string, bcryptCost int, params Argon2Params) *PasswordHasher {
+	t.Helper()
+	h, err := NewPasswordHasher(algorithm, bcryptCost, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func TestArgon2idEncodeDecode(t *testing.T) {
+	salt := []byte("saltsalt")
+	hash := encodeArgon2id(testArgon2Params, salt, "Secret123")
+	if !strings.HasPrefix(hash, "$argon2id$v=19$m=64,t=1,p=1$") {
+		t.Fatalf("unexpected PHC string %q", hash)
+	}
+
+	params, gotSalt, key, err := decodeArgon2id(hash)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if params != testArgon2Params {
+		t.Fatalf("decoded params %+v, want %+v", params, testArgon2Params)
+	}
+	if !bytes.Equal(gotSalt, salt) || len(key) != int(testArgon2Params.KeyLength) {
+		t.Fatalf("decoded salt %q, key length %d", gotSalt, len(key))
+	}
+	if !verifyHash(hash, "Secret123") || verifyHash(hash, "Secret124") {
+		t.Fatal("argon2id hash did not verify only the original password")
+	}
+
+	invalid := []struct {
+		name string
+		hash string
+	}{
+		{"missing parts", "$argon2id$v=19$m=64,t=1,p=1$c2FsdA"},
+		{"unsupported version", strings.Replace(hash, "v=19", "v=16", 1)},
+		{"malformed parameters", strings.Replace(hash, "m=64,t=1,p=1", "m=64;t=1;p=1", 1)},
+		{"invalid salt encoding", strings.Replace(hash, "$"+strings.Split(hash, "$")[4]+"$", "$!!$", 1)},
+		{"invalid key encoding", hash[:strings.LastIndex(hash, "$")+1] + "!!"},
+	}
+	for _, tt := range invalid {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, _, _, err := decodeArgon2id(tt.hash); err == nil {
+				t.Fatalf("expected error decoding %q", tt.hash)
+			}
+			if verifyHash(tt.hash, "Secret123") {
+				t.Fatal("invalid hash must not verify")
+			}
+		})
+	}
+}
+
+func TestPasswordHasherHashAndVerify(t *testing.T) {
+	hashers := []struct {
+		name   string
+		hasher *PasswordHasher
+		prefix string
+	}{
+		{"bcrypt", newTestHasher(t, PasswordAlgorithmBcrypt, bcrypt.MinCost, Argon2Params{}), "$2a$04$"},
+		{"argon2id", newTestHasher(t, PasswordAlgorithmArgon2id, 0, testArgon2Params), "$argon2id$v=19$m=64,t=1,p=1$"},
+	}
+	for _, tt := range hashers {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := tt.hasher.Hash("Secret123")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !strings.HasPrefix(p.Hash(), tt.prefix) {
+				t.Fatalf("hash %q does not start with %q", p.Hash(), tt.prefix)
+			}
+			if needsRehash, err := tt.hasher.Verify(p, "Secret123"); err != nil || needsRehash {
+				t.Fatalf("verify: needsRehash %v, err %v", needsRehash, err)
+			}
+			if _, err := tt.hasher.Verify(p, "wrong"); err == nil {
+				t.Fatal("expected wrong password to fail")
+			}
+		})
+	}
+}
+
+func TestPasswordHasherNeedsRehash(t *testing.T) {
+	bcryptHash := func(cost int) Password {
+		h, err := bcrypt.GenerateFromPassword([]byte("Secret123"), cost)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return NewPasswordFromHash(string(h))
+	}
+	argon2Hash := func(change func(p *Argon2Params)) Password {
+		params := testArgon2Params
+		if change != nil {
+			change(&params)
+		}
+		return NewPasswordFromHash(encodeArgon2id(params, []byte("saltsalt"), "Secret123"))
+	}
+
+	bcryptHasher := newTestHasher(t, PasswordAlgorithmBcrypt, 5, Argon2Params{})
+	argon2Hasher := newTestHasher(t, PasswordAlgorithmArgon2id, 0, testArgon2Params)
+
+	tests := []struct {
+		name   string
+		hasher *PasswordHasher
+		p      Password
+		want   bool
+	}{
+		{"bcrypt at configured cost", bcryptHasher, bcryptHash(5), false},
+		{"bcrypt above configured cost", bcryptHasher, bcryptHash(6), false},
+		{"bcrypt below configured cost", bcryptHasher, bcryptHash(4), true},
+		{"bcrypt when argon2id is configured", argon2Hasher, bcryptHash(5), true},
+		{"argon2id with configured parameters", argon2Hasher, argon2Hash(nil), false},
+		{"argon2id with more memory", argon2Hasher, argon2Hash(func(p *Argon2Params) { p.Memory = 128 }), false},
+		{"argon2id with less memory", argon2Hasher, argon2Hash(func(p *Argon2Params) { p.Memory = 32 }), true},
+		{"argon2id with fewer iterations", newTestHasher(t, PasswordAlgorithmArgon2id, 0, Argon2Params{Memory: 64, Iterations: 2, Parallelism: 1, SaltLength: 8, KeyLength: 16}), argon2Hash(nil), true},
+		{"argon2id with less parallelism", newTestHasher(t, PasswordAlgorithmArgon2id, 0, Argon2Params{Memory: 64, Iterations: 1, Parallelism: 2, SaltLength: 8, KeyLength: 16}), argon2Hash(nil), true},
+		{"argon2id with a shorter key", argon2Hasher, argon2Hash(func(p *Argon2Params) { p.KeyLength = 8 }), true},
+		{"argon2id when bcrypt is configured", bcryptHasher, argon2Hash(nil), true},
+		{"unparseable argon2id hash", argon2Hasher, NewPasswordFromHash("$argon2id$broken"), false},
+		{"unknown hash format", bcryptHasher, NewPasswordFromHash("plaintext"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.hasher.NeedsRehash(tt.p); got != tt.want {
+				t.Fatalf("NeedsRehash = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
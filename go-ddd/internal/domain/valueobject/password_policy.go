@@ -0,0 +1,129 @@
+package valueobject
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// PasswordPolicy 密码强度策略
+type PasswordPolicy struct {
+	MinLength     int // 最少字符数
+	MaxLength     int // 最多字符数，0 表示不限制（bcrypt 另有 72 字节的上限）
+	RequireUpper  bool
+	RequireLower  bool
+	RequireDigit  bool
+	RequireSymbol bool
+	Blocklist     *PasswordBlocklist // 常见密码、泄露密码列表，nil 表示不检查
+	HistorySize   int                // 不能与当前密码及最近多少个旧密码相同，0 表示不检查
+}
+
+// DefaultPasswordPolicy 默认策略：至少 8 位，包含大小写字母和数字，拒绝常见弱密码
+func DefaultPasswordPolicy() PasswordPolicy {
+	return PasswordPolicy{
+		MinLength:    8,
+		MaxLength:    72,
+		RequireUpper: true,
+		RequireLower: true,
+		RequireDigit: true,
+		Blocklist:    NewPasswordBlocklist(CommonPasswords()...),
+	}
+}
+
+// Validate 校验明文密码是否满足策略（不包括历史密码，历史密码由领域服务检查）
+func (p PasswordPolicy) Validate(plaintext string) error {
+	length := utf8.RuneCountInString(plaintext)
+	if length < p.MinLength {
+		return fmt.Errorf("%w: must be at least %d characters", ErrPasswordTooShort, p.MinLength)
+	}
+	if p.MaxLength > 0 && length > p.MaxLength {
+		return fmt.Errorf("%w: must be at most %d characters", ErrPasswordTooLong, p.MaxLength)
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, char := range plaintext {
+		switch {
+		case unicode.IsUpper(char):
+			hasUpper = true
+		case unicode.IsLower(char):
+			hasLower = true
+		case unicode.IsDigit(char):
+			hasDigit = true
+		case unicode.IsPunct(char) || unicode.IsSymbol(char):
+			hasSymbol = true
+		}
+	}
+
+	var missing []string
+	if p.RequireUpper && !hasUpper {
+		missing = append(missing, "an uppercase letter")
+	}
+	if p.RequireLower && !hasLower {
+		missing = append(missing, "a lowercase letter")
+	}
+	if p.RequireDigit && !hasDigit {
+		missing = append(missing, "a digit")
+	}
+	if p.RequireSymbol && !hasSymbol {
+		missing = append(missing, "a symbol")
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("%w: must contain %s", ErrPasswordTooWeak, strings.Join(missing, ", "))
+	}
+
+	if p.Blocklist.Contains(plaintext) {
+		return ErrPasswordTooCommon
+	}
+	return nil
+}
+
+// PasswordBlocklist 禁止使用的密码集合（常见弱密码、泄露密码），不区分大小写
+type PasswordBlocklist struct {
+	words map[string]struct{}
+}
+
+// NewPasswordBlocklist 创建禁用密码集合
+func NewPasswordBlocklist(words ...string) *PasswordBlocklist {
+	b := &PasswordBlocklist{words: make(map[string]struct{}, len(words))}
+	b.Add(words...)
+	return b
+}
+
+// Add 追加禁用密码，空行忽略
+func (b *PasswordBlocklist) Add(words ...string) {
+	for _, w := range words {
+		if w = strings.TrimSpace(w); w != "" {
+			b.words[strings.ToLower(w)] = struct{}{}
+		}
+	}
+}
+
+// Contains 检查密码是否在集合中
+func (b *PasswordBlocklist) Contains(plaintext string) bool {
+	if b == nil {
+		return false
+	}
+	_, ok := b.words[strings.ToLower(plaintext)]
+	return ok
+}
+
+// Len 返回集合大小
+func (b *PasswordBlocklist) Len() int {
+	if b == nil {
+		return 0
+	}
+	return len(b.words)
+}
+
+// CommonPasswords 内置的常见弱密码，只包含满足默认长度和字符类别要求、但仍极易被猜中的密码
+// 更完整的泄露密码列表通过配置文件加载
+func CommonPasswords() []string {
+	return []string{
+		"Password1", "Password12", "Password123", "Password1234", "Passw0rd", "P@ssw0rd", "P@ssword1",
+		"Qwerty123", "Qwerty1234", "Qwertyuiop1", "Abcd1234", "Abc12345", "Abcdef123", "Aa123456",
+		"Admin123", "Admin1234", "Administrator1", "Welcome1", "Welcome123", "Letmein1", "Iloveyou1",
+		"Changeme1", "Changeme123", "Football1", "Baseball1", "Monkey123", "Dragon123", "Sunshine1",
+		"Princess1", "Master123", "Superman1", "Trustno1", "Zaq12wsx", "1qaz2WSX", "Test1234", "Test12345",
+	}
+}
@@ -0,0 +1,54 @@
+package valueobject
+
+// Permission 权限值对象
+// 权限采用 "资源:动作" 的形式描述，例如 user:update
+type Permission string
+
+const (
+	PermissionUserRead           Permission = "user:read"
+	PermissionUserList           Permission = "user:list"
+	PermissionUserUpdate         Permission = "user:update"
+	PermissionUserChangePassword Permission = "user:change_password"
+	PermissionUserDelete         Permission = "user:delete"
+	PermissionUserManageStatus   Permission = "user:manage_status"
+	PermissionUserManageRole     Permission = "user:manage_role"
+)
+
+// String 实现 Stringer 接口
+func (p Permission) String() string {
+	return string(p)
+}
+
+// Scope 权限作用范围
+type Scope int
+
+const (
+	// ScopeOwn 仅限自己拥有的资源
+	ScopeOwn Scope = iota
+	// ScopeAny 任意资源
+	ScopeAny
+)
+
+// Subject 发起操作的主体
+type Subject struct {
+	UserID uint64
+	Role   string
+}
+
+// Resource 被操作的资源
+// OwnerID 为 0 表示集合类资源（例如用户列表），不存在归属者
+type Resource struct {
+	Type    string
+	ID      uint64
+	OwnerID uint64
+}
+
+// NewUserResource 创建用户资源，用户资源的归属者就是用户本人
+func NewUserResource(userID uint64) Resource {
+	return Resource{Type: "user", ID: userID, OwnerID: userID}
+}
+
+// IsOwnedBy 判断资源是否归属于指定用户
+func (r Resource) IsOwnedBy(userID uint64) bool {
+	return r.OwnerID != 0 && r.OwnerID == userID
+}
@@ -0,0 +1,140 @@
+package valueobject
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	totpDigits = 6
+	totpPeriod = 30 * time.Second
+	totpSkew   = 1 // 允许前后各一个时间步的时钟偏差
+)
+
+var ErrTOTPSecretInvalid = errors.New("invalid totp secret")
+
+// SecretCipher 加密存储敏感数据（如 TOTP 密钥），由基础设施层实现
+type SecretCipher interface {
+	Encrypt(plaintext []byte) (string, error)
+	Decrypt(ciphertext string) ([]byte, error)
+}
+
+// TOTPSecret TOTP 密钥值对象（RFC 6238，HMAC-SHA1，6 位，30 秒）
+// 只保存加密后的密文，校验验证码时才用 SecretCipher 解密
+type TOTPSecret struct {
+	ciphertext string
+}
+
+// GenerateTOTPSecret 生成随机密钥并加密，同时返回 Base32 明文供用户录入验证器
+func GenerateTOTPSecret(cipher SecretCipher) (TOTPSecret, string, error) {
+	raw := make([]byte, 20)
+	if _, err := rand.Read(raw); err != nil {
+		return TOTPSecret{}, "", err
+	}
+	ciphertext, err := cipher.Encrypt(raw)
+	if err != nil {
+		return TOTPSecret{}, "", err
+	}
+	return TOTPSecret{ciphertext: ciphertext}, base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(raw), nil
+}
+
+// NewTOTPSecretFromCiphertext 从数据库中的密文恢复值对象
+func NewTOTPSecretFromCiphertext(ciphertext string) TOTPSecret {
+	return TOTPSecret{ciphertext: ciphertext}
+}
+
+// Ciphertext 返回密文
+func (s TOTPSecret) Ciphertext() string {
+	return s.ciphertext
+}
+
+// IsZero 是否未设置密钥
+func (s TOTPSecret) IsZero() bool {
+	return s.ciphertext == ""
+}
+
+// Verify 校验验证码，返回验证码所在的时间步
+// 只接受晚于 lastStep 的时间步：调用方保存每次通过校验的时间步，同一验证码（及更早的验证码）不能再次使用
+func (s TOTPSecret) Verify(code string, now time.Time, lastStep int64, cipher SecretCipher) (step int64, ok bool, err error) {
+	if s.IsZero() {
+		return 0, false, ErrTOTPSecretInvalid
+	}
+	code = strings.TrimSpace(code)
+	if len(code) != totpDigits {
+		return 0, false, nil
+	}
+	key, err := cipher.Decrypt(s.ciphertext)
+	if err != nil {
+		return 0, false, err
+	}
+
+	current := now.Unix() / int64(totpPeriod/time.Second)
+	for i := -totpSkew; i <= totpSkew; i++ {
+		step := current + int64(i)
+		if step <= lastStep {
+			continue
+		}
+		if subtle.ConstantTimeCompare([]byte(totpCode(key, step)), []byte(code)) == 1 {
+			return step, true, nil
+		}
+	}
+	return 0, false, nil
+}
+
+// TOTPURI 生成验证器 App 扫码使用的 otpauth:// URI
+func TOTPURI(issuer, account, base32Secret string) string {
+	label := url.PathEscape(issuer + ":" + account)
+	q := url.Values{}
+	q.Set("secret", base32Secret)
+	q.Set("issuer", issuer)
+	q.Set("algorithm", "SHA1")
+	q.Set("digits", fmt.Sprint(totpDigits))
+	q.Set("period", fmt.Sprint(int(totpPeriod/time.Second)))
+	return "otpauth://totp/" + label + "?" + q.Encode()
+}
+
+// totpCode 计算某个时间步的验证码（RFC 4226 动态截断）
+func totpCode(key []byte, step int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+	h := hmac.New(sha1.New, key)
+	h.Write(msg[:])
+	sum := h.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+	return fmt.Sprintf("%0*d", totpDigits, value%1000000)
+}
+
+// GenerateRecoveryCodes 生成 n 个一次性恢复码，返回明文（只展示一次）和待保存的哈希
+func GenerateRecoveryCodes(n int) (codes []string, hashes []string, err error) {
+	for i := 0; i < n; i++ {
+		buf := make([]byte, 5)
+		if _, err := rand.Read(buf); err != nil {
+			return nil, nil, err
+		}
+		raw := strings.ToLower(base32.StdEncoding.EncodeToString(buf)) // 8 个字符
+		code := raw[:4] + "-" + raw[4:]
+		codes = append(codes, code)
+		hashes = append(hashes, HashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
+
+// HashRecoveryCode 计算恢复码的哈希；恢复码是高熵随机值，SHA-256 即可
+func HashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+	sum := sha256.Sum256([]byte(normalized))
+	return hex.EncodeToString(sum[:])
+}
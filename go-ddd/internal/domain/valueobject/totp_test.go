@@ -0,0 +1,81 @@
+package valueobject
+
+import (
+	"testing"
+	"time"
+)
+
+// plainCipher 不加密，密文即明文，便于使用 RFC 6238 的测试密钥
+type plainCipher struct{}
+
+func (plainCipher) Encrypt(plaintext []byte) (string, error)  { return string(plaintext), nil }
+func (plainCipher) Decrypt(ciphertext string) ([]byte, error) { return []byte(ciphertext), nil }
+
+// rfc6238Secret RFC 6238 附录 B 中 HMAC-SHA1 的测试密钥
+const rfc6238Secret = "12345678901234567890"
+
+// RFC 6238 附录 B 的 HMAC-SHA1 测试向量（8 位验证码的后 6 位）
+var rfc6238Vectors = []struct {
+	unix int64
+	code string
+}{
+	{59, "287082"},
+	{1111111109, "081804"},
+	{1111111111, "050471"},
+	{1234567890, "005924"},
+	{2000000000, "279037"},
+	{20000000000, "353130"},
+}
+
+func TestTOTPCodeRFC6238(t *testing.T) {
+	for _, v := range rfc6238Vectors {
+		step := v.unix / int64(totpPeriod/time.Second)
+		if got := totpCode([]byte(rfc6238Secret), step); got != v.code {
+			t.Errorf("T=%d: got %s, want %s", v.unix, got, v.code)
+		}
+	}
+}
+
+func TestTOTPVerify(t *testing.T) {
+	secret := NewTOTPSecretFromCiphertext(rfc6238Secret)
+	now := time.Unix(1111111111, 0) // 时间步 37037037，验证码 050471
+	const current = 1111111111 / 30
+
+	tests := []struct {
+		name     string
+		code     string
+		now      time.Time
+		lastStep int64
+		wantStep int64
+		wantOK   bool
+	}{
+		{"current step", "050471", now, 0, current, true},
+		{"previous step within skew", "081804", now, 0, current - 1, true},
+		{"code from next step within skew", "050471", now.Add(-30 * time.Second), 0, current, true},
+		{"outside skew", "050471", now.Add(2 * totpPeriod), 0, 0, false},
+		{"wrong code", "123456", now, 0, 0, false},
+		{"wrong length", "05047", now, 0, 0, false},
+		{"surrounding spaces", " 050471 ", now, 0, current, true},
+		{"replay of accepted step", "050471", now, current, 0, false},
+		{"earlier step after a later one was accepted", "081804", now, current, 0, false},
+		{"later step than the accepted one", "050471", now, current - 1, current, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			step, ok, err := secret.Verify(tt.code, tt.now, tt.lastStep, plainCipher{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if ok != tt.wantOK || step != tt.wantStep {
+				t.Fatalf("got (%d, %v), want (%d, %v)", step, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestTOTPVerifyWithoutSecret(t *testing.T) {
+	if _, _, err := (TOTPSecret{}).Verify("050471", time.Now(), 0, plainCipher{}); err != ErrTOTPSecretInvalid {
+		t.Fatalf("expected ErrTOTPSecretInvalid, got %v", err)
+	}
+}
@@ -0,0 +1,72 @@
+package auth
+
+import (
+	"encoding/base64"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"yiwen/go-ddd/internal/application/port"
+)
+
+func TestHMACActionTokenSigner(t *testing.T) {
+	signer := NewHMACActionTokenSigner("action-token-test-secret-0123456789")
+	sign := func(purpose, state string, ttl time.Duration) string {
+		token, err := signer.Sign(purpose, "uuid-alice", state, ttl)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	valid := sign(port.PurposeResetPassword, "hash-1", time.Hour)
+	payload, signature, _ := strings.Cut(valid, ".")
+
+	// 把载荷中的 subject 换成其他用户，签名保持不变
+	raw, err := base64.RawURLEncoding.DecodeString(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged := base64.RawURLEncoding.EncodeToString([]byte(strings.Replace(string(raw), "uuid-alice", "uuid-mallory", 1)))
+
+	tests := []struct {
+		name    string
+		signer  *HMACActionTokenSigner
+		token   string
+		purpose string
+		state   string
+		wantErr bool
+	}{
+		{"valid", signer, valid, port.PurposeResetPassword, "hash-1", false},
+		{"expired", signer, sign(port.PurposeResetPassword, "hash-1", -2*time.Second), port.PurposeResetPassword, "hash-1", true},
+		{"wrong purpose", signer, valid, port.PurposeVerifyEmail, "hash-1", true},
+		{"state changed since signing", signer, valid, port.PurposeResetPassword, "hash-2", true},
+		{"tampered payload", signer, forged + "." + signature, port.PurposeResetPassword, "hash-1", true},
+		{"tampered signature", signer, payload + "." + base64.RawURLEncoding.EncodeToString([]byte("forged")), port.PurposeResetPassword, "hash-1", true},
+		{"signed with another secret", NewHMACActionTokenSigner("another-secret-0123456789abcdefgh"), valid, port.PurposeResetPassword, "hash-1", true},
+		{"missing signature", signer, payload, port.PurposeResetPassword, "hash-1", true},
+		{"not base64", signer, "!!!.???", port.PurposeResetPassword, "hash-1", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.signer.Verify(tt.token, tt.purpose, tt.state)
+			if !tt.wantErr {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			if !errors.Is(err, port.ErrInvalidActionToken) {
+				t.Fatalf("expected ErrInvalidActionToken, got %v", err)
+			}
+		})
+	}
+
+	// Subject 不校验签名，只用于查找校验所需的状态
+	if subject, err := signer.Subject(forged + "." + signature); err != nil || subject != "uuid-mallory" {
+		t.Fatalf("subject = %q, %v", subject, err)
+	}
+	if _, err := signer.Subject("malformed"); !errors.Is(err, port.ErrInvalidActionToken) {
+		t.Fatalf("expected ErrInvalidActionToken, got %v", err)
+	}
+}
@@ -0,0 +1,34 @@
+package auth
+
+import (
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// HMACKeyProvider 共享密钥（HS256）签名，未配置密钥目录时使用
+// 共享密钥不能公开，JWKS 为空；仅建议在开发环境或迁移期间使用
+type HMACKeyProvider struct {
+	secret []byte
+}
+
+// NewHMACKeyProvider 创建共享密钥签名
+func NewHMACKeyProvider(secret string) *HMACKeyProvider {
+	return &HMACKeyProvider{secret: []byte(secret)}
+}
+
+// SigningKey 返回签名密钥，kid 为空
+func (p *HMACKeyProvider) SigningKey() (string, jwt.SigningMethod, interface{}, error) {
+	return "", jwt.SigningMethodHS256, p.secret, nil
+}
+
+// VerificationKey 返回验证密钥，只接受没有 kid 的令牌
+func (p *HMACKeyProvider) VerificationKey(kid string) (jwt.SigningMethod, interface{}, error) {
+	if kid != "" {
+		return nil, nil, ErrUnknownKey
+	}
+	return jwt.SigningMethodHS256, p.secret, nil
+}
+
+// JWKS 共享密钥不发布
+func (p *HMACKeyProvider) JWKS() JSONWebKeySet {
+	return JSONWebKeySet{Keys: []JSONWebKey{}}
+}
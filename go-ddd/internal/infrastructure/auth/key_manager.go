@@ -0,0 +1,322 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	ErrNoSigningKey = errors.New("no signing key available")
+	ErrUnknownKey   = errors.New("unknown key id")
+)
+
+// 支持的签名算法
+const (
+	AlgorithmRS256 = "RS256"
+	AlgorithmEdDSA = "EdDSA"
+	AlgorithmHS256 = "HS256"
+)
+
+// rsaKeyBits 自动生成 RSA 密钥的长度
+const rsaKeyBits = 2048
+
+// KeyManagerConfig 密钥管理配置
+type KeyManagerConfig struct {
+	Dir              string        // 密钥目录，文件名（去掉 .pem / .pub.pem）即 kid
+	Algorithm        string        // 自动生成密钥使用的算法：RS256 或 EdDSA
+	RotationInterval time.Duration // 签名密钥使用超过该时长后自动生成新密钥，0 表示不自动轮换
+	VerifyFor        time.Duration // 密钥被新密钥替换后继续用于验证的时长，应不短于访问令牌有效期；0 表示一直有效
+	ReloadInterval   time.Duration // 重新扫描目录的间隔，默认 1 分钟
+}
+
+// managedKey 目录中的一个密钥
+type managedKey struct {
+	kid       string
+	method    jwt.SigningMethod
+	private   crypto.Signer // 仅公钥文件时为 nil
+	public    crypto.PublicKey
+	createdAt time.Time // 文件修改时间，决定签名密钥的先后
+}
+
+// KeyManager 非对称签名密钥管理
+// 目录中最新的私钥用于签名，较早的密钥在 VerifyFor 期间继续用于验证，
+// 以便轮换前签发的令牌平滑过期；*.pub.pem 公钥文件只用于验证
+type KeyManager struct {
+	config KeyManagerConfig
+
+	mu        sync.RWMutex
+	signing   *managedKey
+	verifiers map[string]*managedKey
+}
+
+// NewKeyManager 创建密钥管理器并加载目录中的密钥
+// 目录中没有私钥时，如果配置了 Algorithm 会自动生成一个
+func NewKeyManager(config KeyManagerConfig) (*KeyManager, error) {
+	if config.Dir == "" {
+		return nil, errors.New("key directory is required")
+	}
+	if config.ReloadInterval <= 0 {
+		config.ReloadInterval = time.Minute
+	}
+	if err := os.MkdirAll(config.Dir, 0o700); err != nil {
+		return nil, fmt.Errorf("create key directory: %w", err)
+	}
+
+	m := &KeyManager{config: config}
+	if err := m.Reload(); err != nil {
+		return nil, err
+	}
+	if err := m.rotateIfDue(); err != nil {
+		return nil, err
+	}
+	if _, _, _, err := m.SigningKey(); err != nil {
+		return nil, fmt.Errorf("%w in %s", err, config.Dir)
+	}
+	return m, nil
+}
+
+// Run 定期重新加载目录并检查是否需要轮换，直到 ctx 取消
+func (m *KeyManager) Run(ctx context.Context) {
+	ticker := time.NewTicker(m.config.ReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := m.Reload(); err != nil {
+				log.Printf("[keys] reload failed: %v", err)
+				continue
+			}
+			if err := m.rotateIfDue(); err != nil {
+				log.Printf("[keys] rotation failed: %v", err)
+			}
+		}
+	}
+}
+
+// Reload 重新扫描密钥目录
+func (m *KeyManager) Reload() error {
+	entries, err := os.ReadDir(m.config.Dir)
+	if err != nil {
+		return fmt.Errorf("read key directory: %w", err)
+	}
+
+	var keys []*managedKey
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".pem") {
+			continue
+		}
+		key, err := loadKeyFile(filepath.Join(m.config.Dir, entry.Name()))
+		if err != nil {
+			return err
+		}
+		keys = append(keys, key)
+	}
+
+	// 按创建时间从新到旧排序，最新的私钥用于签名
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].createdAt.After(keys[j].createdAt)
+	})
+
+	now := time.Now()
+	var signing *managedKey
+	verifiers := make(map[string]*managedKey, len(keys))
+	var replacedAt time.Time // 比当前密钥更新的那个私钥的创建时间
+	for _, key := range keys {
+		if _, dup := verifiers[key.kid]; dup {
+			return fmt.Errorf("duplicate key id %q in %s", key.kid, m.config.Dir)
+		}
+		if key.private == nil {
+			verifiers[key.kid] = key
+			continue
+		}
+		if signing == nil {
+			signing = key
+		} else if m.config.VerifyFor > 0 && now.Sub(replacedAt) > m.config.VerifyFor {
+			// 被替换已久，用它签发的令牌都已过期
+			replacedAt = key.createdAt
+			continue
+		}
+		verifiers[key.kid] = key
+		replacedAt = key.createdAt
+	}
+
+	m.mu.Lock()
+	m.signing = signing
+	m.verifiers = verifiers
+	m.mu.Unlock()
+	return nil
+}
+
+// Rotate 立即生成新的签名密钥
+func (m *KeyManager) Rotate() error {
+	if err := m.generateKey(); err != nil {
+		return err
+	}
+	return m.Reload()
+}
+
+// SigningKey 返回当前签名密钥
+func (m *KeyManager) SigningKey() (string, jwt.SigningMethod, interface{}, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if m.signing == nil {
+		return "", nil, nil, ErrNoSigningKey
+	}
+	return m.signing.kid, m.signing.method, m.signing.private, nil
+}
+
+// VerificationKey 根据 kid 返回验证用的公钥及其算法
+func (m *KeyManager) VerificationKey(kid string) (jwt.SigningMethod, interface{}, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	key, ok := m.verifiers[kid]
+	if !ok {
+		return nil, nil, ErrUnknownKey
+	}
+	return key.method, key.public, nil
+}
+
+// JWKS 返回全部验证公钥，供 /.well-known/jwks.json 发布
+func (m *KeyManager) JWKS() JSONWebKeySet {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	set := JSONWebKeySet{Keys: make([]JSONWebKey, 0, len(m.verifiers))}
+	for _, key := range m.verifiers {
+		set.Keys = append(set.Keys, toJWK(key.kid, key.method.Alg(), key.public))
+	}
+	sort.Slice(set.Keys, func(i, j int) bool { return set.Keys[i].Kid < set.Keys[j].Kid })
+	return set
+}
+
+// rotateIfDue 签名密钥不存在或超过轮换间隔时生成新密钥
+func (m *KeyManager) rotateIfDue() error {
+	if m.config.Algorithm == "" {
+		return nil
+	}
+
+	m.mu.RLock()
+	signing := m.signing
+	m.mu.RUnlock()
+
+	due := signing == nil ||
+		(m.config.RotationInterval > 0 && time.Since(signing.createdAt) >= m.config.RotationInterval)
+	if !due {
+		return nil
+	}
+
+	log.Printf("[keys] generating new %s signing key in %s", m.config.Algorithm, m.config.Dir)
+	return m.Rotate()
+}
+
+// generateKey 生成新私钥，以生成时间作为 kid 写入目录
+func (m *KeyManager) generateKey() error {
+	var private crypto.Signer
+	switch m.config.Algorithm {
+	case AlgorithmRS256:
+		key, err := rsa.GenerateKey(rand.Reader, rsaKeyBits)
+		if err != nil {
+			return err
+		}
+		private = key
+	case AlgorithmEdDSA:
+		_, key, err := ed25519.GenerateKey(rand.Reader)
+		if err != nil {
+			return err
+		}
+		private = key
+	default:
+		return fmt.Errorf("unsupported key algorithm: %s", m.config.Algorithm)
+	}
+
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return err
+	}
+
+	kid := time.Now().UTC().Format("20060102T150405Z")
+	path := filepath.Join(m.config.Dir, kid+".pem")
+	data := pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	// O_EXCL：同一秒内重复轮换时报错，而不是覆盖正在使用的密钥
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0o600)
+	if err != nil {
+		return err
+	}
+	if _, err := f.Write(data); err != nil {
+		f.Close()
+		return err
+	}
+	return f.Close()
+}
+
+// loadKeyFile 解析 PEM 文件：PKCS#8 / PKCS#1 私钥，或 PKIX 公钥（*.pub.pem）
+func loadKeyFile(path string) (*managedKey, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("%s: no PEM block found", path)
+	}
+
+	name := filepath.Base(path)
+	key := &managedKey{
+		kid:       strings.TrimSuffix(strings.TrimSuffix(name, ".pem"), ".pub"),
+		createdAt: info.ModTime(),
+	}
+
+	var parsed interface{}
+	switch block.Type {
+	case "PRIVATE KEY":
+		parsed, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	case "RSA PRIVATE KEY":
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "PUBLIC KEY":
+		parsed, err = x509.ParsePKIXPublicKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("%s: unsupported PEM block %q", path, block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	switch k := parsed.(type) {
+	case *rsa.PrivateKey:
+		key.method, key.private, key.public = jwt.SigningMethodRS256, k, &k.PublicKey
+	case ed25519.PrivateKey:
+		key.method, key.private, key.public = jwt.SigningMethodEdDSA, k, k.Public()
+	case *rsa.PublicKey:
+		key.method, key.public = jwt.SigningMethodRS256, k
+	case ed25519.PublicKey:
+		key.method, key.public = jwt.SigningMethodEdDSA, k
+	default:
+		return nil, fmt.Errorf("%s: unsupported key type %T", path, parsed)
+	}
+	return key, nil
+}
@@ -0,0 +1,67 @@
+package auth
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+)
+
+var errCiphertextTooShort = errors.New("ciphertext too short")
+
+// AESGCMCipher 使用 AES-256-GCM 加密敏感字段，实现 valueobject.SecretCipher
+// 密文格式：base64(随机 nonce + 密文 + 认证标签)
+type AESGCMCipher struct {
+	aead cipher.AEAD
+}
+
+// NewAESGCMCipher 使用 32 字节密钥创建加密器
+func NewAESGCMCipher(key []byte) (*AESGCMCipher, error) {
+	if len(key) != 32 {
+		return nil, fmt.Errorf("encryption key must be 32 bytes, got %d", len(key))
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, err
+	}
+	return &AESGCMCipher{aead: aead}, nil
+}
+
+// NewAESGCMCipherFromConfig 根据配置创建加密器
+// encodedKey 为 base64 编码的 32 字节密钥
+func NewAESGCMCipherFromConfig(encodedKey string) (*AESGCMCipher, error) {
+	key, err := base64.StdEncoding.DecodeString(encodedKey)
+	if err != nil {
+		return nil, fmt.Errorf("decode encryption key: %w", err)
+	}
+	return NewAESGCMCipher(key)
+}
+
+// Encrypt 加密，每次使用新的随机 nonce
+func (c *AESGCMCipher) Encrypt(plaintext []byte) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", err
+	}
+	sealed := c.aead.Seal(nonce, nonce, plaintext, nil)
+	return base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt 解密并校验认证标签
+func (c *AESGCMCipher) Decrypt(ciphertext string) ([]byte, error) {
+	data, err := base64.StdEncoding.DecodeString(ciphertext)
+	if err != nil {
+		return nil, err
+	}
+	if len(data) < c.aead.NonceSize() {
+		return nil, errCiphertextTooShort
+	}
+	nonce, sealed := data[:c.aead.NonceSize()], data[c.aead.NonceSize():]
+	return c.aead.Open(nil, nonce, sealed, nil)
+}
@@ -1,24 +1,45 @@
 package config
 
 import (
+	"encoding/base64"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/spf13/viper"
 )
 
 // Config 应用配置
 type Config struct {
-	App      AppConfig      `mapstructure:"app"`
-	Database DatabaseConfig `mapstructure:"database"`
-	JWT      JWTConfig      `mapstructure:"jwt"`
+	App           AppConfig           `mapstructure:"app"`
+	GRPC          GRPCConfig          `mapstructure:"grpc"`
+	Database      DatabaseConfig      `mapstructure:"database"`
+	JWT           JWTConfig           `mapstructure:"jwt"`
+	Outbox        OutboxConfig        `mapstructure:"outbox"`
+	EventSourcing EventSourcingConfig `mapstructure:"event_sourcing"`
+	ReadModel     ReadModelConfig     `mapstructure:"read_model"`
+	Mail          MailConfig          `mapstructure:"mail"`
+	Account       AccountConfig       `mapstructure:"account"`
+	Login         LoginConfig         `mapstructure:"login"`
+	MFA           MFAConfig           `mapstructure:"mfa"`
+	Password      PasswordConfig      `mapstructure:"password"`
+	BlobStorage   BlobStorageConfig   `mapstructure:"blob_storage"`
+	Avatar        AvatarConfig        `mapstructure:"avatar"`
+	Erasure       ErasureConfig       `mapstructure:"erasure"`
 }
 
 // AppConfig 应用配置
 type AppConfig struct {
-	Name string `mapstructure:"name"`
-	Port int    `mapstructure:"port"`
-	Mode string `mapstructure:"mode"` // debug, release, test
+	Name           string   `mapstructure:"name"`
+	Port           int      `mapstructure:"port"`
+	Mode           string   `mapstructure:"mode"`            // debug, release, test
+	TrustedProxies []string `mapstructure:"trusted_proxies"` // 可信反向代理的 IP 或 CIDR，为空时不信任 X-Forwarded-For
+}
+
+// GRPCConfig gRPC 服务配置，与 HTTP 服务同时运行
+type GRPCConfig struct {
+	Port       int  `mapstructure:"port"`       // 0 表示不启动 gRPC 服务
+	Reflection bool `mapstructure:"reflection"` // 注册服务反射，便于 grpcurl 调试
 }
 
 // DatabaseConfig 数据库配置
@@ -30,6 +51,9 @@ type DatabaseConfig struct {
 	Database     string `mapstructure:"database"`
 	MaxIdleConns int    `mapstructure:"max_idle_conns"`
 	MaxOpenConns int    `mapstructure:"max_open_conns"`
+	TxMaxRetries int    `mapstructure:"tx_max_retries"` // 事务遇到死锁、锁等待超时后的最大重试次数
+	SQLitePath   string `mapstructure:"sqlite_path"`    // --storage=sqlite 时使用的数据库文件
+	AutoMigrate  bool   `mapstructure:"auto_migrate"`   // 启动时执行未执行的 MySQL 迁移，关闭时需预先运行 migrate up
 }
 
 // DSN 返回数据库连接字符串
@@ -40,9 +64,153 @@ func (c *DatabaseConfig) DSN() string {
 
 // JWTConfig JWT配置
 type JWTConfig struct {
-	Secret     string `mapstructure:"secret"`
-	ExpireHour int    `mapstructure:"expire_hour"`
-	Issuer     string `mapstructure:"issuer"`
+	Secret          string        `mapstructure:"secret"`
+	ExpireHour      int           `mapstructure:"expire_hour"` // 已废弃，未设置 access_token_ttl 时作为访问令牌有效期
+	Issuer          string        `mapstructure:"issuer"`
+	AccessTokenTTL  time.Duration `mapstructure:"access_token_ttl"`
+	RefreshTokenTTL time.Duration `mapstructure:"refresh_token_ttl"`
+
+	// 非对称签名：配置 KeyDir 后使用目录中的 RS256/EdDSA 密钥，否则使用 Secret（HS256）
+	KeyDir              string        `mapstructure:"key_dir"`
+	SigningAlgorithm    string        `mapstructure:"signing_algorithm"`     // 自动生成密钥的算法：RS256 或 EdDSA
+	AllowedAlgorithms   []string      `mapstructure:"allowed_algorithms"`    // 解析时接受的算法白名单
+	KeyRotationInterval time.Duration `mapstructure:"key_rotation_interval"` // 0 表示不自动轮换
+	KeyReloadInterval   time.Duration `mapstructure:"key_reload_interval"`
+}
+
+// OutboxConfig 发件箱中继配置
+type OutboxConfig struct {
+	Enabled      bool          `mapstructure:"enabled"`
+	PollInterval time.Duration `mapstructure:"poll_interval"` // 如 1s、500ms
+	BatchSize    int           `mapstructure:"batch_size"`
+	MaxAttempts  int           `mapstructure:"max_attempts"` // 0 表示无限重试
+}
+
+// EventSourcingConfig 事件溯源配置
+type EventSourcingConfig struct {
+	Enabled       bool `mapstructure:"enabled"`        // 启用后用户聚合以事件存储为事实来源，users 表作为读模型
+	SnapshotEvery int  `mapstructure:"snapshot_every"` // 每多少个事件保存一次快照，0 表示不保存
+}
+
+// ReadModelConfig 查询端读模型配置
+type ReadModelConfig struct {
+	SkipRebuild bool `mapstructure:"skip_rebuild"` // 启动时不从写模型全量重建读模型（用户量很大、确认读模型未落后时使用）
+}
+
+// MailConfig 邮件配置
+type MailConfig struct {
+	Driver  string     `mapstructure:"driver"` // smtp、file 或 log
+	From    string     `mapstructure:"from"`
+	SMTP    SMTPConfig `mapstructure:"smtp"`
+	FileDir string     `mapstructure:"file_dir"` // driver 为 file 时邮件写入的目录
+}
+
+// SMTPConfig SMTP 服务器配置
+type SMTPConfig struct {
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+}
+
+// AccountConfig 邮箱验证和密码重置配置
+type AccountConfig struct {
+	TokenSecret string        `mapstructure:"token_secret"` // 验证、重置令牌的签名密钥，必填，不能与 jwt.secret 相同
+	VerifyURL   string        `mapstructure:"verify_url"`   // 邮件中的验证链接，{token} 会被替换为令牌
+	ResetURL    string        `mapstructure:"reset_url"`    // 邮件中的重置链接，{token} 会被替换为令牌
+	VerifyTTL   time.Duration `mapstructure:"verify_ttl"`
+	ResetTTL    time.Duration `mapstructure:"reset_ttl"`
+}
+
+// LoginConfig 登录防暴力破解配置
+type LoginConfig struct {
+	User LoginThrottleConfig `mapstructure:"user"` // 按用户名统计
+	IP   LoginThrottleConfig `mapstructure:"ip"`   // 按客户端IP统计
+}
+
+// LoginThrottleConfig 登录失败退避和锁定配置
+type LoginThrottleConfig struct {
+	FreeAttempts  int           `mapstructure:"free_attempts"`  // 不受限制的失败次数
+	BaseDelay     time.Duration `mapstructure:"base_delay"`     // 之后每次失败等待时间从此值开始翻倍
+	MaxDelay      time.Duration `mapstructure:"max_delay"`      // 等待时间上限
+	LockThreshold int           `mapstructure:"lock_threshold"` // 达到该失败次数后锁定，负数表示不锁定
+	LockDuration  time.Duration `mapstructure:"lock_duration"`
+	Window        time.Duration `mapstructure:"window"` // 距上次失败超过该时长后重新计数
+}
+
+// MFAConfig 两步验证配置
+type MFAConfig struct {
+	Issuer           string        `mapstructure:"issuer"`             // 验证器 App 中显示的发行方，默认使用 app.name
+	EncryptionKey    string        `mapstructure:"encryption_key"`     // base64 编码的 32 字节 AES 密钥，必填
+	ChallengeTTL     time.Duration `mapstructure:"challenge_ttl"`      // 登录第二步令牌的有效期
+	RequireForAdmins bool          `mapstructure:"require_for_admins"` // 管理员必须启用两步验证
+	RecoveryCodes    int           `mapstructure:"recovery_codes"`     // 每次绑定生成的恢复码数量
+}
+
+// PasswordConfig 密码策略配置，未配置 password 段时使用默认策略
+type PasswordConfig struct {
+	MinLength     int                `mapstructure:"min_length"`
+	MaxLength     int                `mapstructure:"max_length"` // bcrypt 最多 72 字节
+	RequireUpper  bool               `mapstructure:"require_upper"`
+	RequireLower  bool               `mapstructure:"require_lower"`
+	RequireDigit  bool               `mapstructure:"require_digit"`
+	RequireSymbol bool               `mapstructure:"require_symbol"`
+	CheckCommon   bool               `mapstructure:"check_common"`   // 拒绝内置的常见弱密码
+	BlocklistFile string             `mapstructure:"blocklist_file"` // 泄露、常见密码列表文件，每行一个
+	HistorySize   int                `mapstructure:"history_size"`   // 不能与最近多少个旧密码相同
+	Hash          PasswordHashConfig `mapstructure:"hash"`
+}
+
+// PasswordHashConfig 密码哈希配置
+type PasswordHashConfig struct {
+	Algorithm  string       `mapstructure:"algorithm"` // bcrypt 或 argon2id
+	BcryptCost int          `mapstructure:"bcrypt_cost"`
+	Argon2     Argon2Config `mapstructure:"argon2"`
+}
+
+// Argon2Config argon2id 参数
+type Argon2Config struct {
+	Memory      uint32 `mapstructure:"memory"` // KiB
+	Iterations  uint32 `mapstructure:"iterations"`
+	Parallelism uint8  `mapstructure:"parallelism"`
+	SaltLength  uint32 `mapstructure:"salt_length"`
+	KeyLength   uint32 `mapstructure:"key_length"`
+}
+
+// BlobStorageConfig 文件存储配置（头像等上传文件）
+type BlobStorageConfig struct {
+	Driver string             `mapstructure:"driver"` // local 或 s3
+	Local  LocalStorageConfig `mapstructure:"local"`
+	S3     S3StorageConfig    `mapstructure:"s3"`
+}
+
+// LocalStorageConfig 本地文件存储配置
+type LocalStorageConfig struct {
+	Dir     string `mapstructure:"dir"`
+	BaseURL string `mapstructure:"base_url"` // 文件对外的访问地址，路径部分由本服务以静态文件提供
+}
+
+// S3StorageConfig S3 兼容对象存储配置
+type S3StorageConfig struct {
+	Endpoint        string `mapstructure:"endpoint"`
+	Region          string `mapstructure:"region"`
+	Bucket          string `mapstructure:"bucket"`
+	AccessKeyID     string `mapstructure:"access_key_id"`
+	SecretAccessKey string `mapstructure:"secret_access_key"`
+	PublicURL       string `mapstructure:"public_url"` // 为空时使用 endpoint/bucket
+}
+
+// AvatarConfig 头像上传配置
+type AvatarConfig struct {
+	MaxBytes  int64 `mapstructure:"max_bytes"`  // 上传文件的最大字节数
+	Sizes     []int `mapstructure:"sizes"`      // 生成的缩略图边长
+	MaxPixels int   `mapstructure:"max_pixels"` // 允许解码的最大像素数
+}
+
+// ErasureConfig 删除账户配置
+type ErasureConfig struct {
+	GracePeriod   time.Duration `mapstructure:"grace_period"`   // 申请删除到擦除个人数据之间的宽限期，期间可以撤销
+	CheckInterval time.Duration `mapstructure:"check_interval"` // 检查到期申请的间隔
 }
 
 // Load 加载配置
@@ -76,9 +244,178 @@ func Load(configPath string) (*Config, error) {
 	if config.Database.MaxOpenConns == 0 {
 		config.Database.MaxOpenConns = 100
 	}
-	if config.JWT.ExpireHour == 0 {
-		config.JWT.ExpireHour = 24
+	if config.Database.SQLitePath == "" {
+		config.Database.SQLitePath = "go_ddd.db"
+	}
+	if config.Database.TxMaxRetries == 0 {
+		config.Database.TxMaxRetries = 3
+	}
+	if config.JWT.AccessTokenTTL == 0 {
+		if config.JWT.ExpireHour > 0 {
+			config.JWT.AccessTokenTTL = time.Duration(config.JWT.ExpireHour) * time.Hour
+		} else {
+			config.JWT.AccessTokenTTL = 15 * time.Minute
+		}
+	}
+	if config.JWT.RefreshTokenTTL == 0 {
+		config.JWT.RefreshTokenTTL = 7 * 24 * time.Hour
+	}
+	if config.JWT.KeyDir != "" && config.JWT.SigningAlgorithm == "" {
+		config.JWT.SigningAlgorithm = "RS256"
+	}
+	if len(config.JWT.AllowedAlgorithms) == 0 {
+		if config.JWT.KeyDir != "" {
+			config.JWT.AllowedAlgorithms = []string{config.JWT.SigningAlgorithm}
+		} else {
+			config.JWT.AllowedAlgorithms = []string{"HS256"}
+		}
+	}
+	if config.Outbox.PollInterval == 0 {
+		config.Outbox.PollInterval = time.Second
+	}
+	if config.Outbox.BatchSize == 0 {
+		config.Outbox.BatchSize = 100
+	}
+	if config.Mail.Driver == "" {
+		config.Mail.Driver = "log"
+	}
+	if config.Mail.From == "" {
+		config.Mail.From = "no-reply@example.com"
+	}
+	if config.Mail.SMTP.Port == 0 {
+		config.Mail.SMTP.Port = 587
+	}
+	if config.Mail.FileDir == "" {
+		config.Mail.FileDir = "tmp/mail"
+	}
+	if config.Account.VerifyURL == "" {
+		config.Account.VerifyURL = fmt.Sprintf("http://localhost:%d/api/v1/users/verify-email?token={token}", config.App.Port)
+	}
+	if config.Account.ResetURL == "" {
+		config.Account.ResetURL = fmt.Sprintf("http://localhost:%d/reset-password?token={token}", config.App.Port)
+	}
+	if config.Account.VerifyTTL == 0 {
+		config.Account.VerifyTTL = 24 * time.Hour
+	}
+	if config.Account.ResetTTL == 0 {
+		config.Account.ResetTTL = 30 * time.Minute
+	}
+	setLoginThrottleDefaults(&config.Login.User, 3, 10)
+	setLoginThrottleDefaults(&config.Login.IP, 20, 0)
+	if config.MFA.Issuer == "" {
+		config.MFA.Issuer = config.App.Name
+	}
+	if config.MFA.ChallengeTTL == 0 {
+		config.MFA.ChallengeTTL = 5 * time.Minute
+	}
+	if config.MFA.RecoveryCodes == 0 {
+		config.MFA.RecoveryCodes = 10
+	}
+	setPasswordDefaults(&config.Password)
+	if config.BlobStorage.Driver == "" {
+		config.BlobStorage.Driver = "local"
+	}
+	if config.BlobStorage.Local.Dir == "" {
+		config.BlobStorage.Local.Dir = "tmp/uploads"
+	}
+	if config.BlobStorage.Local.BaseURL == "" {
+		config.BlobStorage.Local.BaseURL = fmt.Sprintf("http://localhost:%d/uploads", config.App.Port)
+	}
+	if config.Avatar.MaxBytes == 0 {
+		config.Avatar.MaxBytes = 5 << 20
+	}
+	if len(config.Avatar.Sizes) == 0 {
+		config.Avatar.Sizes = []int{256, 128, 64}
+	}
+	if config.Avatar.MaxPixels == 0 {
+		config.Avatar.MaxPixels = 25_000_000
+	}
+	if config.Erasure.GracePeriod == 0 {
+		config.Erasure.GracePeriod = 30 * 24 * time.Hour
+	}
+	if config.Erasure.CheckInterval == 0 {
+		config.Erasure.CheckInterval = time.Hour
 	}
 
+	if err := validateSecrets(&config); err != nil {
+		return nil, err
+	}
 	return &config, nil
 }
+
+// MinSecretLength 签名密钥的最小长度（字节）
+const MinSecretLength = 32
+
+// validateSecrets 检查必须单独配置的密钥，缺失时拒绝启动
+// 这些密钥不能回退到 jwt.secret：使用 key_dir 签名 JWT 时 jwt.secret 可以为空，
+// 回退后令牌会用空密钥签名、TOTP 密钥由公开可知的值加密，任何人都可以伪造或解密
+func validateSecrets(c *Config) error {
+	if len(c.Account.TokenSecret) < MinSecretLength {
+		return fmt.Errorf("account.token_secret must be set to at least %d bytes", MinSecretLength)
+	}
+	if c.Account.TokenSecret == c.JWT.Secret {
+		return fmt.Errorf("account.token_secret must differ from jwt.secret")
+	}
+	if key, err := base64.StdEncoding.DecodeString(c.MFA.EncryptionKey); err != nil || len(key) != 32 {
+		return fmt.Errorf("mfa.encryption_key must be set to a base64 encoded 32-byte key")
+	}
+	return nil
+}
+
+// setPasswordDefaults 设置密码策略的默认值
+// 布尔开关无法区分"未配置"和"关闭"，因此只有整段未配置（min_length 为 0）时才套用默认策略
+func setPasswordDefaults(c *PasswordConfig) {
+	if c.MinLength == 0 {
+		c.MinLength = 8
+		c.RequireUpper = true
+		c.RequireLower = true
+		c.RequireDigit = true
+		c.CheckCommon = true
+	}
+	if c.MaxLength == 0 {
+		c.MaxLength = 72
+	}
+	if c.Hash.Algorithm == "" {
+		c.Hash.Algorithm = "bcrypt"
+	}
+	if c.Hash.BcryptCost == 0 {
+		c.Hash.BcryptCost = 10
+	}
+	if c.Hash.Argon2.Memory == 0 {
+		c.Hash.Argon2.Memory = 64 * 1024
+	}
+	if c.Hash.Argon2.Iterations == 0 {
+		c.Hash.Argon2.Iterations = 3
+	}
+	if c.Hash.Argon2.Parallelism == 0 {
+		c.Hash.Argon2.Parallelism = 2
+	}
+	if c.Hash.Argon2.SaltLength == 0 {
+		c.Hash.Argon2.SaltLength = 16
+	}
+	if c.Hash.Argon2.KeyLength == 0 {
+		c.Hash.Argon2.KeyLength = 32
+	}
+}
+
+// setLoginThrottleDefaults 设置登录退避的默认值
+func setLoginThrottleDefaults(c *LoginThrottleConfig, freeAttempts, lockThreshold int) {
+	if c.FreeAttempts == 0 {
+		c.FreeAttempts = freeAttempts
+	}
+	if c.BaseDelay == 0 {
+		c.BaseDelay = time.Second
+	}
+	if c.MaxDelay == 0 {
+		c.MaxDelay = 5 * time.Minute
+	}
+	if c.LockThreshold == 0 {
+		c.LockThreshold = lockThreshold
+	}
+	if c.LockDuration == 0 {
+		c.LockDuration = 15 * time.Minute
+	}
+	if c.Window == 0 {
+		c.Window = 15 * time.Minute
+	}
+}
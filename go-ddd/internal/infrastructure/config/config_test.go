@@ -0,0 +1,82 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// load 将 yaml 写入临时文件并加载
+func load(t *testing.T, yaml string) (*Config, error) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(yaml), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return Load(path)
+}
+
+func TestLoadRequiresSecrets(t *testing.T) {
+	const tokenSecret = "account-token-secret-0123456789abcdef"
+	const mfaKey = "\nmfa:\n  encryption_key: MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY=\n"
+
+	tests := []struct {
+		name    string
+		yaml    string
+		wantErr string
+	}{
+		{
+			name:    "missing token secret",
+			yaml:    "jwt:\n  secret: jwt-secret\n",
+			wantErr: "account.token_secret",
+		},
+		{
+			name:    "short token secret",
+			yaml:    "account:\n  token_secret: short\n",
+			wantErr: "account.token_secret",
+		},
+		{
+			name:    "token secret reuses jwt secret",
+			yaml:    "jwt:\n  secret: " + tokenSecret + "\naccount:\n  token_secret: " + tokenSecret + "\n",
+			wantErr: "differ from jwt.secret",
+		},
+		{
+			name: "key_dir without jwt secret",
+			yaml: "jwt:\n  key_dir: keys\naccount:\n  token_secret: " + tokenSecret + mfaKey,
+		},
+		{
+			name:    "missing mfa encryption key",
+			yaml:    "account:\n  token_secret: " + tokenSecret + "\n",
+			wantErr: "mfa.encryption_key",
+		},
+		{
+			name:    "mfa encryption key is not 32 bytes",
+			yaml:    "account:\n  token_secret: " + tokenSecret + "\nmfa:\n  encryption_key: c2hvcnQ=\n",
+			wantErr: "mfa.encryption_key",
+		},
+		{
+			name:    "mfa encryption key is not base64",
+			yaml:    "account:\n  token_secret: " + tokenSecret + "\nmfa:\n  encryption_key: not-base64!\n",
+			wantErr: "mfa.encryption_key",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := load(t, tt.yaml)
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if cfg.Account.TokenSecret != tokenSecret {
+					t.Fatalf("unexpected token secret %q", cfg.Account.TokenSecret)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,34 @@
+package imaging
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+// 运行方式：go test -fuzz=FuzzExifOrientation ./internal/infrastructure/imaging/
+func FuzzExifOrientation(f *testing.F) {
+	for orientation := 1; orientation <= 8; orientation++ {
+		f.Add(withExif(f, quadrants(4, 4), exifSegment(binary.BigEndian, orientation)))
+		f.Add(exifSegment(binary.LittleEndian, orientation)[10:]) // 只有 TIFF 结构
+	}
+	for _, seed := range []string{
+		"",
+		"\xFF\xD8",
+		"\xFF\xD8\xFF\xE1\x00\x02",
+		"\xFF\xD8\xFF\xE1\xFF\xFF Exif\x00\x00",
+		"\xFF\xD8\xFF\xE1\x00\x10Exif\x00\x00II*\x00\xFF\xFF\xFF\xFF",
+		"\xFF\xD8\xFF\xE1\x00\x16Exif\x00\x00MM\x00*\x00\x00\x00\x08\xFF\xFF\x01\x12",
+	} {
+		f.Add([]byte(seed))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		// 上传的文件内容不可信，任意输入都不能 panic，结果必须是合法的方向
+		if got := exifOrientation(data); got < 1 || got > 8 {
+			t.Fatalf("exifOrientation = %d", got)
+		}
+		if got := tiffOrientation(data); got < 1 || got > 8 {
+			t.Fatalf("tiffOrientation = %d", got)
+		}
+	})
+}
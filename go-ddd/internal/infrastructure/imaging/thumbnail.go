@@ -0,0 +1,143 @@
+// Package imaging 图片处理适配器，只使用标准库
+package imaging
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	_ "image/gif" // 注册 GIF 解码器
+	"image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+
+	"yiwen/go-ddd/internal/application/port"
+)
+
+// jpegQuality 缩略图的 JPEG 质量
+const jpegQuality = 85
+
+// Processor 基于标准库的图片处理器，实现 port.ImageProcessor
+// JPEG 输出为 JPEG；PNG、GIF 可能带透明通道，输出为 PNG（GIF 只取第一帧）
+type Processor struct {
+	maxPixels int
+}
+
+// NewProcessor 创建图片处理器，maxPixels 为允许解码的最大像素数，防止解压炸弹耗尽内存
+func NewProcessor(maxPixels int) *Processor {
+	return &Processor{maxPixels: maxPixels}
+}
+
+// Thumbnails 实现 port.ImageProcessor
+func (p *Processor) Thumbnails(r io.Reader, sizes []int) ([]port.ImageVariant, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// 以文件内容为准，不信任客户端声明的类型和扩展名
+	contentType := http.DetectContentType(data)
+	switch contentType {
+	case "image/jpeg", "image/png", "image/gif":
+	default:
+		return nil, port.ErrUnsupportedImage
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", port.ErrUnsupportedImage, err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return nil, port.ErrUnsupportedImage
+	}
+	if p.maxPixels > 0 && cfg.Width*cfg.Height > p.maxPixels {
+		return nil, fmt.Errorf("%w: %dx%d pixels", port.ErrImageTooLarge, cfg.Width, cfg.Height)
+	}
+
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", port.ErrUnsupportedImage, err)
+	}
+	img := toRGBA(decoded)
+	if contentType == "image/jpeg" {
+		// 重新编码会丢弃 EXIF，先按其中的方向把像素转正
+		img = orient(img, exifOrientation(data))
+	}
+	square := cropSquare(img)
+
+	variants := make([]port.ImageVariant, 0, len(sizes))
+	for _, size := range sizes {
+		side := size
+		if side > square.Bounds().Dx() {
+			side = square.Bounds().Dx() // 不放大
+		}
+		thumb := resize(square, side)
+
+		var buf bytes.Buffer
+		variant := port.ImageVariant{Size: size}
+		if contentType == "image/jpeg" {
+			variant.ContentType, variant.Ext = "image/jpeg", "jpg"
+			err = jpeg.Encode(&buf, thumb, &jpeg.Options{Quality: jpegQuality})
+		} else {
+			variant.ContentType, variant.Ext = "image/png", "png"
+			err = png.Encode(&buf, thumb)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("encode %dpx thumbnail: %w", size, err)
+		}
+		variant.Data = buf.Bytes()
+		variants = append(variants, variant)
+	}
+	return variants, nil
+}
+
+// toRGBA 转换为从 (0,0) 开始的 RGBA 图像（预乘透明度，缩放时直接取平均）
+func toRGBA(img image.Image) *image.RGBA {
+	b := img.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(dst, dst.Bounds(), img, b.Min, draw.Src)
+	return dst
+}
+
+// cropSquare 居中裁剪为正方形
+func cropSquare(img *image.RGBA) *image.RGBA {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	if w == h {
+		return img
+	}
+	side := min(w, h)
+	x0, y0 := (w-side)/2, (h-side)/2
+	return img.SubImage(image.Rect(x0, y0, x0+side, y0+side)).(*image.RGBA)
+}
+
+// resize 将正方形图像缩小为 side×side，每个目标像素取对应源区域的平均值（盒式滤波）
+func resize(src *image.RGBA, side int) *image.RGBA {
+	b := src.Bounds()
+	n := b.Dx()
+	dst := image.NewRGBA(image.Rect(0, 0, side, side))
+	for y := 0; y < side; y++ {
+		sy0, sy1 := y*n/side, max((y+1)*n/side, y*n/side+1)
+		for x := 0; x < side; x++ {
+			sx0, sx1 := x*n/side, max((x+1)*n/side, x*n/side+1)
+			var r, g, bl, a, count uint64
+			for sy := sy0; sy < sy1; sy++ {
+				i := src.PixOffset(b.Min.X+sx0, b.Min.Y+sy)
+				for sx := sx0; sx < sx1; sx++ {
+					r += uint64(src.Pix[i])
+					g += uint64(src.Pix[i+1])
+					bl += uint64(src.Pix[i+2])
+					a += uint64(src.Pix[i+3])
+					count++
+					i += 4
+				}
+			}
+			j := dst.PixOffset(x, y)
+			dst.Pix[j] = uint8(r / count)
+			dst.Pix[j+1] = uint8(g / count)
+			dst.Pix[j+2] = uint8(bl / count)
+			dst.Pix[j+3] = uint8(a / count)
+		}
+	}
+	return dst
+}
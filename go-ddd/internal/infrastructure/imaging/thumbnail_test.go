@@ -0,0 +1,208 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"testing"
+)
+
+var (
+	red   = color.RGBA{R: 255, A: 255}
+	green = color.RGBA{G: 255, A: 255}
+	blue  = color.RGBA{B: 255, A: 255}
+	white = color.RGBA{R: 255, G: 255, B: 255, A: 255}
+)
+
+// quadrants 生成正常显示时的图像：左上红、右上绿、左下蓝、右下白
+func quadrants(w, h int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for y := 0; y < h; y++ {
+		for x := 0; x < w; x++ {
+			switch {
+			case x < w/2 && y < h/2:
+				img.SetRGBA(x, y, red)
+			case y < h/2:
+				img.SetRGBA(x, y, green)
+			case x < w/2:
+				img.SetRGBA(x, y, blue)
+			default:
+				img.SetRGBA(x, y, white)
+			}
+		}
+	}
+	return img
+}
+
+// stored 按 EXIF 规范生成相机以该方向保存的像素：第 0 行、第 0 列分别对应显示时的哪一边
+func stored(display *image.RGBA, orientation int) *image.RGBA {
+	w, h := display.Bounds().Dx(), display.Bounds().Dy()
+	sw, sh := w, h
+	if orientation >= 5 {
+		sw, sh = h, w
+	}
+	img := image.NewRGBA(image.Rect(0, 0, sw, sh))
+	for y := 0; y < sh; y++ {
+		for x := 0; x < sw; x++ {
+			var dx, dy int
+			switch orientation {
+			case 1: // 第 0 行在上，第 0 列在左
+				dx, dy = x, y
+			case 2: // 上，右
+				dx, dy = w-1-x, y
+			case 3: // 下，右
+				dx, dy = w-1-x, h-1-y
+			case 4: // 下，左
+				dx, dy = x, h-1-y
+			case 5: // 左，上
+				dx, dy = y, x
+			case 6: // 右，上
+				dx, dy = w-1-y, x
+			case 7: // 右，下
+				dx, dy = w-1-y, h-1-x
+			case 8: // 左，下
+				dx, dy = y, h-1-x
+			}
+			img.SetRGBA(x, y, display.RGBAAt(dx, dy))
+		}
+	}
+	return img
+}
+
+// exifSegment 生成只含 IFD0 方向标签的 APP1 段
+func exifSegment(order binary.ByteOrder, orientation int) []byte {
+	tiff := make([]byte, 8+2+12+4)
+	if order == binary.LittleEndian {
+		copy(tiff, "II")
+	} else {
+		copy(tiff, "MM")
+	}
+	order.PutUint16(tiff[2:], 42)
+	order.PutUint32(tiff[4:], 8)
+	order.PutUint16(tiff[8:], 1)
+	order.PutUint16(tiff[10:], exifOrientationTag)
+	order.PutUint16(tiff[12:], 3) // SHORT
+	order.PutUint32(tiff[14:], 1)
+	order.PutUint16(tiff[18:], uint16(orientation))
+
+	payload := append([]byte("Exif\x00\x00"), tiff...)
+	segment := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(segment[2:], uint16(2+len(payload)))
+	return append(segment, payload...)
+}
+
+// withExif 在 SOI 之后插入 APP1 段
+func withExif(t testing.TB, img image.Image, app1 []byte) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+	return append(append(append([]byte{}, data[:2]...), app1...), data[2:]...)
+}
+
+// markers 列出 JPEG 图像数据之前的所有段标记
+func markers(data []byte) []byte {
+	var found []byte
+	for i := 2; i+4 <= len(data) && data[i] == 0xFF; {
+		found = append(found, data[i+1])
+		if data[i+1] == 0xDA {
+			break
+		}
+		i += 2 + int(binary.BigEndian.Uint16(data[i+2:]))
+	}
+	return found
+}
+
+// nearest 返回最接近的参考颜色，容忍 JPEG 压缩的误差
+func nearest(c color.Color) color.RGBA {
+	r, g, b, _ := c.RGBA()
+	best, bestDist := red, uint64(1<<63)
+	for _, ref := range []color.RGBA{red, green, blue, white} {
+		dr, dg, db := int64(r>>8)-int64(ref.R), int64(g>>8)-int64(ref.G), int64(b>>8)-int64(ref.B)
+		if d := uint64(dr*dr + dg*dg + db*db); d < bestDist {
+			best, bestDist = ref, d
+		}
+	}
+	return best
+}
+
+func TestThumbnailsApplyEXIFOrientation(t *testing.T) {
+	display := quadrants(48, 24)
+	sizes := []int{16, 8, 100}
+	wantSides := []int{16, 8, 24} // 居中裁剪为 24×24，不放大
+
+	for orientation := 1; orientation <= 8; orientation++ {
+		for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+			t.Run(fmt.Sprintf("%d/%v", orientation, order), func(t *testing.T) {
+				data := withExif(t, stored(display, orientation), exifSegment(order, orientation))
+				if got := exifOrientation(data); got != orientation {
+					t.Fatalf("exifOrientation = %d, want %d", got, orientation)
+				}
+
+				variants, err := NewProcessor(0).Thumbnails(bytes.NewReader(data), sizes)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if len(variants) != len(sizes) {
+					t.Fatalf("got %d variants, want %d", len(variants), len(sizes))
+				}
+				for i, v := range variants {
+					if v.Size != sizes[i] || v.ContentType != "image/jpeg" || v.Ext != "jpg" {
+						t.Fatalf("variant %d = %d %s %s", i, v.Size, v.ContentType, v.Ext)
+					}
+					// 重新编码后不再带有 EXIF，否则浏览器会再旋转一次
+					for _, m := range markers(v.Data) {
+						if m == 0xE1 {
+							t.Fatalf("%dpx thumbnail still has an APP1 segment", v.Size)
+						}
+					}
+					if bytes.Contains(v.Data, []byte("Exif\x00\x00")) {
+						t.Fatalf("%dpx thumbnail still contains EXIF data", v.Size)
+					}
+
+					img, err := jpeg.Decode(bytes.NewReader(v.Data))
+					if err != nil {
+						t.Fatal(err)
+					}
+					side := wantSides[i]
+					if b := img.Bounds(); b.Dx() != side || b.Dy() != side {
+						t.Fatalf("%dpx thumbnail is %dx%d, want %dx%d", v.Size, b.Dx(), b.Dy(), side, side)
+					}
+					corners := []color.RGBA{
+						nearest(img.At(1, 1)), nearest(img.At(side-2, 1)),
+						nearest(img.At(1, side-2)), nearest(img.At(side-2, side-2)),
+					}
+					if fmt.Sprint(corners) != fmt.Sprint([]color.RGBA{red, green, blue, white}) {
+						t.Fatalf("%dpx thumbnail is not upright: corners %v", v.Size, corners)
+					}
+				}
+			})
+		}
+	}
+}
+
+func TestExifOrientationFallsBack(t *testing.T) {
+	upright := withExif(t, quadrants(4, 4), nil)
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"no exif", upright},
+		{"not a jpeg", []byte("\x89PNG\r\n\x1a\n")},
+		{"out of range value", withExif(t, quadrants(4, 4), exifSegment(binary.BigEndian, 9))},
+		{"truncated segment", withExif(t, quadrants(4, 4), exifSegment(binary.LittleEndian, 6))[:20]},
+		{"unknown byte order", bytes.Replace(withExif(t, quadrants(4, 4), exifSegment(binary.LittleEndian, 6)), []byte("II*"), []byte("XX*"), 1)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := exifOrientation(tt.data); got != 1 {
+				t.Fatalf("exifOrientation = %d, want 1", got)
+			}
+		})
+	}
+}
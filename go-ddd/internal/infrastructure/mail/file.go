@@ -0,0 +1,36 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"yiwen/go-ddd/internal/application/port"
+)
+
+// FileMailer 将邮件写入目录中的 .eml 文件，便于开发时查看验证、重置链接
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+// NewFileMailer 创建文件邮件发送器，目录不存在时自动创建
+func NewFileMailer(dir, from string) (*FileMailer, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &FileMailer{dir: dir, from: from}, nil
+}
+
+// Send 实现 port.Mailer
+func (m *FileMailer) Send(ctx context.Context, mail port.Mail) error {
+	recipient := strings.NewReplacer("@", "_at_", "/", "_", "\\", "_").Replace(mail.To)
+	name := fmt.Sprintf("%s-%s.eml", time.Now().Format("20060102T150405.000000000"), recipient)
+	if err := os.WriteFile(filepath.Join(m.dir, name), buildMessage(m.from, mail), 0o644); err != nil {
+		return fmt.Errorf("write mail to %s: %w", mail.To, err)
+	}
+	return nil
+}
@@ -0,0 +1,17 @@
+package mail
+
+import (
+	"context"
+	"log"
+
+	"yiwen/go-ddd/internal/application/port"
+)
+
+// LogMailer 只把邮件内容写入日志，不实际发送
+type LogMailer struct{}
+
+// Send 实现 port.Mailer
+func (LogMailer) Send(ctx context.Context, mail port.Mail) error {
+	log.Printf("[mail] to=%s subject=%q\n%s", mail.To, mail.Subject, mail.Body)
+	return nil
+}
@@ -0,0 +1,27 @@
+// Package mail 邮件发送适配器，实现 port.Mailer
+// 生产环境使用 SMTPMailer；开发环境使用 FileMailer（写入 .eml 文件）或 LogMailer（只打印日志）
+package mail
+
+import (
+	"fmt"
+	"mime"
+	"strings"
+	"time"
+
+	"yiwen/go-ddd/internal/application/port"
+)
+
+// buildMessage 构造 RFC 5322 格式的纯文本邮件
+func buildMessage(from string, m port.Mail) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "From: %s\r\n", from)
+	fmt.Fprintf(&b, "To: %s\r\n", m.To)
+	fmt.Fprintf(&b, "Subject: %s\r\n", mime.QEncoding.Encode("utf-8", m.Subject))
+	fmt.Fprintf(&b, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: 8bit\r\n")
+	b.WriteString("\r\n")
+	b.WriteString(strings.ReplaceAll(m.Body, "\n", "\r\n"))
+	return []byte(b.String())
+}
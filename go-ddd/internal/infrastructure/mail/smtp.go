@@ -0,0 +1,41 @@
+package mail
+
+import (
+	"context"
+	"fmt"
+	"net/smtp"
+
+	"yiwen/go-ddd/internal/application/port"
+)
+
+// SMTPConfig SMTP 服务器配置
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string // 为空时不认证
+	Password string
+	From     string
+}
+
+// SMTPMailer 通过 SMTP 发送邮件，服务器支持时自动使用 STARTTLS
+type SMTPMailer struct {
+	cfg SMTPConfig
+}
+
+// NewSMTPMailer 创建 SMTP 邮件发送器
+func NewSMTPMailer(cfg SMTPConfig) *SMTPMailer {
+	return &SMTPMailer{cfg: cfg}
+}
+
+// Send 实现 port.Mailer
+func (m *SMTPMailer) Send(ctx context.Context, mail port.Mail) error {
+	var auth smtp.Auth
+	if m.cfg.Username != "" {
+		auth = smtp.PlainAuth("", m.cfg.Username, m.cfg.Password, m.cfg.Host)
+	}
+	addr := fmt.Sprintf("%s:%d", m.cfg.Host, m.cfg.Port)
+	if err := smtp.SendMail(addr, auth, m.cfg.From, []string{mail.To}, buildMessage(m.cfg.From, mail)); err != nil {
+		return fmt.Errorf("send mail to %s: %w", mail.To, err)
+	}
+	return nil
+}
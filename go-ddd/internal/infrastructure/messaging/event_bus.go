@@ -0,0 +1,92 @@
+package messaging
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+
+	"yiwen/go-ddd/internal/domain/event"
+)
+
+// EventHandlerFunc 函数适配器，让普通函数实现 event.EventHandler
+type EventHandlerFunc func(e event.Event) error
+
+// Handle 实现 event.EventHandler
+func (f EventHandlerFunc) Handle(e event.Event) error {
+	return f(e)
+}
+
+// EventBus 进程内事件总线
+// 实现领域层定义的 event.EventPublisher 接口，按事件名称同步分发给已注册的处理器
+// 进程内分发不保证投递（进程崩溃即丢失），需要可靠投递的场景请消费发件箱
+type EventBus struct {
+	mu       sync.RWMutex
+	handlers map[string][]event.EventHandler // 事件名称 -> 处理器
+	wildcard []event.EventHandler            // 订阅全部事件的处理器
+}
+
+// NewEventBus 创建进程内事件总线
+func NewEventBus() *EventBus {
+	return &EventBus{
+		handlers: make(map[string][]event.EventHandler),
+	}
+}
+
+// Subscribe 订阅指定名称的事件，如 "user.registered"
+func (b *EventBus) Subscribe(eventName string, handler event.EventHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.handlers[eventName] = append(b.handlers[eventName], handler)
+}
+
+// SubscribeAll 订阅全部事件
+func (b *EventBus) SubscribeAll(handler event.EventHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.wildcard = append(b.wildcard, handler)
+}
+
+// Publish 依次将事件分发给处理器
+// 单个处理器失败不影响其他处理器，所有错误合并后返回
+func (b *EventBus) Publish(events ...event.Event) error {
+	var errs []error
+	for _, e := range events {
+		for _, h := range b.handlersFor(e.EventName()) {
+			if err := safeHandle(h, e); err != nil {
+				errs = append(errs, fmt.Errorf("handle %s: %w", e.EventName(), err))
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// handlersFor 返回事件对应的处理器快照，分发过程中不持有锁
+func (b *EventBus) handlersFor(eventName string) []event.EventHandler {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	handlers := make([]event.EventHandler, 0, len(b.handlers[eventName])+len(b.wildcard))
+	handlers = append(handlers, b.handlers[eventName]...)
+	handlers = append(handlers, b.wildcard...)
+	return handlers
+}
+
+// safeHandle 调用处理器，并将 panic 转换为错误
+func safeHandle(h event.EventHandler, e event.Event) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("handler panic: %v", r)
+		}
+	}()
+	return h.Handle(e)
+}
+
+// LoggingHandler 将事件写入日志，便于开发调试
+type LoggingHandler struct{}
+
+// Handle 实现 event.EventHandler
+func (LoggingHandler) Handle(e event.Event) error {
+	log.Printf("[event] %s aggregate=%s at=%s", e.EventName(), e.AggregateID(), e.OccurredAt().Format("2006-01-02T15:04:05Z07:00"))
+	return nil
+}
@@ -0,0 +1,144 @@
+package messaging
+
+import (
+	"context"
+	"errors"
+	"log"
+	"time"
+
+	"yiwen/go-ddd/internal/infrastructure/persistence/model"
+)
+
+// OutboxMessage 从发件箱读出、待投递的消息
+type OutboxMessage struct {
+	EventID       string    // 全局唯一的事件ID，消费方可据此去重
+	AggregateType string    // 聚合类型，如 user
+	AggregateID   string    // 聚合ID（UUID）
+	EventName     string    // 事件名称，如 user.registered
+	Payload       []byte    // 事件的 JSON 序列化内容
+	OccurredAt    time.Time // 事件发生时间
+}
+
+// Sink 发件箱消息的投递目标（消息队列、Webhook 等）
+// 中继保证至少投递一次，同一条消息可能重复投递，Sink 的下游需要按 EventID 幂等处理
+type Sink interface {
+	Deliver(ctx context.Context, msg *OutboxMessage) error
+}
+
+// OutboxStore 中继依赖的发件箱存储
+// FetchPending 按写入顺序返回待投递的事件；maxAttempts > 0 时不返回达到最大重试次数的死信，
+// 以及同一聚合中排在死信之后的事件
+type OutboxStore interface {
+	FetchPending(ctx context.Context, limit, maxAttempts int) ([]*model.OutboxModel, error)
+	MarkProcessed(ctx context.Context, id uint64) error
+	MarkFailed(ctx context.Context, id uint64, cause error) error
+}
+
+// RelayConfig 中继配置
+type RelayConfig struct {
+	PollInterval time.Duration // 轮询间隔
+	BatchSize    int           // 每次读取的最大条数
+	MaxAttempts  int           // 最大投递次数，<= 0 表示无限重试
+}
+
+// OutboxRelay 发件箱中继
+// 定期读取未投递的事件并交给 Sink，成功后标记已处理；
+// 失败或进程在标记前崩溃时，事件会在下一轮重新投递（至少一次语义）
+type OutboxRelay struct {
+	store  OutboxStore
+	sink   Sink
+	config RelayConfig
+}
+
+// NewOutboxRelay 创建发件箱中继
+func NewOutboxRelay(store OutboxStore, sink Sink, config RelayConfig) *OutboxRelay {
+	if config.PollInterval <= 0 {
+		config.PollInterval = time.Second
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 100
+	}
+	return &OutboxRelay{
+		store:  store,
+		sink:   sink,
+		config: config,
+	}
+}
+
+// Run 启动中继循环，直到 ctx 取消
+func (r *OutboxRelay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.config.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := r.RelayOnce(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("[outbox] relay failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// RelayOnce 投递一批事件，返回成功投递的条数
+// 某条失败后跳过本批次中同一聚合的后续事件，保证同一聚合的事件按顺序到达，其他聚合照常投递；
+// 某条事件成为死信后，同一聚合后续的事件由 FetchPending 挡住，直到死信被人工处理
+func (r *OutboxRelay) RelayOnce(ctx context.Context) (int, error) {
+	rows, err := r.store.FetchPending(ctx, r.config.BatchSize, r.config.MaxAttempts)
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	blocked := make(map[string]bool) // 本轮出错的聚合
+	var errs []error
+	for _, row := range rows {
+		if ctx.Err() != nil {
+			return delivered, ctx.Err()
+		}
+		aggregate := row.AggregateType + "/" + row.AggregateID
+		if blocked[aggregate] {
+			continue
+		}
+		if err := r.sink.Deliver(ctx, toOutboxMessage(row)); err != nil {
+			log.Printf("[outbox] deliver %s (%s) failed: %v", row.EventID, row.EventName, err)
+			blocked[aggregate] = true
+			if markErr := r.store.MarkFailed(ctx, row.ID, err); markErr != nil {
+				errs = append(errs, markErr)
+			}
+			continue
+		}
+		if err := r.store.MarkProcessed(ctx, row.ID); err != nil {
+			// 已投递但未能标记，下一轮会重复投递；该聚合后续的事件也留到下一轮，避免乱序
+			blocked[aggregate] = true
+			errs = append(errs, err)
+			continue
+		}
+		delivered++
+	}
+	return delivered, errors.Join(errs...)
+}
+
+// toOutboxMessage 将数据库模型转换为投递消息
+func toOutboxMessage(row *model.OutboxModel) *OutboxMessage {
+	return &OutboxMessage{
+		EventID:       row.EventID,
+		AggregateType: row.AggregateType,
+		AggregateID:   row.AggregateID,
+		EventName:     row.EventName,
+		Payload:       []byte(row.Payload),
+		OccurredAt:    row.OccurredAt,
+	}
+}
+
+// LogSink 将消息写入日志的 Sink，用于开发环境或尚未接入消息队列时
+type LogSink struct{}
+
+// Deliver 实现 Sink
+func (LogSink) Deliver(ctx context.Context, msg *OutboxMessage) error {
+	log.Printf("[outbox] %s %s/%s id=%s payload=%s", msg.EventName, msg.AggregateType, msg.AggregateID, msg.EventID, msg.Payload)
+	return nil
+}
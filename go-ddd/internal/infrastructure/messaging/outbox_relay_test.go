@@ -0,0 +1,73 @@
+package messaging_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"yiwen/go-ddd/internal/infrastructure/messaging"
+	"yiwen/go-ddd/internal/infrastructure/persistence/model"
+)
+
+// fakeOutbox 记录标记结果的发件箱
+type fakeOutbox struct {
+	rows      []*model.OutboxModel
+	processed []uint64
+	failed    []uint64
+}
+
+func (o *fakeOutbox) FetchPending(ctx context.Context, limit, maxAttempts int) ([]*model.OutboxModel, error) {
+	return o.rows, nil
+}
+
+func (o *fakeOutbox) MarkProcessed(ctx context.Context, id uint64) error {
+	o.processed = append(o.processed, id)
+	return nil
+}
+
+func (o *fakeOutbox) MarkFailed(ctx context.Context, id uint64, cause error) error {
+	o.failed = append(o.failed, id)
+	return nil
+}
+
+// failingSink 投递指定事件时失败，记录收到的事件
+type failingSink struct {
+	fail      map[string]bool
+	delivered []string
+}
+
+func (s *failingSink) Deliver(ctx context.Context, msg *messaging.OutboxMessage) error {
+	if s.fail[msg.EventID] {
+		return errors.New("broker unavailable")
+	}
+	s.delivered = append(s.delivered, msg.EventID)
+	return nil
+}
+
+func TestOutboxRelaySkipsFailedAggregate(t *testing.T) {
+	outbox := &fakeOutbox{}
+	for i, aggregateID := range []string{"uuid-a", "uuid-b", "uuid-a", "uuid-c", "uuid-b"} {
+		outbox.rows = append(outbox.rows, &model.OutboxModel{
+			ID:            uint64(i + 1),
+			EventID:       fmt.Sprintf("event-%d", i+1),
+			AggregateType: "user",
+			AggregateID:   aggregateID,
+			EventName:     "user.profile_updated",
+		})
+	}
+	sink := &failingSink{fail: map[string]bool{"event-1": true}}
+
+	delivered, err := messaging.NewOutboxRelay(outbox, sink, messaging.RelayConfig{}).RelayOnce(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// uuid-a 的第一条失败后跳过它之后的事件，其他聚合照常投递
+	if got := fmt.Sprint(sink.delivered); delivered != 3 || got != "[event-2 event-4 event-5]" {
+		t.Fatalf("delivered %d: %s", delivered, got)
+	}
+	if fmt.Sprint(outbox.failed) != "[1]" || fmt.Sprint(outbox.processed) != "[2 4 5]" {
+		t.Fatalf("failed %v, processed %v", outbox.failed, outbox.processed)
+	}
+}
@@ -0,0 +1,318 @@
+package eventsourced
+
+import (
+	"context"
+	"errors"
+	"log"
+
+	"yiwen/go-ddd/internal/domain/aggregate"
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/event"
+	"yiwen/go-ddd/internal/domain/repository"
+	domainservice "yiwen/go-ddd/internal/domain/service"
+)
+
+var errEventsRequired = errors.New("event-sourced repository only accepts changes through SaveAggregate")
+
+// Projector 读模型投影器，将聚合状态同步到查询使用的表
+type Projector interface {
+	Project(ctx context.Context, agg *aggregate.UserAggregate) error
+}
+
+// UserRepository 事件溯源的用户仓储
+// 写入：追加事件到事件存储（乐观并发），然后通过投影器更新读模型
+// 读取：聚合由快照 + 后续事件重放得到；按用户名、邮箱等条件的查询走读模型
+//
+// 只有在工作单元中调用时，事件存储与读模型才处于同一事务；工作单元之外二者分别提交，
+// 投影失败时读模型会落后于事件存储，可以调用 RebuildReadModel 重新投影
+type UserRepository struct {
+	store         event.EventStore
+	readModel     repository.UserRepository
+	projector     Projector
+	snapshotEvery int
+}
+
+// NewUserRepository 创建事件溯源的用户仓储
+// snapshotEvery 为每多少个事件保存一次快照，<= 0 表示不保存快照
+func NewUserRepository(store event.EventStore, readModel repository.UserRepository, projector Projector, snapshotEvery int) repository.UserRepository {
+	return &UserRepository{
+		store:         store,
+		readModel:     readModel,
+		projector:     projector,
+		snapshotEvery: snapshotEvery,
+	}
+}
+
+// Save 事件溯源模式下不允许绕过事件直接修改状态
+func (r *UserRepository) Save(ctx context.Context, user *entity.User) error {
+	return errEventsRequired
+}
+
+// SaveAggregate 追加未提交的事件并更新读模型
+func (r *UserRepository) SaveAggregate(ctx context.Context, agg *aggregate.UserAggregate) error {
+	return r.SaveAggregates(ctx, agg)
+}
+
+// SaveAggregates 原子地追加多个聚合的事件，然后逐个更新读模型
+func (r *UserRepository) SaveAggregates(ctx context.Context, aggs ...*aggregate.UserAggregate) error {
+	streams := make([]event.StreamAppend, 0, len(aggs))
+	changed := make([]*aggregate.UserAggregate, 0, len(aggs))
+	for _, agg := range aggs {
+		events := agg.GetUncommittedEvents()
+		if len(events) == 0 {
+			continue
+		}
+		stream := event.StreamAppend{
+			AggregateID:     agg.User.UUID,
+			ExpectedVersion: agg.Version,
+			Events:          events,
+		}
+		if isErasure(events) {
+			// 擦除个人数据时替换整个事件流：历史事件和快照删除，
+			// 以一个导入事件记录匿名化之后的状态，user.erased 随后作为擦除的记录保留
+			stream.Replace = true
+			stream.Events = append([]event.Event{aggregate.ImportUser(agg.User)}, events...)
+		}
+		streams = append(streams, stream)
+		changed = append(changed, agg)
+	}
+	if len(changed) == 0 {
+		return nil
+	}
+
+	if err := r.store.AppendAll(ctx, streams...); err != nil {
+		return err
+	}
+
+	for i, agg := range changed {
+		expected := streams[i].ExpectedVersion
+		if streams[i].Replace {
+			expected = 0
+		}
+		agg.Version = expected + len(streams[i].Events)
+
+		if r.snapshotEvery > 0 && agg.Version/r.snapshotEvery > expected/r.snapshotEvery {
+			if err := r.saveSnapshot(ctx, agg); err != nil {
+				// 快照只是优化，失败不影响正确性
+				log.Printf("failed to save snapshot for %s: %v", agg.User.UUID, err)
+			}
+		}
+
+		if err := r.projector.Project(ctx, agg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FindAggregateByID 根据ID加载用户聚合
+func (r *UserRepository) FindAggregateByID(ctx context.Context, id uint64) (*aggregate.UserAggregate, error) {
+	row, err := r.readModel.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	agg, err := r.loadOrImport(ctx, row)
+	if err != nil {
+		return nil, err
+	}
+	if agg.User.IsDeleted() {
+		return nil, domainservice.ErrUserNotFound
+	}
+	return agg, nil
+}
+
+// FindByID 根据ID查找用户
+func (r *UserRepository) FindByID(ctx context.Context, id uint64) (*entity.User, error) {
+	agg, err := r.FindAggregateByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return agg.User, nil
+}
+
+// FindByUUID 根据UUID查找用户
+func (r *UserRepository) FindByUUID(ctx context.Context, uuid string) (*entity.User, error) {
+	agg, err := r.Load(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	if row, err := r.readModel.FindByUUID(ctx, uuid); err == nil {
+		agg.User.ID = row.ID
+	}
+	return agg.User, nil
+}
+
+// FindByUsername 根据用户名查找用户
+func (r *UserRepository) FindByUsername(ctx context.Context, username string) (*entity.User, error) {
+	row, err := r.readModel.FindByUsername(ctx, username)
+	if err != nil {
+		return nil, err
+	}
+	return r.loadUser(ctx, row)
+}
+
+// FindByEmail 根据邮箱查找用户
+func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*entity.User, error) {
+	row, err := r.readModel.FindByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	return r.loadUser(ctx, row)
+}
+
+// Delete 删除用户（记录 user.deleted 事件）
+func (r *UserRepository) Delete(ctx context.Context, id uint64) error {
+	agg, err := r.FindAggregateByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	agg.Delete()
+	return r.SaveAggregate(ctx, agg)
+}
+
+// List 按条件查询用户列表（读模型）
+func (r *UserRepository) List(ctx context.Context, opts repository.UserListOptions) ([]*entity.User, int64, error) {
+	return r.readModel.List(ctx, opts)
+}
+
+// ExistsByUsername 检查用户名是否存在（读模型）
+func (r *UserRepository) ExistsByUsername(ctx context.Context, username string) (bool, error) {
+	return r.readModel.ExistsByUsername(ctx, username)
+}
+
+// ExistsByEmail 检查邮箱是否存在（读模型）
+func (r *UserRepository) ExistsByEmail(ctx context.Context, email string) (bool, error) {
+	return r.readModel.ExistsByEmail(ctx, email)
+}
+
+// CountActiveAdmins 统计处于激活状态的管理员数量（读模型）
+// 统计时锁定读模型中的管理员记录；调用方在工作单元中调用时，读模型与事件存储在同一事务中更新，
+// 锁定才能串行化并发的降级。工作单元之外读模型可能落后于事件存储
+func (r *UserRepository) CountActiveAdmins(ctx context.Context) (int64, error) {
+	return r.readModel.CountActiveAdmins(ctx)
+}
+
+// FindPendingErasures 查找等待擦除个人数据的用户（读模型）
+func (r *UserRepository) FindPendingErasures(ctx context.Context) ([]*entity.User, error) {
+	return r.readModel.FindPendingErasures(ctx)
+}
+
+// Load 通过快照和事件重放加载聚合，已删除的用户视为不存在
+func (r *UserRepository) Load(ctx context.Context, uuid string) (*aggregate.UserAggregate, error) {
+	agg, err := r.replay(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	if agg == nil || agg.User.IsDeleted() {
+		return nil, domainservice.ErrUserNotFound
+	}
+	return agg, nil
+}
+
+// RebuildReadModel 从事件存储重新投影一个用户的读模型
+func (r *UserRepository) RebuildReadModel(ctx context.Context, uuid string) error {
+	agg, err := r.replay(ctx, uuid)
+	if err != nil {
+		return err
+	}
+	if agg == nil {
+		return domainservice.ErrUserNotFound
+	}
+	return r.projector.Project(ctx, agg)
+}
+
+// loadUser 根据读模型中的行加载权威状态
+func (r *UserRepository) loadUser(ctx context.Context, row *entity.User) (*entity.User, error) {
+	agg, err := r.loadOrImport(ctx, row)
+	if err != nil {
+		return nil, err
+	}
+	if agg.User.IsDeleted() {
+		return nil, domainservice.ErrUserNotFound
+	}
+	agg.User.ID = row.ID
+	return agg.User, nil
+}
+
+// loadOrImport 加载聚合；启用事件溯源前创建的用户没有事件历史，
+// 此时以读模型中的状态追加一个 user.imported 事件作为历史起点
+func (r *UserRepository) loadOrImport(ctx context.Context, row *entity.User) (*aggregate.UserAggregate, error) {
+	agg, err := r.replay(ctx, row.UUID)
+	if err != nil {
+		return nil, err
+	}
+	if agg != nil {
+		agg.User.ID = row.ID
+		return agg, nil
+	}
+
+	imported := aggregate.ImportUser(row)
+	if err := r.store.Append(ctx, row.UUID, 0, []event.Event{imported}); err != nil {
+		if !errors.Is(err, event.ErrConcurrencyConflict) {
+			return nil, err
+		}
+		// 其他请求已经完成导入，重新加载即可
+		return r.loadOrImport(ctx, row)
+	}
+
+	agg, err = aggregate.LoadUserFromHistory([]event.Event{imported})
+	if err != nil {
+		return nil, err
+	}
+	agg.User.ID = row.ID
+	return agg, nil
+}
+
+// replay 从快照和快照之后的事件重建聚合，没有任何历史时返回 nil
+func (r *UserRepository) replay(ctx context.Context, uuid string) (*aggregate.UserAggregate, error) {
+	var agg *aggregate.UserAggregate
+
+	snapshot, err := r.store.LoadSnapshot(ctx, uuid)
+	if err != nil {
+		return nil, err
+	}
+	if snapshot != nil {
+		if agg, err = aggregate.RestoreUserFromSnapshot(*snapshot); err != nil {
+			return nil, err
+		}
+	}
+
+	after := 0
+	if agg != nil {
+		after = agg.Version
+	}
+	history, err := r.store.Load(ctx, uuid, after)
+	if err != nil {
+		return nil, err
+	}
+
+	if agg == nil {
+		if len(history) == 0 {
+			return nil, nil
+		}
+		return aggregate.LoadUserFromHistory(history)
+	}
+	if err := agg.LoadFromHistory(history); err != nil {
+		return nil, err
+	}
+	return agg, nil
+}
+
+// saveSnapshot 保存当前版本的快照
+func (r *UserRepository) saveSnapshot(ctx context.Context, agg *aggregate.UserAggregate) error {
+	snapshot, err := agg.Snapshot()
+	if err != nil {
+		return err
+	}
+	return r.store.SaveSnapshot(ctx, snapshot)
+}
+
+// isErasure 本次变更是否擦除了用户的个人数据
+func isErasure(events []event.Event) bool {
+	for _, e := range events {
+		if _, ok := e.(*event.UserErasedEvent); ok {
+			return true
+		}
+	}
+	return false
+}
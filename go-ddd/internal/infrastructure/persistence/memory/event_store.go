@@ -0,0 +1,90 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"yiwen/go-ddd/internal/domain/event"
+)
+
+// EventStore 内存事件存储，用于测试和无数据库的本地运行
+type EventStore struct {
+	mu        sync.RWMutex
+	streams   map[string][]event.Event
+	snapshots map[string]event.Snapshot
+}
+
+// NewEventStore 创建内存事件存储
+func NewEventStore() event.EventStore {
+	return &EventStore{
+		streams:   make(map[string][]event.Event),
+		snapshots: make(map[string]event.Snapshot),
+	}
+}
+
+// Append 追加事件（乐观并发控制）
+func (s *EventStore) Append(ctx context.Context, aggregateID string, expectedVersion int, events []event.Event) error {
+	return s.AppendAll(ctx, event.StreamAppend{AggregateID: aggregateID, ExpectedVersion: expectedVersion, Events: events})
+}
+
+// AppendAll 原子地向多个事件流追加事件
+func (s *EventStore) AppendAll(ctx context.Context, streams ...event.StreamAppend) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, stream := range streams {
+		if len(s.streams[stream.AggregateID]) != stream.ExpectedVersion {
+			return event.ErrConcurrencyConflict
+		}
+	}
+	for _, stream := range streams {
+		if stream.Replace {
+			delete(s.streams, stream.AggregateID)
+			delete(s.snapshots, stream.AggregateID)
+		}
+		s.streams[stream.AggregateID] = append(s.streams[stream.AggregateID], stream.Events...)
+	}
+	return nil
+}
+
+// Load 按版本顺序加载事件
+func (s *EventStore) Load(ctx context.Context, aggregateID string, afterVersion int) ([]event.Event, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	stream := s.streams[aggregateID]
+	if afterVersion >= len(stream) {
+		return []event.Event{}, nil
+	}
+	if afterVersion < 0 {
+		afterVersion = 0
+	}
+
+	events := make([]event.Event, len(stream)-afterVersion)
+	copy(events, stream[afterVersion:])
+	return events, nil
+}
+
+// SaveSnapshot 保存（覆盖）聚合快照
+func (s *EventStore) SaveSnapshot(ctx context.Context, snapshot event.Snapshot) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	state := make([]byte, len(snapshot.State))
+	copy(state, snapshot.State)
+	snapshot.State = state
+	s.snapshots[snapshot.AggregateID] = snapshot
+	return nil
+}
+
+// LoadSnapshot 加载聚合快照
+func (s *EventStore) LoadSnapshot(ctx context.Context, aggregateID string) (*event.Snapshot, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	snapshot, ok := s.snapshots[aggregateID]
+	if !ok {
+		return nil, nil
+	}
+	return &snapshot, nil
+}
@@ -0,0 +1,91 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/repository"
+)
+
+// LoginAttemptRepository 内存登录失败计数仓储，只在单个进程内生效
+type LoginAttemptRepository struct {
+	mu       sync.Mutex
+	attempts map[string]entity.LoginAttempt
+}
+
+// NewLoginAttemptRepository 创建内存登录失败计数仓储
+func NewLoginAttemptRepository() repository.LoginAttemptRepository {
+	return &LoginAttemptRepository{
+		attempts: make(map[string]entity.LoginAttempt),
+	}
+}
+
+// Get 获取失败记录
+func (r *LoginAttemptRepository) Get(ctx context.Context, key string) (*entity.LoginAttempt, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attempt, ok := r.attempts[key]
+	if !ok {
+		return &entity.LoginAttempt{Key: key}, nil
+	}
+	return &attempt, nil
+}
+
+// Reserve 检查并预占一次尝试
+func (r *LoginAttemptRepository) Reserve(ctx context.Context, key string, now time.Time, window time.Duration, allow func(*entity.LoginAttempt) bool) (*entity.LoginAttempt, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	attempt, ok := r.attempts[key]
+	if !ok {
+		attempt = entity.LoginAttempt{Key: key}
+	}
+	current := attempt
+	if !allow(&current) {
+		return &current, false, nil
+	}
+	if attempt.LastFailedAt.Before(now.Add(-window)) {
+		attempt.Failures = 0
+	}
+	attempt.Failures++
+	attempt.LastFailedAt = now
+	r.attempts[key] = attempt
+	return &attempt, true, nil
+}
+
+// Release 撤销一次预占
+func (r *LoginAttemptRepository) Release(ctx context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if attempt, ok := r.attempts[key]; ok && attempt.Failures > 0 {
+		attempt.Failures--
+		r.attempts[key] = attempt
+	}
+	return nil
+}
+
+// Reset 清除失败记录
+func (r *LoginAttemptRepository) Reset(ctx context.Context, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.attempts, key)
+	return nil
+}
+
+// DeleteBefore 清理过期的失败记录
+func (r *LoginAttemptRepository) DeleteBefore(ctx context.Context, before time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for key, attempt := range r.attempts {
+		if attempt.LastFailedAt.Before(before) {
+			delete(r.attempts, key)
+		}
+	}
+	return nil
+}
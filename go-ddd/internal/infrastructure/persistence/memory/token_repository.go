@@ -0,0 +1,162 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/repository"
+)
+
+// RefreshTokenRepository 内存刷新令牌仓储
+type RefreshTokenRepository struct {
+	mu     sync.Mutex
+	tokens map[uint64]*entity.RefreshToken
+	nextID uint64
+}
+
+// NewRefreshTokenRepository 创建内存刷新令牌仓储
+func NewRefreshTokenRepository() repository.RefreshTokenRepository {
+	return &RefreshTokenRepository{
+		tokens: make(map[uint64]*entity.RefreshToken),
+		nextID: 1,
+	}
+}
+
+// Create 保存新令牌
+func (r *RefreshTokenRepository) Create(ctx context.Context, token *entity.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, t := range r.tokens {
+		if t.TokenHash == token.TokenHash {
+			return errors.New("refresh token already exists")
+		}
+	}
+	token.ID = r.nextID
+	r.nextID++
+	r.tokens[token.ID] = copyToken(token)
+	return nil
+}
+
+// FindByHash 根据令牌哈希查找
+func (r *RefreshTokenRepository) FindByHash(ctx context.Context, tokenHash string) (*entity.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, t := range r.tokens {
+		if t.TokenHash == tokenHash {
+			return copyToken(t), nil
+		}
+	}
+	return nil, errors.New("refresh token not found")
+}
+
+// MarkRotated 将未吊销的令牌标记为已轮换
+func (r *RefreshTokenRepository) MarkRotated(ctx context.Context, id, replacedBy uint64) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	t, ok := r.tokens[id]
+	if !ok || t.RevokedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	t.RevokedAt = &now
+	t.ReplacedBy = replacedBy
+	return true, nil
+}
+
+// RevokeFamily 吊销整个令牌家族
+func (r *RefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	r.revokeWhere(func(t *entity.RefreshToken) bool { return t.FamilyID == familyID })
+	return nil
+}
+
+// RevokeAllForUser 吊销用户的全部令牌
+func (r *RefreshTokenRepository) RevokeAllForUser(ctx context.Context, userID uint64) error {
+	r.revokeWhere(func(t *entity.RefreshToken) bool { return t.UserID == userID })
+	return nil
+}
+
+// DeleteExpired 清理已过期的令牌
+func (r *RefreshTokenRepository) DeleteExpired(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, t := range r.tokens {
+		if t.IsExpired() {
+			delete(r.tokens, id)
+		}
+	}
+	return nil
+}
+
+func (r *RefreshTokenRepository) revokeWhere(match func(t *entity.RefreshToken) bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for _, t := range r.tokens {
+		if t.RevokedAt == nil && match(t) {
+			revokedAt := now
+			t.RevokedAt = &revokedAt
+		}
+	}
+}
+
+func copyToken(t *entity.RefreshToken) *entity.RefreshToken {
+	c := *t
+	if t.RevokedAt != nil {
+		revokedAt := *t.RevokedAt
+		c.RevokedAt = &revokedAt
+	}
+	return &c
+}
+
+// RevokedTokenRepository 内存已吊销访问令牌仓储
+type RevokedTokenRepository struct {
+	mu      sync.RWMutex
+	revoked map[string]time.Time
+}
+
+// NewRevokedTokenRepository 创建内存已吊销访问令牌仓储
+func NewRevokedTokenRepository() repository.RevokedTokenRepository {
+	return &RevokedTokenRepository{revoked: make(map[string]time.Time)}
+}
+
+// Revoke 吊销访问令牌，重复吊销是幂等的
+func (r *RevokedTokenRepository) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.revoked[jti]; !ok {
+		r.revoked[jti] = expiresAt
+	}
+	return nil
+}
+
+// IsRevoked 检查访问令牌是否已被吊销
+func (r *RevokedTokenRepository) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	_, ok := r.revoked[jti]
+	return ok, nil
+}
+
+// DeleteExpired 清理已过期的记录
+func (r *RevokedTokenRepository) DeleteExpired(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	now := time.Now()
+	for jti, expiresAt := range r.revoked {
+		if expiresAt.Before(now) {
+			delete(r.revoked, jti)
+		}
+	}
+	return nil
+}
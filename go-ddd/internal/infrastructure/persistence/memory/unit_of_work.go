@@ -0,0 +1,33 @@
+package memory
+
+import (
+	"context"
+	"sync"
+
+	"yiwen/go-ddd/internal/domain/repository"
+)
+
+type txKey struct{}
+
+// UnitOfWork 内存工作单元
+// 内存仓储没有事务，这里把命令串行化执行，保证"检查唯一性再写入"这类操作不会交错；
+// fn 返回错误时已经写入的数据不会回滚，需要原子性的批量写入由仓储自身保证（例如 SaveAggregates）
+type UnitOfWork struct {
+	mu sync.Mutex
+}
+
+// NewUnitOfWork 创建内存工作单元
+func NewUnitOfWork() repository.UnitOfWork {
+	return &UnitOfWork{}
+}
+
+// Do 串行执行 fn，嵌套调用直接执行
+func (u *UnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	if ctx.Value(txKey{}) != nil {
+		return fn(ctx)
+	}
+
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	return fn(context.WithValue(ctx, txKey{}, true))
+}
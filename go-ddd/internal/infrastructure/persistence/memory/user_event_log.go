@@ -0,0 +1,58 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"yiwen/go-ddd/internal/application/port"
+	"yiwen/go-ddd/internal/domain/event"
+)
+
+// UserEventLog 内存用户事件记录
+// 内存存储没有发件箱，订阅事件总线（SubscribeAll）记录已发布的事件，与发件箱一样只保存脱敏后的内容
+type UserEventLog struct {
+	mu     sync.RWMutex
+	events map[string][]port.RecordedEvent
+}
+
+// NewUserEventLog 创建内存用户事件记录
+func NewUserEventLog() *UserEventLog {
+	return &UserEventLog{events: make(map[string][]port.RecordedEvent)}
+}
+
+// Handle 实现 event.EventHandler，记录一个已发布的事件
+func (l *UserEventLog) Handle(e event.Event) error {
+	payload, err := json.Marshal(event.Redact(e))
+	if err != nil {
+		return err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.events[e.AggregateID()] = append(l.events[e.AggregateID()], port.RecordedEvent{
+		Name:       e.EventName(),
+		OccurredAt: e.OccurredAt(),
+		Payload:    payload,
+	})
+	return nil
+}
+
+// Events 按发布顺序返回聚合的全部事件
+func (l *UserEventLog) Events(ctx context.Context, aggregateID string) ([]port.RecordedEvent, error) {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+
+	events := make([]port.RecordedEvent, len(l.events[aggregateID]))
+	copy(events, l.events[aggregateID])
+	return events, nil
+}
+
+// Erase 删除聚合的全部事件
+func (l *UserEventLog) Erase(ctx context.Context, aggregateID string) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	delete(l.events, aggregateID)
+	return nil
+}
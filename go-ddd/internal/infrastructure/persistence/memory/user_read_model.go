@@ -0,0 +1,132 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+
+	"yiwen/go-ddd/internal/application/port"
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/repository"
+	"yiwen/go-ddd/internal/domain/valueobject"
+)
+
+// UserReadModel 内存用户读模型
+type UserReadModel struct {
+	mu    sync.RWMutex
+	views map[uint64]port.UserView
+}
+
+// NewUserReadModel 创建内存用户读模型
+func NewUserReadModel() *UserReadModel {
+	return &UserReadModel{views: make(map[uint64]port.UserView)}
+}
+
+// FindByID 根据ID查找
+func (r *UserReadModel) FindByID(ctx context.Context, id uint64) (*port.UserView, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	v, ok := r.views[id]
+	if !ok {
+		return nil, port.ErrUserViewNotFound
+	}
+	return &v, nil
+}
+
+// FindByUUID 根据UUID查找
+func (r *UserReadModel) FindByUUID(ctx context.Context, uuid string) (*port.UserView, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, v := range r.views {
+		if v.UUID == uuid {
+			return &v, nil
+		}
+	}
+	return nil, port.ErrUserViewNotFound
+}
+
+// List 按条件查询，过滤和排序规则与内存用户仓储相同
+func (r *UserReadModel) List(ctx context.Context, opts repository.UserListOptions) ([]port.UserView, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var all []*entity.User
+	byID := make(map[uint64]port.UserView)
+	for _, v := range r.views {
+		if u := listKey(v); opts.Filter.Matches(u) {
+			all = append(all, u)
+			byID[v.ID] = v
+		}
+	}
+	sort.Slice(all, func(i, j int) bool {
+		return compareUser(all[i], repository.UserCursorOf(all[j]), opts) < 0
+	})
+	total := int64(len(all))
+
+	offset := opts.Offset
+	if opts.After != nil {
+		offset = sort.Search(len(all), func(i int) bool {
+			return compareUser(all[i], opts.After, opts) > 0
+		})
+	}
+	if offset > len(all) {
+		offset = len(all)
+	}
+	end := offset + opts.Limit
+	if end > len(all) {
+		end = len(all)
+	}
+
+	views := make([]port.UserView, 0, end-offset)
+	for _, u := range all[offset:end] {
+		views = append(views, byID[u.ID])
+	}
+	return views, total, nil
+}
+
+// listKey 只带有过滤、排序所需字段的用户，用于复用仓储的过滤和排序规则
+func listKey(v port.UserView) *entity.User {
+	email, _ := valueobject.NewEmail(v.Email)
+	return &entity.User{
+		ID:        v.ID,
+		Username:  v.Username,
+		Email:     email,
+		Nickname:  v.Nickname,
+		Status:    entity.UserStatus(v.Status),
+		Role:      entity.UserRole(v.Role),
+		CreatedAt: v.CreatedAt,
+	}
+}
+
+// Upsert 按ID写入或覆盖
+func (r *UserReadModel) Upsert(ctx context.Context, view port.UserView) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.views[view.ID] = view
+	return nil
+}
+
+// Delete 删除一行
+func (r *UserReadModel) Delete(ctx context.Context, uuid string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for id, v := range r.views {
+		if v.UUID == uuid {
+			delete(r.views, id)
+		}
+	}
+	return nil
+}
+
+// Reset 清空读模型
+func (r *UserReadModel) Reset(ctx context.Context) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.views = make(map[uint64]port.UserView)
+	return nil
+}
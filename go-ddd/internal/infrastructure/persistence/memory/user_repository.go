@@ -0,0 +1,327 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"yiwen/go-ddd/internal/domain/aggregate"
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/repository"
+	domainservice "yiwen/go-ddd/internal/domain/service"
+)
+
+var errUUIDDuplicate = errors.New("uuid already exists")
+
+// UserRepository 内存用户仓储，用于测试和无数据库的本地运行
+// 行为与 GORM 实现保持一致：
+//   - 删除是软删除，查询、列表、存在性检查都不包含已删除的用户
+//   - 唯一性（UUID、用户名、邮箱）包括已删除的用户，与数据库唯一索引一致
+//   - 保存和读取都复制实体，调用方修改返回值不会影响仓储中的数据
+type UserRepository struct {
+	mu     sync.RWMutex
+	users  map[uint64]*entity.User
+	nextID uint64
+}
+
+// NewUserRepository 创建内存用户仓储
+func NewUserRepository() *UserRepository {
+	return &UserRepository{
+		users:  make(map[uint64]*entity.User),
+		nextID: 1,
+	}
+}
+
+// Save 保存用户（创建或更新）
+func (r *UserRepository) Save(ctx context.Context, user *entity.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err := r.checkUnique(user); err != nil {
+		return err
+	}
+	r.store(user)
+	return nil
+}
+
+// SaveAggregate 保存聚合根；内存实现没有发件箱，事件只由应用服务在进程内发布
+func (r *UserRepository) SaveAggregate(ctx context.Context, agg *aggregate.UserAggregate) error {
+	return r.SaveAggregates(ctx, agg)
+}
+
+// SaveAggregates 保存多个聚合，任一聚合违反唯一性时全部不保存
+func (r *UserRepository) SaveAggregates(ctx context.Context, aggs ...*aggregate.UserAggregate) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, agg := range aggs {
+		if err := r.checkUnique(agg.User); err != nil {
+			return err
+		}
+		// 同一批次内的聚合之间也不能冲突
+		for _, other := range aggs[:i] {
+			if other.User.ID != 0 && other.User.ID == agg.User.ID {
+				continue
+			}
+			if err := conflict(agg.User, other.User); err != nil {
+				return err
+			}
+		}
+	}
+	for _, agg := range aggs {
+		r.store(agg.User)
+	}
+	return nil
+}
+
+// Project 将聚合的最新状态写入仓储，使内存仓储可以作为事件溯源的读模型
+func (r *UserRepository) Project(ctx context.Context, agg *aggregate.UserAggregate) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	agg.User.ID = 0
+	for _, u := range r.users {
+		if u.UUID == agg.User.UUID {
+			agg.User.ID = u.ID
+			break
+		}
+	}
+	if err := r.checkUnique(agg.User); err != nil {
+		return err
+	}
+	r.store(agg.User)
+	return nil
+}
+
+// FindAggregateByID 根据ID加载用户聚合
+func (r *UserRepository) FindAggregateByID(ctx context.Context, id uint64) (*aggregate.UserAggregate, error) {
+	user, err := r.FindByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return aggregate.NewUserAggregate(user), nil
+}
+
+// FindByID 根据ID查找用户
+func (r *UserRepository) FindByID(ctx context.Context, id uint64) (*entity.User, error) {
+	return r.findOne(func(u *entity.User) bool { return u.ID == id })
+}
+
+// FindByUUID 根据UUID查找用户
+func (r *UserRepository) FindByUUID(ctx context.Context, uuid string) (*entity.User, error) {
+	return r.findOne(func(u *entity.User) bool { return u.UUID == uuid })
+}
+
+// FindByUsername 根据用户名查找用户
+func (r *UserRepository) FindByUsername(ctx context.Context, username string) (*entity.User, error) {
+	return r.findOne(func(u *entity.User) bool { return u.Username == username })
+}
+
+// FindByEmail 根据邮箱查找用户
+func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*entity.User, error) {
+	return r.findOne(func(u *entity.User) bool { return u.Email.String() == email })
+}
+
+// Delete 删除用户（软删除）
+func (r *UserRepository) Delete(ctx context.Context, id uint64) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if u, ok := r.users[id]; ok && !u.IsDeleted() {
+		u.MarkDeleted()
+	}
+	return nil
+}
+
+// List 按条件查询用户列表
+func (r *UserRepository) List(ctx context.Context, opts repository.UserListOptions) ([]*entity.User, int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	all := make([]*entity.User, 0, len(r.users))
+	for _, u := range r.users {
+		if !u.IsDeleted() && opts.Filter.Matches(u) {
+			all = append(all, u)
+		}
+	}
+	sort.Slice(all, func(i, j int) bool {
+		return compareUser(all[i], repository.UserCursorOf(all[j]), opts) < 0
+	})
+	total := int64(len(all))
+
+	offset := opts.Offset
+	if opts.After != nil {
+		offset = sort.Search(len(all), func(i int) bool {
+			return compareUser(all[i], opts.After, opts) > 0
+		})
+	}
+	if offset > len(all) {
+		offset = len(all)
+	}
+	end := offset + opts.Limit
+	if end > len(all) {
+		end = len(all)
+	}
+
+	users := make([]*entity.User, 0, end-offset)
+	for _, u := range all[offset:end] {
+		users = append(users, copyUser(u))
+	}
+	return users, total, nil
+}
+
+// compareUser 比较用户 u 与位置 c 在 opts 排序下的先后，负数表示 u 在前
+// 排序值相同时按 ID 同方向排序
+func compareUser(u *entity.User, c *repository.UserCursor, opts repository.UserListOptions) int {
+	cmp := 0
+	switch opts.SortBy {
+	case repository.UserSortByCreatedAt:
+		cmp = u.CreatedAt.Compare(c.CreatedAt)
+	case repository.UserSortByUsername:
+		cmp = strings.Compare(u.Username, c.Username)
+	}
+	if cmp == 0 {
+		switch {
+		case u.ID < c.ID:
+			cmp = -1
+		case u.ID > c.ID:
+			cmp = 1
+		}
+	}
+	if opts.Desc {
+		return -cmp
+	}
+	return cmp
+}
+
+// ExistsByUsername 检查用户名是否存在
+func (r *UserRepository) ExistsByUsername(ctx context.Context, username string) (bool, error) {
+	_, err := r.FindByUsername(ctx, username)
+	return err == nil, nil
+}
+
+// ExistsByEmail 检查邮箱是否存在
+func (r *UserRepository) ExistsByEmail(ctx context.Context, email string) (bool, error) {
+	_, err := r.FindByEmail(ctx, email)
+	return err == nil, nil
+}
+
+// CountActiveAdmins 统计处于激活状态的管理员数量
+// 内存工作单元串行执行命令，检查和保存之间不会有其他命令插入，无需额外加锁
+func (r *UserRepository) CountActiveAdmins(ctx context.Context) (int64, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var count int64
+	for _, u := range r.users {
+		if !u.IsDeleted() && u.IsAdmin() && u.IsActive() {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// FindPendingErasures 查找已申请删除账户、等待擦除个人数据的用户，按擦除时间升序
+func (r *UserRepository) FindPendingErasures(ctx context.Context) ([]*entity.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var users []*entity.User
+	for _, u := range r.users {
+		if !u.IsDeleted() && u.IsErasurePending() {
+			users = append(users, copyUser(u))
+		}
+	}
+	sort.Slice(users, func(i, j int) bool {
+		a, b := users[i].ErasureScheduledAt, users[j].ErasureScheduledAt
+		if !a.Equal(*b) {
+			return a.Before(*b)
+		}
+		return users[i].ID < users[j].ID
+	})
+	return users, nil
+}
+
+// findOne 查找第一个满足条件且未删除的用户
+func (r *UserRepository) findOne(match func(u *entity.User) bool) (*entity.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, u := range r.users {
+		if !u.IsDeleted() && match(u) {
+			return copyUser(u), nil
+		}
+	}
+	return nil, domainservice.ErrUserNotFound
+}
+
+// checkUnique 检查用户与其他已保存用户（包括已删除的）是否冲突，调用方需持有写锁
+func (r *UserRepository) checkUnique(user *entity.User) error {
+	for _, u := range r.users {
+		if u.ID == user.ID {
+			continue
+		}
+		if err := conflict(user, u); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// store 保存用户副本，新用户分配自增ID，调用方需持有写锁
+func (r *UserRepository) store(user *entity.User) {
+	if user.ID == 0 {
+		user.ID = r.nextID
+	}
+	if user.ID >= r.nextID {
+		r.nextID = user.ID + 1
+	}
+
+	stored := copyUser(user)
+	now := time.Now()
+	if stored.CreatedAt.IsZero() {
+		stored.CreatedAt = now
+	}
+	stored.UpdatedAt = now
+	r.users[stored.ID] = stored
+}
+
+// conflict 判断两个不同的用户是否违反唯一性
+func conflict(a, b *entity.User) error {
+	switch {
+	case a.UUID == b.UUID:
+		return errUUIDDuplicate
+	case a.Username == b.Username:
+		return domainservice.ErrUsernameAlreadyExists
+	case a.Email.String() == b.Email.String():
+		return domainservice.ErrEmailAlreadyExists
+	}
+	return nil
+}
+
+// copyUser 复制用户实体
+func copyUser(u *entity.User) *entity.User {
+	c := *u
+	if u.DeletedAt != nil {
+		deletedAt := *u.DeletedAt
+		c.DeletedAt = &deletedAt
+	}
+	if u.EmailVerifiedAt != nil {
+		verifiedAt := *u.EmailVerifiedAt
+		c.EmailVerifiedAt = &verifiedAt
+	}
+	if u.LockedUntil != nil {
+		lockedUntil := *u.LockedUntil
+		c.LockedUntil = &lockedUntil
+	}
+	if u.RecoveryCodes != nil {
+		c.RecoveryCodes = append([]string(nil), u.RecoveryCodes...)
+	}
+	if u.PasswordHistory != nil {
+		c.PasswordHistory = append([]string(nil), u.PasswordHistory...)
+	}
+	return &c
+}
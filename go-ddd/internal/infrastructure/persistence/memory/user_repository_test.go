@@ -0,0 +1,64 @@
+package memory
+
+import (
+	"testing"
+
+	"yiwen/go-ddd/internal/application/port"
+	"yiwen/go-ddd/internal/domain/aggregate"
+	"yiwen/go-ddd/internal/domain/event"
+	"yiwen/go-ddd/internal/domain/repository"
+	"yiwen/go-ddd/internal/infrastructure/persistence/eventsourced"
+	"yiwen/go-ddd/internal/infrastructure/persistence/repotest"
+)
+
+func TestUserRepository(t *testing.T) {
+	repotest.RunUserRepositoryTests(t, func(t *testing.T) repository.UserRepository {
+		return NewUserRepository()
+	})
+}
+
+func TestEventSourcedUserRepository(t *testing.T) {
+	repotest.RunEventSourcedUserRepositoryTests(t, func(t *testing.T) (repository.UserRepository, event.EventStore) {
+		users := NewUserRepository()
+		store := NewEventStore()
+		return eventsourced.NewUserRepository(store, users, users, 2), store
+	})
+}
+
+func TestUserReadModel(t *testing.T) {
+	repotest.RunUserReadModelTests(t, func(t *testing.T) port.UserReadModel {
+		return NewUserReadModel()
+	})
+}
+
+func TestUserEventLog(t *testing.T) {
+	repotest.RunUserEventLogTests(t, func(t *testing.T) (port.UserEventLog, repotest.RecordFunc) {
+		log := NewUserEventLog()
+		return log, func(t *testing.T, agg *aggregate.UserAggregate) {
+			for _, e := range agg.GetUncommittedEvents() {
+				if err := log.Handle(e); err != nil {
+					t.Fatal(err)
+				}
+			}
+			agg.ClearEvents()
+		}
+	})
+}
+
+func TestRefreshTokenRepository(t *testing.T) {
+	repotest.RunRefreshTokenRepositoryTests(t, func(t *testing.T) repository.RefreshTokenRepository {
+		return NewRefreshTokenRepository()
+	})
+}
+
+func TestRevokedTokenRepository(t *testing.T) {
+	repotest.RunRevokedTokenRepositoryTests(t, func(t *testing.T) repository.RevokedTokenRepository {
+		return NewRevokedTokenRepository()
+	})
+}
+
+func TestLoginAttemptRepository(t *testing.T) {
+	repotest.RunLoginAttemptRepositoryTests(t, func(t *testing.T) repository.LoginAttemptRepository {
+		return NewLoginAttemptRepository()
+	})
+}
@@ -0,0 +1,132 @@
+// Package migration 版本化的数据库迁移
+// 迁移文件随程序一起编译（embed），命名为 <版本>_<名称>.up.sql 和 <版本>_<名称>.down.sql，
+// 版本号为正整数，按数值从小到大执行；已执行的版本、内容校验和记录在 schema_migrations 表中
+package migration
+
+import (
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"fmt"
+	"io/fs"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+//go:embed mysql/*.sql
+var mysqlFiles embed.FS
+
+// Migration 一个版本的迁移
+type Migration struct {
+	Version  uint64
+	Name     string
+	Up       string
+	Down     string // 为空表示不可回滚
+	Checksum string // Up 内容的 SHA-256，用于发现已执行后又被修改的迁移
+}
+
+// MySQL 返回内置的 MySQL 迁移
+func MySQL() ([]Migration, error) {
+	sub, err := fs.Sub(mysqlFiles, "mysql")
+	if err != nil {
+		return nil, err
+	}
+	return Load(sub)
+}
+
+var fileName = regexp.MustCompile(`^(\d+)_([a-z0-9_]+)\.(up|down)\.sql$`)
+
+// Load 读取目录中的迁移文件，按版本升序返回
+// 每个版本必须有 up 文件，down 文件可选；同一版本的两个文件名称必须一致
+func Load(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := make(map[uint64]*Migration)
+	for _, entry := range entries {
+		if entry.IsDir() || !strings.HasSuffix(entry.Name(), ".sql") {
+			continue
+		}
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %q, expected <version>_<name>.up.sql or .down.sql", entry.Name())
+		}
+		version, err := strconv.ParseUint(match[1], 10, 64)
+		if err != nil || version == 0 {
+			return nil, fmt.Errorf("invalid migration version in %q", entry.Name())
+		}
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		} else if m.Name != match[2] {
+			return nil, fmt.Errorf("migration %d has conflicting names %q and %q", version, m.Name, match[2])
+		}
+		if match[3] == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if strings.TrimSpace(m.Up) == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up file", m.Version, m.Name)
+		}
+		sum := sha256.Sum256([]byte(m.Up))
+		m.Checksum = hex.EncodeToString(sum[:])
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// String 返回 <版本>_<名称>
+func (m Migration) String() string {
+	return fmt.Sprintf("%d_%s", m.Version, m.Name)
+}
+
+// createTablePattern 匹配 CREATE TABLE 语句中的表名
+var createTablePattern = regexp.MustCompile(`(?i)\bCREATE\s+TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?(\w+)`)
+
+// createdTables 返回迁移内容中 CREATE TABLE 创建的表
+func createdTables(sql string) []string {
+	var tables []string
+	for _, match := range createTablePattern.FindAllStringSubmatch(sql, -1) {
+		tables = append(tables, match[1])
+	}
+	return tables
+}
+
+// statements 将迁移内容拆分为单条语句，逐条执行（MySQL 驱动默认不允许一次执行多条）
+// 语句以行尾的分号结束，"--" 开头的整行注释会被忽略；迁移中不能使用存储过程等包含分号的语句块
+func statements(sql string) []string {
+	var stmts []string
+	var current strings.Builder
+	for _, line := range strings.Split(sql, "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteString("\n")
+		if strings.HasSuffix(trimmed, ";") {
+			stmts = append(stmts, strings.TrimSpace(current.String()))
+			current.Reset()
+		}
+	}
+	if rest := strings.TrimSpace(current.String()); rest != "" {
+		stmts = append(stmts, rest)
+	}
+	return stmts
+}
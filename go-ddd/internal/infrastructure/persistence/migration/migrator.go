@@ -0,0 +1,445 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrDirty            = errors.New("a migration failed halfway, fix the schema manually and run migrate force")
+	ErrSchemaOutdated   = errors.New("database schema is older than this build, run migrate up")
+	ErrSchemaTooNew     = errors.New("database schema is newer than this build")
+	ErrChecksumMismatch = errors.New("applied migration has been modified")
+	ErrUnknownVersion   = errors.New("unknown migration version")
+	ErrIrreversible     = errors.New("migration has no down file")
+	ErrLocked           = errors.New("migrations are locked by another process")
+	ErrUnversioned      = errors.New("database already has tables but no recorded migrations, bring the schema up to date and run migrate force <version> as a baseline")
+)
+
+// lockPollInterval 等待迁移锁时的重试间隔
+const lockPollInterval = 500 * time.Millisecond
+
+// schemaMigration schema_migrations 表的一行：一个已执行（或执行到一半）的迁移
+type schemaMigration struct {
+	Version   uint64 `gorm:"primaryKey;autoIncrement:false"`
+	Name      string
+	Checksum  string
+	Dirty     bool
+	AppliedAt time.Time
+}
+
+func (schemaMigration) TableName() string { return "schema_migrations" }
+
+// schemaLock schema_lock 表只有一行（id = 1），存在即表示有进程正在迁移
+type schemaLock struct {
+	ID       int `gorm:"primaryKey;autoIncrement:false"`
+	Owner    string
+	LockedAt time.Time
+}
+
+func (schemaLock) TableName() string { return "schema_lock" }
+
+// 两张表的定义同时兼容 MySQL 和 SQLite
+var bookkeeping = []string{
+	`CREATE TABLE IF NOT EXISTS schema_migrations (
+    version BIGINT NOT NULL PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    checksum CHAR(64) NOT NULL,
+    dirty BOOLEAN NOT NULL DEFAULT FALSE,
+    applied_at DATETIME NOT NULL
+)`,
+	`CREATE TABLE IF NOT EXISTS schema_lock (
+    id INT NOT NULL PRIMARY KEY,
+    owner VARCHAR(255) NOT NULL,
+    locked_at DATETIME NOT NULL
+)`,
+}
+
+// State 迁移的状态
+type State string
+
+const (
+	StateApplied  State = "applied"
+	StatePending  State = "pending"
+	StateDirty    State = "dirty"    // 执行到一半失败
+	StateModified State = "modified" // 执行后文件内容被修改
+	StateUnknown  State = "unknown"  // 数据库中已执行，但本程序中没有（由更新的版本执行）
+)
+
+// Status 一个迁移的状态
+type Status struct {
+	Version   uint64
+	Name      string
+	State     State
+	AppliedAt *time.Time
+}
+
+// Options 迁移配置
+type Options struct {
+	LockTimeout time.Duration // 等待其他进程释放迁移锁的最长时间，默认 1 分钟
+	Owner       string        // 写入锁表的持有者标识，默认 <主机名>:<进程号>
+}
+
+// Migrator 执行迁移并校验数据库结构版本
+// 所有修改都在迁移锁内进行，多个实例同时启动时只有一个执行迁移，其余等待后看到已是最新版本
+//
+// MySQL 的 DDL 会隐式提交，迁移无法整体回滚：执行前先记录 dirty 的版本行，全部语句成功后再清除，
+// 中途失败时版本保持 dirty，需要人工修复后用 Force 标记版本
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+	opts       Options
+}
+
+// New 创建迁移器，migrations 需按版本升序（Load 的返回值）
+func New(db *gorm.DB, migrations []Migration, opts Options) *Migrator {
+	if opts.LockTimeout <= 0 {
+		opts.LockTimeout = time.Minute
+	}
+	if opts.Owner == "" {
+		host, _ := os.Hostname()
+		opts.Owner = fmt.Sprintf("%s:%d", host, os.Getpid())
+	}
+	return &Migrator{db: db, migrations: migrations, opts: opts}
+}
+
+// Latest 本程序包含的最新版本，没有迁移时为 0
+func (m *Migrator) Latest() uint64 {
+	if len(m.migrations) == 0 {
+		return 0
+	}
+	return m.migrations[len(m.migrations)-1].Version
+}
+
+// Up 执行全部未执行的迁移，返回本次执行的迁移
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	return m.To(ctx, m.Latest())
+}
+
+// Down 按版本倒序回滚最近执行的 steps 个迁移，返回本次回滚的迁移
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	var done []Migration
+	err := m.locked(ctx, func(db *gorm.DB) error {
+		applied, err := m.applied(db)
+		if err != nil {
+			return err
+		}
+		target := uint64(0)
+		if steps < len(applied) {
+			target = applied[len(applied)-steps-1].Version
+		}
+		done, err = m.migrate(db, applied, target)
+		return err
+	})
+	return done, err
+}
+
+// To 执行或回滚迁移，使数据库停在 version；version 为 0 表示回滚全部迁移
+func (m *Migrator) To(ctx context.Context, version uint64) ([]Migration, error) {
+	if version != 0 {
+		if _, ok := m.find(version); !ok {
+			return nil, fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+		}
+	}
+
+	var done []Migration
+	err := m.locked(ctx, func(db *gorm.DB) error {
+		applied, err := m.applied(db)
+		if err != nil {
+			return err
+		}
+		done, err = m.migrate(db, applied, version)
+		return err
+	})
+	return done, err
+}
+
+// migrate 先按版本倒序回滚高于 target 的迁移，再按版本升序执行不高于 target 的未执行迁移
+func (m *Migrator) migrate(db *gorm.DB, applied []schemaMigration, target uint64) ([]Migration, error) {
+	done := []Migration{}
+	if len(applied) == 0 && target > 0 {
+		if err := m.checkUnversioned(db); err != nil {
+			return done, err
+		}
+	}
+	isApplied := make(map[uint64]bool, len(applied))
+	for _, row := range applied {
+		if row.Dirty {
+			return done, fmt.Errorf("%w: version %d", ErrDirty, row.Version)
+		}
+		if _, ok := m.find(row.Version); !ok {
+			return done, fmt.Errorf("%w: version %d was applied by another build", ErrUnknownVersion, row.Version)
+		}
+		isApplied[row.Version] = true
+	}
+
+	for i := len(applied) - 1; i >= 0 && applied[i].Version > target; i-- {
+		mig, _ := m.find(applied[i].Version)
+		if err := m.revert(db, mig); err != nil {
+			return done, err
+		}
+		done = append(done, mig)
+	}
+	for _, mig := range m.migrations {
+		if mig.Version > target || isApplied[mig.Version] {
+			continue
+		}
+		if err := m.apply(db, mig); err != nil {
+			return done, err
+		}
+		done = append(done, mig)
+	}
+	return done, nil
+}
+
+// apply 执行一个迁移：先写入 dirty 的版本行，全部语句成功后清除 dirty
+func (m *Migrator) apply(db *gorm.DB, mig Migration) error {
+	row := schemaMigration{Version: mig.Version, Name: mig.Name, Checksum: mig.Checksum, Dirty: true, AppliedAt: time.Now()}
+	if err := db.Create(&row).Error; err != nil {
+		return fmt.Errorf("record migration %s: %w", mig, err)
+	}
+	for _, stmt := range statements(mig.Up) {
+		if err := db.Exec(stmt).Error; err != nil {
+			return fmt.Errorf("migration %s: %w", mig, err)
+		}
+	}
+	return db.Model(&row).Update("dirty", false).Error
+}
+
+// revert 回滚一个迁移：先标记 dirty，全部语句成功后删除版本行
+func (m *Migrator) revert(db *gorm.DB, mig Migration) error {
+	if strings.TrimSpace(mig.Down) == "" {
+		return fmt.Errorf("%w: %s", ErrIrreversible, mig)
+	}
+	row := schemaMigration{Version: mig.Version}
+	if err := db.Model(&row).Update("dirty", true).Error; err != nil {
+		return fmt.Errorf("record migration %s: %w", mig, err)
+	}
+	for _, stmt := range statements(mig.Down) {
+		if err := db.Exec(stmt).Error; err != nil {
+			return fmt.Errorf("revert migration %s: %w", mig, err)
+		}
+	}
+	return db.Delete(&row).Error
+}
+
+// Status 返回本程序中全部迁移的状态，以及数据库中有记录但本程序中没有的版本，按版本升序
+func (m *Migrator) Status(ctx context.Context) ([]Status, error) {
+	applied, err := m.appliedIfExists(m.db.WithContext(ctx))
+	if err != nil {
+		return nil, err
+	}
+	rows := make(map[uint64]schemaMigration, len(applied))
+	for _, row := range applied {
+		rows[row.Version] = row
+	}
+
+	statuses := make([]Status, 0, len(m.migrations))
+	for _, mig := range m.migrations {
+		s := Status{Version: mig.Version, Name: mig.Name, State: StatePending}
+		if row, ok := rows[mig.Version]; ok {
+			s.State, s.AppliedAt = rowState(row, mig), &row.AppliedAt
+			delete(rows, mig.Version)
+		}
+		statuses = append(statuses, s)
+	}
+	for _, row := range rows {
+		row := row
+		statuses = append(statuses, Status{Version: row.Version, Name: row.Name, State: StateUnknown, AppliedAt: &row.AppliedAt})
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Version < statuses[j].Version })
+	return statuses, nil
+}
+
+func rowState(row schemaMigration, mig Migration) State {
+	switch {
+	case row.Dirty:
+		return StateDirty
+	case row.Checksum != mig.Checksum:
+		return StateModified
+	default:
+		return StateApplied
+	}
+}
+
+// Verify 检查数据库结构与本程序是否兼容，返回当前版本
+// 要求本程序的迁移全部已执行、没有 dirty 的版本、已执行的迁移内容未被修改，
+// 并且数据库中没有本程序不认识的更高版本（由更新的程序执行，旧程序可能无法正确读写）
+func (m *Migrator) Verify(ctx context.Context) (uint64, error) {
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	current := uint64(0)
+	var pending []string
+	for _, s := range statuses {
+		switch s.State {
+		case StateDirty:
+			return 0, fmt.Errorf("%w: version %d", ErrDirty, s.Version)
+		case StateModified:
+			return 0, fmt.Errorf("%w: %d_%s", ErrChecksumMismatch, s.Version, s.Name)
+		case StateUnknown:
+			if s.Version > m.Latest() {
+				return 0, fmt.Errorf("%w: database is at version %d, this build supports up to %d", ErrSchemaTooNew, s.Version, m.Latest())
+			}
+			return 0, fmt.Errorf("%w: version %d was applied by another build", ErrUnknownVersion, s.Version)
+		case StatePending:
+			pending = append(pending, fmt.Sprintf("%d_%s", s.Version, s.Name))
+			continue
+		}
+		current = s.Version
+	}
+	if len(pending) > 0 {
+		if current == 0 {
+			if err := m.checkUnversioned(m.db.WithContext(ctx)); err != nil {
+				return 0, err
+			}
+		}
+		return current, fmt.Errorf("%w: pending %s", ErrSchemaOutdated, strings.Join(pending, ", "))
+	}
+	return current, nil
+}
+
+// checkUnversioned 在没有任何迁移记录时检查迁移要创建的表是否已经存在
+// 这样的库由 AutoMigrate 或旧版 init.sql 创建，直接执行迁移时 CREATE TABLE IF NOT EXISTS 会跳过已有的表，
+// 缺少的列不会补上却记录为已执行；需要人工补齐表结构后用 Force 建立基线
+func (m *Migrator) checkUnversioned(db *gorm.DB) error {
+	var existing []string
+	for _, mig := range m.migrations {
+		for _, table := range createdTables(mig.Up) {
+			if db.Migrator().HasTable(table) {
+				existing = append(existing, table)
+			}
+		}
+	}
+	if len(existing) > 0 {
+		return fmt.Errorf("%w: found %s", ErrUnversioned, strings.Join(existing, ", "))
+	}
+	return nil
+}
+
+// Force 将记录的版本设置为 version，不执行任何迁移
+// 用于迁移中途失败、人工修复数据库之后，或者为已有的数据库建立基线：
+// 不高于 version 的迁移记为已执行（清除 dirty），高于 version 的记录删除
+func (m *Migrator) Force(ctx context.Context, version uint64) error {
+	if version != 0 {
+		if _, ok := m.find(version); !ok {
+			return fmt.Errorf("%w: %d", ErrUnknownVersion, version)
+		}
+	}
+
+	return m.locked(ctx, func(db *gorm.DB) error {
+		return db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Where("1 = 1").Delete(&schemaMigration{}).Error; err != nil {
+				return err
+			}
+			for _, mig := range m.migrations {
+				if mig.Version > version {
+					break
+				}
+				row := schemaMigration{Version: mig.Version, Name: mig.Name, Checksum: mig.Checksum, AppliedAt: time.Now()}
+				if err := tx.Create(&row).Error; err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	})
+}
+
+// Unlock 强制释放迁移锁，用于持有锁的进程异常退出之后
+func (m *Migrator) Unlock(ctx context.Context) error {
+	db := m.db.WithContext(ctx)
+	if err := m.ensureTables(db); err != nil {
+		return err
+	}
+	return db.Where("id = ?", 1).Delete(&schemaLock{}).Error
+}
+
+// locked 在迁移锁内执行 fn
+func (m *Migrator) locked(ctx context.Context, fn func(db *gorm.DB) error) error {
+	db := m.db.WithContext(ctx)
+	if err := m.ensureTables(db); err != nil {
+		return err
+	}
+	if err := m.lock(ctx, db); err != nil {
+		return err
+	}
+	defer func() {
+		// 使用新的上下文，保证 ctx 取消后仍然释放锁
+		m.db.Where("id = ? AND owner = ?", 1, m.opts.Owner).Delete(&schemaLock{})
+	}()
+	return fn(db)
+}
+
+// lock 写入锁表中唯一的一行，已存在时等待，超过 LockTimeout 返回 ErrLocked
+func (m *Migrator) lock(ctx context.Context, db *gorm.DB) error {
+	deadline := time.Now().Add(m.opts.LockTimeout)
+	for {
+		err := db.Create(&schemaLock{ID: 1, Owner: m.opts.Owner, LockedAt: time.Now()}).Error
+		if err == nil {
+			return nil
+		}
+
+		var held schemaLock
+		if findErr := db.Where("id = ?", 1).Take(&held).Error; findErr != nil && !errors.Is(findErr, gorm.ErrRecordNotFound) {
+			return fmt.Errorf("acquire migration lock: %w", err)
+		}
+		if time.Now().After(deadline) {
+			if held.Owner == "" {
+				return fmt.Errorf("acquire migration lock: %w", err)
+			}
+			return fmt.Errorf("%w: held by %s since %s, run migrate unlock if that process is gone",
+				ErrLocked, held.Owner, held.LockedAt.Format(time.RFC3339))
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(lockPollInterval):
+		}
+	}
+}
+
+func (m *Migrator) ensureTables(db *gorm.DB) error {
+	for _, stmt := range bookkeeping {
+		if err := db.Exec(stmt).Error; err != nil {
+			return fmt.Errorf("create migration tables: %w", err)
+		}
+	}
+	return nil
+}
+
+// applied 按版本升序返回已记录的迁移
+func (m *Migrator) applied(db *gorm.DB) ([]schemaMigration, error) {
+	var rows []schemaMigration
+	if err := db.Order("version ASC").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// appliedIfExists 与 applied 相同，但 schema_migrations 表不存在时（从未迁移过）返回空，不创建表
+func (m *Migrator) appliedIfExists(db *gorm.DB) ([]schemaMigration, error) {
+	if !db.Migrator().HasTable(&schemaMigration{}) {
+		return nil, nil
+	}
+	return m.applied(db)
+}
+
+func (m *Migrator) find(version uint64) (Migration, bool) {
+	for _, mig := range m.migrations {
+		if mig.Version == version {
+			return mig, true
+		}
+	}
+	return Migration{}, false
+}
@@ -0,0 +1,313 @@
+package migration
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// sqliteFiles 测试用的 SQLite 迁移
+var sqliteFiles = fstest.MapFS{
+	"1_create_accounts.up.sql":   {Data: []byte("-- 账户表\nCREATE TABLE accounts (\n    id INTEGER PRIMARY KEY\n);\n")},
+	"1_create_accounts.down.sql": {Data: []byte("DROP TABLE accounts;\n")},
+	"2_add_name.up.sql":          {Data: []byte("ALTER TABLE accounts ADD COLUMN name TEXT;\nCREATE INDEX idx_accounts_name ON accounts (name);\n")},
+	"2_add_name.down.sql":        {Data: []byte("DROP INDEX idx_accounts_name;\nALTER TABLE accounts DROP COLUMN name;\n")},
+	"10_create_logs.up.sql":      {Data: []byte("CREATE TABLE logs (id INTEGER PRIMARY KEY);")},
+	"10_create_logs.down.sql":    {Data: []byte("DROP TABLE logs;")},
+}
+
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(":memory:"), &gorm.Config{
+		Logger:         logger.Default.LogMode(logger.Silent),
+		TranslateError: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB, err := db.DB()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	return db
+}
+
+func load(t *testing.T, fsys fstest.MapFS) []Migration {
+	t.Helper()
+	migrations, err := Load(fsys)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return migrations
+}
+
+func versions(migrations []Migration) string {
+	vs := make([]string, len(migrations))
+	for i, m := range migrations {
+		vs[i] = fmt.Sprint(m.Version)
+	}
+	return strings.Join(vs, ",")
+}
+
+func TestLoad(t *testing.T) {
+	migrations := load(t, sqliteFiles)
+	if got := versions(migrations); got != "1,2,10" {
+		t.Fatalf("expected versions sorted numerically, got %s", got)
+	}
+	if migrations[0].Name != "create_accounts" || migrations[0].Down == "" || len(migrations[0].Checksum) != 64 {
+		t.Fatalf("unexpected migration %+v", migrations[0])
+	}
+
+	invalid := map[string]fstest.MapFS{
+		"bad name":      {"1-create.up.sql": {Data: []byte("SELECT 1;")}},
+		"zero version":  {"0_create.up.sql": {Data: []byte("SELECT 1;")}},
+		"missing up":    {"1_create.down.sql": {Data: []byte("SELECT 1;")}},
+		"name conflict": {"1_a.up.sql": {Data: []byte("SELECT 1;")}, "1_b.down.sql": {Data: []byte("SELECT 1;")}},
+	}
+	for name, fsys := range invalid {
+		if _, err := Load(fsys); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestMySQLMigrations(t *testing.T) {
+	migrations, err := MySQL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("no mysql migrations embedded")
+	}
+	for i, m := range migrations {
+		if m.Version != uint64(i+1) {
+			t.Fatalf("expected contiguous versions, got %d at position %d", m.Version, i)
+		}
+		if m.Down == "" {
+			t.Errorf("%s has no down file", m)
+		}
+		for _, stmt := range append(statements(m.Up), statements(m.Down)...) {
+			if !strings.HasSuffix(stmt, ";") {
+				t.Errorf("%s: statement not terminated: %q", m, stmt)
+			}
+		}
+	}
+}
+
+func TestStatements(t *testing.T) {
+	got := statements("-- comment\nCREATE TABLE a (\n  id INT -- trailing\n);\n\nDROP TABLE b;\nSELECT 1")
+	want := []string{"CREATE TABLE a (\n  id INT -- trailing\n);", "DROP TABLE b;", "SELECT 1"}
+	if fmt.Sprintf("%q", got) != fmt.Sprintf("%q", want) {
+		t.Fatalf("expected %q, got %q", want, got)
+	}
+}
+
+func TestUpDownTo(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	m := New(db, load(t, sqliteFiles), Options{})
+
+	done, err := m.Up(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if versions(done) != "1,2,10" || !db.Migrator().HasColumn("accounts", "name") || !db.Migrator().HasTable("logs") {
+		t.Fatalf("up did not apply all migrations: %s", versions(done))
+	}
+	if current, err := m.Verify(ctx); err != nil || current != 10 {
+		t.Fatalf("expected version 10, got %d, %v", current, err)
+	}
+	if done, _ := m.Up(ctx); len(done) != 0 {
+		t.Fatalf("second up applied %s", versions(done))
+	}
+
+	if done, err = m.Down(ctx, 1); err != nil || versions(done) != "10" {
+		t.Fatalf("down 1: %s, %v", versions(done), err)
+	}
+	if db.Migrator().HasTable("logs") {
+		t.Fatal("down did not revert the latest migration")
+	}
+	if _, err := m.Verify(ctx); !errors.Is(err, ErrSchemaOutdated) {
+		t.Fatalf("expected ErrSchemaOutdated, got %v", err)
+	}
+
+	if done, err = m.To(ctx, 1); err != nil || versions(done) != "2" {
+		t.Fatalf("to 1: %s, %v", versions(done), err)
+	}
+	if done, err = m.To(ctx, 10); err != nil || versions(done) != "2,10" {
+		t.Fatalf("to 10: %s, %v", versions(done), err)
+	}
+	if done, err = m.To(ctx, 0); err != nil || versions(done) != "10,2,1" {
+		t.Fatalf("to 0: %s, %v", versions(done), err)
+	}
+	if db.Migrator().HasTable("accounts") {
+		t.Fatal("to 0 did not revert all migrations")
+	}
+	if _, err := m.To(ctx, 3); !errors.Is(err, ErrUnknownVersion) {
+		t.Fatalf("expected ErrUnknownVersion, got %v", err)
+	}
+}
+
+func TestStatus(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	m := New(db, load(t, sqliteFiles), Options{})
+	if _, err := m.To(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+
+	statuses, err := m.Status(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, s := range statuses {
+		got = append(got, fmt.Sprintf("%d:%s", s.Version, s.State))
+		if (s.State == StateApplied) != (s.AppliedAt != nil) {
+			t.Fatalf("unexpected applied time for %+v", s)
+		}
+	}
+	if strings.Join(got, " ") != "1:applied 2:applied 10:pending" {
+		t.Fatalf("unexpected status %v", got)
+	}
+}
+
+func TestVerify(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	migrations := load(t, sqliteFiles)
+	m := New(db, migrations, Options{})
+
+	if _, err := m.Verify(ctx); !errors.Is(err, ErrSchemaOutdated) {
+		t.Fatalf("expected ErrSchemaOutdated on an empty database, got %v", err)
+	}
+	if db.Migrator().HasTable("schema_migrations") {
+		t.Fatal("verify should not create the migration tables")
+	}
+	if _, err := m.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	older := New(db, migrations[:2], Options{})
+	if _, err := older.Verify(ctx); !errors.Is(err, ErrSchemaTooNew) {
+		t.Fatalf("expected ErrSchemaTooNew, got %v", err)
+	}
+
+	modified := append([]Migration(nil), migrations...)
+	modified[1].Checksum = "changed"
+	if _, err := New(db, modified, Options{}).Verify(ctx); !errors.Is(err, ErrChecksumMismatch) {
+		t.Fatalf("expected ErrChecksumMismatch, got %v", err)
+	}
+}
+
+func TestUnversionedSchema(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	// 模拟 AutoMigrate 建的库：表已存在但缺少迁移 2 添加的列，也没有迁移记录
+	if err := db.Exec("CREATE TABLE accounts (id INTEGER PRIMARY KEY)").Error; err != nil {
+		t.Fatal(err)
+	}
+	m := New(db, load(t, sqliteFiles), Options{})
+
+	if _, err := m.Verify(ctx); !errors.Is(err, ErrUnversioned) {
+		t.Fatalf("expected verify to report ErrUnversioned, got %v", err)
+	}
+	done, err := m.Up(ctx)
+	if !errors.Is(err, ErrUnversioned) || !strings.Contains(err.Error(), "accounts") {
+		t.Fatalf("expected ErrUnversioned naming the existing table, got %v", err)
+	}
+	if len(done) != 0 || db.Migrator().HasColumn("accounts", "name") {
+		t.Fatalf("up changed an unversioned schema: %s", versions(done))
+	}
+	if statuses, _ := m.Status(ctx); statuses[0].State != StatePending {
+		t.Fatalf("up recorded a version on an unversioned schema: %+v", statuses)
+	}
+
+	// 人工补齐表结构后以实际所处的版本为基线，之后正常迁移
+	if err := db.Exec("ALTER TABLE accounts ADD COLUMN name TEXT").Error; err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Force(ctx, 2); err != nil {
+		t.Fatal(err)
+	}
+	if done, err = m.Up(ctx); err != nil || versions(done) != "10" {
+		t.Fatalf("up after baseline: %s, %v", versions(done), err)
+	}
+}
+
+func TestFailedMigrationIsDirty(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	files := fstest.MapFS{
+		"1_create_accounts.up.sql": sqliteFiles["1_create_accounts.up.sql"],
+		"2_broken.up.sql":          {Data: []byte("CREATE TABLE broken (id INTEGER);\nNOT VALID SQL;\n")},
+	}
+	m := New(db, load(t, files), Options{})
+
+	if _, err := m.Up(ctx); err == nil {
+		t.Fatal("expected the broken migration to fail")
+	}
+	if _, err := m.Verify(ctx); !errors.Is(err, ErrDirty) {
+		t.Fatalf("expected ErrDirty, got %v", err)
+	}
+	if _, err := m.Up(ctx); !errors.Is(err, ErrDirty) {
+		t.Fatalf("expected up to refuse a dirty schema, got %v", err)
+	}
+
+	// 人工清理后标记回版本 1
+	db.Exec("DROP TABLE broken")
+	if err := m.Force(ctx, 1); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Verify(ctx); !errors.Is(err, ErrSchemaOutdated) {
+		t.Fatalf("expected ErrSchemaOutdated after force, got %v", err)
+	}
+	if _, err := m.Down(ctx, 1); !errors.Is(err, ErrIrreversible) {
+		t.Fatalf("expected ErrIrreversible without a down file, got %v", err)
+	}
+}
+
+func TestLock(t *testing.T) {
+	ctx := context.Background()
+	db := openTestDB(t)
+	migrations := load(t, sqliteFiles)
+
+	holder := New(db, migrations, Options{Owner: "holder"})
+	if err := holder.ensureTables(db); err != nil {
+		t.Fatal(err)
+	}
+	if err := holder.lock(ctx, db); err != nil {
+		t.Fatal(err)
+	}
+
+	waiter := New(db, migrations, Options{Owner: "waiter", LockTimeout: 100 * time.Millisecond})
+	_, err := waiter.Up(ctx)
+	if !errors.Is(err, ErrLocked) || !strings.Contains(err.Error(), "holder") {
+		t.Fatalf("expected ErrLocked naming the holder, got %v", err)
+	}
+	if db.Migrator().HasTable("accounts") {
+		t.Fatal("migration ran without the lock")
+	}
+
+	if err := waiter.Unlock(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := waiter.Up(ctx); err != nil {
+		t.Fatal(err)
+	}
+	var count int64
+	db.Table("schema_lock").Count(&count)
+	if count != 0 {
+		t.Fatal("lock not released after migrating")
+	}
+}
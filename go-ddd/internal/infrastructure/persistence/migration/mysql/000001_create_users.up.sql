@@ -0,0 +1,50 @@
+-- =====================================================
+-- 用户表
+-- =====================================================
+CREATE TABLE IF NOT EXISTS users (
+    -- 主键：数据库自增ID
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+
+    -- 业务唯一标识：UUID
+    uuid VARCHAR(36) NOT NULL COMMENT '业务唯一标识UUID',
+
+    -- 用户基本信息
+    username VARCHAR(50) NOT NULL COMMENT '用户名',
+    email VARCHAR(100) NOT NULL COMMENT '邮箱',
+    password_hash VARCHAR(255) NOT NULL COMMENT '密码哈希值',
+    nickname VARCHAR(50) DEFAULT '' COMMENT '昵称',
+    avatar VARCHAR(255) DEFAULT '' COMMENT '头像URL',
+
+    -- 状态和角色
+    status TINYINT NOT NULL DEFAULT 1 COMMENT '状态: 1-激活 2-未激活（管理员停用） 3-禁用 4-等待验证邮箱',
+    role VARCHAR(20) NOT NULL DEFAULT 'user' COMMENT '角色: user-普通用户 admin-管理员',
+
+    -- 时间戳
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间',
+    deleted_at TIMESTAMP NULL COMMENT '删除时间（软删除）',
+    email_verified_at TIMESTAMP NULL COMMENT '邮箱验证时间（NULL 表示未验证）',
+    locked_until TIMESTAMP NULL COMMENT '登录锁定截止时间（NULL 表示未锁定）',
+
+    -- 两步验证
+    totp_secret VARCHAR(255) NULL COMMENT 'TOTP 密钥（AES-GCM 加密）',
+    totp_last_step BIGINT NOT NULL DEFAULT 0 COMMENT '最近一次通过校验的 TOTP 时间步（防止验证码重放）',
+    mfa_enabled TINYINT(1) NOT NULL DEFAULT 0 COMMENT '是否启用两步验证',
+    recovery_codes TEXT NULL COMMENT '未使用的恢复码哈希（JSON 数组）',
+    password_history TEXT NULL COMMENT '最近使用过的旧密码哈希（JSON 数组）',
+    erasure_requested_at TIMESTAMP NULL COMMENT '申请删除账户时间',
+    erasure_scheduled_at TIMESTAMP NULL COMMENT '擦除个人数据时间',
+
+    -- 唯一索引
+    UNIQUE INDEX uk_uuid (uuid),
+    UNIQUE INDEX uk_username (username),
+    UNIQUE INDEX uk_email (email),
+
+    -- 普通索引
+    INDEX idx_status (status),
+    INDEX idx_role (role),
+    INDEX idx_created_at (created_at),
+    INDEX idx_deleted_at (deleted_at),
+    INDEX idx_erasure_scheduled_at (erasure_scheduled_at)
+
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='用户表';
@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS aggregate_snapshots;
+DROP TABLE IF EXISTS domain_events;
+DROP TABLE IF EXISTS outbox_events;
@@ -0,0 +1,51 @@
+-- =====================================================
+-- 发件箱表（Transactional Outbox）
+-- 领域事件与聚合在同一事务中写入，由中继异步投递
+-- =====================================================
+CREATE TABLE IF NOT EXISTS outbox_events (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID（投递顺序）',
+    event_id VARCHAR(36) NOT NULL COMMENT '事件唯一标识，消费方据此去重',
+    aggregate_type VARCHAR(50) NOT NULL COMMENT '聚合类型',
+    aggregate_id VARCHAR(36) NOT NULL COMMENT '聚合ID（UUID）',
+    event_name VARCHAR(100) NOT NULL COMMENT '事件名称',
+    payload JSON NOT NULL COMMENT '事件内容',
+    occurred_at DATETIME(3) NOT NULL COMMENT '事件发生时间',
+    attempts INT NOT NULL DEFAULT 0 COMMENT '投递次数',
+    last_error VARCHAR(500) DEFAULT '' COMMENT '最近一次投递错误',
+    processed_at DATETIME(3) NULL COMMENT '投递成功时间，NULL 表示待投递',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+
+    UNIQUE INDEX uk_event_id (event_id),
+    INDEX idx_aggregate_id (aggregate_id),
+    INDEX idx_processed_at (processed_at)
+
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='领域事件发件箱';
+
+-- =====================================================
+-- 事件存储表（Event Sourcing）
+-- event_sourcing.enabled 为 true 时，用户聚合以此表为事实来源
+-- =====================================================
+CREATE TABLE IF NOT EXISTS domain_events (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    aggregate_id VARCHAR(36) NOT NULL COMMENT '聚合ID（UUID）',
+    version INT NOT NULL COMMENT '聚合版本，从1开始连续递增',
+    event_name VARCHAR(100) NOT NULL COMMENT '事件名称',
+    payload JSON NOT NULL COMMENT '事件内容',
+    occurred_at DATETIME(3) NOT NULL COMMENT '事件发生时间',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+
+    -- 乐观并发控制：同一聚合的同一版本只能写入一次
+    UNIQUE INDEX uk_aggregate_version (aggregate_id, version)
+
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='领域事件存储';
+
+-- =====================================================
+-- 聚合快照表
+-- =====================================================
+CREATE TABLE IF NOT EXISTS aggregate_snapshots (
+    aggregate_id VARCHAR(36) NOT NULL PRIMARY KEY COMMENT '聚合ID（UUID）',
+    version INT NOT NULL COMMENT '快照对应的聚合版本',
+    state JSON NOT NULL COMMENT '聚合状态',
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP COMMENT '更新时间'
+
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='聚合快照';
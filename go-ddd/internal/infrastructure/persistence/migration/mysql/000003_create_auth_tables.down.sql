@@ -0,0 +1,3 @@
+DROP TABLE IF EXISTS login_attempts;
+DROP TABLE IF EXISTS revoked_tokens;
+DROP TABLE IF EXISTS refresh_tokens;
@@ -0,0 +1,45 @@
+-- =====================================================
+-- 刷新令牌表
+-- 只保存令牌哈希；family_id 标识同一次登录产生的令牌，用于重用检测
+-- =====================================================
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id BIGINT UNSIGNED AUTO_INCREMENT PRIMARY KEY COMMENT '主键ID',
+    user_id BIGINT UNSIGNED NOT NULL COMMENT '用户ID',
+    family_id VARCHAR(36) NOT NULL COMMENT '令牌家族',
+    token_hash CHAR(64) NOT NULL COMMENT '令牌SHA-256哈希',
+    expires_at DATETIME(3) NOT NULL COMMENT '过期时间',
+    revoked_at DATETIME(3) NULL COMMENT '吊销或轮换时间',
+    replaced_by BIGINT UNSIGNED NOT NULL DEFAULT 0 COMMENT '轮换后的新令牌ID',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '创建时间',
+
+    UNIQUE INDEX uk_token_hash (token_hash),
+    INDEX idx_user_id (user_id),
+    INDEX idx_family_id (family_id),
+    INDEX idx_expires_at (expires_at)
+
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='刷新令牌';
+
+-- =====================================================
+-- 已吊销的访问令牌
+-- =====================================================
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    jti VARCHAR(36) NOT NULL PRIMARY KEY COMMENT '访问令牌ID',
+    expires_at DATETIME(3) NOT NULL COMMENT '令牌原过期时间，之后可清理',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP COMMENT '吊销时间',
+
+    INDEX idx_expires_at (expires_at)
+
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='已吊销的访问令牌';
+
+-- =====================================================
+-- 登录失败计数表
+-- 按用户名（user:<username>）和客户端IP（ip:<ip>）分别计数，用于退避和锁定
+-- =====================================================
+CREATE TABLE IF NOT EXISTS login_attempts (
+    attempt_key VARCHAR(191) NOT NULL PRIMARY KEY COMMENT '计数键',
+    failures INT NOT NULL DEFAULT 0 COMMENT '窗口内连续失败次数',
+    last_failed_at DATETIME(3) NOT NULL COMMENT '最近一次失败时间',
+
+    INDEX idx_last_failed_at (last_failed_at)
+
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='登录失败计数';
@@ -0,0 +1,27 @@
+-- =====================================================
+-- 用户读模型表（CQRS 查询端）
+-- 由用户投影订阅领域事件维护，用户查询只读此表；启动时默认从 users 全量重建
+-- =====================================================
+CREATE TABLE IF NOT EXISTS user_views (
+    id BIGINT UNSIGNED NOT NULL PRIMARY KEY COMMENT '用户ID（与 users.id 相同）',
+    uuid VARCHAR(36) NOT NULL COMMENT '用户UUID',
+    username VARCHAR(50) NOT NULL COMMENT '用户名',
+    email VARCHAR(100) NOT NULL COMMENT '邮箱',
+    nickname VARCHAR(50) DEFAULT '' COMMENT '昵称',
+    avatar VARCHAR(255) DEFAULT '' COMMENT '头像URL',
+    status TINYINT NOT NULL COMMENT '状态: 1-激活 2-未激活（管理员停用） 3-禁用 4-等待验证邮箱',
+    role VARCHAR(20) NOT NULL COMMENT '角色',
+    created_at DATETIME(3) NOT NULL COMMENT '注册时间',
+    updated_at DATETIME(3) NOT NULL COMMENT '更新时间',
+    email_verified TINYINT(1) NOT NULL DEFAULT 0 COMMENT '邮箱是否已验证',
+    locked_until DATETIME(3) NULL COMMENT '登录锁定截止时间',
+    mfa_enabled TINYINT(1) NOT NULL DEFAULT 0 COMMENT '是否启用两步验证',
+
+    UNIQUE INDEX uk_uuid (uuid),
+    INDEX idx_username (username),
+    INDEX idx_email (email),
+    INDEX idx_status (status),
+    INDEX idx_role (role),
+    INDEX idx_created_at (created_at)
+
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='用户读模型';
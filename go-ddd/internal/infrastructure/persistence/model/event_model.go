@@ -0,0 +1,36 @@
+package model
+
+import (
+	"time"
+)
+
+// EventModel 事件存储数据库模型
+// (aggregate_id, version) 唯一索引是乐观并发控制的最后一道防线：
+// 两个写入者基于同一版本追加时，后提交的一方会触发唯一键冲突
+type EventModel struct {
+	ID          uint64    `gorm:"primaryKey;autoIncrement"`
+	AggregateID string    `gorm:"type:varchar(36);not null;uniqueIndex:uk_aggregate_version,priority:1"`
+	Version     int       `gorm:"not null;uniqueIndex:uk_aggregate_version,priority:2"`
+	EventName   string    `gorm:"type:varchar(100);not null"`
+	Payload     string    `gorm:"type:json;not null"`
+	OccurredAt  time.Time `gorm:"not null"`
+	CreatedAt   time.Time `gorm:"autoCreateTime"`
+}
+
+// TableName 指定表名
+func (EventModel) TableName() string {
+	return "domain_events"
+}
+
+// SnapshotModel 聚合快照数据库模型，每个聚合只保留最新一份
+type SnapshotModel struct {
+	AggregateID string    `gorm:"primaryKey;type:varchar(36)"`
+	Version     int       `gorm:"not null"`
+	State       string    `gorm:"type:json;not null"`
+	UpdatedAt   time.Time `gorm:"autoUpdateTime"`
+}
+
+// TableName 指定表名
+func (SnapshotModel) TableName() string {
+	return "aggregate_snapshots"
+}
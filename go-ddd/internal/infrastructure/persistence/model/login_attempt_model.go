@@ -0,0 +1,28 @@
+package model
+
+import (
+	"time"
+
+	"yiwen/go-ddd/internal/domain/entity"
+)
+
+// LoginAttemptModel 登录失败计数数据库模型
+type LoginAttemptModel struct {
+	AttemptKey   string    `gorm:"primaryKey;type:varchar(191)"`
+	Failures     int       `gorm:"not null;default:0"`
+	LastFailedAt time.Time `gorm:"index;not null"`
+}
+
+// TableName 指定表名
+func (LoginAttemptModel) TableName() string {
+	return "login_attempts"
+}
+
+// ToEntity 将数据库模型转换为领域实体
+func (m *LoginAttemptModel) ToEntity() *entity.LoginAttempt {
+	return &entity.LoginAttempt{
+		Key:          m.AttemptKey,
+		Failures:     m.Failures,
+		LastFailedAt: m.LastFailedAt,
+	}
+}
@@ -0,0 +1,15 @@
+package model
+
+// AllModels 返回全部数据库模型，用于 SQLite 自动建表（MySQL 使用 migration 包中的版本化迁移）
+func AllModels() []interface{} {
+	return []interface{}{
+		&UserModel{},
+		&UserViewModel{},
+		&OutboxModel{},
+		&EventModel{},
+		&SnapshotModel{},
+		&RefreshTokenModel{},
+		&RevokedTokenModel{},
+		&LoginAttemptModel{},
+	}
+}
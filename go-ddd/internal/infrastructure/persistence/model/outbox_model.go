@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+)
+
+// OutboxModel 发件箱（transactional outbox）数据库模型
+// 领域事件与聚合在同一个事务中写入此表，保证"数据已保存则事件一定不会丢"
+// 由中继（relay）异步读取未处理的记录并投递，投递成功后标记 ProcessedAt
+type OutboxModel struct {
+	ID            uint64     `gorm:"primaryKey;autoIncrement"`
+	EventID       string     `gorm:"type:varchar(36);uniqueIndex;not null"`
+	AggregateType string     `gorm:"type:varchar(50);not null"`
+	AggregateID   string     `gorm:"type:varchar(36);index;not null"`
+	EventName     string     `gorm:"type:varchar(100);not null"`
+	Payload       string     `gorm:"type:json;not null"`
+	OccurredAt    time.Time  `gorm:"not null"`
+	Attempts      int        `gorm:"not null;default:0"`
+	LastError     string     `gorm:"type:varchar(500)"`
+	ProcessedAt   *time.Time `gorm:"index"`
+	CreatedAt     time.Time  `gorm:"autoCreateTime"`
+}
+
+// TableName 指定表名
+func (OutboxModel) TableName() string {
+	return "outbox_events"
+}
@@ -0,0 +1,64 @@
+package model
+
+import (
+	"time"
+
+	"yiwen/go-ddd/internal/domain/entity"
+)
+
+// RefreshTokenModel 刷新令牌数据库模型
+type RefreshTokenModel struct {
+	ID         uint64    `gorm:"primaryKey;autoIncrement"`
+	UserID     uint64    `gorm:"index;not null"`
+	FamilyID   string    `gorm:"type:varchar(36);index;not null"`
+	TokenHash  string    `gorm:"type:char(64);uniqueIndex;not null"`
+	ExpiresAt  time.Time `gorm:"index;not null"`
+	RevokedAt  *time.Time
+	ReplacedBy uint64    `gorm:"not null;default:0"`
+	CreatedAt  time.Time `gorm:"autoCreateTime"`
+}
+
+// TableName 指定表名
+func (RefreshTokenModel) TableName() string {
+	return "refresh_tokens"
+}
+
+// ToEntity 将数据库模型转换为领域实体
+func (m *RefreshTokenModel) ToEntity() *entity.RefreshToken {
+	return &entity.RefreshToken{
+		ID:         m.ID,
+		UserID:     m.UserID,
+		FamilyID:   m.FamilyID,
+		TokenHash:  m.TokenHash,
+		ExpiresAt:  m.ExpiresAt,
+		RevokedAt:  m.RevokedAt,
+		ReplacedBy: m.ReplacedBy,
+		CreatedAt:  m.CreatedAt,
+	}
+}
+
+// RefreshTokenFromEntity 从领域实体创建数据库模型
+func RefreshTokenFromEntity(t *entity.RefreshToken) *RefreshTokenModel {
+	return &RefreshTokenModel{
+		ID:         t.ID,
+		UserID:     t.UserID,
+		FamilyID:   t.FamilyID,
+		TokenHash:  t.TokenHash,
+		ExpiresAt:  t.ExpiresAt,
+		RevokedAt:  t.RevokedAt,
+		ReplacedBy: t.ReplacedBy,
+		CreatedAt:  t.CreatedAt,
+	}
+}
+
+// RevokedTokenModel 已吊销的访问令牌
+type RevokedTokenModel struct {
+	JTI       string    `gorm:"primaryKey;type:varchar(36)"`
+	ExpiresAt time.Time `gorm:"index;not null"`
+	CreatedAt time.Time `gorm:"autoCreateTime"`
+}
+
+// TableName 指定表名
+func (RevokedTokenModel) TableName() string {
+	return "revoked_tokens"
+}
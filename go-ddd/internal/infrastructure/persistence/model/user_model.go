@@ -27,6 +27,19 @@ type UserModel struct {
 	CreatedAt    time.Time      `gorm:"autoCreateTime"`
 	UpdatedAt    time.Time      `gorm:"autoUpdateTime"`
 	DeletedAt    gorm.DeletedAt `gorm:"index"`
+
+	EmailVerifiedAt *time.Time
+	LockedUntil     *time.Time
+
+	TOTPSecret    string   `gorm:"type:varchar(255)"`  // AES-GCM 加密后的 TOTP 密钥
+	TOTPLastStep  int64    `gorm:"not null;default:0"` // 最近一次通过校验的验证码时间步
+	MFAEnabled    bool     `gorm:"not null;default:false"`
+	RecoveryCodes []string `gorm:"type:text;serializer:json"` // 未使用的恢复码哈希
+
+	PasswordHistory []string `gorm:"type:text;serializer:json"` // 最近使用过的旧密码哈希
+
+	ErasureRequestedAt *time.Time
+	ErasureScheduledAt *time.Time `gorm:"index"` // 申请删除账户后擦除个人数据的时间
 }
 
 // TableName 指定表名
@@ -39,6 +52,12 @@ func (m *UserModel) ToEntity() *entity.User {
 	email, _ := valueobject.NewEmail(m.Email)
 	password := valueobject.NewPasswordFromHash(m.PasswordHash)
 
+	var deletedAt *time.Time
+	if m.DeletedAt.Valid {
+		t := m.DeletedAt.Time
+		deletedAt = &t
+	}
+
 	return &entity.User{
 		ID:        m.ID,
 		UUID:      m.UUID,
@@ -51,11 +70,30 @@ func (m *UserModel) ToEntity() *entity.User {
 		Role:      entity.UserRole(m.Role),
 		CreatedAt: m.CreatedAt,
 		UpdatedAt: m.UpdatedAt,
+		DeletedAt: deletedAt,
+
+		EmailVerifiedAt: m.EmailVerifiedAt,
+		LockedUntil:     m.LockedUntil,
+
+		TOTPSecret:    valueobject.NewTOTPSecretFromCiphertext(m.TOTPSecret),
+		TOTPLastStep:  m.TOTPLastStep,
+		MFAEnabled:    m.MFAEnabled,
+		RecoveryCodes: m.RecoveryCodes,
+
+		PasswordHistory: m.PasswordHistory,
+
+		ErasureRequestedAt: m.ErasureRequestedAt,
+		ErasureScheduledAt: m.ErasureScheduledAt,
 	}
 }
 
 // FromEntity 从领域实体创建数据库模型
 func FromEntity(user *entity.User) *UserModel {
+	var deletedAt gorm.DeletedAt
+	if user.DeletedAt != nil {
+		deletedAt = gorm.DeletedAt{Time: *user.DeletedAt, Valid: true}
+	}
+
 	return &UserModel{
 		ID:           user.ID,
 		UUID:         user.UUID,
@@ -68,5 +106,19 @@ func FromEntity(user *entity.User) *UserModel {
 		Role:         string(user.Role),
 		CreatedAt:    user.CreatedAt,
 		UpdatedAt:    user.UpdatedAt,
+		DeletedAt:    deletedAt,
+
+		EmailVerifiedAt: user.EmailVerifiedAt,
+		LockedUntil:     user.LockedUntil,
+
+		TOTPSecret:    user.TOTPSecret.Ciphertext(),
+		TOTPLastStep:  user.TOTPLastStep,
+		MFAEnabled:    user.MFAEnabled,
+		RecoveryCodes: user.RecoveryCodes,
+
+		PasswordHistory: user.PasswordHistory,
+
+		ErasureRequestedAt: user.ErasureRequestedAt,
+		ErasureScheduledAt: user.ErasureScheduledAt,
 	}
 }
@@ -0,0 +1,72 @@
+package model
+
+import (
+	"time"
+
+	"yiwen/go-ddd/internal/application/port"
+)
+
+// UserViewModel 用户读模型数据库模型
+// 由投影按用户ID覆盖写入，时间字段直接来自写模型，不由 GORM 自动维护
+// 用户名、邮箱不加唯一索引：投影之间的短暂不一致（例如两个用户交换用户名）不应导致写入失败
+type UserViewModel struct {
+	ID        uint64    `gorm:"primaryKey;autoIncrement:false"`
+	UUID      string    `gorm:"type:varchar(36);uniqueIndex;not null"`
+	Username  string    `gorm:"type:varchar(50);index;not null"`
+	Email     string    `gorm:"type:varchar(100);index;not null"`
+	Nickname  string    `gorm:"type:varchar(50)"`
+	Avatar    string    `gorm:"type:varchar(255)"`
+	Status    int       `gorm:"type:tinyint;index;not null"`
+	Role      string    `gorm:"type:varchar(20);index;not null"`
+	CreatedAt time.Time `gorm:"index;autoCreateTime:false"`
+	UpdatedAt time.Time `gorm:"autoUpdateTime:false"`
+
+	EmailVerified bool `gorm:"not null;default:false"`
+	LockedUntil   *time.Time
+	MFAEnabled    bool `gorm:"not null;default:false"`
+}
+
+// TableName 指定表名
+func (UserViewModel) TableName() string {
+	return "user_views"
+}
+
+// ToView 将数据库模型转换为读模型
+func (m *UserViewModel) ToView() port.UserView {
+	return port.UserView{
+		ID:        m.ID,
+		UUID:      m.UUID,
+		Username:  m.Username,
+		Email:     m.Email,
+		Nickname:  m.Nickname,
+		Avatar:    m.Avatar,
+		Status:    m.Status,
+		Role:      m.Role,
+		CreatedAt: m.CreatedAt,
+		UpdatedAt: m.UpdatedAt,
+
+		EmailVerified: m.EmailVerified,
+		LockedUntil:   m.LockedUntil,
+		MFAEnabled:    m.MFAEnabled,
+	}
+}
+
+// UserViewModelFromView 从读模型创建数据库模型
+func UserViewModelFromView(v port.UserView) *UserViewModel {
+	return &UserViewModel{
+		ID:        v.ID,
+		UUID:      v.UUID,
+		Username:  v.Username,
+		Email:     v.Email,
+		Nickname:  v.Nickname,
+		Avatar:    v.Avatar,
+		Status:    v.Status,
+		Role:      v.Role,
+		CreatedAt: v.CreatedAt,
+		UpdatedAt: v.UpdatedAt,
+
+		EmailVerified: v.EmailVerified,
+		LockedUntil:   v.LockedUntil,
+		MFAEnabled:    v.MFAEnabled,
+	}
+}
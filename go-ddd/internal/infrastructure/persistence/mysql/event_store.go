@@ -0,0 +1,139 @@
+package mysql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"yiwen/go-ddd/internal/domain/event"
+	"yiwen/go-ddd/internal/infrastructure/persistence/model"
+)
+
+// EventStore 基于 GORM 的事件存储实现
+type EventStore struct {
+	db       *gorm.DB
+	registry *event.Registry
+}
+
+// NewEventStore 创建事件存储，registry 用于反序列化事件
+func NewEventStore(db *gorm.DB, registry *event.Registry) event.EventStore {
+	return &EventStore{db: db, registry: registry}
+}
+
+// Append 追加事件（乐观并发控制）
+func (s *EventStore) Append(ctx context.Context, aggregateID string, expectedVersion int, events []event.Event) error {
+	return s.AppendAll(ctx, event.StreamAppend{AggregateID: aggregateID, ExpectedVersion: expectedVersion, Events: events})
+}
+
+// AppendAll 在同一事务中向多个事件流追加事件
+func (s *EventStore) AppendAll(ctx context.Context, streams ...event.StreamAppend) error {
+	err := conn(ctx, s.db).Transaction(func(tx *gorm.DB) error {
+		for _, stream := range streams {
+			if err := appendStream(tx, stream); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+
+	// 并发写入时，检查版本后仍可能被对方抢先插入同一版本
+	if errors.Is(err, gorm.ErrDuplicatedKey) {
+		return event.ErrConcurrencyConflict
+	}
+	return err
+}
+
+func appendStream(tx *gorm.DB, stream event.StreamAppend) error {
+	if len(stream.Events) == 0 {
+		return nil
+	}
+
+	var current int
+	if err := tx.Model(&model.EventModel{}).
+		Where("aggregate_id = ?", stream.AggregateID).
+		Select("COALESCE(MAX(version), 0)").
+		Scan(&current).Error; err != nil {
+		return err
+	}
+	if current != stream.ExpectedVersion {
+		return event.ErrConcurrencyConflict
+	}
+
+	base := stream.ExpectedVersion
+	if stream.Replace {
+		if err := tx.Where("aggregate_id = ?", stream.AggregateID).Delete(&model.EventModel{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("aggregate_id = ?", stream.AggregateID).Delete(&model.SnapshotModel{}).Error; err != nil {
+			return err
+		}
+		base = 0
+	}
+
+	rows := make([]*model.EventModel, 0, len(stream.Events))
+	for i, e := range stream.Events {
+		payload, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		rows = append(rows, &model.EventModel{
+			AggregateID: stream.AggregateID,
+			Version:     base + i + 1,
+			EventName:   e.EventName(),
+			Payload:     string(payload),
+			OccurredAt:  e.OccurredAt(),
+		})
+	}
+	return tx.Create(&rows).Error
+}
+
+// Load 按版本顺序加载事件
+func (s *EventStore) Load(ctx context.Context, aggregateID string, afterVersion int) ([]event.Event, error) {
+	var rows []model.EventModel
+	if err := conn(ctx, s.db).
+		Where("aggregate_id = ? AND version > ?", aggregateID, afterVersion).
+		Order("version ASC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	events := make([]event.Event, 0, len(rows))
+	for _, row := range rows {
+		e, err := s.registry.Decode(row.EventName, []byte(row.Payload))
+		if err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+	return events, nil
+}
+
+// SaveSnapshot 保存（覆盖）聚合快照
+func (s *EventStore) SaveSnapshot(ctx context.Context, snapshot event.Snapshot) error {
+	return conn(ctx, s.db).
+		Clauses(clause.OnConflict{UpdateAll: true}).
+		Create(&model.SnapshotModel{
+			AggregateID: snapshot.AggregateID,
+			Version:     snapshot.Version,
+			State:       string(snapshot.State),
+		}).Error
+}
+
+// LoadSnapshot 加载聚合快照
+func (s *EventStore) LoadSnapshot(ctx context.Context, aggregateID string) (*event.Snapshot, error) {
+	var row model.SnapshotModel
+	if err := conn(ctx, s.db).Where("aggregate_id = ?", aggregateID).First(&row).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return &event.Snapshot{
+		AggregateID: row.AggregateID,
+		Version:     row.Version,
+		State:       []byte(row.State),
+	}, nil
+}
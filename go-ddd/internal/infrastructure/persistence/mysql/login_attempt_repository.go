@@ -0,0 +1,87 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/repository"
+	"yiwen/go-ddd/internal/infrastructure/persistence/model"
+)
+
+// LoginAttemptRepository MySQL登录失败计数仓储实现
+type LoginAttemptRepository struct {
+	db *gorm.DB
+}
+
+// NewLoginAttemptRepository 创建登录失败计数仓储
+func NewLoginAttemptRepository(db *gorm.DB) repository.LoginAttemptRepository {
+	return &LoginAttemptRepository{db: db}
+}
+
+// Get 获取失败记录
+func (r *LoginAttemptRepository) Get(ctx context.Context, key string) (*entity.LoginAttempt, error) {
+	var m model.LoginAttemptModel
+	if err := conn(ctx, r.db).Where("attempt_key = ?", key).First(&m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return &entity.LoginAttempt{Key: key}, nil
+		}
+		return nil, err
+	}
+	return m.ToEntity(), nil
+}
+
+// Reserve 在事务中锁定该键的记录后判断并计数，同一个键的并发预占依次执行
+// 记录不存在时先插入失败次数为 0 的记录，保证总能锁定到已存在的行
+func (r *LoginAttemptRepository) Reserve(ctx context.Context, key string, now time.Time, window time.Duration, allow func(*entity.LoginAttempt) bool) (*entity.LoginAttempt, bool, error) {
+	var attempt *entity.LoginAttempt
+	var reserved bool
+	err := conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		placeholder := &model.LoginAttemptModel{AttemptKey: key, LastFailedAt: now}
+		if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(placeholder).Error; err != nil {
+			return err
+		}
+		var m model.LoginAttemptModel
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("attempt_key = ?", key).First(&m).Error; err != nil {
+			return err
+		}
+		attempt = m.ToEntity()
+		if !allow(attempt) {
+			return nil
+		}
+
+		if attempt.LastFailedAt.Before(now.Add(-window)) {
+			attempt.Failures = 0
+		}
+		attempt.Failures++
+		attempt.LastFailedAt = now
+		reserved = true
+		return tx.Model(&model.LoginAttemptModel{}).Where("attempt_key = ?", key).
+			Updates(map[string]interface{}{"failures": attempt.Failures, "last_failed_at": now}).Error
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return attempt, reserved, nil
+}
+
+// Release 撤销一次预占
+func (r *LoginAttemptRepository) Release(ctx context.Context, key string) error {
+	return conn(ctx, r.db).Model(&model.LoginAttemptModel{}).
+		Where("attempt_key = ? AND failures > 0", key).
+		Update("failures", gorm.Expr("failures - 1")).Error
+}
+
+// Reset 清除失败记录
+func (r *LoginAttemptRepository) Reset(ctx context.Context, key string) error {
+	return conn(ctx, r.db).Where("attempt_key = ?", key).Delete(&model.LoginAttemptModel{}).Error
+}
+
+// DeleteBefore 清理过期的失败记录
+func (r *LoginAttemptRepository) DeleteBefore(ctx context.Context, before time.Time) error {
+	return conn(ctx, r.db).Where("last_failed_at < ?", before).Delete(&model.LoginAttemptModel{}).Error
+}
@@ -0,0 +1,98 @@
+package mysql
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	"yiwen/go-ddd/internal/domain/event"
+	"yiwen/go-ddd/internal/infrastructure/persistence/model"
+)
+
+// maxLastErrorLen 与 outbox_events.last_error 列宽保持一致
+const maxLastErrorLen = 500
+
+// OutboxRepository 发件箱仓储
+// 供发件箱中继读取待投递的事件并回写投递结果
+type OutboxRepository struct {
+	db *gorm.DB
+}
+
+// NewOutboxRepository 创建发件箱仓储
+func NewOutboxRepository(db *gorm.DB) *OutboxRepository {
+	return &OutboxRepository{db: db}
+}
+
+// FetchPending 按写入顺序获取未投递的事件
+// maxAttempts > 0 时，达到最大重试次数的事件视为死信，不再投递（需要人工介入）；
+// 同一聚合中排在死信之后的事件也一并暂停，避免越过死信乱序投递
+func (r *OutboxRepository) FetchPending(ctx context.Context, limit, maxAttempts int) ([]*model.OutboxModel, error) {
+	var rows []*model.OutboxModel
+	q := conn(ctx, r.db).Where("processed_at IS NULL")
+	if maxAttempts > 0 {
+		q = q.Where("attempts < ?", maxAttempts).
+			Where(`NOT EXISTS (SELECT 1 FROM outbox_events dead
+				WHERE dead.aggregate_type = outbox_events.aggregate_type
+				AND dead.aggregate_id = outbox_events.aggregate_id
+				AND dead.processed_at IS NULL AND dead.attempts >= ? AND dead.id < outbox_events.id)`, maxAttempts)
+	}
+	if err := q.Order("id ASC").Limit(limit).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	return rows, nil
+}
+
+// MarkProcessed 标记事件已投递
+func (r *OutboxRepository) MarkProcessed(ctx context.Context, id uint64) error {
+	return conn(ctx, r.db).
+		Model(&model.OutboxModel{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"processed_at": time.Now(),
+			"attempts":     gorm.Expr("attempts + 1"),
+			"last_error":   "",
+		}).Error
+}
+
+// MarkFailed 记录一次失败的投递，事件保留在发件箱中等待下次重试
+func (r *OutboxRepository) MarkFailed(ctx context.Context, id uint64, cause error) error {
+	msg := cause.Error()
+	if len(msg) > maxLastErrorLen {
+		msg = msg[:maxLastErrorLen]
+	}
+	return conn(ctx, r.db).
+		Model(&model.OutboxModel{}).
+		Where("id = ?", id).
+		Updates(map[string]interface{}{
+			"attempts":   gorm.Expr("attempts + 1"),
+			"last_error": msg,
+		}).Error
+}
+
+// appendOutbox 在给定事务中写入领域事件
+func appendOutbox(tx *gorm.DB, aggregateType string, events []event.Event) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	rows := make([]*model.OutboxModel, 0, len(events))
+	for _, e := range events {
+		// 发件箱中的事件会被投递到外部，不能包含密码哈希等敏感字段
+		payload, err := json.Marshal(event.Redact(e))
+		if err != nil {
+			return err
+		}
+		rows = append(rows, &model.OutboxModel{
+			EventID:       uuid.New().String(),
+			AggregateType: aggregateType,
+			AggregateID:   e.AggregateID(),
+			EventName:     e.EventName(),
+			Payload:       string(payload),
+			OccurredAt:    e.OccurredAt(),
+		})
+	}
+	return tx.Create(&rows).Error
+}
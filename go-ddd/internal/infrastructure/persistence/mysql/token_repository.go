@@ -0,0 +1,120 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/repository"
+	"yiwen/go-ddd/internal/infrastructure/persistence/model"
+)
+
+// RefreshTokenRepository MySQL刷新令牌仓储实现
+type RefreshTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewRefreshTokenRepository 创建刷新令牌仓储
+func NewRefreshTokenRepository(db *gorm.DB) repository.RefreshTokenRepository {
+	return &RefreshTokenRepository{db: db}
+}
+
+// Create 保存新令牌
+func (r *RefreshTokenRepository) Create(ctx context.Context, token *entity.RefreshToken) error {
+	m := model.RefreshTokenFromEntity(token)
+	if err := conn(ctx, r.db).Create(m).Error; err != nil {
+		return err
+	}
+	token.ID = m.ID
+	return nil
+}
+
+// FindByHash 根据令牌哈希查找
+func (r *RefreshTokenRepository) FindByHash(ctx context.Context, tokenHash string) (*entity.RefreshToken, error) {
+	var m model.RefreshTokenModel
+	if err := conn(ctx, r.db).Where("token_hash = ?", tokenHash).First(&m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("refresh token not found")
+		}
+		return nil, err
+	}
+	return m.ToEntity(), nil
+}
+
+// MarkRotated 将未吊销的令牌标记为已轮换（条件更新，防止并发刷新同时成功）
+func (r *RefreshTokenRepository) MarkRotated(ctx context.Context, id, replacedBy uint64) (bool, error) {
+	result := conn(ctx, r.db).
+		Model(&model.RefreshTokenModel{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{
+			"revoked_at":  time.Now(),
+			"replaced_by": replacedBy,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+// RevokeFamily 吊销整个令牌家族
+func (r *RefreshTokenRepository) RevokeFamily(ctx context.Context, familyID string) error {
+	return conn(ctx, r.db).
+		Model(&model.RefreshTokenModel{}).
+		Where("family_id = ? AND revoked_at IS NULL", familyID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// RevokeAllForUser 吊销用户的全部令牌
+func (r *RefreshTokenRepository) RevokeAllForUser(ctx context.Context, userID uint64) error {
+	return conn(ctx, r.db).
+		Model(&model.RefreshTokenModel{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Update("revoked_at", time.Now()).Error
+}
+
+// DeleteExpired 清理已过期的令牌
+func (r *RefreshTokenRepository) DeleteExpired(ctx context.Context) error {
+	return conn(ctx, r.db).
+		Where("expires_at < ?", time.Now()).
+		Delete(&model.RefreshTokenModel{}).Error
+}
+
+// RevokedTokenRepository MySQL已吊销访问令牌仓储实现
+type RevokedTokenRepository struct {
+	db *gorm.DB
+}
+
+// NewRevokedTokenRepository 创建已吊销访问令牌仓储
+func NewRevokedTokenRepository(db *gorm.DB) repository.RevokedTokenRepository {
+	return &RevokedTokenRepository{db: db}
+}
+
+// Revoke 吊销访问令牌，重复吊销是幂等的
+func (r *RevokedTokenRepository) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	return conn(ctx, r.db).
+		Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&model.RevokedTokenModel{JTI: jti, ExpiresAt: expiresAt}).Error
+}
+
+// IsRevoked 检查访问令牌是否已被吊销
+func (r *RevokedTokenRepository) IsRevoked(ctx context.Context, jti string) (bool, error) {
+	var count int64
+	if err := conn(ctx, r.db).
+		Model(&model.RevokedTokenModel{}).
+		Where("jti = ?", jti).
+		Count(&count).Error; err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// DeleteExpired 清理已过期的记录
+func (r *RevokedTokenRepository) DeleteExpired(ctx context.Context) error {
+	return conn(ctx, r.db).
+		Where("expires_at < ?", time.Now()).
+		Delete(&model.RevokedTokenModel{}).Error
+}
@@ -0,0 +1,85 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+	"log"
+	"math/rand"
+	"time"
+
+	mysqldriver "github.com/go-sql-driver/mysql"
+	"gorm.io/gorm"
+
+	"yiwen/go-ddd/internal/domain/repository"
+)
+
+// MySQL 中可以通过重试整个事务解决的错误
+const (
+	errLockWaitTimeout = 1205
+	errDeadlock        = 1213
+)
+
+type txKey struct{}
+
+// conn 返回当前上下文中的事务，不在事务中时返回普通连接
+// 所有仓储都通过它访问数据库，从而参与 UnitOfWork 开启的事务
+func conn(ctx context.Context, db *gorm.DB) *gorm.DB {
+	if tx, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return tx
+	}
+	return db.WithContext(ctx)
+}
+
+// UnitOfWork 基于 GORM 事务的工作单元
+type UnitOfWork struct {
+	db         *gorm.DB
+	maxRetries int
+}
+
+// NewUnitOfWork 创建工作单元，maxRetries 为死锁、锁等待超时后的最大重试次数
+func NewUnitOfWork(db *gorm.DB, maxRetries int) repository.UnitOfWork {
+	if maxRetries < 0 {
+		maxRetries = 0
+	}
+	return &UnitOfWork{db: db, maxRetries: maxRetries}
+}
+
+// Do 在事务中执行 fn，遇到死锁时重试
+func (u *UnitOfWork) Do(ctx context.Context, fn func(ctx context.Context) error) error {
+	// 已经在事务中：直接加入外层事务，由外层负责提交和重试
+	if _, ok := ctx.Value(txKey{}).(*gorm.DB); ok {
+		return fn(ctx)
+	}
+
+	var err error
+	for attempt := 0; ; attempt++ {
+		err = u.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			return fn(context.WithValue(ctx, txKey{}, tx))
+		})
+		if err == nil || !isRetryable(err) || attempt >= u.maxRetries {
+			return err
+		}
+
+		log.Printf("transaction aborted (attempt %d), retrying: %v", attempt+1, err)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(backoff(attempt)):
+		}
+	}
+}
+
+// isRetryable 判断事务错误是否可以通过重试解决
+func isRetryable(err error) bool {
+	var mysqlErr *mysqldriver.MySQLError
+	if errors.As(err, &mysqlErr) {
+		return mysqlErr.Number == errDeadlock || mysqlErr.Number == errLockWaitTimeout
+	}
+	return false
+}
+
+// backoff 指数退避加随机抖动，避免冲突的事务同时重试再次死锁
+func backoff(attempt int) time.Duration {
+	base := 10 * time.Millisecond << attempt
+	return base + time.Duration(rand.Int63n(int64(base)))
+}
@@ -0,0 +1,49 @@
+package mysql
+
+import (
+	"context"
+	"encoding/json"
+
+	"gorm.io/gorm"
+
+	"yiwen/go-ddd/internal/application/port"
+	"yiwen/go-ddd/internal/infrastructure/persistence/model"
+)
+
+// UserEventLog 基于发件箱的用户事件记录
+// 发件箱中的事件已经脱敏，投递后仍然保留，因此也作为导出个人数据时的事件来源
+type UserEventLog struct {
+	db *gorm.DB
+}
+
+// NewUserEventLog 创建用户事件记录
+func NewUserEventLog(db *gorm.DB) *UserEventLog {
+	return &UserEventLog{db: db}
+}
+
+// Events 按写入顺序返回聚合的全部事件
+func (l *UserEventLog) Events(ctx context.Context, aggregateID string) ([]port.RecordedEvent, error) {
+	var rows []*model.OutboxModel
+	if err := conn(ctx, l.db).
+		Where("aggregate_type = ? AND aggregate_id = ?", "user", aggregateID).
+		Order("id ASC").
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	events := make([]port.RecordedEvent, len(rows))
+	for i, row := range rows {
+		events[i] = port.RecordedEvent{
+			Name:       row.EventName,
+			OccurredAt: row.OccurredAt,
+			Payload:    json.RawMessage(row.Payload),
+		}
+	}
+	return events, nil
+}
+
+// Erase 删除聚合的全部事件，包括尚未投递的
+func (l *UserEventLog) Erase(ctx context.Context, aggregateID string) error {
+	return conn(ctx, l.db).
+		Where("aggregate_type = ? AND aggregate_id = ?", "user", aggregateID).
+		Delete(&model.OutboxModel{}).Error
+}
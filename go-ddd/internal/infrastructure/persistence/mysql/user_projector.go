@@ -0,0 +1,42 @@
+package mysql
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"yiwen/go-ddd/internal/domain/aggregate"
+	"yiwen/go-ddd/internal/infrastructure/persistence/model"
+)
+
+// UserProjector 用户读模型投影器
+// 事件溯源模式下事件存储是事实来源，users 表只是供查询使用的投影
+// 投影时按 UUID 定位行（包括已软删除的行），并在同一事务中把本次事件写入发件箱
+type UserProjector struct {
+	db *gorm.DB
+}
+
+// NewUserProjector 创建用户投影器
+func NewUserProjector(db *gorm.DB) *UserProjector {
+	return &UserProjector{db: db}
+}
+
+// Project 将聚合的最新状态写入 users 表
+func (p *UserProjector) Project(ctx context.Context, agg *aggregate.UserAggregate) error {
+	return conn(ctx, p.db).Transaction(func(tx *gorm.DB) error {
+		var id uint64
+		if err := tx.Unscoped().
+			Model(&model.UserModel{}).
+			Where("uuid = ?", agg.User.UUID).
+			Select("id").
+			Scan(&id).Error; err != nil {
+			return err
+		}
+		agg.User.ID = id
+
+		if err := saveUser(tx.Unscoped(), agg.User); err != nil {
+			return err
+		}
+		return appendOutbox(tx, "user", agg.GetUncommittedEvents())
+	})
+}
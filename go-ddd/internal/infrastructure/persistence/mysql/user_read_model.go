@@ -0,0 +1,82 @@
+package mysql
+
+import (
+	"context"
+	"errors"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"yiwen/go-ddd/internal/application/port"
+	"yiwen/go-ddd/internal/domain/repository"
+	"yiwen/go-ddd/internal/infrastructure/persistence/model"
+)
+
+// UserReadModel 基于 user_views 表的用户读模型
+// 列名与 users 表一致，过滤、排序、游标条件与 UserRepository.List 共用
+type UserReadModel struct {
+	db *gorm.DB
+}
+
+// NewUserReadModel 创建用户读模型
+func NewUserReadModel(db *gorm.DB) *UserReadModel {
+	return &UserReadModel{db: db}
+}
+
+// FindByID 根据ID查找
+func (r *UserReadModel) FindByID(ctx context.Context, id uint64) (*port.UserView, error) {
+	return r.findOne(ctx, "id = ?", id)
+}
+
+// FindByUUID 根据UUID查找
+func (r *UserReadModel) FindByUUID(ctx context.Context, uuid string) (*port.UserView, error) {
+	return r.findOne(ctx, "uuid = ?", uuid)
+}
+
+func (r *UserReadModel) findOne(ctx context.Context, cond string, arg interface{}) (*port.UserView, error) {
+	var m model.UserViewModel
+	if err := conn(ctx, r.db).Where(cond, arg).First(&m).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, port.ErrUserViewNotFound
+		}
+		return nil, err
+	}
+	view := m.ToView()
+	return &view, nil
+}
+
+// List 按条件查询
+func (r *UserReadModel) List(ctx context.Context, opts repository.UserListOptions) ([]port.UserView, int64, error) {
+	var models []model.UserViewModel
+	var total int64
+
+	if err := filterUsers(conn(ctx, r.db).Model(&model.UserViewModel{}), opts.Filter).Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+	if err := listUsers(filterUsers(conn(ctx, r.db), opts.Filter), opts).Find(&models).Error; err != nil {
+		return nil, 0, err
+	}
+
+	views := make([]port.UserView, len(models))
+	for i := range models {
+		views[i] = models[i].ToView()
+	}
+	return views, total, nil
+}
+
+// Upsert 按ID写入或覆盖
+func (r *UserReadModel) Upsert(ctx context.Context, view port.UserView) error {
+	return conn(ctx, r.db).
+		Clauses(clause.OnConflict{UpdateAll: true}).
+		Create(model.UserViewModelFromView(view)).Error
+}
+
+// Delete 删除一行
+func (r *UserReadModel) Delete(ctx context.Context, uuid string) error {
+	return conn(ctx, r.db).Where("uuid = ?", uuid).Delete(&model.UserViewModel{}).Error
+}
+
+// Reset 清空读模型
+func (r *UserReadModel) Reset(ctx context.Context) error {
+	return conn(ctx, r.db).Session(&gorm.Session{AllowGlobalUpdate: true}).Delete(&model.UserViewModel{}).Error
+}
@@ -3,11 +3,16 @@ package mysql
 import (
 	"context"
 	"errors"
+	"fmt"
+	"strings"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
+	"yiwen/go-ddd/internal/domain/aggregate"
 	"yiwen/go-ddd/internal/domain/entity"
 	"yiwen/go-ddd/internal/domain/repository"
+	domainservice "yiwen/go-ddd/internal/domain/service"
 	"yiwen/go-ddd/internal/infrastructure/persistence/model"
 )
 
@@ -25,30 +30,107 @@ func NewUserRepository(db *gorm.DB) repository.UserRepository {
 
 // Save 保存用户（创建或更新）
 func (r *UserRepository) Save(ctx context.Context, user *entity.User) error {
+	return saveUser(conn(ctx, r.db), user)
+}
+
+// SaveAggregate 保存聚合根，并在同一事务中写入发件箱
+func (r *UserRepository) SaveAggregate(ctx context.Context, agg *aggregate.UserAggregate) error {
+	return r.SaveAggregates(ctx, agg)
+}
+
+// SaveAggregates 在同一事务中保存多个聚合及其事件
+func (r *UserRepository) SaveAggregates(ctx context.Context, aggs ...*aggregate.UserAggregate) error {
+	return conn(ctx, r.db).Transaction(func(tx *gorm.DB) error {
+		for _, agg := range aggs {
+			if err := saveUser(tx, agg.User); err != nil {
+				return err
+			}
+			if err := appendOutbox(tx, "user", agg.GetUncommittedEvents()); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// saveUser 在给定连接（或事务）上保存用户
+func saveUser(db *gorm.DB, user *entity.User) error {
 	userModel := model.FromEntity(user)
 
 	if user.ID == 0 {
 		// 创建
-		if err := r.db.WithContext(ctx).Create(userModel).Error; err != nil {
-			return err
+		if err := db.Create(userModel).Error; err != nil {
+			return translateUniqueViolation(db, user, err)
 		}
 		user.ID = userModel.ID
 	} else {
 		// 更新
-		if err := r.db.WithContext(ctx).Save(userModel).Error; err != nil {
-			return err
+		if err := db.Save(userModel).Error; err != nil {
+			return translateUniqueViolation(db, user, err)
 		}
 	}
 
 	return nil
 }
 
+// translateUniqueViolation 将唯一索引冲突翻译为领域错误
+// 应用层的唯一性检查与写入之间存在竞争，唯一索引才是最终的保证；
+// 驱动返回的错误不区分具体索引，因此冲突后再查询一次确定是哪个字段，查询失败时返回查询错误
+func translateUniqueViolation(db *gorm.DB, user *entity.User, err error) error {
+	if !errors.Is(err, gorm.ErrDuplicatedKey) {
+		return err
+	}
+
+	taken := func(column, value string) (bool, error) {
+		var count int64
+		if err := db.Unscoped().Model(&model.UserModel{}).
+			Where(column+" = ? AND id <> ?", value, user.ID).
+			Count(&count).Error; err != nil {
+			return false, fmt.Errorf("check duplicate %s: %w", column, err)
+		}
+		return count > 0, nil
+	}
+
+	conflicts := []struct {
+		column, value string
+		err           error
+	}{
+		{"username", user.Username, domainservice.ErrUsernameAlreadyExists},
+		{"email", user.Email.String(), domainservice.ErrEmailAlreadyExists},
+	}
+	for _, c := range conflicts {
+		ok, checkErr := taken(c.column, c.value)
+		if checkErr != nil {
+			return checkErr
+		}
+		if ok {
+			return c.err
+		}
+	}
+	return err
+}
+
+// FindAggregateByID 根据ID加载用户聚合
+// 状态直接保存在 users 表中，聚合没有版本概念，版本始终为 0；
+// 因此加载时锁定该行，同一用户的并发命令在事务中串行执行，
+// 不会都基于旧状态通过检查（如同一个 TOTP 验证码被并发地用于两次登录）
+func (r *UserRepository) FindAggregateByID(ctx context.Context, id uint64) (*aggregate.UserAggregate, error) {
+	var userModel model.UserModel
+	if err := conn(ctx, r.db).Clauses(clause.Locking{Strength: "UPDATE"}).First(&userModel, id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, domainservice.ErrUserNotFound
+		}
+		return nil, err
+	}
+	return aggregate.NewUserAggregate(userModel.ToEntity()), nil
+}
+
 // FindByID 根据ID查找用户
 func (r *UserRepository) FindByID(ctx context.Context, id uint64) (*entity.User, error) {
 	var userModel model.UserModel
-	if err := r.db.WithContext(ctx).First(&userModel, id).Error; err != nil {
+	if err := conn(ctx, r.db).First(&userModel, id).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not found")
+			return nil, domainservice.ErrUserNotFound
 		}
 		return nil, err
 	}
@@ -58,9 +140,9 @@ func (r *UserRepository) FindByID(ctx context.Context, id uint64) (*entity.User,
 // FindByUUID 根据UUID查找用户
 func (r *UserRepository) FindByUUID(ctx context.Context, uuid string) (*entity.User, error) {
 	var userModel model.UserModel
-	if err := r.db.WithContext(ctx).Where("uuid = ?", uuid).First(&userModel).Error; err != nil {
+	if err := conn(ctx, r.db).Where("uuid = ?", uuid).First(&userModel).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not found")
+			return nil, domainservice.ErrUserNotFound
 		}
 		return nil, err
 	}
@@ -70,9 +152,9 @@ func (r *UserRepository) FindByUUID(ctx context.Context, uuid string) (*entity.U
 // FindByUsername 根据用户名查找用户
 func (r *UserRepository) FindByUsername(ctx context.Context, username string) (*entity.User, error) {
 	var userModel model.UserModel
-	if err := r.db.WithContext(ctx).Where("username = ?", username).First(&userModel).Error; err != nil {
+	if err := conn(ctx, r.db).Where("username = ?", username).First(&userModel).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not found")
+			return nil, domainservice.ErrUserNotFound
 		}
 		return nil, err
 	}
@@ -82,9 +164,9 @@ func (r *UserRepository) FindByUsername(ctx context.Context, username string) (*
 // FindByEmail 根据邮箱查找用户
 func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*entity.User, error) {
 	var userModel model.UserModel
-	if err := r.db.WithContext(ctx).Where("email = ?", email).First(&userModel).Error; err != nil {
+	if err := conn(ctx, r.db).Where("email = ?", email).First(&userModel).Error; err != nil {
 		if errors.Is(err, gorm.ErrRecordNotFound) {
-			return nil, errors.New("user not found")
+			return nil, domainservice.ErrUserNotFound
 		}
 		return nil, err
 	}
@@ -93,25 +175,21 @@ func (r *UserRepository) FindByEmail(ctx context.Context, email string) (*entity
 
 // Delete 删除用户（软删除）
 func (r *UserRepository) Delete(ctx context.Context, id uint64) error {
-	return r.db.WithContext(ctx).Delete(&model.UserModel{}, id).Error
+	return conn(ctx, r.db).Delete(&model.UserModel{}, id).Error
 }
 
-// List 分页查询用户列表
-func (r *UserRepository) List(ctx context.Context, offset, limit int) ([]*entity.User, int64, error) {
+// List 按条件查询用户列表
+func (r *UserRepository) List(ctx context.Context, opts repository.UserListOptions) ([]*entity.User, int64, error) {
 	var userModels []model.UserModel
 	var total int64
 
-	// 查询总数
-	if err := r.db.WithContext(ctx).Model(&model.UserModel{}).Count(&total).Error; err != nil {
+	// 查询总数（只受过滤条件影响）
+	if err := filterUsers(conn(ctx, r.db).Model(&model.UserModel{}), opts.Filter).Count(&total).Error; err != nil {
 		return nil, 0, err
 	}
 
 	// 查询列表
-	if err := r.db.WithContext(ctx).
-		Offset(offset).
-		Limit(limit).
-		Order("id DESC").
-		Find(&userModels).Error; err != nil {
+	if err := listUsers(filterUsers(conn(ctx, r.db), opts.Filter), opts).Find(&userModels).Error; err != nil {
 		return nil, 0, err
 	}
 
@@ -124,10 +202,77 @@ func (r *UserRepository) List(ctx context.Context, offset, limit int) ([]*entity
 	return users, total, nil
 }
 
+// listUsers 添加排序和分页（游标或偏移量）
+func listUsers(db *gorm.DB, opts repository.UserListOptions) *gorm.DB {
+	column := sortColumn(opts.SortBy)
+	direction, cmp := "ASC", ">"
+	if opts.Desc {
+		direction, cmp = "DESC", "<"
+	}
+	if opts.After != nil {
+		db = afterCursor(db, column, cmp, opts.After)
+	} else {
+		db = db.Offset(opts.Offset)
+	}
+	if column != "id" {
+		db = db.Order(column + " " + direction)
+	}
+	return db.Order("id " + direction).Limit(opts.Limit)
+}
+
+// filterUsers 添加过滤条件
+func filterUsers(db *gorm.DB, f repository.UserFilter) *gorm.DB {
+	if f.Status != 0 {
+		db = db.Where("status = ?", int(f.Status))
+	}
+	if f.Role != "" {
+		db = db.Where("role = ?", string(f.Role))
+	}
+	if f.CreatedFrom != nil {
+		db = db.Where("created_at >= ?", *f.CreatedFrom)
+	}
+	if f.CreatedBefore != nil {
+		db = db.Where("created_at < ?", *f.CreatedBefore)
+	}
+	if f.Keyword != "" {
+		// MySQL 和 SQLite 的默认排序规则下 LIKE 都不区分大小写；用 ! 作转义符，两者写法一致
+		pattern := "%" + likeEscaper.Replace(f.Keyword) + "%"
+		db = db.Where("(username LIKE ? ESCAPE '!' OR email LIKE ? ESCAPE '!' OR nickname LIKE ? ESCAPE '!')", pattern, pattern, pattern)
+	}
+	return db
+}
+
+var likeEscaper = strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+
+// sortColumn 排序字段对应的列，只允许白名单中的列
+func sortColumn(field repository.UserSortField) string {
+	switch field {
+	case repository.UserSortByCreatedAt:
+		return "created_at"
+	case repository.UserSortByUsername:
+		return "username"
+	}
+	return "id"
+}
+
+// afterCursor 添加键集分页条件：排序值越过游标，或排序值相同且ID越过游标
+func afterCursor(db *gorm.DB, column, cmp string, c *repository.UserCursor) *gorm.DB {
+	var value interface{}
+	switch column {
+	case "created_at":
+		value = c.CreatedAt
+	case "username":
+		value = c.Username
+	default:
+		return db.Where("id "+cmp+" ?", c.ID)
+	}
+	return db.Where("("+column+" "+cmp+" ? OR ("+column+" = ? AND id "+cmp+" ?))", value, value, c.ID)
+}
+
 // ExistsByUsername 检查用户名是否存在
 func (r *UserRepository) ExistsByUsername(ctx context.Context, username string) (bool, error) {
 	var count int64
-	if err := r.db.WithContext(ctx).
+	if err := conn(ctx, r.db).
 		Model(&model.UserModel{}).
 		Where("username = ?", username).
 		Count(&count).Error; err != nil {
@@ -139,7 +284,7 @@ func (r *UserRepository) ExistsByUsername(ctx context.Context, username string)
 // ExistsByEmail 检查邮箱是否存在
 func (r *UserRepository) ExistsByEmail(ctx context.Context, email string) (bool, error) {
 	var count int64
-	if err := r.db.WithContext(ctx).
+	if err := conn(ctx, r.db).
 		Model(&model.UserModel{}).
 		Where("email = ?", email).
 		Count(&count).Error; err != nil {
@@ -147,3 +292,35 @@ func (r *UserRepository) ExistsByEmail(ctx context.Context, email string) (bool,
 	}
 	return count > 0, nil
 }
+
+// CountActiveAdmins 统计处于激活状态的管理员数量
+// 使用 SELECT ... FOR UPDATE 按主键顺序锁定这些记录：两个事务同时降级不同的管理员时，
+// 后一个会等待前一个提交后再统计，看到的是降级后的数量（SQLite 只有一个连接，本身就是串行的）
+func (r *UserRepository) CountActiveAdmins(ctx context.Context) (int64, error) {
+	var ids []uint64
+	if err := conn(ctx, r.db).
+		Model(&model.UserModel{}).
+		Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where("role = ? AND status = ?", string(entity.UserRoleAdmin), int(entity.UserStatusActive)).
+		Order("id").
+		Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+	return int64(len(ids)), nil
+}
+
+// FindPendingErasures 查找已申请删除账户、等待擦除个人数据的用户，按擦除时间升序
+func (r *UserRepository) FindPendingErasures(ctx context.Context) ([]*entity.User, error) {
+	var userModels []*model.UserModel
+	if err := conn(ctx, r.db).
+		Where("erasure_scheduled_at IS NOT NULL").
+		Order("erasure_scheduled_at, id").
+		Find(&userModels).Error; err != nil {
+		return nil, err
+	}
+	users := make([]*entity.User, len(userModels))
+	for i, m := range userModels {
+		users[i] = m.ToEntity()
+	}
+	return users, nil
+}
@@ -0,0 +1,175 @@
+package mysql
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	"gorm.io/driver/mysql"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"yiwen/go-ddd/internal/application/port"
+	"yiwen/go-ddd/internal/domain/aggregate"
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/event"
+	"yiwen/go-ddd/internal/domain/repository"
+	"yiwen/go-ddd/internal/domain/valueobject"
+	"yiwen/go-ddd/internal/infrastructure/messaging"
+	"yiwen/go-ddd/internal/infrastructure/persistence/eventsourced"
+	"yiwen/go-ddd/internal/infrastructure/persistence/migration"
+	"yiwen/go-ddd/internal/infrastructure/persistence/model"
+	"yiwen/go-ddd/internal/infrastructure/persistence/repotest"
+)
+
+// openTestDB 连接 GO_DDD_TEST_MYSQL_DSN 指定的数据库并清空相关表，未设置时跳过
+// 例如：GO_DDD_TEST_MYSQL_DSN="root:root@tcp(localhost:3306)/go_ddd_test?parseTime=True"
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	dsn := os.Getenv("GO_DDD_TEST_MYSQL_DSN")
+	if dsn == "" {
+		t.Skip("GO_DDD_TEST_MYSQL_DSN not set")
+	}
+
+	db, err := gorm.Open(mysql.Open(dsn), &gorm.Config{
+		Logger:         logger.Default.LogMode(logger.Silent),
+		TranslateError: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 用与生产相同的迁移建表，同时验证迁移与模型一致
+	tables := append(model.AllModels(), "schema_migrations", "schema_lock")
+	if err := db.Migrator().DropTable(tables...); err != nil {
+		t.Fatal(err)
+	}
+	migrations, err := migration.MySQL()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migration.New(db, migrations, migration.Options{}).Up(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+func TestUserRepository(t *testing.T) {
+	repotest.RunUserRepositoryTests(t, func(t *testing.T) repository.UserRepository {
+		return NewUserRepository(openTestDB(t))
+	})
+}
+
+func TestEventSourcedUserRepository(t *testing.T) {
+	repotest.RunEventSourcedUserRepositoryTests(t, func(t *testing.T) (repository.UserRepository, event.EventStore) {
+		db := openTestDB(t)
+		store := NewEventStore(db, event.NewUserEventRegistry())
+		return eventsourced.NewUserRepository(store, NewUserRepository(db), NewUserProjector(db), 2), store
+	})
+}
+
+func TestUserReadModel(t *testing.T) {
+	repotest.RunUserReadModelTests(t, func(t *testing.T) port.UserReadModel {
+		return NewUserReadModel(openTestDB(t))
+	})
+}
+
+func TestUserEventLog(t *testing.T) {
+	repotest.RunUserEventLogTests(t, func(t *testing.T) (port.UserEventLog, repotest.RecordFunc) {
+		db := openTestDB(t)
+		users := NewUserRepository(db)
+		return NewUserEventLog(db), func(t *testing.T, agg *aggregate.UserAggregate) {
+			if err := users.SaveAggregate(context.Background(), agg); err != nil {
+				t.Fatal(err)
+			}
+			agg.ClearEvents()
+		}
+	})
+}
+
+func TestOutboxRepository(t *testing.T) {
+	repotest.RunOutboxStoreTests(t, func(t *testing.T) (messaging.OutboxStore, repotest.RecordFunc) {
+		db := openTestDB(t)
+		users := NewUserRepository(db)
+		return NewOutboxRepository(db), func(t *testing.T, agg *aggregate.UserAggregate) {
+			if err := users.SaveAggregate(context.Background(), agg); err != nil {
+				t.Fatal(err)
+			}
+			agg.ClearEvents()
+		}
+	})
+}
+
+func TestRefreshTokenRepository(t *testing.T) {
+	repotest.RunRefreshTokenRepositoryTests(t, func(t *testing.T) repository.RefreshTokenRepository {
+		return NewRefreshTokenRepository(openTestDB(t))
+	})
+}
+
+func TestRevokedTokenRepository(t *testing.T) {
+	repotest.RunRevokedTokenRepositoryTests(t, func(t *testing.T) repository.RevokedTokenRepository {
+		return NewRevokedTokenRepository(openTestDB(t))
+	})
+}
+
+func TestLoginAttemptRepository(t *testing.T) {
+	repotest.RunLoginAttemptRepositoryTests(t, func(t *testing.T) repository.LoginAttemptRepository {
+		return NewLoginAttemptRepository(openTestDB(t))
+	})
+}
+
+// 两个事务同时检查"至少保留一个管理员"时，后一个要等前一个提交，看到降级后的数量
+func TestCountActiveAdminsLocksRows(t *testing.T) {
+	db := openTestDB(t)
+	repo := NewUserRepository(db)
+	uow := NewUnitOfWork(db, 0)
+	ctx := context.Background()
+	for _, name := range []string{"alice", "bob"} {
+		email, _ := valueobject.NewEmail(name + "@example.com")
+		user := entity.NewUser("uuid-"+name, name, email, valueobject.NewPasswordFromHash("hash"))
+		user.PromoteToAdmin()
+		if err := repo.Save(ctx, user); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	locked := make(chan struct{})
+	release := make(chan struct{})
+	first := make(chan error, 1)
+	go func() {
+		first <- uow.Do(ctx, func(ctx context.Context) error {
+			if _, err := repo.CountActiveAdmins(ctx); err != nil {
+				return err
+			}
+			close(locked)
+			<-release
+			return conn(ctx, db).Model(&model.UserModel{}).Where("username = ?", "alice").Update("role", string(entity.UserRoleUser)).Error
+		})
+	}()
+	<-locked
+
+	second := make(chan int64, 1)
+	go func() {
+		uow.Do(ctx, func(ctx context.Context) error {
+			count, err := repo.CountActiveAdmins(ctx)
+			if err != nil {
+				t.Error(err)
+			}
+			second <- count
+			return nil
+		})
+	}()
+
+	select {
+	case count := <-second:
+		t.Fatalf("second count did not wait for the lock, got %d", count)
+	case <-time.After(200 * time.Millisecond):
+	}
+	close(release)
+	if err := <-first; err != nil {
+		t.Fatal(err)
+	}
+	if count := <-second; count != 1 {
+		t.Fatalf("expected the second transaction to see 1 admin, got %d", count)
+	}
+}
@@ -0,0 +1,134 @@
+package repotest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"yiwen/go-ddd/internal/domain/aggregate"
+	"yiwen/go-ddd/internal/domain/event"
+	"yiwen/go-ddd/internal/domain/repository"
+	domainservice "yiwen/go-ddd/internal/domain/service"
+	"yiwen/go-ddd/internal/domain/valueobject"
+)
+
+// RunEventSourcedUserRepositoryTests 对事件溯源模式下的用户仓储运行一致性测试
+// newRepo 每次调用都应返回一个空仓储，以及仓储使用的事件存储；仓储应每两个事件保存一次快照
+func RunEventSourcedUserRepositoryTests(t *testing.T, newRepo func(t *testing.T) (repository.UserRepository, event.EventStore)) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, repo repository.UserRepository, store event.EventStore)
+	}{
+		{"Erase", testEventSourcedErase},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			repo, store := newRepo(t)
+			tt.fn(t, repo, store)
+		})
+	}
+}
+
+// saveAndClear 保存聚合并清除已提交的事件，与应用服务提交后的处理一致
+func saveAndClear(t *testing.T, repo repository.UserRepository, agg *aggregate.UserAggregate) {
+	t.Helper()
+	if err := repo.SaveAggregate(context.Background(), agg); err != nil {
+		t.Fatalf("save %s: %v", agg.User.UUID, err)
+	}
+	agg.ClearEvents()
+}
+
+func testEventSourcedErase(t *testing.T, repo repository.UserRepository, store event.EventStore) {
+	ctx := context.Background()
+	now := time.Now()
+	// UUID 不含用户名，擦除后由 UUID 生成的占位用户名不会误匹配个人数据
+	personal := []string{"alice", "alice@example.com", "hash-alice", "Alice Liddell", "https://example.com/alice.png", "totp-ciphertext-alice"}
+
+	alice := aggregate.Register("uuid-erase-me", "alice", mustEmail(t, "alice@example.com"), valueobject.NewPasswordFromHash("hash-alice"), "Alice")
+	saveAndClear(t, repo, alice)
+	alice.UpdateProfile("Alice Liddell", "https://example.com/alice.png")
+	if err := alice.BeginMFAEnrollment(valueobject.NewTOTPSecretFromCiphertext("totp-ciphertext-alice"), []string{"recovery-hash-alice"}); err != nil {
+		t.Fatal(err)
+	}
+	saveAndClear(t, repo, alice)
+	bob := registerAggregate(t, "bob")
+	saveAndClear(t, repo, bob)
+
+	if snapshot, err := store.LoadSnapshot(ctx, "uuid-erase-me"); err != nil || snapshot == nil {
+		t.Fatalf("expected a snapshot before erasure, got %v, %v", snapshot, err)
+	}
+
+	row, err := repo.FindByUsername(ctx, "alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice, err = repo.FindAggregateByID(ctx, row.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	alice.RequestErasure(now, time.Hour)
+	saveAndClear(t, repo, alice)
+	if err := alice.Erase(now.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	saveAndClear(t, repo, alice)
+
+	// 事件流只剩匿名化后的状态和擦除记录
+	events, err := store.Load(ctx, "uuid-erase-me", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, len(events))
+	for i, e := range events {
+		names[i] = e.EventName()
+	}
+	if got := fmt.Sprint(names); got != "[user.imported user.erased]" {
+		t.Fatalf("unexpected stream after erasure: %s", got)
+	}
+	payload, err := json.Marshal(events)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertNoPersonalData(t, "event stream", string(payload), personal)
+
+	snapshot, err := store.LoadSnapshot(ctx, "uuid-erase-me")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if snapshot != nil {
+		assertNoPersonalData(t, "snapshot", string(snapshot.State), personal)
+	}
+
+	if _, err := repo.FindByID(ctx, row.ID); !errors.Is(err, domainservice.ErrUserNotFound) {
+		t.Fatalf("expected erased user to be gone, got %v", err)
+	}
+	// 替换后的事件流可以继续重放，版本从新的事件流开始计算
+	if alice.Version != len(events) {
+		t.Fatalf("expected version %d after erasure, got %d", len(events), alice.Version)
+	}
+
+	// 其他用户的事件流不受影响
+	others, err := store.Load(ctx, "uuid-bob", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(others) != 1 || others[0].EventName() != "user.registered" {
+		t.Fatalf("unexpected stream for bob: %v", others)
+	}
+	if user, err := repo.FindByUsername(ctx, "bob"); err != nil || user.Email.String() != "bob@example.com" {
+		t.Fatalf("expected bob to be untouched, got %v, %v", user, err)
+	}
+}
+
+func assertNoPersonalData(t *testing.T, where, data string, personal []string) {
+	t.Helper()
+	for _, value := range personal {
+		if strings.Contains(data, value) {
+			t.Fatalf("%s still contains %q: %s", where, value, data)
+		}
+	}
+}
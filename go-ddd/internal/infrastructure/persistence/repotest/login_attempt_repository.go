@@ -0,0 +1,105 @@
+package repotest
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/repository"
+)
+
+// RunLoginAttemptRepositoryTests 对登录失败计数仓储实现运行一致性测试
+func RunLoginAttemptRepositoryTests(t *testing.T, newRepo func(t *testing.T) repository.LoginAttemptRepository) {
+	ctx := context.Background()
+	repo := newRepo(t)
+	window := 15 * time.Minute
+	start := time.Now().Add(-time.Hour).Truncate(time.Millisecond)
+
+	if got, err := repo.Get(ctx, "user:alice"); err != nil || got.Failures != 0 {
+		t.Fatalf("expected empty record, got %+v %v", got, err)
+	}
+
+	for i := 1; i <= 3; i++ {
+		got, ok, err := repo.Reserve(ctx, "user:alice", start.Add(time.Duration(i)*time.Minute), window, allowAll)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !ok || got.Failures != i {
+			t.Fatalf("expected %d failures, got %d", i, got.Failures)
+		}
+	}
+	if _, _, err := repo.Reserve(ctx, "ip:203.0.113.7", start, window, allowAll); err != nil {
+		t.Fatal(err)
+	}
+
+	// 不允许时不计数，返回当前记录
+	got, ok, err := repo.Reserve(ctx, "user:alice", start.Add(4*time.Minute), window, func(a *entity.LoginAttempt) bool {
+		return a.Failures < 3
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok || got.Failures != 3 || !got.LastFailedAt.Equal(start.Add(3*time.Minute)) {
+		t.Fatalf("expected a blocked reservation to leave the record unchanged, got %v %+v", ok, got)
+	}
+
+	// 撤销预占减少一次计数
+	if err := repo.Release(ctx, "user:alice"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := repo.Get(ctx, "user:alice"); got.Failures != 2 {
+		t.Fatalf("expected release to undo one failure, got %+v", got)
+	}
+
+	// 距上次失败超过窗口后重新计数
+	got, _, err = repo.Reserve(ctx, "user:alice", start.Add(30*time.Minute), window, allowAll)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Failures != 1 || !got.LastFailedAt.Equal(start.Add(30*time.Minute)) {
+		t.Fatalf("expected counter to restart after window, got %+v", got)
+	}
+
+	if err := repo.Reset(ctx, "user:alice"); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := repo.Get(ctx, "user:alice"); got.Failures != 0 {
+		t.Fatalf("expected reset record, got %+v", got)
+	}
+
+	if err := repo.DeleteBefore(ctx, start.Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := repo.Get(ctx, "ip:203.0.113.7"); got.Failures != 0 {
+		t.Fatalf("expected old record to be deleted, got %+v", got)
+	}
+
+	// 同一个键的并发预占依次判断，不会都看到预占之前的记录
+	const burst, limit = 20, 3
+	var wg sync.WaitGroup
+	var reserved atomic.Int32
+	for i := 0; i < burst; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, ok, err := repo.Reserve(ctx, "user:burst", time.Now(), window, func(a *entity.LoginAttempt) bool {
+				return a.Failures < limit
+			})
+			if err != nil {
+				t.Error(err)
+			}
+			if ok {
+				reserved.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if got, _ := repo.Get(ctx, "user:burst"); reserved.Load() != limit || got.Failures != limit {
+		t.Fatalf("expected %d of %d concurrent reservations, got %d (failures %d)", limit, burst, reserved.Load(), got.Failures)
+	}
+}
+
+func allowAll(*entity.LoginAttempt) bool { return true }
@@ -0,0 +1,96 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+
+	"yiwen/go-ddd/internal/infrastructure/messaging"
+)
+
+// RunOutboxStoreTests 对发件箱存储实现运行一致性测试
+// newStore 每次调用都应返回一个空发件箱，以及保存聚合并写入其事件的方法
+func RunOutboxStoreTests(t *testing.T, newStore func(t *testing.T) (messaging.OutboxStore, RecordFunc)) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, store messaging.OutboxStore, record RecordFunc)
+	}{
+		{"FetchInOrder", testOutboxFetchInOrder},
+		{"DeadLetterBlocksAggregate", testOutboxDeadLetter},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store, record := newStore(t)
+			tt.fn(t, store, record)
+		})
+	}
+}
+
+// pending 返回待投递事件的 "聚合/事件名" 列表
+func pending(t *testing.T, store messaging.OutboxStore, maxAttempts int) []string {
+	t.Helper()
+	rows, err := store.FetchPending(context.Background(), 100, maxAttempts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	names := make([]string, len(rows))
+	for i, row := range rows {
+		names[i] = row.AggregateID + "/" + row.EventName
+	}
+	return names
+}
+
+func testOutboxFetchInOrder(t *testing.T, store messaging.OutboxStore, record RecordFunc) {
+	ctx := context.Background()
+	alice := registerAggregate(t, "alice")
+	record(t, alice)
+	record(t, registerAggregate(t, "bob"))
+	alice.UpdateProfile("Alice", "")
+	record(t, alice)
+
+	want := "[uuid-alice/user.registered uuid-bob/user.registered uuid-alice/user.profile_updated]"
+	if got := fmt.Sprint(pending(t, store, 3)); got != want {
+		t.Fatalf("expected %s, got %s", want, got)
+	}
+
+	rows, err := store.FetchPending(ctx, 1, 3)
+	if err != nil || len(rows) != 1 {
+		t.Fatalf("expected one row, got %v, %v", rows, err)
+	}
+	if err := store.MarkProcessed(ctx, rows[0].ID); err != nil {
+		t.Fatal(err)
+	}
+	want = "[uuid-bob/user.registered uuid-alice/user.profile_updated]"
+	if got := fmt.Sprint(pending(t, store, 3)); got != want {
+		t.Fatalf("expected %s after processing, got %s", want, got)
+	}
+}
+
+func testOutboxDeadLetter(t *testing.T, store messaging.OutboxStore, record RecordFunc) {
+	ctx := context.Background()
+	alice := registerAggregate(t, "alice")
+	record(t, alice)
+	record(t, registerAggregate(t, "bob"))
+	alice.UpdateProfile("Alice", "")
+	record(t, alice)
+
+	rows, err := store.FetchPending(ctx, 1, 2)
+	if err != nil || len(rows) != 1 {
+		t.Fatalf("expected one row, got %v, %v", rows, err)
+	}
+	for i := 0; i < 2; i++ {
+		if err := store.MarkFailed(ctx, rows[0].ID, errors.New("sink unavailable")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// alice 的第一条事件成为死信，她后续的事件不能越过它投递
+	if got := fmt.Sprint(pending(t, store, 2)); got != "[uuid-bob/user.registered]" {
+		t.Fatalf("expected only bob's event while alice's is dead, got %s", got)
+	}
+	// 无限重试时没有死信
+	if got := len(pending(t, store, 0)); got != 3 {
+		t.Fatalf("expected all events without a retry limit, got %d", got)
+	}
+}
@@ -0,0 +1,112 @@
+package repotest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/repository"
+)
+
+// RunRefreshTokenRepositoryTests 对刷新令牌仓储实现运行一致性测试
+func RunRefreshTokenRepositoryTests(t *testing.T, newRepo func(t *testing.T) repository.RefreshTokenRepository) {
+	t.Run("RotateAndRevoke", func(t *testing.T) {
+		ctx := context.Background()
+		repo := newRepo(t)
+
+		first := entity.NewRefreshToken(1, "family", "hash-1", time.Hour)
+		if err := repo.Create(ctx, first); err != nil {
+			t.Fatal(err)
+		}
+		second := entity.NewRefreshToken(1, "family", "hash-2", time.Hour)
+		if err := repo.Create(ctx, second); err != nil {
+			t.Fatal(err)
+		}
+		if first.ID == 0 || second.ID == 0 || first.ID == second.ID {
+			t.Fatalf("expected distinct ids, got %d and %d", first.ID, second.ID)
+		}
+
+		rotated, err := repo.MarkRotated(ctx, first.ID, second.ID)
+		if err != nil || !rotated {
+			t.Fatalf("expected first rotation to succeed, got %v %v", rotated, err)
+		}
+		// 并发刷新的另一方不能再次轮换同一个令牌
+		if rotated, _ := repo.MarkRotated(ctx, first.ID, second.ID); rotated {
+			t.Fatal("token rotated twice")
+		}
+
+		got, err := repo.FindByHash(ctx, "hash-1")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !got.IsRevoked() || got.ReplacedBy != second.ID {
+			t.Fatalf("unexpected rotated token: %+v", got)
+		}
+
+		if err := repo.RevokeFamily(ctx, "family"); err != nil {
+			t.Fatal(err)
+		}
+		got, _ = repo.FindByHash(ctx, "hash-2")
+		if !got.IsRevoked() {
+			t.Fatal("family not revoked")
+		}
+	})
+
+	t.Run("DeleteExpired", func(t *testing.T) {
+		ctx := context.Background()
+		repo := newRepo(t)
+
+		expired := entity.NewRefreshToken(1, "a", "expired", -time.Minute)
+		valid := entity.NewRefreshToken(1, "b", "valid", time.Hour)
+		for _, token := range []*entity.RefreshToken{expired, valid} {
+			if err := repo.Create(ctx, token); err != nil {
+				t.Fatal(err)
+			}
+		}
+
+		if err := repo.DeleteExpired(ctx); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := repo.FindByHash(ctx, "expired"); err == nil {
+			t.Fatal("expired token not deleted")
+		}
+		if _, err := repo.FindByHash(ctx, "valid"); err != nil {
+			t.Fatal("valid token deleted")
+		}
+	})
+}
+
+// RunRevokedTokenRepositoryTests 对已吊销访问令牌仓储实现运行一致性测试
+func RunRevokedTokenRepositoryTests(t *testing.T, newRepo func(t *testing.T) repository.RevokedTokenRepository) {
+	ctx := context.Background()
+	repo := newRepo(t)
+
+	if err := repo.Revoke(ctx, "jti-1", time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	// 重复吊销是幂等的
+	if err := repo.Revoke(ctx, "jti-1", time.Now().Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.Revoke(ctx, "jti-old", time.Now().Add(-time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	if revoked, _ := repo.IsRevoked(ctx, "jti-1"); !revoked {
+		t.Fatal("expected jti-1 to be revoked")
+	}
+	if revoked, _ := repo.IsRevoked(ctx, "jti-2"); revoked {
+		t.Fatal("expected jti-2 not to be revoked")
+	}
+
+	if err := repo.DeleteExpired(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if revoked, _ := repo.IsRevoked(ctx, "jti-old"); revoked {
+		t.Fatal("expired record not deleted")
+	}
+	if revoked, _ := repo.IsRevoked(ctx, "jti-1"); !revoked {
+		t.Fatal("unexpired record deleted")
+	}
+}
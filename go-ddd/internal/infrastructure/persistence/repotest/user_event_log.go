@@ -0,0 +1,98 @@
+package repotest
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"yiwen/go-ddd/internal/application/port"
+	"yiwen/go-ddd/internal/domain/aggregate"
+	"yiwen/go-ddd/internal/domain/valueobject"
+)
+
+// RecordFunc 保存聚合并使其未提交的事件进入事件记录，随后清除这些事件
+type RecordFunc func(t *testing.T, agg *aggregate.UserAggregate)
+
+// RunUserEventLogTests 对用户事件记录实现运行一致性测试
+// newLog 每次调用都应返回一个空记录，以及把聚合事件写入该记录的方法
+func RunUserEventLogTests(t *testing.T, newLog func(t *testing.T) (port.UserEventLog, RecordFunc)) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, log port.UserEventLog, record RecordFunc)
+	}{
+		{"EventsInOrder", testEventLogOrder},
+		{"Erase", testEventLogErase},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			log, record := newLog(t)
+			tt.fn(t, log, record)
+		})
+	}
+}
+
+func registerAggregate(t *testing.T, name string) *aggregate.UserAggregate {
+	t.Helper()
+	return aggregate.Register("uuid-"+name, name, mustEmail(t, name+"@example.com"), valueobject.NewPasswordFromHash("hash-"+name), name)
+}
+
+func eventNames(events []port.RecordedEvent) []string {
+	names := make([]string, len(events))
+	for i, e := range events {
+		names[i] = e.Name
+	}
+	return names
+}
+
+func testEventLogOrder(t *testing.T, log port.UserEventLog, record RecordFunc) {
+	ctx := context.Background()
+	alice := registerAggregate(t, "alice")
+	record(t, alice)
+	record(t, registerAggregate(t, "bob"))
+	alice.UpdateProfile("Alice", "")
+	record(t, alice)
+
+	events, err := log.Events(ctx, "uuid-alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := fmt.Sprint(eventNames(events)); got != "[user.registered user.profile_updated]" {
+		t.Fatalf("unexpected events %s", got)
+	}
+	if strings.Contains(string(events[0].Payload), "hash-alice") {
+		t.Fatal("recorded event contains the password hash")
+	}
+	if !strings.Contains(string(events[0].Payload), "alice@example.com") || events[0].OccurredAt.IsZero() {
+		t.Fatalf("unexpected payload %s", events[0].Payload)
+	}
+
+	events, err = log.Events(ctx, "uuid-nobody")
+	if err != nil || len(events) != 0 {
+		t.Fatalf("expected no events for unknown aggregate, got %v, %v", events, err)
+	}
+}
+
+func testEventLogErase(t *testing.T, log port.UserEventLog, record RecordFunc) {
+	ctx := context.Background()
+	alice := registerAggregate(t, "alice")
+	record(t, alice)
+	record(t, registerAggregate(t, "bob"))
+
+	if err := log.Erase(ctx, "uuid-alice"); err != nil {
+		t.Fatal(err)
+	}
+	if events, _ := log.Events(ctx, "uuid-alice"); len(events) != 0 {
+		t.Fatalf("expected erased events to be gone, got %v", eventNames(events))
+	}
+	if events, _ := log.Events(ctx, "uuid-bob"); len(events) != 1 {
+		t.Fatalf("erase removed other aggregates' events: %v", eventNames(events))
+	}
+
+	// 擦除之后产生的事件（如 user.erased）照常记录
+	alice.Delete()
+	record(t, alice)
+	if events, _ := log.Events(ctx, "uuid-alice"); fmt.Sprint(eventNames(events)) != "[user.deleted]" {
+		t.Fatalf("expected only events after erasure, got %v", eventNames(events))
+	}
+}
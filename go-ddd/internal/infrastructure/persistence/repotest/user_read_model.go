@@ -0,0 +1,176 @@
+package repotest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"yiwen/go-ddd/internal/application/port"
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/repository"
+)
+
+// RunUserReadModelTests 对用户读模型实现运行一致性测试
+// newReadModel 每次调用都应返回一个空读模型
+func RunUserReadModelTests(t *testing.T, newReadModel func(t *testing.T) port.UserReadModel) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, rm port.UserReadModel)
+	}{
+		{"UpsertAndFind", testViewUpsertAndFind},
+		{"DeleteAndReset", testViewDeleteAndReset},
+		{"List", testViewList},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newReadModel(t))
+		})
+	}
+}
+
+var viewBase = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func newView(id uint64, name string) port.UserView {
+	return port.UserView{
+		ID:        id,
+		UUID:      fmt.Sprintf("uuid-%s", name),
+		Username:  name,
+		Email:     name + "@example.com",
+		Nickname:  name,
+		Status:    int(entity.UserStatusActive),
+		Role:      string(entity.UserRoleUser),
+		CreatedAt: viewBase.Add(time.Duration(id) * time.Hour),
+		UpdatedAt: viewBase.Add(time.Duration(id) * time.Hour),
+	}
+}
+
+func upsert(t *testing.T, rm port.UserReadModel, views ...port.UserView) {
+	t.Helper()
+	for _, v := range views {
+		if err := rm.Upsert(context.Background(), v); err != nil {
+			t.Fatalf("upsert %s: %v", v.Username, err)
+		}
+	}
+}
+
+func testViewUpsertAndFind(t *testing.T, rm port.UserReadModel) {
+	ctx := context.Background()
+	if _, err := rm.FindByID(ctx, 1); !errors.Is(err, port.ErrUserViewNotFound) {
+		t.Fatalf("expected ErrUserViewNotFound, got %v", err)
+	}
+
+	v := newView(1, "alice")
+	upsert(t, rm, v)
+
+	// 覆盖写入：UpdatedAt 等字段取投影给出的值，不由存储自动修改
+	locked := viewBase.Add(48 * time.Hour)
+	v.Nickname = "Alice"
+	v.Status = int(entity.UserStatusBanned)
+	v.EmailVerified = true
+	v.MFAEnabled = true
+	v.LockedUntil = &locked
+	v.UpdatedAt = viewBase.Add(72 * time.Hour)
+	upsert(t, rm, v)
+
+	for name, find := range map[string]func() (*port.UserView, error){
+		"FindByID":   func() (*port.UserView, error) { return rm.FindByID(ctx, 1) },
+		"FindByUUID": func() (*port.UserView, error) { return rm.FindByUUID(ctx, "uuid-alice") },
+	} {
+		got, err := find()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if got.Nickname != "Alice" || got.Status != v.Status || !got.EmailVerified || !got.MFAEnabled {
+			t.Fatalf("%s: unexpected view %+v", name, got)
+		}
+		if got.LockedUntil == nil || !got.LockedUntil.Equal(locked) || !got.UpdatedAt.Equal(v.UpdatedAt) || !got.CreatedAt.Equal(v.CreatedAt) {
+			t.Fatalf("%s: times not preserved: %+v", name, got)
+		}
+	}
+}
+
+func testViewDeleteAndReset(t *testing.T, rm port.UserReadModel) {
+	ctx := context.Background()
+	upsert(t, rm, newView(1, "alice"), newView(2, "bob"), newView(3, "carol"))
+
+	if err := rm.Delete(ctx, "uuid-bob"); err != nil {
+		t.Fatal(err)
+	}
+	if err := rm.Delete(ctx, "uuid-missing"); err != nil {
+		t.Fatalf("deleting a missing view should not fail: %v", err)
+	}
+	if _, err := rm.FindByUUID(ctx, "uuid-bob"); !errors.Is(err, port.ErrUserViewNotFound) {
+		t.Fatalf("expected deleted view to be gone, got %v", err)
+	}
+	if _, total, _ := rm.List(ctx, repository.UserListOptions{Limit: 10}); total != 2 {
+		t.Fatalf("expected 2 views after delete, got %d", total)
+	}
+
+	if err := rm.Reset(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if _, total, _ := rm.List(ctx, repository.UserListOptions{Limit: 10}); total != 0 {
+		t.Fatalf("expected empty read model after reset, got %d", total)
+	}
+}
+
+func testViewList(t *testing.T, rm port.UserReadModel) {
+	ctx := context.Background()
+	admin := newView(1, "dan")
+	admin.Role = string(entity.UserRoleAdmin)
+	banned := newView(2, "amy")
+	banned.Status = int(entity.UserStatusBanned)
+	upsert(t, rm, admin, banned, newView(3, "eve"), newView(4, "bob"), newView(5, "cat"))
+
+	names := func(views []port.UserView) []string {
+		var out []string
+		for _, v := range views {
+			out = append(out, v.Username)
+		}
+		return out
+	}
+
+	from := viewBase.Add(3 * time.Hour)
+	for want, filter := range map[string]repository.UserFilter{
+		"[eve bob cat]": {Status: entity.UserStatusActive, CreatedFrom: &from},
+		"[cat]":         {Status: entity.UserStatusActive, Keyword: "T"},
+		"[dan]":         {Role: entity.UserRoleAdmin},
+	} {
+		filtered, total, err := rm.List(ctx, repository.UserListOptions{Filter: filter, Limit: 10})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := names(filtered); fmt.Sprint(got) != want || total != int64(len(got)) {
+			t.Fatalf("expected %s, got %v (total %d)", want, got, total)
+		}
+	}
+
+	// 按用户名正序，每页两个，用上一页最后一行作为游标
+	opts := repository.UserListOptions{SortBy: repository.UserSortByUsername, Limit: 2}
+	var walked []string
+	for i := 0; i < 5; i++ {
+		page, _, err := rm.List(ctx, opts)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(page) == 0 {
+			break
+		}
+		walked = append(walked, names(page)...)
+		last := page[len(page)-1]
+		opts.After = &repository.UserCursor{ID: last.ID, CreatedAt: last.CreatedAt, Username: last.Username}
+	}
+	if fmt.Sprint(walked) != "[amy bob cat dan eve]" {
+		t.Fatalf("cursor walk: got %v", walked)
+	}
+
+	byCreated, _, err := rm.List(ctx, repository.UserListOptions{SortBy: repository.UserSortByCreatedAt, Desc: true, Offset: 1, Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fmt.Sprint(names(byCreated)) != "[bob eve]" {
+		t.Fatalf("unexpected created_at page %v", names(byCreated))
+	}
+}
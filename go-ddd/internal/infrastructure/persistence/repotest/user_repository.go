@@ -0,0 +1,553 @@
+// Package repotest 仓储一致性测试
+// 每种仓储实现（内存、SQLite、MySQL）都用同一组用例验证，保证它们在软删除、
+// 唯一性、分页等行为上可以互相替换
+package repotest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"yiwen/go-ddd/internal/domain/aggregate"
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/repository"
+	domainservice "yiwen/go-ddd/internal/domain/service"
+	"yiwen/go-ddd/internal/domain/valueobject"
+)
+
+// RunUserRepositoryTests 对用户仓储实现运行一致性测试
+// newRepo 每次调用都应返回一个空仓储
+func RunUserRepositoryTests(t *testing.T, newRepo func(t *testing.T) repository.UserRepository) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, repo repository.UserRepository)
+	}{
+		{"SaveAndFind", testSaveAndFind},
+		{"Update", testUpdate},
+		{"MFAFields", testMFAFields},
+		{"PasswordHistory", testPasswordHistory},
+		{"UniqueUsername", testUniqueUsername},
+		{"UniqueEmail", testUniqueEmail},
+		{"SoftDelete", testSoftDelete},
+		{"DeleteThroughAggregate", testDeleteThroughAggregate},
+		{"SaveAggregatesIsAtomic", testSaveAggregatesIsAtomic},
+		{"List", testList},
+		{"ListFilter", testListFilter},
+		{"ListSortAndCursor", testListSortAndCursor},
+		{"CountActiveAdmins", testCountActiveAdmins},
+		{"FindAggregateByID", testFindAggregateByID},
+		{"Erasure", testErasure},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newRepo(t))
+		})
+	}
+}
+
+func newUser(t *testing.T, name string) *entity.User {
+	t.Helper()
+	email, err := valueobject.NewEmail(name + "@example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	user := entity.NewUser("uuid-"+name, name, email, valueobject.NewPasswordFromHash("hash-"+name))
+	user.Nickname = name
+	return user
+}
+
+func save(t *testing.T, repo repository.UserRepository, user *entity.User) {
+	t.Helper()
+	if err := repo.Save(context.Background(), user); err != nil {
+		t.Fatalf("save %s: %v", user.Username, err)
+	}
+}
+
+func testSaveAndFind(t *testing.T, repo repository.UserRepository) {
+	ctx := context.Background()
+	user := newUser(t, "alice")
+	save(t, repo, user)
+	if user.ID == 0 {
+		t.Fatal("expected ID to be assigned on create")
+	}
+
+	finders := map[string]func() (*entity.User, error){
+		"FindByID":       func() (*entity.User, error) { return repo.FindByID(ctx, user.ID) },
+		"FindByUUID":     func() (*entity.User, error) { return repo.FindByUUID(ctx, user.UUID) },
+		"FindByUsername": func() (*entity.User, error) { return repo.FindByUsername(ctx, "alice") },
+		"FindByEmail":    func() (*entity.User, error) { return repo.FindByEmail(ctx, "alice@example.com") },
+	}
+	for name, find := range finders {
+		got, err := find()
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if got.ID != user.ID || got.UUID != user.UUID || got.Username != "alice" ||
+			got.Email.String() != "alice@example.com" || got.Password.Hash() != "hash-alice" ||
+			got.Status != entity.UserStatusActive || got.Role != entity.UserRoleUser {
+			t.Fatalf("%s: unexpected user %+v", name, got)
+		}
+	}
+
+	if _, err := repo.FindByID(ctx, user.ID+100); !errors.Is(err, domainservice.ErrUserNotFound) {
+		t.Fatalf("expected ErrUserNotFound for unknown id, got %v", err)
+	}
+	if _, err := repo.FindByUUID(ctx, "uuid-unknown"); !errors.Is(err, domainservice.ErrUserNotFound) {
+		t.Fatalf("expected ErrUserNotFound for unknown uuid, got %v", err)
+	}
+	if ok, _ := repo.ExistsByUsername(ctx, "alice"); !ok {
+		t.Fatal("expected username to exist")
+	}
+	if ok, _ := repo.ExistsByEmail(ctx, "bob@example.com"); ok {
+		t.Fatal("expected email not to exist")
+	}
+}
+
+func testUpdate(t *testing.T, repo repository.UserRepository) {
+	ctx := context.Background()
+	user := newUser(t, "alice")
+	save(t, repo, user)
+
+	user.UpdateProfile("Alice", "https://example.com/a.png")
+	save(t, repo, user)
+
+	got, err := repo.FindByID(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Nickname != "Alice" || got.Avatar != "https://example.com/a.png" {
+		t.Fatalf("update not persisted: %+v", got)
+	}
+
+	// 返回值是副本，修改它不影响仓储
+	got.Nickname = "changed"
+	again, _ := repo.FindByID(ctx, user.ID)
+	if again.Nickname != "Alice" {
+		t.Fatal("repository returned a shared reference")
+	}
+}
+
+func testMFAFields(t *testing.T, repo repository.UserRepository) {
+	ctx := context.Background()
+	user := newUser(t, "alice")
+	user.BeginMFAEnrollment(valueobject.NewTOTPSecretFromCiphertext("ciphertext"), []string{"h1", "h2"})
+	user.EnableMFA()
+	user.UseTOTPStep(56789012)
+	save(t, repo, user)
+
+	got, err := repo.FindByID(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !got.IsMFAEnabled() || got.TOTPSecret.Ciphertext() != "ciphertext" || got.TOTPLastStep != 56789012 || len(got.RecoveryCodes) != 2 {
+		t.Fatalf("mfa fields not persisted: %+v", got)
+	}
+
+	// 消耗恢复码后只剩一个；返回的切片是副本
+	got.RecoveryCodes[1] = "changed"
+	if !user.UseRecoveryCode("h1") {
+		t.Fatal("expected recovery code to be consumed")
+	}
+	save(t, repo, user)
+	got, _ = repo.FindByID(ctx, user.ID)
+	if len(got.RecoveryCodes) != 1 || got.RecoveryCodes[0] != "h2" {
+		t.Fatalf("recovery codes = %v, want [h2]", got.RecoveryCodes)
+	}
+
+	user.DisableMFA()
+	save(t, repo, user)
+	got, _ = repo.FindByID(ctx, user.ID)
+	if got.IsMFAEnabled() || !got.TOTPSecret.IsZero() || got.TOTPLastStep != 0 || len(got.RecoveryCodes) != 0 {
+		t.Fatalf("mfa not disabled: %+v", got)
+	}
+}
+
+func testPasswordHistory(t *testing.T, repo repository.UserRepository) {
+	ctx := context.Background()
+	user := newUser(t, "alice")
+	save(t, repo, user)
+
+	user.ChangePassword(valueobject.NewPasswordFromHash("hash-2"), 2)
+	user.ChangePassword(valueobject.NewPasswordFromHash("hash-3"), 2)
+	user.ChangePassword(valueobject.NewPasswordFromHash("hash-4"), 2)
+	save(t, repo, user)
+
+	got, err := repo.FindByID(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Password.Hash() != "hash-4" || fmt.Sprint(got.PasswordHistory) != "[hash-3 hash-2]" {
+		t.Fatalf("password = %s, history = %v", got.Password.Hash(), got.PasswordHistory)
+	}
+}
+
+func testUniqueUsername(t *testing.T, repo repository.UserRepository) {
+	save(t, repo, newUser(t, "alice"))
+
+	dup := newUser(t, "alice")
+	dup.UUID = "uuid-other"
+	dup.Email, _ = valueobject.NewEmail("other@example.com")
+	err := repo.Save(context.Background(), dup)
+	if !errors.Is(err, domainservice.ErrUsernameAlreadyExists) {
+		t.Fatalf("expected ErrUsernameAlreadyExists, got %v", err)
+	}
+}
+
+func testUniqueEmail(t *testing.T, repo repository.UserRepository) {
+	save(t, repo, newUser(t, "alice"))
+
+	dup := newUser(t, "bob")
+	dup.Email, _ = valueobject.NewEmail("alice@example.com")
+	err := repo.Save(context.Background(), dup)
+	if !errors.Is(err, domainservice.ErrEmailAlreadyExists) {
+		t.Fatalf("expected ErrEmailAlreadyExists, got %v", err)
+	}
+}
+
+func testSoftDelete(t *testing.T, repo repository.UserRepository) {
+	ctx := context.Background()
+	user := newUser(t, "alice")
+	save(t, repo, user)
+	save(t, repo, newUser(t, "bob"))
+
+	if err := repo.Delete(ctx, user.ID); err != nil {
+		t.Fatal(err)
+	}
+	assertDeleted(t, repo, user)
+
+	// 已删除的用户仍然占用用户名（唯一索引包括已删除的行）
+	again := newUser(t, "alice")
+	again.UUID = "uuid-again"
+	again.Email, _ = valueobject.NewEmail("again@example.com")
+	if err := repo.Save(ctx, again); !errors.Is(err, domainservice.ErrUsernameAlreadyExists) {
+		t.Fatalf("expected ErrUsernameAlreadyExists for deleted username, got %v", err)
+	}
+}
+
+func testDeleteThroughAggregate(t *testing.T, repo repository.UserRepository) {
+	ctx := context.Background()
+	agg := aggregate.Register("uuid-alice", "alice", mustEmail(t, "alice@example.com"), valueobject.NewPasswordFromHash("hash"), "alice")
+	if err := repo.SaveAggregate(ctx, agg); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded, err := repo.FindAggregateByID(ctx, agg.User.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded.Delete()
+	if err := repo.SaveAggregate(ctx, loaded); err != nil {
+		t.Fatal(err)
+	}
+	assertDeleted(t, repo, agg.User)
+}
+
+func testSaveAggregatesIsAtomic(t *testing.T, repo repository.UserRepository) {
+	ctx := context.Background()
+	save(t, repo, newUser(t, "alice"))
+
+	ok := aggregate.Register("uuid-bob", "bob", mustEmail(t, "bob@example.com"), valueobject.NewPasswordFromHash("hash"), "bob")
+	dup := aggregate.Register("uuid-dup", "alice", mustEmail(t, "dup@example.com"), valueobject.NewPasswordFromHash("hash"), "dup")
+	if err := repo.SaveAggregates(ctx, ok, dup); !errors.Is(err, domainservice.ErrUsernameAlreadyExists) {
+		t.Fatalf("expected ErrUsernameAlreadyExists, got %v", err)
+	}
+	if exists, _ := repo.ExistsByUsername(ctx, "bob"); exists {
+		t.Fatal("first aggregate was saved although the batch failed")
+	}
+}
+
+func testList(t *testing.T, repo repository.UserRepository) {
+	ctx := context.Background()
+	var users []*entity.User
+	for i := 0; i < 5; i++ {
+		u := newUser(t, fmt.Sprintf("user%d", i))
+		save(t, repo, u)
+		users = append(users, u)
+	}
+	if err := repo.Delete(ctx, users[4].ID); err != nil {
+		t.Fatal(err)
+	}
+
+	page, total, err := repo.List(ctx, repository.UserListOptions{Desc: true, Offset: 1, Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 4 {
+		t.Fatalf("expected total 4, got %d", total)
+	}
+	// 按ID倒序：user3, user2, user1, user0；offset 1 limit 2 → user2, user1
+	if len(page) != 2 || page[0].ID != users[2].ID || page[1].ID != users[1].ID {
+		t.Fatalf("unexpected page: %v", usernames(page))
+	}
+
+	rest, _, err := repo.List(ctx, repository.UserListOptions{Desc: true, Offset: 10, Limit: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rest) != 0 {
+		t.Fatalf("expected empty page past the end, got %v", usernames(rest))
+	}
+}
+
+func testListFilter(t *testing.T, repo repository.UserRepository) {
+	ctx := context.Background()
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	newAt := func(name string, day int) *entity.User {
+		u := newUser(t, name)
+		u.CreatedAt = base.AddDate(0, 0, day)
+		u.UpdatedAt = u.CreatedAt
+		return u
+	}
+
+	alice := newAt("alice", 0)
+	alice.PromoteToAdmin()
+	save(t, repo, alice)
+	bob := newAt("bob", 1)
+	bob.Ban()
+	save(t, repo, bob)
+	carol := newAt("carol", 2)
+	carol.Nickname = "Davey Jones"
+	save(t, repo, carol)
+	dave := newAt("dave", 3)
+	dave.Deactivate()
+	save(t, repo, dave)
+	deleted := newAt("alibaba", 1)
+	save(t, repo, deleted)
+	if err := repo.Delete(ctx, deleted.ID); err != nil {
+		t.Fatal(err)
+	}
+
+	from, before := base.AddDate(0, 0, 1), base.AddDate(0, 0, 3)
+	tests := []struct {
+		name   string
+		filter repository.UserFilter
+		want   []string
+	}{
+		{"None", repository.UserFilter{}, []string{"alice", "bob", "carol", "dave"}},
+		{"Status", repository.UserFilter{Status: entity.UserStatusBanned}, []string{"bob"}},
+		{"Role", repository.UserFilter{Role: entity.UserRoleAdmin}, []string{"alice"}},
+		{"CreatedRange", repository.UserFilter{CreatedFrom: &from, CreatedBefore: &before}, []string{"bob", "carol"}},
+		{"KeywordUsername", repository.UserFilter{Keyword: "ALI"}, []string{"alice"}},
+		{"KeywordEmail", repository.UserFilter{Keyword: "bob@example"}, []string{"bob"}},
+		{"KeywordNickname", repository.UserFilter{Keyword: "jones"}, []string{"carol"}},
+		{"KeywordWildcards", repository.UserFilter{Keyword: "_"}, nil},
+		{"Combined", repository.UserFilter{Status: entity.UserStatusActive, Keyword: "a"}, []string{"alice", "carol"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			users, total, err := repo.List(ctx, repository.UserListOptions{Filter: tt.filter, Limit: 10})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := usernames(users); fmt.Sprint(got) != fmt.Sprint(tt.want) || total != int64(len(tt.want)) {
+				t.Fatalf("expected %v (total %d), got %v (total %d)", tt.want, len(tt.want), got, total)
+			}
+		})
+	}
+}
+
+func testListSortAndCursor(t *testing.T, repo repository.UserRepository) {
+	ctx := context.Background()
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	// 创建时间有相同值，验证按 ID 定序
+	for i, name := range []string{"dan", "amy", "eve", "bob", "cat"} {
+		u := newUser(t, name)
+		u.CreatedAt = base.Add(time.Duration(i/2) * time.Hour)
+		u.UpdatedAt = u.CreatedAt
+		save(t, repo, u)
+	}
+
+	tests := []struct {
+		sortBy repository.UserSortField
+		desc   bool
+		want   []string
+	}{
+		{repository.UserSortByID, false, []string{"dan", "amy", "eve", "bob", "cat"}},
+		{repository.UserSortByID, true, []string{"cat", "bob", "eve", "amy", "dan"}},
+		{repository.UserSortByCreatedAt, true, []string{"cat", "bob", "eve", "amy", "dan"}},
+		{repository.UserSortByCreatedAt, false, []string{"dan", "amy", "eve", "bob", "cat"}},
+		{repository.UserSortByUsername, false, []string{"amy", "bob", "cat", "dan", "eve"}},
+		{repository.UserSortByUsername, true, []string{"eve", "dan", "cat", "bob", "amy"}},
+	}
+	for _, tt := range tests {
+		name := string(tt.sortBy)
+		if tt.desc {
+			name += "Desc"
+		}
+		t.Run(name, func(t *testing.T) {
+			opts := repository.UserListOptions{SortBy: tt.sortBy, Desc: tt.desc, Limit: 10}
+			all, _, err := repo.List(ctx, opts)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := usernames(all); fmt.Sprint(got) != fmt.Sprint(tt.want) {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+
+			// 每页两个，用上一页最后一个用户作为游标
+			var walked []string
+			opts.Limit = 2
+			for i := 0; i < 5; i++ {
+				page, total, err := repo.List(ctx, opts)
+				if err != nil {
+					t.Fatal(err)
+				}
+				if total != 5 {
+					t.Fatalf("expected total 5 with cursor, got %d", total)
+				}
+				if len(page) == 0 {
+					break
+				}
+				walked = append(walked, usernames(page)...)
+				opts.After = repository.UserCursorOf(page[len(page)-1])
+			}
+			if fmt.Sprint(walked) != fmt.Sprint(tt.want) {
+				t.Fatalf("cursor walk: expected %v, got %v", tt.want, walked)
+			}
+		})
+	}
+}
+
+func testCountActiveAdmins(t *testing.T, repo repository.UserRepository) {
+	ctx := context.Background()
+	admin := newUser(t, "admin")
+	admin.PromoteToAdmin()
+	save(t, repo, admin)
+
+	banned := newUser(t, "banned")
+	banned.PromoteToAdmin()
+	banned.Ban()
+	save(t, repo, banned)
+
+	save(t, repo, newUser(t, "user"))
+
+	count, err := repo.CountActiveAdmins(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Fatalf("expected 1 active admin, got %d", count)
+	}
+}
+
+func testFindAggregateByID(t *testing.T, repo repository.UserRepository) {
+	ctx := context.Background()
+	user := newUser(t, "alice")
+	save(t, repo, user)
+
+	agg, err := repo.FindAggregateByID(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if agg.User.ID != user.ID || len(agg.GetUncommittedEvents()) != 0 {
+		t.Fatalf("unexpected aggregate: %+v", agg)
+	}
+
+	agg.UpdateProfile("Alice", "")
+	if err := repo.SaveAggregate(ctx, agg); err != nil {
+		t.Fatal(err)
+	}
+	got, _ := repo.FindByID(ctx, user.ID)
+	if got.Nickname != "Alice" {
+		t.Fatal("aggregate change not persisted")
+	}
+}
+
+func testErasure(t *testing.T, repo repository.UserRepository) {
+	ctx := context.Background()
+	now := time.Now().Truncate(time.Second)
+	later, sooner := newUser(t, "later"), newUser(t, "sooner")
+	save(t, repo, later)
+	save(t, repo, sooner)
+	save(t, repo, newUser(t, "bystander"))
+
+	for user, grace := range map[*entity.User]time.Duration{later: 2 * time.Hour, sooner: time.Hour} {
+		agg, err := repo.FindAggregateByID(ctx, user.ID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		agg.RequestErasure(now, grace)
+		if err := repo.SaveAggregate(ctx, agg); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	pending, err := repo.FindPendingErasures(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := usernames(pending); fmt.Sprint(got) != "[sooner later]" {
+		t.Fatalf("expected [sooner later], got %v", got)
+	}
+	if !pending[0].ErasureScheduledAt.Equal(now.Add(time.Hour)) || !pending[0].ErasureRequestedAt.Equal(now) {
+		t.Fatalf("erasure schedule not persisted: %v %v", pending[0].ErasureRequestedAt, pending[0].ErasureScheduledAt)
+	}
+
+	agg, err := repo.FindAggregateByID(ctx, sooner.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := agg.Erase(now.Add(time.Hour)); err != nil {
+		t.Fatal(err)
+	}
+	if err := repo.SaveAggregate(ctx, agg); err != nil {
+		t.Fatal(err)
+	}
+	assertDeleted(t, repo, agg.User)
+	if ok, _ := repo.ExistsByUsername(ctx, "sooner"); ok {
+		t.Fatal("erased username still reported as existing")
+	}
+	if ok, _ := repo.ExistsByEmail(ctx, "sooner@example.com"); ok {
+		t.Fatal("erased email still reported as existing")
+	}
+
+	pending, err = repo.FindPendingErasures(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := usernames(pending); fmt.Sprint(got) != "[later]" {
+		t.Fatalf("expected [later] after erasure, got %v", got)
+	}
+}
+
+func assertDeleted(t *testing.T, repo repository.UserRepository, user *entity.User) {
+	t.Helper()
+	ctx := context.Background()
+	if _, err := repo.FindByID(ctx, user.ID); err == nil {
+		t.Fatal("deleted user found by id")
+	}
+	if _, err := repo.FindByUsername(ctx, user.Username); err == nil {
+		t.Fatal("deleted user found by username")
+	}
+	if _, err := repo.FindAggregateByID(ctx, user.ID); err == nil {
+		t.Fatal("deleted user aggregate found")
+	}
+	if ok, _ := repo.ExistsByUsername(ctx, user.Username); ok {
+		t.Fatal("deleted username reported as existing")
+	}
+	users, _, _ := repo.List(ctx, repository.UserListOptions{Limit: 100})
+	for _, u := range users {
+		if u.ID == user.ID {
+			t.Fatal("deleted user listed")
+		}
+	}
+}
+
+func mustEmail(t *testing.T, s string) valueobject.Email {
+	t.Helper()
+	email, err := valueobject.NewEmail(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return email
+}
+
+func usernames(users []*entity.User) []string {
+	names := make([]string, len(users))
+	for i, u := range users {
+		names[i] = u.Username
+	}
+	return names
+}
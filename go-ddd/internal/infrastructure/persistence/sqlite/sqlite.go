@@ -0,0 +1,51 @@
+package sqlite
+
+import (
+	"strings"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+
+	"yiwen/go-ddd/internal/infrastructure/persistence/model"
+)
+
+// Open 打开 SQLite 数据库并自动迁移全部表
+// path 为数据库文件路径，":memory:" 表示内存数据库（进程退出后数据丢失）
+//
+// SQLite 与 MySQL 共用 persistence/mysql 中基于 GORM 的仓储实现，
+// 这里只负责连接和建表，适合本地运行和测试，不需要外部服务
+//
+// 建表使用 GORM 的 AutoMigrate 而非 migration 包的版本化迁移（迁移脚本是 MySQL 方言），
+// SQLite 数据库不记录结构版本，模型变化后旧文件中缺少的列会自动补上，但不会删除或修改已有列
+func Open(path string, config *gorm.Config) (*gorm.DB, error) {
+	if config == nil {
+		config = &gorm.Config{}
+	}
+	config.TranslateError = true // 唯一约束冲突转换为 gorm.ErrDuplicatedKey
+
+	dsn := path
+	if !strings.Contains(dsn, "?") {
+		dsn += "?"
+	} else {
+		dsn += "&"
+	}
+	dsn += "_pragma=foreign_keys(1)&_pragma=busy_timeout(5000)"
+
+	db, err := gorm.Open(sqlite.Open(dsn), config)
+	if err != nil {
+		return nil, err
+	}
+
+	// SQLite 同一时间只允许一个写事务；只用一个连接，事务之间排队而不是返回 SQLITE_BUSY
+	// 内存数据库每个连接都是独立的库，也必须只用一个连接
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+	sqlDB.SetMaxOpenConns(1)
+
+	if err := db.AutoMigrate(model.AllModels()...); err != nil {
+		return nil, err
+	}
+	return db, nil
+}
@@ -0,0 +1,128 @@
+package sqlite
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"yiwen/go-ddd/internal/application/port"
+	"yiwen/go-ddd/internal/domain/aggregate"
+	"yiwen/go-ddd/internal/domain/entity"
+	"yiwen/go-ddd/internal/domain/event"
+	"yiwen/go-ddd/internal/domain/repository"
+	"yiwen/go-ddd/internal/domain/valueobject"
+	"yiwen/go-ddd/internal/infrastructure/messaging"
+	"yiwen/go-ddd/internal/infrastructure/persistence/eventsourced"
+	"yiwen/go-ddd/internal/infrastructure/persistence/mysql"
+	"yiwen/go-ddd/internal/infrastructure/persistence/repotest"
+)
+
+// openTestDB 每个用例使用独立的内存数据库
+func openTestDB(t *testing.T) *gorm.DB {
+	t.Helper()
+	db, err := Open(":memory:", &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+	return db
+}
+
+func TestUserRepository(t *testing.T) {
+	repotest.RunUserRepositoryTests(t, func(t *testing.T) repository.UserRepository {
+		return mysql.NewUserRepository(openTestDB(t))
+	})
+}
+
+func TestEventSourcedUserRepository(t *testing.T) {
+	repotest.RunEventSourcedUserRepositoryTests(t, func(t *testing.T) (repository.UserRepository, event.EventStore) {
+		db := openTestDB(t)
+		store := mysql.NewEventStore(db, event.NewUserEventRegistry())
+		return eventsourced.NewUserRepository(store, mysql.NewUserRepository(db), mysql.NewUserProjector(db), 2), store
+	})
+}
+
+func TestUserReadModel(t *testing.T) {
+	repotest.RunUserReadModelTests(t, func(t *testing.T) port.UserReadModel {
+		return mysql.NewUserReadModel(openTestDB(t))
+	})
+}
+
+func TestUserEventLog(t *testing.T) {
+	repotest.RunUserEventLogTests(t, func(t *testing.T) (port.UserEventLog, repotest.RecordFunc) {
+		db := openTestDB(t)
+		users := mysql.NewUserRepository(db)
+		return mysql.NewUserEventLog(db), func(t *testing.T, agg *aggregate.UserAggregate) {
+			if err := users.SaveAggregate(context.Background(), agg); err != nil {
+				t.Fatal(err)
+			}
+			agg.ClearEvents()
+		}
+	})
+}
+
+func TestOutboxRepository(t *testing.T) {
+	repotest.RunOutboxStoreTests(t, func(t *testing.T) (messaging.OutboxStore, repotest.RecordFunc) {
+		db := openTestDB(t)
+		users := mysql.NewUserRepository(db)
+		return mysql.NewOutboxRepository(db), func(t *testing.T, agg *aggregate.UserAggregate) {
+			if err := users.SaveAggregate(context.Background(), agg); err != nil {
+				t.Fatal(err)
+			}
+			agg.ClearEvents()
+		}
+	})
+}
+
+func TestRefreshTokenRepository(t *testing.T) {
+	repotest.RunRefreshTokenRepositoryTests(t, func(t *testing.T) repository.RefreshTokenRepository {
+		return mysql.NewRefreshTokenRepository(openTestDB(t))
+	})
+}
+
+func TestRevokedTokenRepository(t *testing.T) {
+	repotest.RunRevokedTokenRepositoryTests(t, func(t *testing.T) repository.RevokedTokenRepository {
+		return mysql.NewRevokedTokenRepository(openTestDB(t))
+	})
+}
+
+func TestLoginAttemptRepository(t *testing.T) {
+	repotest.RunLoginAttemptRepositoryTests(t, func(t *testing.T) repository.LoginAttemptRepository {
+		return mysql.NewLoginAttemptRepository(openTestDB(t))
+	})
+}
+
+// 唯一索引冲突后确定冲突字段的查询失败时，返回查询错误而不是当作未冲突
+func TestUniqueViolationCheckError(t *testing.T) {
+	db := openTestDB(t)
+	repo := mysql.NewUserRepository(db)
+	ctx := context.Background()
+	newUser := func(address string) *entity.User {
+		email, err := valueobject.NewEmail(address)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return entity.NewUser("uuid-"+address, "alice", email, valueobject.NewPasswordFromHash("hash"))
+	}
+	if err := repo.Save(ctx, newUser("alice@example.com")); err != nil {
+		t.Fatal(err)
+	}
+
+	errQuery := errors.New("query failed")
+	if err := db.Callback().Query().Before("gorm:query").Register("test:fail_query", func(db *gorm.DB) {
+		db.AddError(errQuery)
+	}); err != nil {
+		t.Fatal(err)
+	}
+	err := repo.Save(ctx, newUser("other@example.com"))
+	if !errors.Is(err, errQuery) {
+		t.Fatalf("expected the query error, got %v", err)
+	}
+}
@@ -0,0 +1,132 @@
+// Package blobtest 文件存储一致性测试
+// 每种存储实现（本地文件系统、S3）都用同一组用例验证，文件通过 URL 读回
+package blobtest
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"testing"
+
+	"yiwen/go-ddd/internal/application/port"
+)
+
+// RunBlobStorageTests 对文件存储实现运行一致性测试
+// newStorage 每次调用都应返回一个空存储，并且 URL 返回的地址可以通过 HTTP 读取
+func RunBlobStorageTests(t *testing.T, newStorage func(t *testing.T) port.BlobStorage) {
+	tests := []struct {
+		name string
+		fn   func(t *testing.T, s port.BlobStorage)
+	}{
+		{"PutAndRead", testPutAndRead},
+		{"DeletePrefix", testDeletePrefix},
+		{"KeyOf", testKeyOf},
+		{"InvalidKey", testInvalidKey},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.fn(t, newStorage(t))
+		})
+	}
+}
+
+func put(t *testing.T, s port.BlobStorage, key, content string) {
+	t.Helper()
+	if err := s.Put(context.Background(), key, bytes.NewReader([]byte(content)), int64(len(content)), "image/png"); err != nil {
+		t.Fatalf("put %s: %v", key, err)
+	}
+}
+
+// read 通过公开地址读取文件，不存在时返回 false
+func read(t *testing.T, s port.BlobStorage, key string) (string, bool) {
+	t.Helper()
+	resp, err := http.Get(s.URL(key))
+	if err != nil {
+		t.Fatalf("get %s: %v", key, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusNotFound {
+		return "", false
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("get %s: unexpected status %s", key, resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(body), true
+}
+
+func testPutAndRead(t *testing.T, s port.BlobStorage) {
+	put(t, s, "avatars/u1/a/256.png", "first")
+	if got, ok := read(t, s, "avatars/u1/a/256.png"); !ok || got != "first" {
+		t.Fatalf("expected first, got %q (found=%v)", got, ok)
+	}
+
+	// 覆盖写入
+	put(t, s, "avatars/u1/a/256.png", "second")
+	if got, _ := read(t, s, "avatars/u1/a/256.png"); got != "second" {
+		t.Fatalf("expected overwritten content, got %q", got)
+	}
+
+	if _, ok := read(t, s, "avatars/u1/a/missing.png"); ok {
+		t.Fatal("expected missing file not to be found")
+	}
+}
+
+func testDeletePrefix(t *testing.T, s port.BlobStorage) {
+	ctx := context.Background()
+	put(t, s, "avatars/u1/a/256.png", "a256")
+	put(t, s, "avatars/u1/a/64.png", "a64")
+	put(t, s, "avatars/u1/ab/64.png", "ab64")
+	put(t, s, "avatars/u1/b/64.png", "b64")
+
+	if err := s.DeletePrefix(ctx, "avatars/u1/a/"); err != nil {
+		t.Fatal(err)
+	}
+	for _, key := range []string{"avatars/u1/a/256.png", "avatars/u1/a/64.png"} {
+		if _, ok := read(t, s, key); ok {
+			t.Fatalf("expected %s to be deleted", key)
+		}
+	}
+	// 只删除该目录，名字以相同字符开头的目录不受影响
+	for _, key := range []string{"avatars/u1/ab/64.png", "avatars/u1/b/64.png"} {
+		if _, ok := read(t, s, key); !ok {
+			t.Fatalf("expected %s to be kept", key)
+		}
+	}
+
+	// 没有匹配的文件时不报错
+	if err := s.DeletePrefix(ctx, "avatars/u1/a/"); err != nil {
+		t.Fatalf("expected deleting an empty prefix to succeed, got %v", err)
+	}
+	if err := s.DeletePrefix(ctx, "avatars/u1/b"); err == nil {
+		t.Fatal("expected error for prefix without trailing slash")
+	}
+}
+
+func testKeyOf(t *testing.T, s port.BlobStorage) {
+	key := "avatars/u1/a/256.png"
+	if got, ok := s.KeyOf(s.URL(key)); !ok || got != key {
+		t.Fatalf("expected %s, got %q (ok=%v)", key, got, ok)
+	}
+	for _, url := range []string{"https://elsewhere.example.com/avatars/u1/a/256.png", "", s.URL("../secret")} {
+		if got, ok := s.KeyOf(url); ok {
+			t.Fatalf("expected %q not to belong to the storage, got key %q", url, got)
+		}
+	}
+}
+
+func testInvalidKey(t *testing.T, s port.BlobStorage) {
+	ctx := context.Background()
+	for _, key := range []string{"../escape.png", "/abs.png", "a//b.png", "a/./b.png", ""} {
+		if err := s.Put(ctx, key, bytes.NewReader(nil), 0, "image/png"); err == nil {
+			t.Fatalf("expected error for key %q", key)
+		}
+	}
+	if err := s.DeletePrefix(ctx, "../"); err == nil {
+		t.Fatal("expected error for prefix escaping the root")
+	}
+}
@@ -0,0 +1,211 @@
+package blobtest
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// FakeS3 内存中的 S3 兼容服务，用于测试 S3 适配器
+// 支持单个存储桶的 PutObject、GetObject（允许匿名读取）、DeleteObject 和 ListObjectsV2，
+// 带 Authorization 头的请求都会独立校验 SigV4 签名和内容哈希
+type FakeS3 struct {
+	URL    string
+	Region string
+	Bucket string
+
+	AccessKeyID     string
+	SecretAccessKey string
+
+	// MaxKeys 每页最多返回的对象数，便于测试翻页
+	MaxKeys int
+
+	mu      sync.Mutex
+	objects map[string][]byte
+}
+
+// NewFakeS3 启动 FakeS3，测试结束时自动关闭
+func NewFakeS3(t *testing.T) *FakeS3 {
+	f := &FakeS3{
+		Region:          "us-east-1",
+		Bucket:          "avatars-test",
+		AccessKeyID:     "AKIDEXAMPLE",
+		SecretAccessKey: "wJalrXUtnFEMI/K7MDENG+bPxRfiCYEXAMPLEKEY",
+		MaxKeys:         2,
+		objects:         make(map[string][]byte),
+	}
+	server := httptest.NewServer(f)
+	t.Cleanup(server.Close)
+	f.URL = server.URL
+	return f
+}
+
+// ServeHTTP 实现 http.Handler
+func (f *FakeS3) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		f.fail(w, http.StatusBadRequest, "IncompleteBody", err.Error())
+		return
+	}
+
+	bucket, key, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/"), "/")
+	if bucket != f.Bucket {
+		f.fail(w, http.StatusNotFound, "NoSuchBucket", bucket)
+		return
+	}
+
+	anonymousRead := r.Method == http.MethodGet && key != "" && r.Header.Get("Authorization") == ""
+	if !anonymousRead {
+		if msg := f.verify(r, body); msg != "" {
+			f.fail(w, http.StatusForbidden, "SignatureDoesNotMatch", msg)
+			return
+		}
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch {
+	case r.Method == http.MethodPut && key != "":
+		f.objects[key] = body
+		w.WriteHeader(http.StatusOK)
+	case r.Method == http.MethodGet && key != "":
+		data, ok := f.objects[key]
+		if !ok {
+			f.fail(w, http.StatusNotFound, "NoSuchKey", key)
+			return
+		}
+		_, _ = w.Write(data)
+	case r.Method == http.MethodDelete && key != "":
+		delete(f.objects, key)
+		w.WriteHeader(http.StatusNoContent)
+	case r.Method == http.MethodGet && r.URL.Query().Get("list-type") == "2":
+		f.list(w, r.URL.Query())
+	default:
+		f.fail(w, http.StatusMethodNotAllowed, "MethodNotAllowed", r.Method)
+	}
+}
+
+// list 实现 ListObjectsV2，continuation-token 为上一页最后一个 key
+func (f *FakeS3) list(w http.ResponseWriter, query url.Values) {
+	prefix, after := query.Get("prefix"), query.Get("continuation-token")
+	var keys []string
+	for k := range f.objects {
+		if strings.HasPrefix(k, prefix) && k > after {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+
+	type content struct {
+		Key string `xml:"Key"`
+	}
+	result := struct {
+		XMLName               xml.Name  `xml:"ListBucketResult"`
+		Contents              []content `xml:"Contents"`
+		IsTruncated           bool      `xml:"IsTruncated"`
+		NextContinuationToken string    `xml:"NextContinuationToken,omitempty"`
+	}{}
+	if len(keys) > f.MaxKeys {
+		keys = keys[:f.MaxKeys]
+		result.IsTruncated = true
+		result.NextContinuationToken = keys[len(keys)-1]
+	}
+	for _, k := range keys {
+		result.Contents = append(result.Contents, content{Key: k})
+	}
+	w.Header().Set("Content-Type", "application/xml")
+	_ = xml.NewEncoder(w).Encode(result)
+}
+
+// verify 重新计算 SigV4 签名，不匹配时返回原因
+func (f *FakeS3) verify(r *http.Request, body []byte) string {
+	auth := r.Header.Get("Authorization")
+	fields := map[string]string{}
+	for _, part := range strings.Split(strings.TrimPrefix(auth, "AWS4-HMAC-SHA256 "), ", ") {
+		k, v, _ := strings.Cut(part, "=")
+		fields[k] = v
+	}
+	credential := strings.Split(fields["Credential"], "/")
+	if len(credential) != 5 || credential[0] != f.AccessKeyID || credential[2] != f.Region || credential[3] != "s3" {
+		return "bad credential " + fields["Credential"]
+	}
+
+	sum := sha256.Sum256(body)
+	payloadHash := hex.EncodeToString(sum[:])
+	if r.Header.Get("X-Amz-Content-Sha256") != payloadHash {
+		return "payload hash mismatch"
+	}
+
+	var headers strings.Builder
+	for _, h := range strings.Split(fields["SignedHeaders"], ";") {
+		value := r.Header.Get(h)
+		if h == "host" {
+			value = r.Host
+		}
+		fmt.Fprintf(&headers, "%s:%s\n", h, strings.TrimSpace(value))
+	}
+
+	query := r.URL.Query()
+	names := make([]string, 0, len(query))
+	for k := range query {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	var params []string
+	for _, k := range names {
+		params = append(params, escape(k)+"="+escape(query.Get(k)))
+	}
+
+	canonical := strings.Join([]string{
+		r.Method,
+		strings.ReplaceAll(escape(r.URL.Path), "%2F", "/"),
+		strings.Join(params, "&"),
+		headers.String(),
+		fields["SignedHeaders"],
+		payloadHash,
+	}, "\n")
+	canonicalSum := sha256.Sum256([]byte(canonical))
+	amzDate := r.Header.Get("X-Amz-Date")
+	scope := strings.Join(credential[1:], "/")
+	stringToSign := "AWS4-HMAC-SHA256\n" + amzDate + "\n" + scope + "\n" + hex.EncodeToString(canonicalSum[:])
+
+	key := []byte("AWS4" + f.SecretAccessKey)
+	for _, part := range append(credential[1:4], "aws4_request") {
+		key = sign(key, part)
+	}
+	if expected := hex.EncodeToString(sign(key, stringToSign)); !hmac.Equal([]byte(expected), []byte(fields["Signature"])) {
+		return "signature mismatch"
+	}
+	return ""
+}
+
+func (f *FakeS3) fail(w http.ResponseWriter, status int, code, message string) {
+	w.Header().Set("Content-Type", "application/xml")
+	w.WriteHeader(status)
+	_ = xml.NewEncoder(w).Encode(struct {
+		XMLName xml.Name `xml:"Error"`
+		Code    string   `xml:"Code"`
+		Message string   `xml:"Message"`
+	}{Code: code, Message: message})
+}
+
+func sign(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// escape 按 RFC 3986 编码，空格编码为 %20
+func escape(s string) string {
+	return strings.ReplaceAll(url.QueryEscape(s), "+", "%20")
+}
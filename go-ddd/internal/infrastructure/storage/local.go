@@ -0,0 +1,111 @@
+// Package storage 文件存储适配器（本地文件系统、S3 兼容的对象存储）
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+var errInvalidKey = errors.New("invalid blob key")
+
+// LocalStorage 本地文件系统存储，文件由本服务以静态文件的形式对外提供
+type LocalStorage struct {
+	dir     string
+	baseURL string
+}
+
+// NewLocalStorage 创建本地文件存储
+// dir 为存放文件的根目录（不存在时创建），baseURL 为该目录对外的访问地址，如 http://localhost:8080/uploads
+func NewLocalStorage(dir, baseURL string) (*LocalStorage, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	return &LocalStorage{dir: dir, baseURL: strings.TrimSuffix(baseURL, "/")}, nil
+}
+
+// Dir 返回存放文件的根目录
+func (s *LocalStorage) Dir() string {
+	return s.dir
+}
+
+// Put 实现 port.BlobStorage，先写临时文件再重命名，读者不会看到写了一半的文件
+func (s *LocalStorage) Put(ctx context.Context, key string, body io.Reader, size int64, contentType string) error {
+	name, err := s.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return err
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".upload-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, body); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), name)
+}
+
+// DeletePrefix 实现 port.BlobStorage，删除 prefix 对应的目录
+func (s *LocalStorage) DeletePrefix(ctx context.Context, prefix string) error {
+	if !strings.HasSuffix(prefix, "/") {
+		return fmt.Errorf("%w: prefix %q must end with /", errInvalidKey, prefix)
+	}
+	dir, err := s.path(strings.TrimSuffix(prefix, "/"))
+	if err != nil {
+		return err
+	}
+	return os.RemoveAll(dir)
+}
+
+// URL 实现 port.BlobStorage
+func (s *LocalStorage) URL(key string) string {
+	return s.baseURL + "/" + key
+}
+
+// KeyOf 实现 port.BlobStorage
+func (s *LocalStorage) KeyOf(url string) (string, bool) {
+	return keyOf(s.baseURL, url)
+}
+
+// path 将 key 转换为根目录下的文件路径，拒绝逃出根目录的 key
+func (s *LocalStorage) path(key string) (string, error) {
+	if err := validateKey(key); err != nil {
+		return "", err
+	}
+	return filepath.Join(s.dir, filepath.FromSlash(key)), nil
+}
+
+// validateKey key 必须是规范的相对路径，不能包含 ..、空段或以 / 开头
+func validateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || path.Clean(key) != key || key == ".." || strings.HasPrefix(key, "../") {
+		return fmt.Errorf("%w: %q", errInvalidKey, key)
+	}
+	return nil
+}
+
+// keyOf 去掉访问地址的前缀得到 key
+func keyOf(baseURL, url string) (string, bool) {
+	key, ok := strings.CutPrefix(url, baseURL+"/")
+	if !ok || validateKey(key) != nil {
+		return "", false
+	}
+	return key, true
+}
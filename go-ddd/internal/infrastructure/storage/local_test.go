@@ -0,0 +1,24 @@
+package storage
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"yiwen/go-ddd/internal/application/port"
+	"yiwen/go-ddd/internal/infrastructure/storage/blobtest"
+)
+
+func TestLocalStorage(t *testing.T) {
+	blobtest.RunBlobStorageTests(t, func(t *testing.T) port.BlobStorage {
+		dir := t.TempDir()
+		server := httptest.NewServer(http.FileServer(http.Dir(dir)))
+		t.Cleanup(server.Close)
+
+		s, err := NewLocalStorage(dir, server.URL)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	})
+}
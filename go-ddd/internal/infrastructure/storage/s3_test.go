@@ -0,0 +1,45 @@
+package storage
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"yiwen/go-ddd/internal/application/port"
+	"yiwen/go-ddd/internal/infrastructure/storage/blobtest"
+)
+
+func TestS3Storage(t *testing.T) {
+	blobtest.RunBlobStorageTests(t, func(t *testing.T) port.BlobStorage {
+		fake := blobtest.NewFakeS3(t)
+		s, err := NewS3Storage(S3Config{
+			Endpoint:        fake.URL,
+			Region:          fake.Region,
+			Bucket:          fake.Bucket,
+			AccessKeyID:     fake.AccessKeyID,
+			SecretAccessKey: fake.SecretAccessKey,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		return s
+	})
+}
+
+func TestS3StorageRejectsWrongSecret(t *testing.T) {
+	fake := blobtest.NewFakeS3(t)
+	s, err := NewS3Storage(S3Config{
+		Endpoint:        fake.URL,
+		Region:          fake.Region,
+		Bucket:          fake.Bucket,
+		AccessKeyID:     fake.AccessKeyID,
+		SecretAccessKey: "wrong",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = s.Put(context.Background(), "avatars/u1/a/64.png", strings.NewReader("x"), 1, "image/png")
+	if err == nil || !strings.Contains(err.Error(), "SignatureDoesNotMatch") {
+		t.Fatalf("expected signature error, got %v", err)
+	}
+}
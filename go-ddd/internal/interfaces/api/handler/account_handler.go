@@ -0,0 +1,115 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"yiwen/go-ddd/internal/application/command"
+	"yiwen/go-ddd/internal/application/dto"
+	"yiwen/go-ddd/internal/application/service"
+)
+
+// AccountHandler 邮箱验证和密码重置处理器，接口均无需认证
+type AccountHandler struct {
+	accountService *service.AccountApplicationService
+}
+
+// NewAccountHandler 创建账户处理器
+func NewAccountHandler(accountService *service.AccountApplicationService) *AccountHandler {
+	return &AccountHandler{
+		accountService: accountService,
+	}
+}
+
+// VerifyEmail 验证邮箱
+// GET /api/v1/users/verify-email?token=...（邮件中的链接）
+// POST /api/v1/users/verify-email
+func (h *AccountHandler) VerifyEmail(c *gin.Context) {
+	var req dto.TokenRequest
+	var err error
+	if c.Request.Method == http.MethodGet {
+		err = c.ShouldBindQuery(&req)
+	} else {
+		err = c.ShouldBindJSON(&req)
+	}
+	if err != nil {
+		respondError(c, bindError(err))
+		return
+	}
+
+	user, err := h.accountService.VerifyEmail(c.Request.Context(), command.NewVerifyEmailCommand(req.Token))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "email verified successfully",
+		"data":    user,
+	})
+}
+
+// ResendVerification 重新发送验证邮件
+// POST /api/v1/users/verify-email/resend
+func (h *AccountHandler) ResendVerification(c *gin.Context) {
+	var req dto.EmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, bindError(err))
+		return
+	}
+
+	if err := h.accountService.ResendVerification(c.Request.Context(), command.NewResendVerificationCommand(req.Email)); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	// 无论邮箱是否存在都返回同样的响应
+	c.JSON(http.StatusAccepted, gin.H{
+		"code":    0,
+		"message": "if the email is registered and not yet verified, a verification email has been sent",
+	})
+}
+
+// RequestPasswordReset 申请重置密码
+// POST /api/v1/users/password-reset
+func (h *AccountHandler) RequestPasswordReset(c *gin.Context) {
+	var req dto.EmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, bindError(err))
+		return
+	}
+
+	if err := h.accountService.RequestPasswordReset(c.Request.Context(), command.NewRequestPasswordResetCommand(req.Email)); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	// 无论邮箱是否存在都返回同样的响应
+	c.JSON(http.StatusAccepted, gin.H{
+		"code":    0,
+		"message": "if the email is registered, a password reset email has been sent",
+	})
+}
+
+// ResetPassword 使用重置令牌设置新密码
+// POST /api/v1/users/password-reset/confirm
+func (h *AccountHandler) ResetPassword(c *gin.Context) {
+	var req dto.ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, bindError(err))
+		return
+	}
+
+	cmd := command.NewResetPasswordCommand(req.Token, req.NewPassword)
+	if err := h.accountService.ResetPassword(c.Request.Context(), cmd); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "password reset successfully",
+	})
+}
@@ -0,0 +1,77 @@
+package handler
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"yiwen/go-ddd/internal/application/bus"
+	"yiwen/go-ddd/internal/application/command"
+	"yiwen/go-ddd/internal/application/dto"
+	"yiwen/go-ddd/internal/application/port"
+	apperrors "yiwen/go-ddd/pkg/errors"
+)
+
+// multipartOverhead 请求体中除文件外的 multipart 边界、头部等允许的字节数
+const multipartOverhead = 64 << 10
+
+var errAvatarRequired = apperrors.NewAppError(http.StatusBadRequest, apperrors.CodeValidationFailed, "avatar file is required", nil)
+
+// AvatarHandler 头像处理器
+type AvatarHandler struct {
+	commands *bus.Bus
+	maxBytes int64
+}
+
+// NewAvatarHandler 创建头像处理器，maxBytes 为头像文件的最大字节数
+func NewAvatarHandler(commands *bus.Bus, maxBytes int64) *AvatarHandler {
+	return &AvatarHandler{
+		commands: commands,
+		maxBytes: maxBytes,
+	}
+}
+
+// UploadAvatar 上传头像，multipart/form-data 的 avatar 字段为图片文件
+// POST /api/v1/users/:id/avatar
+func (h *AvatarHandler) UploadAvatar(c *gin.Context) {
+	id, err := strconv.ParseUint(c.Param("id"), 10, 64)
+	if err != nil {
+		respondError(c, errInvalidUserID)
+		return
+	}
+
+	// 限制请求体大小，超出时停止读取，不把整个请求读进内存或临时文件
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxBytes+multipartOverhead)
+	header, err := c.FormFile("avatar")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			respondError(c, fmt.Errorf("%w: exceeds the limit of %d bytes", port.ErrImageTooLarge, h.maxBytes))
+			return
+		}
+		respondError(c, errAvatarRequired.WithCause(err))
+		return
+	}
+	file, err := header.Open()
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	defer file.Close()
+
+	cmd := command.NewUploadAvatarCommand(id, header.Header.Get("Content-Type"), header.Size, file)
+	avatar, err := bus.Dispatch[*dto.AvatarDTO](c.Request.Context(), h.commands, cmd)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data":    avatar,
+	})
+}
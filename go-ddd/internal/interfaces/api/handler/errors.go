@@ -0,0 +1,72 @@
+package handler
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+
+	domainservice "yiwen/go-ddd/internal/domain/service"
+	apperrors "yiwen/go-ddd/pkg/errors"
+)
+
+// 接口层自身的错误，领域层、应用层的错误由 errmap 映射
+var (
+	errInvalidUserID   = apperrors.NewAppError(http.StatusBadRequest, "invalid_user_id", "invalid user id", nil)
+	errSelfAction      = apperrors.NewAppError(http.StatusBadRequest, "self_action_forbidden", "cannot perform this action on yourself", nil)
+	errUnauthenticated = apperrors.ErrUnauthorizedError("unauthorized")
+	errInvalidRequest  = apperrors.NewAppError(http.StatusBadRequest, apperrors.CodeBadRequest, "invalid request", nil)
+)
+
+func init() {
+	// 参数校验失败时用 json、form 标签中的名字报告字段，与客户端看到的参数名一致
+	if v, ok := binding.Validator.Engine().(*validator.Validate); ok {
+		v.RegisterTagNameFunc(func(field reflect.StructField) string {
+			for _, tag := range []string{"json", "form"} {
+				name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+				if name == "-" {
+					return ""
+				}
+				if name != "" {
+					return name
+				}
+			}
+			return field.Name
+		})
+	}
+}
+
+// respondError 记录错误，响应由 middleware.ErrorHandler 统一渲染为 problem+json
+// 登录处于退避或锁定中时额外设置 Retry-After；不论用户名是否存在都返回同样的响应
+func respondError(c *gin.Context, err error) {
+	var blocked *domainservice.LoginBlockedError
+	if errors.As(err, &blocked) {
+		c.Header("Retry-After", strconv.Itoa(int(math.Ceil(blocked.RetryAfter.Seconds()))))
+	}
+	_ = c.Error(err)
+}
+
+// bindError 将请求参数绑定、校验的错误转换为 400，逐个列出校验失败的字段
+// JSON 解析错误的原始信息可能包含内部类型名，不返回给客户端
+func bindError(err error) error {
+	var invalid validator.ValidationErrors
+	if !errors.As(err, &invalid) {
+		return errInvalidRequest.WithCause(err)
+	}
+
+	appErr := apperrors.NewAppError(http.StatusBadRequest, apperrors.CodeValidationFailed, "request parameters are invalid", err)
+	for _, fe := range invalid {
+		reason := "failed on " + fe.Tag()
+		if fe.Param() != "" {
+			reason += "=" + fe.Param()
+		}
+		appErr.InvalidParams = append(appErr.InvalidParams, apperrors.InvalidParam{Name: fe.Field(), Reason: reason})
+	}
+	return appErr
+}
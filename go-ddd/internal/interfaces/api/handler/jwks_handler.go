@@ -0,0 +1,33 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"yiwen/go-ddd/internal/infrastructure/auth"
+)
+
+// JWKSProvider 提供用于验证访问令牌的公钥集合
+type JWKSProvider interface {
+	JWKS() auth.JSONWebKeySet
+}
+
+// JWKSHandler 公钥发布处理器
+// 其他服务可通过 JWKS 独立验证本服务签发的访问令牌，无需共享密钥
+type JWKSHandler struct {
+	provider JWKSProvider
+}
+
+// NewJWKSHandler 创建公钥发布处理器
+func NewJWKSHandler(provider JWKSProvider) *JWKSHandler {
+	return &JWKSHandler{provider: provider}
+}
+
+// JWKS 返回公钥集合（RFC 7517）
+// GET /.well-known/jwks.json
+func (h *JWKSHandler) JWKS(c *gin.Context) {
+	// 允许缓存，但要短于密钥重新加载的周期，以便尽快发现新密钥
+	c.Header("Cache-Control", "public, max-age=60")
+	c.JSON(http.StatusOK, h.provider.JWKS())
+}
@@ -0,0 +1,155 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"yiwen/go-ddd/internal/application/command"
+	"yiwen/go-ddd/internal/application/dto"
+	"yiwen/go-ddd/internal/application/service"
+	"yiwen/go-ddd/internal/interfaces/api/middleware"
+)
+
+// MFAHandler 两步验证处理器
+// 登录第二步和登录过程中的绑定使用两步验证令牌（无需认证），其余接口针对当前登录用户
+type MFAHandler struct {
+	mfaService  *service.MFAApplicationService
+	authService *service.AuthApplicationService
+	jwtAuth     *middleware.JWTAuth
+}
+
+// NewMFAHandler 创建两步验证处理器
+func NewMFAHandler(mfaService *service.MFAApplicationService, authService *service.AuthApplicationService, jwtAuth *middleware.JWTAuth) *MFAHandler {
+	return &MFAHandler{
+		mfaService:  mfaService,
+		authService: authService,
+		jwtAuth:     jwtAuth,
+	}
+}
+
+// VerifyLogin 登录第二步：提交验证码或恢复码换取访问令牌
+// POST /api/v1/users/login/mfa
+func (h *MFAHandler) VerifyLogin(c *gin.Context) {
+	var req dto.MFALoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, bindError(err))
+		return
+	}
+
+	cmd := command.NewMFAChallengeCommand(req.MFAToken, req.Code, c.ClientIP())
+	user, err := h.mfaService.VerifyLogin(c.Request.Context(), cmd)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondWithTokens(c, h.jwtAuth, h.authService, user)
+}
+
+// EnrollWithChallenge 策略要求绑定两步验证时，在登录过程中开始绑定
+// POST /api/v1/users/login/mfa/enroll
+func (h *MFAHandler) EnrollWithChallenge(c *gin.Context) {
+	var req dto.MFATokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, bindError(err))
+		return
+	}
+
+	cmd := command.NewMFAChallengeCommand(req.MFAToken, "", c.ClientIP())
+	enrollment, err := h.mfaService.EnrollWithChallenge(c.Request.Context(), cmd)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data":    enrollment,
+	})
+}
+
+// ConfirmWithChallenge 在登录过程中确认绑定，成功后签发访问令牌
+// POST /api/v1/users/login/mfa/enroll/confirm
+func (h *MFAHandler) ConfirmWithChallenge(c *gin.Context) {
+	var req dto.MFALoginRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, bindError(err))
+		return
+	}
+
+	cmd := command.NewMFAChallengeCommand(req.MFAToken, req.Code, c.ClientIP())
+	user, err := h.mfaService.ConfirmWithChallenge(c.Request.Context(), cmd)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	respondWithTokens(c, h.jwtAuth, h.authService, user)
+}
+
+// Enroll 当前用户开始绑定两步验证
+// POST /api/v1/users/me/mfa
+func (h *MFAHandler) Enroll(c *gin.Context) {
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		respondError(c, errUnauthenticated)
+		return
+	}
+
+	enrollment, err := h.mfaService.Enroll(c.Request.Context(), command.NewEnrollMFACommand(userID))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data":    enrollment,
+	})
+}
+
+// Confirm 当前用户提交验证码确认绑定
+// POST /api/v1/users/me/mfa/confirm
+func (h *MFAHandler) Confirm(c *gin.Context) {
+	h.withCode(c, "two-factor authentication enabled", func(userID uint64, code string) (*dto.UserDTO, error) {
+		return h.mfaService.Confirm(c.Request.Context(), command.NewConfirmMFACommand(userID, code))
+	})
+}
+
+// Disable 当前用户关闭两步验证
+// DELETE /api/v1/users/me/mfa
+func (h *MFAHandler) Disable(c *gin.Context) {
+	h.withCode(c, "two-factor authentication disabled", func(userID uint64, code string) (*dto.UserDTO, error) {
+		return h.mfaService.Disable(c.Request.Context(), command.NewDisableMFACommand(userID, code))
+	})
+}
+
+// withCode 当前用户提交验证码的接口的公共流程
+func (h *MFAHandler) withCode(c *gin.Context, message string, action func(userID uint64, code string) (*dto.UserDTO, error)) {
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		respondError(c, errUnauthenticated)
+		return
+	}
+
+	var req dto.MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, bindError(err))
+		return
+	}
+
+	user, err := action(userID, req.Code)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": message,
+		"data":    user,
+	})
+}
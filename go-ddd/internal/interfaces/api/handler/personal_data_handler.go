@@ -0,0 +1,107 @@
+package handler
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"yiwen/go-ddd/internal/application/bus"
+	"yiwen/go-ddd/internal/application/command"
+	"yiwen/go-ddd/internal/application/dto"
+	"yiwen/go-ddd/internal/application/query"
+	"yiwen/go-ddd/internal/interfaces/api/middleware"
+)
+
+// PersonalDataHandler 个人数据处理器：导出个人数据和删除账户
+type PersonalDataHandler struct {
+	commands *bus.Bus
+	queries  *bus.Bus
+}
+
+// NewPersonalDataHandler 创建个人数据处理器
+func NewPersonalDataHandler(commands, queries *bus.Bus) *PersonalDataHandler {
+	return &PersonalDataHandler{
+		commands: commands,
+		queries:  queries,
+	}
+}
+
+// ExportData 当前用户导出个人数据，以 JSON 文件下载
+// GET /api/v1/users/me/export
+func (h *PersonalDataHandler) ExportData(c *gin.Context) {
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		respondError(c, errUnauthenticated)
+		return
+	}
+
+	export, err := bus.Dispatch[*dto.UserDataExportDTO](c.Request.Context(), h.commands, command.NewExportUserDataCommand(userID))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="user-%s-export.json"`, export.User.UUID))
+	c.IndentedJSON(http.StatusOK, export)
+}
+
+// RequestErasure 当前用户申请删除账户，宽限期结束后擦除个人数据
+// DELETE /api/v1/users/me
+func (h *PersonalDataHandler) RequestErasure(c *gin.Context) {
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		respondError(c, errUnauthenticated)
+		return
+	}
+
+	erasure, err := bus.Dispatch[*dto.ErasureDTO](c.Request.Context(), h.commands, command.NewRequestErasureCommand(userID))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, gin.H{
+		"code":    0,
+		"message": "account deletion scheduled",
+		"data":    erasure,
+	})
+}
+
+// CancelErasure 当前用户在宽限期内撤销删除申请
+// DELETE /api/v1/users/me/erasure
+func (h *PersonalDataHandler) CancelErasure(c *gin.Context) {
+	userID, exists := middleware.GetUserIDFromContext(c)
+	if !exists {
+		respondError(c, errUnauthenticated)
+		return
+	}
+
+	user, err := bus.Dispatch[*dto.UserDTO](c.Request.Context(), h.commands, command.NewCancelErasureCommand(userID))
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "account deletion cancelled",
+		"data":    user,
+	})
+}
+
+// ListPendingErasures 管理员查看等待擦除的删除申请
+// GET /api/v1/users/erasures
+func (h *PersonalDataHandler) ListPendingErasures(c *gin.Context) {
+	erasures, err := bus.Dispatch[[]dto.ErasureDTO](c.Request.Context(), h.queries, query.NewListPendingErasuresQuery())
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data":    erasures,
+	})
+}
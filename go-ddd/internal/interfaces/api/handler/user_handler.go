@@ -6,6 +6,7 @@ import (
 
 	"github.com/gin-gonic/gin"
 
+	"yiwen/go-ddd/internal/application/bus"
 	"yiwen/go-ddd/internal/application/command"
 	"yiwen/go-ddd/internal/application/dto"
 	"yiwen/go-ddd/internal/application/query"
@@ -19,15 +20,25 @@ import (
 // 2. 参数校验和转换
 // 3. 调用应用服务
 // 4. 返回HTTP响应
+//
+// 用户命令和查询通过命令总线、查询总线分发；登录涉及退避计数和令牌签发，仍直接调用应用服务
 type UserHandler struct {
 	userService *service.UserApplicationService
+	authService *service.AuthApplicationService
+	mfaService  *service.MFAApplicationService
+	commands    *bus.Bus
+	queries     *bus.Bus
 	jwtAuth     *middleware.JWTAuth
 }
 
 // NewUserHandler 创建用户处理器
-func NewUserHandler(userService *service.UserApplicationService, jwtAuth *middleware.JWTAuth) *UserHandler {
+func NewUserHandler(userService *service.UserApplicationService, authService *service.AuthApplicationService, mfaService *service.MFAApplicationService, commands, queries *bus.Bus, jwtAuth *middleware.JWTAuth) *UserHandler {
 	return &UserHandler{
 		userService: userService,
+		authService: authService,
+		mfaService:  mfaService,
+		commands:    commands,
+		queries:     queries,
 		jwtAuth:     jwtAuth,
 	}
 }
@@ -37,20 +48,14 @@ func NewUserHandler(userService *service.UserApplicationService, jwtAuth *middle
 func (h *UserHandler) Register(c *gin.Context) {
 	var req dto.RegisterRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": err.Error(),
-		})
+		respondError(c, bindError(err))
 		return
 	}
 
 	cmd := command.NewRegisterUserCommand(req.Username, req.Email, req.Password, req.Nickname)
-	user, err := h.userService.Register(c.Request.Context(), cmd)
+	user, err := bus.Dispatch[*dto.UserDTO](c.Request.Context(), h.commands, cmd)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": err.Error(),
-		})
+		respondError(c, err)
 		return
 	}
 
@@ -66,30 +71,82 @@ func (h *UserHandler) Register(c *gin.Context) {
 func (h *UserHandler) Login(c *gin.Context) {
 	var req dto.LoginRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": err.Error(),
-		})
+		respondError(c, bindError(err))
 		return
 	}
 
-	q := query.NewLoginQuery(req.Username, req.Password)
+	q := query.NewLoginQuery(req.Username, req.Password, c.ClientIP())
 	user, err := h.userService.Login(c.Request.Context(), q)
 	if err != nil {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"code":    401,
-			"message": err.Error(),
+		respondError(c, err)
+		return
+	}
+
+	// 启用（或策略要求启用）两步验证时只返回两步验证令牌，提交验证码后再签发访问令牌
+	challenge, err := h.mfaService.LoginChallenge(c.Request.Context(), user.ID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+	if challenge != nil {
+		c.JSON(http.StatusOK, gin.H{
+			"code":    0,
+			"message": "mfa required",
+			"data":    challenge,
 		})
 		return
 	}
 
+	respondWithTokens(c, h.jwtAuth, h.authService, user)
+}
+
+// respondWithTokens 登录完成：签发访问令牌和刷新令牌
+func respondWithTokens(c *gin.Context, jwtAuth *middleware.JWTAuth, authService *service.AuthApplicationService, user *dto.UserDTO) {
 	// 生成JWT Token
+	token, expiresAt, err := jwtAuth.GenerateToken(user.ID, user.Username, user.Role)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	refreshToken, refreshExpiresAt, err := authService.IssueRefreshToken(c.Request.Context(), user.ID)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data": dto.LoginResponse{
+			Token:            token,
+			ExpiresAt:        expiresAt,
+			RefreshToken:     refreshToken,
+			RefreshExpiresAt: refreshExpiresAt.Unix(),
+			User:             *user,
+		},
+	})
+}
+
+// Refresh 刷新令牌
+// POST /api/v1/users/refresh
+func (h *UserHandler) Refresh(c *gin.Context) {
+	var req dto.RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		respondError(c, bindError(err))
+		return
+	}
+
+	cmd := command.NewRefreshTokenCommand(req.RefreshToken)
+	user, refreshToken, refreshExpiresAt, err := h.authService.Refresh(c.Request.Context(), cmd)
+	if err != nil {
+		respondError(c, err)
+		return
+	}
+
 	token, expiresAt, err := h.jwtAuth.GenerateToken(user.ID, user.Username, user.Role)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code":    500,
-			"message": "failed to generate token",
-		})
+		respondError(c, err)
 		return
 	}
 
@@ -97,33 +154,55 @@ func (h *UserHandler) Login(c *gin.Context) {
 		"code":    0,
 		"message": "success",
 		"data": dto.LoginResponse{
-			Token:     token,
-			ExpiresAt: expiresAt,
-			User:      *user,
+			Token:            token,
+			ExpiresAt:        expiresAt,
+			RefreshToken:     refreshToken,
+			RefreshExpiresAt: refreshExpiresAt.Unix(),
+			User:             *user,
 		},
 	})
 }
 
+// Logout 登出
+// POST /api/v1/users/logout
+func (h *UserHandler) Logout(c *gin.Context) {
+	var req dto.LogoutRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondError(c, bindError(err))
+			return
+		}
+	}
+
+	userID, _ := middleware.GetUserIDFromContext(c)
+	tokenID, tokenExpiresAt := middleware.GetTokenFromContext(c)
+
+	cmd := command.NewLogoutCommand(userID, tokenID, tokenExpiresAt, req.RefreshToken)
+	if err := h.authService.Logout(c.Request.Context(), cmd); err != nil {
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "logged out successfully",
+	})
+}
+
 // GetUser 获取用户信息
 // GET /api/v1/users/:id
 func (h *UserHandler) GetUser(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": "invalid user id",
-		})
+		respondError(c, errInvalidUserID)
 		return
 	}
 
 	q := query.NewGetUserByIDQuery(id)
-	user, err := h.userService.GetUserByID(c.Request.Context(), q)
+	user, err := bus.Dispatch[*dto.UserDTO](c.Request.Context(), h.queries, q)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"code":    404,
-			"message": err.Error(),
-		})
+		respondError(c, err)
 		return
 	}
 
@@ -137,22 +216,31 @@ func (h *UserHandler) GetUser(c *gin.Context) {
 // ListUsers 获取用户列表
 // GET /api/v1/users
 func (h *UserHandler) ListUsers(c *gin.Context) {
-	var req dto.PaginationRequest
+	var req dto.ListUsersRequest
 	if err := c.ShouldBindQuery(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": err.Error(),
-		})
+		respondError(c, bindError(err))
 		return
 	}
 
 	q := query.NewListUsersQuery(req.GetOffset(), req.GetLimit())
-	result, err := h.userService.ListUsers(c.Request.Context(), q)
+	q.Status = req.Status
+	q.Role = req.Role
+	if !req.CreatedFrom.IsZero() {
+		q.CreatedFrom = &req.CreatedFrom
+	}
+	if !req.CreatedBefore.IsZero() {
+		q.CreatedBefore = &req.CreatedBefore
+	}
+	q.Keyword = req.Keyword
+	if req.Sort != "" {
+		q.SortBy = req.Sort
+	}
+	q.Desc = req.IsDesc()
+	q.Cursor = req.Cursor
+
+	result, err := bus.Dispatch[*dto.UserListDTO](c.Request.Context(), h.queries, q)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code":    500,
-			"message": err.Error(),
-		})
+		respondError(c, err)
 		return
 	}
 
@@ -169,39 +257,20 @@ func (h *UserHandler) UpdateProfile(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": "invalid user id",
-		})
-		return
-	}
-
-	// 验证是否是本人操作
-	currentUserID, _ := middleware.GetUserIDFromContext(c)
-	if currentUserID != id {
-		c.JSON(http.StatusForbidden, gin.H{
-			"code":    403,
-			"message": "permission denied",
-		})
+		respondError(c, errInvalidUserID)
 		return
 	}
 
 	var req dto.UpdateProfileRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": err.Error(),
-		})
+		respondError(c, bindError(err))
 		return
 	}
 
 	cmd := command.NewUpdateProfileCommand(id, req.Nickname, req.Avatar)
-	user, err := h.userService.UpdateProfile(c.Request.Context(), cmd)
+	user, err := bus.Dispatch[*dto.UserDTO](c.Request.Context(), h.commands, cmd)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code":    500,
-			"message": err.Error(),
-		})
+		respondError(c, err)
 		return
 	}
 
@@ -218,38 +287,19 @@ func (h *UserHandler) ChangePassword(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": "invalid user id",
-		})
-		return
-	}
-
-	// 验证是否是本人操作
-	currentUserID, _ := middleware.GetUserIDFromContext(c)
-	if currentUserID != id {
-		c.JSON(http.StatusForbidden, gin.H{
-			"code":    403,
-			"message": "permission denied",
-		})
+		respondError(c, errInvalidUserID)
 		return
 	}
 
 	var req dto.ChangePasswordRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": err.Error(),
-		})
+		respondError(c, bindError(err))
 		return
 	}
 
 	cmd := command.NewChangePasswordCommand(id, req.OldPassword, req.NewPassword)
-	if err := h.userService.ChangePassword(c.Request.Context(), cmd); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": err.Error(),
-		})
+	if err := bus.Send(c.Request.Context(), h.commands, cmd); err != nil {
+		respondError(c, err)
 		return
 	}
 
@@ -265,19 +315,13 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 	idStr := c.Param("id")
 	id, err := strconv.ParseUint(idStr, 10, 64)
 	if err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"code":    400,
-			"message": "invalid user id",
-		})
+		respondError(c, errInvalidUserID)
 		return
 	}
 
 	cmd := command.NewDeleteUserCommand(id)
-	if err := h.userService.DeleteUser(c.Request.Context(), cmd); err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"code":    500,
-			"message": err.Error(),
-		})
+	if err := bus.Send(c.Request.Context(), h.commands, cmd); err != nil {
+		respondError(c, err)
 		return
 	}
 
@@ -292,20 +336,118 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 func (h *UserHandler) GetCurrentUser(c *gin.Context) {
 	userID, exists := middleware.GetUserIDFromContext(c)
 	if !exists {
-		c.JSON(http.StatusUnauthorized, gin.H{
-			"code":    401,
-			"message": "unauthorized",
-		})
+		respondError(c, errUnauthenticated)
 		return
 	}
 
 	q := query.NewGetUserByIDQuery(userID)
-	user, err := h.userService.GetUserByID(c.Request.Context(), q)
+	user, err := bus.Dispatch[*dto.UserDTO](c.Request.Context(), h.queries, q)
 	if err != nil {
-		c.JSON(http.StatusNotFound, gin.H{
-			"code":    404,
-			"message": err.Error(),
-		})
+		respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"code":    0,
+		"message": "success",
+		"data":    user,
+	})
+}
+
+// BanUser 禁用用户
+// POST /api/v1/users/:id/ban
+func (h *UserHandler) BanUser(c *gin.Context) {
+	h.manageUser(c, func(id uint64, reason string) any {
+		return command.NewBanUserCommand(id, reason)
+	})
+}
+
+// UnbanUser 解除禁用
+// POST /api/v1/users/:id/unban
+func (h *UserHandler) UnbanUser(c *gin.Context) {
+	h.manageUser(c, func(id uint64, reason string) any {
+		return command.NewUnbanUserCommand(id, reason)
+	})
+}
+
+// ActivateUser 激活用户
+// POST /api/v1/users/:id/activate
+func (h *UserHandler) ActivateUser(c *gin.Context) {
+	h.manageUser(c, func(id uint64, reason string) any {
+		return command.NewActivateUserCommand(id)
+	})
+}
+
+// DeactivateUser 停用用户
+// POST /api/v1/users/:id/deactivate
+func (h *UserHandler) DeactivateUser(c *gin.Context) {
+	h.manageUser(c, func(id uint64, reason string) any {
+		return command.NewDeactivateUserCommand(id, reason)
+	})
+}
+
+// PromoteUser 提升为管理员
+// POST /api/v1/users/:id/promote
+func (h *UserHandler) PromoteUser(c *gin.Context) {
+	h.manageUser(c, func(id uint64, reason string) any {
+		return command.NewPromoteUserCommand(id)
+	})
+}
+
+// DemoteUser 管理员降级为普通用户
+// POST /api/v1/users/:id/demote
+func (h *UserHandler) DemoteUser(c *gin.Context) {
+	h.manageUser(c, func(id uint64, reason string) any {
+		return command.NewDemoteUserCommand(id, reason)
+	})
+}
+
+// UnlockUser 解除登录锁定
+// POST /api/v1/users/:id/unlock
+func (h *UserHandler) UnlockUser(c *gin.Context) {
+	h.manageUser(c, func(id uint64, reason string) any {
+		return command.NewUnlockUserCommand(id, reason)
+	})
+}
+
+// TransferAdmin 将当前管理员的权限转移给目标用户，当前用户随之降级为普通用户
+// POST /api/v1/users/:id/transfer-admin
+func (h *UserHandler) TransferAdmin(c *gin.Context) {
+	currentUserID, _ := middleware.GetUserIDFromContext(c)
+	h.manageUser(c, func(id uint64, reason string) any {
+		return command.NewTransferAdminCommand(currentUserID, id, reason)
+	})
+}
+
+// manageUser 管理员操作的公共流程：解析用户ID和原因，禁止对自己操作
+// newCommand 根据用户ID和原因构造要分发的命令
+func (h *UserHandler) manageUser(c *gin.Context, newCommand func(id uint64, reason string) any) {
+	idStr := c.Param("id")
+	id, err := strconv.ParseUint(idStr, 10, 64)
+	if err != nil {
+		respondError(c, errInvalidUserID)
+		return
+	}
+
+	// 防止管理员误把自己禁用或降级
+	currentUserID, _ := middleware.GetUserIDFromContext(c)
+	if currentUserID == id {
+		respondError(c, errSelfAction)
+		return
+	}
+
+	// 请求体可以为空（激活、提升不需要原因）
+	var req dto.ReasonRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			respondError(c, bindError(err))
+			return
+		}
+	}
+
+	user, err := bus.Dispatch[*dto.UserDTO](c.Request.Context(), h.commands, newCommand(id, req.Reason))
+	if err != nil {
+		respondError(c, err)
 		return
 	}
 
@@ -1,12 +1,26 @@
 package middleware
 
 import (
+	"context"
+	"fmt"
 	"net/http"
 	"strings"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+
+	"yiwen/go-ddd/pkg/errors"
+)
+
+// 认证失败的错误，均返回 401
+var (
+	errMissingToken      = errors.NewAppError(http.StatusUnauthorized, "missing_access_token", "missing authorization header", nil)
+	errInvalidAuthHeader = errors.NewAppError(http.StatusUnauthorized, "invalid_access_token", "invalid authorization header format", nil)
+	errInvalidToken      = errors.NewAppError(http.StatusUnauthorized, "invalid_access_token", "invalid or expired token", nil)
+	errTokenRevoked      = errors.NewAppError(http.StatusUnauthorized, "access_token_revoked", "token has been revoked", nil)
+	errAccessRevoked     = errors.NewAppError(http.StatusUnauthorized, "access_revoked", "user is no longer allowed to access", nil)
 )
 
 // JWTClaims JWT 声明
@@ -17,39 +31,87 @@ type JWTClaims struct {
 	jwt.RegisteredClaims
 }
 
+// UserStatusChecker 检查用户当前是否允许访问
+// 返回用户的最新角色；用户不存在、被禁用或停用时返回错误
+type UserStatusChecker interface {
+	CheckUserAccess(ctx context.Context, userID uint64) (string, error)
+}
+
+// TokenRevocationChecker 检查访问令牌（jti）是否已被吊销
+type TokenRevocationChecker interface {
+	IsRevoked(ctx context.Context, jti string) (bool, error)
+}
+
+// KeyProvider 签名密钥提供者
+// 签发时使用当前签名密钥并在头部写入 kid，验证时按 kid 查找密钥
+type KeyProvider interface {
+	// SigningKey 返回当前签名密钥的 kid、算法和私钥
+	SigningKey() (string, jwt.SigningMethod, interface{}, error)
+	// VerificationKey 根据 kid 返回验证密钥及其算法
+	VerificationKey(kid string) (jwt.SigningMethod, interface{}, error)
+}
+
 // JWTAuth JWT认证中间件
 type JWTAuth struct {
-	secret     string
-	expireHour int
-	issuer     string
+	keys              KeyProvider
+	allowedAlgorithms []string
+	accessTTL         time.Duration
+	issuer            string
+	statusChecker     UserStatusChecker
+	revocationChecker TokenRevocationChecker
 }
 
 // NewJWTAuth 创建JWT认证中间件
-func NewJWTAuth(secret string, expireHour int, issuer string) *JWTAuth {
+// allowedAlgorithms 为解析时接受的算法白名单（如 RS256、EdDSA），不在名单中的令牌一律拒绝
+// accessTTL 为访问令牌有效期，应保持较短，长期会话通过刷新令牌维持
+func NewJWTAuth(keys KeyProvider, allowedAlgorithms []string, accessTTL time.Duration, issuer string) *JWTAuth {
 	return &JWTAuth{
-		secret:     secret,
-		expireHour: expireHour,
-		issuer:     issuer,
+		keys:              keys,
+		allowedAlgorithms: allowedAlgorithms,
+		accessTTL:         accessTTL,
+		issuer:            issuer,
 	}
 }
 
+// SetStatusChecker 设置用户状态检查器
+// 设置后认证中间件每次请求都会检查用户状态，并以数据库中的角色为准，
+// 被禁用、停用或降级的用户持有的 Token 立即失效
+func (j *JWTAuth) SetStatusChecker(checker UserStatusChecker) {
+	j.statusChecker = checker
+}
+
+// SetRevocationChecker 设置访问令牌吊销检查，登出后的令牌立即失效
+func (j *JWTAuth) SetRevocationChecker(checker TokenRevocationChecker) {
+	j.revocationChecker = checker
+}
+
 // GenerateToken 生成JWT Token
+// 每个令牌带有唯一的 jti，用于登出时吊销
 func (j *JWTAuth) GenerateToken(userID uint64, username, role string) (string, int64, error) {
-	expiresAt := time.Now().Add(time.Duration(j.expireHour) * time.Hour)
+	expiresAt := time.Now().Add(j.accessTTL)
 
 	claims := JWTClaims{
 		UserID:   userID,
 		Username: username,
 		Role:     role,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
 			ExpiresAt: jwt.NewNumericDate(expiresAt),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			Issuer:    j.issuer,
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(j.secret))
+	kid, method, key, err := j.keys.SigningKey()
+	if err != nil {
+		return "", 0, err
+	}
+
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	tokenString, err := token.SignedString(key)
 	if err != nil {
 		return "", 0, err
 	}
@@ -58,10 +120,20 @@ func (j *JWTAuth) GenerateToken(userID uint64, username, role string) (string, i
 }
 
 // ParseToken 解析JWT Token
+// 只接受白名单中的算法，并且令牌头部的算法必须与 kid 对应密钥的算法一致，
+// 防止用公钥冒充 HMAC 密钥等算法混淆攻击
 func (j *JWTAuth) ParseToken(tokenString string) (*JWTClaims, error) {
 	token, err := jwt.ParseWithClaims(tokenString, &JWTClaims{}, func(token *jwt.Token) (interface{}, error) {
-		return []byte(j.secret), nil
-	})
+		kid, _ := token.Header["kid"].(string)
+		method, key, err := j.keys.VerificationKey(kid)
+		if err != nil {
+			return nil, err
+		}
+		if token.Method.Alg() != method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method %s for key %q", token.Method.Alg(), kid)
+		}
+		return key, nil
+	}, jwt.WithValidMethods(j.allowedAlgorithms), jwt.WithIssuer(j.issuer))
 
 	if err != nil {
 		return nil, err
@@ -74,61 +146,78 @@ func (j *JWTAuth) ParseToken(tokenString string) (*JWTClaims, error) {
 	return nil, jwt.ErrSignatureInvalid
 }
 
-// AuthMiddleware 认证中间件
-func (j *JWTAuth) AuthMiddleware() gin.HandlerFunc {
-	return func(c *gin.Context) {
-		authHeader := c.GetHeader("Authorization")
-		if authHeader == "" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"code":    401,
-				"message": "missing authorization header",
-			})
-			c.Abort()
-			return
-		}
+// Principal 通过认证的当前用户
+type Principal struct {
+	UserID         uint64
+	Username       string
+	Role           string // 设置了状态检查器时为数据库中的最新角色
+	TokenID        string
+	TokenExpiresAt time.Time
+}
 
-		// Bearer token
-		parts := strings.SplitN(authHeader, " ", 2)
-		if len(parts) != 2 || parts[0] != "Bearer" {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"code":    401,
-				"message": "invalid authorization header format",
-			})
-			c.Abort()
-			return
+// Authenticate 校验 Authorization 头（Bearer <访问令牌>），返回当前用户
+// HTTP 中间件和 gRPC 拦截器共用，失败时返回 401 的 AppError
+func (j *JWTAuth) Authenticate(ctx context.Context, authHeader string) (*Principal, error) {
+	if authHeader == "" {
+		return nil, errMissingToken
+	}
+
+	// Bearer token
+	parts := strings.SplitN(authHeader, " ", 2)
+	if len(parts) != 2 || parts[0] != "Bearer" {
+		return nil, errInvalidAuthHeader
+	}
+
+	claims, err := j.ParseToken(parts[1])
+	if err != nil {
+		return nil, errInvalidToken.WithCause(err)
+	}
+
+	if j.revocationChecker != nil && claims.ID != "" {
+		revoked, err := j.revocationChecker.IsRevoked(ctx, claims.ID)
+		if err != nil || revoked {
+			return nil, errTokenRevoked.WithCause(err)
 		}
+	}
 
-		claims, err := j.ParseToken(parts[1])
+	role := claims.Role
+	if j.statusChecker != nil {
+		role, err = j.statusChecker.CheckUserAccess(ctx, claims.UserID)
 		if err != nil {
-			c.JSON(http.StatusUnauthorized, gin.H{
-				"code":    401,
-				"message": "invalid or expired token",
-			})
-			c.Abort()
-			return
+			return nil, errAccessRevoked.WithCause(err)
 		}
+	}
 
-		// 将用户信息存入上下文
-		c.Set("user_id", claims.UserID)
-		c.Set("username", claims.Username)
-		c.Set("role", claims.Role)
-
-		c.Next()
+	principal := &Principal{
+		UserID:   claims.UserID,
+		Username: claims.Username,
+		Role:     role,
+		TokenID:  claims.ID,
+	}
+	if claims.ExpiresAt != nil {
+		principal.TokenExpiresAt = claims.ExpiresAt.Time
 	}
+	return principal, nil
 }
 
-// AdminMiddleware 管理员权限中间件
-func (j *JWTAuth) AdminMiddleware() gin.HandlerFunc {
+// AuthMiddleware 认证中间件
+func (j *JWTAuth) AuthMiddleware() gin.HandlerFunc {
 	return func(c *gin.Context) {
-		role, exists := c.Get("role")
-		if !exists || role != "admin" {
-			c.JSON(http.StatusForbidden, gin.H{
-				"code":    403,
-				"message": "admin permission required",
-			})
-			c.Abort()
+		principal, err := j.Authenticate(c.Request.Context(), c.GetHeader("Authorization"))
+		if err != nil {
+			AbortWithError(c, err)
 			return
 		}
+
+		// 将用户信息存入上下文
+		c.Set("user_id", principal.UserID)
+		c.Set("username", principal.Username)
+		c.Set("role", principal.Role)
+		c.Set("token_id", principal.TokenID)
+		if !principal.TokenExpiresAt.IsZero() {
+			c.Set("token_expires_at", principal.TokenExpiresAt)
+		}
+
 		c.Next()
 	}
 }
@@ -150,3 +239,10 @@ func GetUsernameFromContext(c *gin.Context) (string, bool) {
 	}
 	return username.(string), true
 }
+
+// GetTokenFromContext 从上下文获取当前访问令牌的 jti 和过期时间
+func GetTokenFromContext(c *gin.Context) (string, time.Time) {
+	jti := c.GetString("token_id")
+	expiresAt := c.GetTime("token_expires_at")
+	return jti, expiresAt
+}
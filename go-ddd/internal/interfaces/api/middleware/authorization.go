@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"yiwen/go-ddd/internal/domain/valueobject"
+	"yiwen/go-ddd/pkg/errors"
+)
+
+var (
+	errUnauthenticated = errors.ErrUnauthorizedError("unauthorized")
+	errInvalidUserID   = errors.NewAppError(http.StatusBadRequest, "invalid_user_id", "invalid user id", nil)
+)
+
+// PolicyAuthorizer 授权策略
+type PolicyAuthorizer interface {
+	Authorize(subject valueobject.Subject, permission valueobject.Permission, resource valueobject.Resource) error
+}
+
+// ResourceResolver 从请求中解析被操作的资源
+type ResourceResolver func(c *gin.Context) (valueobject.Resource, error)
+
+// Authorizer 授权中间件
+// 必须挂在 AuthMiddleware 之后，依赖其写入上下文的 user_id 和 role
+type Authorizer struct {
+	policy PolicyAuthorizer
+}
+
+// NewAuthorizer 创建授权中间件
+func NewAuthorizer(policy PolicyAuthorizer) *Authorizer {
+	return &Authorizer{policy: policy}
+}
+
+// Require 要求当前用户对资源拥有指定权限
+// resolve 为 nil 时表示集合类资源（例如用户列表）
+func (a *Authorizer) Require(permission valueobject.Permission, resolve ResourceResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		userID, exists := GetUserIDFromContext(c)
+		if !exists {
+			AbortWithError(c, errUnauthenticated)
+			return
+		}
+
+		var resource valueobject.Resource
+		if resolve != nil {
+			var err error
+			resource, err = resolve(c)
+			if err != nil {
+				AbortWithError(c, err)
+				return
+			}
+		}
+
+		subject := valueobject.Subject{UserID: userID, Role: c.GetString("role")}
+		if err := a.policy.Authorize(subject, permission, resource); err != nil {
+			AbortWithError(c, err)
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// UserFromParam 以路径参数中的用户ID作为被操作的资源
+func UserFromParam(name string) ResourceResolver {
+	return func(c *gin.Context) (valueobject.Resource, error) {
+		id, err := strconv.ParseUint(c.Param(name), 10, 64)
+		if err != nil {
+			return valueobject.Resource{}, errInvalidUserID
+		}
+		return valueobject.NewUserResource(id), nil
+	}
+}
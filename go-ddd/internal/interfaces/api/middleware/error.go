@@ -0,0 +1,76 @@
+package middleware
+
+import (
+	"log"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"yiwen/go-ddd/internal/interfaces/errmap"
+	"yiwen/go-ddd/pkg/errors"
+)
+
+// ProblemContentType RFC 7807 错误响应的媒体类型
+const ProblemContentType = "application/problem+json"
+
+// Problem RFC 7807 错误响应
+// code 为扩展字段，是稳定的错误码，客户端应据此判断错误类型而不是解析 detail
+type Problem struct {
+	Type          string                `json:"type"`
+	Title         string                `json:"title"`
+	Status        int                   `json:"status"`
+	Detail        string                `json:"detail,omitempty"`
+	Instance      string                `json:"instance,omitempty"`
+	Code          string                `json:"code"`
+	InvalidParams []errors.InvalidParam `json:"invalid_params,omitempty"`
+}
+
+// ErrorHandler 统一渲染错误响应
+// 处理器和中间件只需 c.Error(err) 并中止，本中间件在请求结束后把最后一个错误映射为 AppError，
+// 以 application/problem+json 返回；5xx 错误记录完整的错误链，响应中只包含通用信息
+func ErrorHandler() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Next()
+
+		if len(c.Errors) == 0 || c.Writer.Written() {
+			return
+		}
+		appErr := errmap.ToAppError(c.Errors.Last().Err)
+		if appErr.Status >= http.StatusInternalServerError {
+			log.Printf("%s %s: %v", c.Request.Method, c.Request.URL.Path, c.Errors.Last().Err)
+		}
+		renderProblem(c, appErr)
+	}
+}
+
+// Recovery 处理器 panic 时返回 500 错误响应
+func Recovery() gin.HandlerFunc {
+	return gin.CustomRecovery(func(c *gin.Context, recovered any) {
+		c.Abort()
+		renderProblem(c, errors.ErrInternalError("internal server error"))
+	})
+}
+
+// NoRoute 未匹配到路由时返回 404 错误响应
+func NoRoute(c *gin.Context) {
+	_ = c.Error(errors.ErrNotFoundError("resource not found"))
+}
+
+// AbortWithError 记录错误并中止后续处理器，响应由 ErrorHandler 渲染
+func AbortWithError(c *gin.Context, err error) {
+	_ = c.Error(err)
+	c.Abort()
+}
+
+func renderProblem(c *gin.Context, appErr *errors.AppError) {
+	c.Header("Content-Type", ProblemContentType)
+	c.JSON(appErr.Status, Problem{
+		Type:          "about:blank",
+		Title:         http.StatusText(appErr.Status),
+		Status:        appErr.Status,
+		Detail:        appErr.Message,
+		Instance:      c.Request.URL.Path,
+		Code:          appErr.Code,
+		InvalidParams: appErr.InvalidParams,
+	})
+}
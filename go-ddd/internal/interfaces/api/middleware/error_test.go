@@ -0,0 +1,90 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+
+	domainservice "yiwen/go-ddd/internal/domain/service"
+	"yiwen/go-ddd/internal/interfaces/api/middleware"
+	"yiwen/go-ddd/internal/interfaces/errmap"
+	"yiwen/go-ddd/pkg/errors"
+)
+
+func TestErrorHandler(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	engine := gin.New()
+	engine.Use(middleware.ErrorHandler(), middleware.Recovery())
+	engine.NoRoute(middleware.NoRoute)
+	engine.GET("/users/:id", func(c *gin.Context) {
+		middleware.AbortWithError(c, fmt.Errorf("load user %s: %w", c.Param("id"), domainservice.ErrUserNotFound))
+	})
+	engine.GET("/broken", func(c *gin.Context) {
+		middleware.AbortWithError(c, errors.New("dial tcp 10.0.0.5:3306: access denied for user root"))
+	})
+	engine.GET("/panic", func(c *gin.Context) {
+		panic("nil map")
+	})
+	engine.GET("/written", func(c *gin.Context) {
+		c.String(http.StatusAccepted, "done")
+		_ = c.Error(errors.New("after the response"))
+	})
+
+	tests := []struct {
+		name       string
+		path       string
+		wantStatus int
+		wantCode   string
+		wantDetail string
+	}{
+		{"wrapped domain error", "/users/42", http.StatusNotFound, errmap.CodeUserNotFound, "user not found"},
+		{"unknown error", "/broken", http.StatusInternalServerError, errors.CodeInternalError, "internal server error"},
+		{"panic", "/panic", http.StatusInternalServerError, errors.CodeInternalError, "internal server error"},
+		{"no route", "/missing", http.StatusNotFound, errors.CodeNotFound, "resource not found"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, tt.path, nil))
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if ct := w.Header().Get("Content-Type"); ct != middleware.ProblemContentType {
+				t.Fatalf("Content-Type = %q, want %q", ct, middleware.ProblemContentType)
+			}
+			var problem middleware.Problem
+			if err := json.Unmarshal(w.Body.Bytes(), &problem); err != nil {
+				t.Fatal(err)
+			}
+			want := middleware.Problem{
+				Type:     "about:blank",
+				Title:    http.StatusText(tt.wantStatus),
+				Status:   tt.wantStatus,
+				Detail:   tt.wantDetail,
+				Instance: tt.path,
+				Code:     tt.wantCode,
+			}
+			if fmt.Sprint(problem) != fmt.Sprint(want) {
+				t.Fatalf("problem = %+v, want %+v", problem, want)
+			}
+			// 5xx 只返回通用信息，不泄露内部错误
+			if strings.Contains(w.Body.String(), "10.0.0.5") || strings.Contains(w.Body.String(), "nil map") {
+				t.Fatalf("response leaks the cause: %s", w.Body.String())
+			}
+		})
+	}
+
+	// 处理器已经写出响应时不再渲染错误
+	w := httptest.NewRecorder()
+	engine.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/written", nil))
+	if w.Code != http.StatusAccepted || w.Body.String() != "done" {
+		t.Fatalf("written response was replaced: %d %q", w.Code, w.Body.String())
+	}
+}
@@ -1,40 +1,66 @@
 package router
 
 import (
+	"fmt"
+
 	"github.com/gin-gonic/gin"
 
+	"yiwen/go-ddd/internal/domain/valueobject"
 	"yiwen/go-ddd/internal/interfaces/api/handler"
 	"yiwen/go-ddd/internal/interfaces/api/middleware"
 )
 
 // Router 路由管理
 type Router struct {
-	engine      *gin.Engine
-	userHandler *handler.UserHandler
-	jwtAuth     *middleware.JWTAuth
+	engine         *gin.Engine
+	userHandler    *handler.UserHandler
+	accountHandler *handler.AccountHandler
+	mfaHandler     *handler.MFAHandler
+	avatarHandler  *handler.AvatarHandler
+	dataHandler    *handler.PersonalDataHandler
+	jwksHandler    *handler.JWKSHandler
+	jwtAuth        *middleware.JWTAuth
+	authorizer     *middleware.Authorizer
 }
 
 // NewRouter 创建路由
-func NewRouter(userHandler *handler.UserHandler, jwtAuth *middleware.JWTAuth) *Router {
+func NewRouter(userHandler *handler.UserHandler, accountHandler *handler.AccountHandler, mfaHandler *handler.MFAHandler, avatarHandler *handler.AvatarHandler, dataHandler *handler.PersonalDataHandler, jwksHandler *handler.JWKSHandler, jwtAuth *middleware.JWTAuth, authorizer *middleware.Authorizer) *Router {
 	return &Router{
-		engine:      gin.New(),
-		userHandler: userHandler,
-		jwtAuth:     jwtAuth,
+		engine:         gin.New(),
+		userHandler:    userHandler,
+		accountHandler: accountHandler,
+		mfaHandler:     mfaHandler,
+		avatarHandler:  avatarHandler,
+		dataHandler:    dataHandler,
+		jwksHandler:    jwksHandler,
+		jwtAuth:        jwtAuth,
+		authorizer:     authorizer,
 	}
 }
 
 // Setup 设置路由
-func (r *Router) Setup() *gin.Engine {
+// trustedProxies 为可信反向代理的 IP 或 CIDR，只有来自这些地址的请求才采用 X-Forwarded-For 中的客户端地址；
+// 为空时不信任任何代理，客户端地址即连接的远端地址，避免伪造请求头绕过按 IP 的登录限流
+func (r *Router) Setup(trustedProxies []string) (*gin.Engine, error) {
+	if err := r.engine.SetTrustedProxies(trustedProxies); err != nil {
+		return nil, fmt.Errorf("invalid trusted proxies: %w", err)
+	}
+
 	// 全局中间件
 	r.engine.Use(gin.Logger())
-	r.engine.Use(gin.Recovery())
+	r.engine.Use(middleware.Recovery())
 	r.engine.Use(CORSMiddleware())
+	r.engine.Use(middleware.ErrorHandler()) // 错误统一渲染为 RFC 7807 problem+json
+	r.engine.NoRoute(middleware.NoRoute)
 
 	// 健康检查
 	r.engine.GET("/health", func(c *gin.Context) {
 		c.JSON(200, gin.H{"status": "ok"})
 	})
 
+	// 访问令牌验证公钥
+	r.engine.GET("/.well-known/jwks.json", r.jwksHandler.JWKS)
+
 	// API v1
 	v1 := r.engine.Group("/api/v1")
 	{
@@ -44,29 +70,57 @@ func (r *Router) Setup() *gin.Engine {
 			// 公开接口（无需认证）
 			users.POST("/register", r.userHandler.Register)
 			users.POST("/login", r.userHandler.Login)
+			users.POST("/refresh", r.userHandler.Refresh)
+
+			// 登录第二步（使用登录接口返回的两步验证令牌）
+			users.POST("/login/mfa", r.mfaHandler.VerifyLogin)
+			users.POST("/login/mfa/enroll", r.mfaHandler.EnrollWithChallenge)
+			users.POST("/login/mfa/enroll/confirm", r.mfaHandler.ConfirmWithChallenge)
+
+			// 邮箱验证和密码重置（无需认证）
+			users.GET("/verify-email", r.accountHandler.VerifyEmail)
+			users.POST("/verify-email", r.accountHandler.VerifyEmail)
+			users.POST("/verify-email/resend", r.accountHandler.ResendVerification)
+			users.POST("/password-reset", r.accountHandler.RequestPasswordReset)
+			users.POST("/password-reset/confirm", r.accountHandler.ResetPassword)
+
+			// 需要认证的接口，资源级权限由授权策略判定
+			self := middleware.UserFromParam("id")
+			can := r.authorizer.Require
 
-			// 需要认证的接口
 			authUsers := users.Group("")
 			authUsers.Use(r.jwtAuth.AuthMiddleware())
 			{
 				authUsers.GET("/me", r.userHandler.GetCurrentUser)
-				authUsers.GET("/:id", r.userHandler.GetUser)
-				authUsers.PUT("/:id", r.userHandler.UpdateProfile)
-				authUsers.POST("/:id/password", r.userHandler.ChangePassword)
-			}
+				authUsers.POST("/logout", r.userHandler.Logout)
+				authUsers.POST("/me/mfa", r.mfaHandler.Enroll)
+				authUsers.POST("/me/mfa/confirm", r.mfaHandler.Confirm)
+				authUsers.DELETE("/me/mfa", r.mfaHandler.Disable)
+				authUsers.GET("/me/export", r.dataHandler.ExportData)
+				authUsers.DELETE("/me", r.dataHandler.RequestErasure)
+				authUsers.DELETE("/me/erasure", r.dataHandler.CancelErasure)
+				authUsers.GET("/:id", can(valueobject.PermissionUserRead, self), r.userHandler.GetUser)
+				authUsers.PUT("/:id", can(valueobject.PermissionUserUpdate, self), r.userHandler.UpdateProfile)
+				authUsers.POST("/:id/avatar", can(valueobject.PermissionUserUpdate, self), r.avatarHandler.UploadAvatar)
+				authUsers.POST("/:id/password", can(valueobject.PermissionUserChangePassword, self), r.userHandler.ChangePassword)
 
-			// 管理员接口
-			adminUsers := users.Group("")
-			adminUsers.Use(r.jwtAuth.AuthMiddleware())
-			adminUsers.Use(r.jwtAuth.AdminMiddleware())
-			{
-				adminUsers.GET("", r.userHandler.ListUsers)
-				adminUsers.DELETE("/:id", r.userHandler.DeleteUser)
+				// 管理接口
+				authUsers.GET("", can(valueobject.PermissionUserList, nil), r.userHandler.ListUsers)
+				authUsers.GET("/erasures", can(valueobject.PermissionUserList, nil), r.dataHandler.ListPendingErasures)
+				authUsers.DELETE("/:id", can(valueobject.PermissionUserDelete, self), r.userHandler.DeleteUser)
+				authUsers.POST("/:id/ban", can(valueobject.PermissionUserManageStatus, self), r.userHandler.BanUser)
+				authUsers.POST("/:id/unban", can(valueobject.PermissionUserManageStatus, self), r.userHandler.UnbanUser)
+				authUsers.POST("/:id/activate", can(valueobject.PermissionUserManageStatus, self), r.userHandler.ActivateUser)
+				authUsers.POST("/:id/deactivate", can(valueobject.PermissionUserManageStatus, self), r.userHandler.DeactivateUser)
+				authUsers.POST("/:id/unlock", can(valueobject.PermissionUserManageStatus, self), r.userHandler.UnlockUser)
+				authUsers.POST("/:id/promote", can(valueobject.PermissionUserManageRole, self), r.userHandler.PromoteUser)
+				authUsers.POST("/:id/demote", can(valueobject.PermissionUserManageRole, self), r.userHandler.DemoteUser)
+				authUsers.POST("/:id/transfer-admin", can(valueobject.PermissionUserManageRole, self), r.userHandler.TransferAdmin)
 			}
 		}
 	}
 
-	return r.engine
+	return r.engine, nil
 }
 
 // CORSMiddleware CORS中间件
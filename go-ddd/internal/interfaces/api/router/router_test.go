@@ -0,0 +1,60 @@
+package router_test
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	domainservice "yiwen/go-ddd/internal/domain/service"
+	"yiwen/go-ddd/internal/infrastructure/auth"
+	"yiwen/go-ddd/internal/interfaces/api/middleware"
+	"yiwen/go-ddd/internal/interfaces/api/router"
+)
+
+func TestTrustedProxies(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	tests := []struct {
+		name       string
+		proxies    []string
+		remoteAddr string
+		want       string
+	}{
+		{"no trusted proxies ignores forwarded header", nil, "10.0.0.1:1234", "10.0.0.1"},
+		{"trusted proxy uses forwarded header", []string{"10.0.0.0/8"}, "10.0.0.1:1234", "203.0.113.7"},
+		{"untrusted peer ignores forwarded header", []string{"10.0.0.0/8"}, "192.0.2.1:1234", "192.0.2.1"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			jwtAuth := middleware.NewJWTAuth(auth.NewHMACKeyProvider("test"), []string{"HS256"}, time.Hour, "go-ddd")
+			r := router.NewRouter(nil, nil, nil, nil, nil, nil, jwtAuth, middleware.NewAuthorizer(domainservice.NewDefaultPolicyEngine()))
+			engine, err := r.Setup(tt.proxies)
+			if err != nil {
+				t.Fatal(err)
+			}
+			engine.GET("/ip", func(c *gin.Context) { c.String(http.StatusOK, c.ClientIP()) })
+
+			req := httptest.NewRequest(http.MethodGet, "/ip", nil)
+			req.RemoteAddr = tt.remoteAddr
+			req.Header.Set("X-Forwarded-For", "203.0.113.7")
+			rec := httptest.NewRecorder()
+			engine.ServeHTTP(rec, req)
+
+			if got := rec.Body.String(); got != tt.want {
+				t.Fatalf("client ip = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestInvalidTrustedProxy(t *testing.T) {
+	jwtAuth := middleware.NewJWTAuth(auth.NewHMACKeyProvider("test"), []string{"HS256"}, time.Hour, "go-ddd")
+	r := router.NewRouter(nil, nil, nil, nil, nil, nil, jwtAuth, middleware.NewAuthorizer(domainservice.NewDefaultPolicyEngine()))
+	if _, err := r.Setup([]string{"not-an-ip"}); err == nil {
+		t.Fatal("expected an error for an invalid proxy address")
+	}
+}
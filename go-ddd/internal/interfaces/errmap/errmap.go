@@ -0,0 +1,184 @@
+// Package errmap 将领域层、应用层的错误映射为带稳定错误码的 AppError
+// 领域层不关心 HTTP，错误到状态码、错误码的对应关系集中在这里维护，HTTP 和其他接口共用
+package errmap
+
+import (
+	"net/http"
+
+	"yiwen/go-ddd/internal/application/bus"
+	"yiwen/go-ddd/internal/application/port"
+	"yiwen/go-ddd/internal/application/service"
+	"yiwen/go-ddd/internal/domain/aggregate"
+	"yiwen/go-ddd/internal/domain/event"
+	domainservice "yiwen/go-ddd/internal/domain/service"
+	"yiwen/go-ddd/internal/domain/valueobject"
+	"yiwen/go-ddd/pkg/errors"
+)
+
+// 业务错误码，发布后不再修改
+const (
+	CodeUserNotFound         = "user_not_found"
+	CodeUsernameTaken        = "username_taken"
+	CodeEmailTaken           = "email_taken"
+	CodeInvalidEmail         = "invalid_email"
+	CodeInvalidCredentials   = "invalid_credentials"
+	CodeUserNotActive        = "user_not_active"
+	CodeEmailNotVerified     = "email_not_verified"
+	CodeAccountLocked        = "account_locked"
+	CodeTooManyLoginAttempts = "too_many_login_attempts"
+	CodeNotAdmin             = "not_admin"
+	CodeAlreadyAdmin         = "already_admin"
+	CodeTransferToSelf       = "transfer_to_self"
+	CodeLastAdmin            = "last_admin"
+	CodeReasonRequired       = "reason_required"
+	CodePermissionDenied     = "permission_denied"
+	CodePasswordTooShort     = "password_too_short"
+	CodePasswordTooLong      = "password_too_long"
+	CodePasswordTooWeak      = "password_too_weak"
+	CodePasswordTooCommon    = "password_too_common"
+	CodePasswordReused       = "password_reused"
+	CodeMFARequired          = "mfa_required"
+	CodeMFAAlreadyEnabled    = "mfa_already_enabled"
+	CodeMFANotEnrolled       = "mfa_not_enrolled"
+	CodeInvalidMFACode       = "invalid_mfa_code"
+	CodeInvalidToken         = "invalid_token"
+	CodeInvalidRefreshToken  = "invalid_refresh_token"
+	CodeRefreshTokenReused   = "refresh_token_reused"
+	CodeInvalidSort          = "invalid_sort"
+	CodeInvalidCursor        = "invalid_cursor"
+	CodeConcurrencyConflict  = "concurrency_conflict"
+	CodeUnsupportedImage     = "unsupported_image"
+	CodeImageTooLarge        = "image_too_large"
+	CodeErasureNotRequested  = "erasure_not_requested"
+	CodeErasureNotDue        = "erasure_not_due"
+)
+
+// mapping 一个领域、应用错误对应的 AppError
+// detailed 为 true 时使用领域层对该错误的补充说明（如密码的具体要求）作为信息
+type mapping struct {
+	target   error
+	appErr   *errors.AppError
+	detailed bool
+}
+
+func newMapping(target error, status int, code, message string) mapping {
+	return mapping{target: target, appErr: errors.NewAppError(status, code, message, nil)}
+}
+
+func detailed(target error, status int, code, message string) mapping {
+	m := newMapping(target, status, code, message)
+	m.detailed = true
+	return m
+}
+
+// mappings 按顺序匹配，先匹配到的生效
+var mappings = []mapping{
+	newMapping(domainservice.ErrUserNotFound, http.StatusNotFound, CodeUserNotFound, "user not found"),
+	newMapping(port.ErrUserViewNotFound, http.StatusNotFound, CodeUserNotFound, "user not found"),
+	newMapping(domainservice.ErrUsernameAlreadyExists, http.StatusConflict, CodeUsernameTaken, "username already exists"),
+	newMapping(domainservice.ErrEmailAlreadyExists, http.StatusConflict, CodeEmailTaken, "email already exists"),
+	newMapping(valueobject.ErrInvalidEmail, http.StatusBadRequest, CodeInvalidEmail, "invalid email format"),
+
+	// 登录
+	newMapping(domainservice.ErrInvalidCredentials, http.StatusUnauthorized, CodeInvalidCredentials, "invalid username or password"),
+	newMapping(domainservice.ErrUserNotActive, http.StatusForbidden, CodeUserNotActive, "user is not active"),
+	newMapping(domainservice.ErrEmailNotVerified, http.StatusForbidden, CodeEmailNotVerified, "email is not verified"),
+	newMapping(domainservice.ErrAccountLocked, http.StatusTooManyRequests, CodeAccountLocked, "account is temporarily locked, try again later"),
+	newMapping(domainservice.ErrTooManyLoginAttempts, http.StatusTooManyRequests, CodeTooManyLoginAttempts, "too many failed login attempts, try again later"),
+
+	// 角色管理
+	newMapping(domainservice.ErrNotAdmin, http.StatusConflict, CodeNotAdmin, "source user is not an admin"),
+	newMapping(domainservice.ErrAlreadyAdmin, http.StatusConflict, CodeAlreadyAdmin, "target user is already an admin"),
+	newMapping(domainservice.ErrTransferToSelf, http.StatusBadRequest, CodeTransferToSelf, "cannot transfer admin to yourself"),
+	newMapping(domainservice.ErrLastAdmin, http.StatusConflict, CodeLastAdmin, "cannot demote, disable or delete the last active admin"),
+	newMapping(aggregate.ErrReasonRequired, http.StatusBadRequest, CodeReasonRequired, "reason is required"),
+	newMapping(domainservice.ErrPermissionDenied, http.StatusForbidden, CodePermissionDenied, "permission denied"),
+
+	// 密码
+	detailed(valueobject.ErrPasswordTooShort, http.StatusUnprocessableEntity, CodePasswordTooShort, "password is too short"),
+	detailed(valueobject.ErrPasswordTooLong, http.StatusUnprocessableEntity, CodePasswordTooLong, "password is too long"),
+	detailed(valueobject.ErrPasswordTooWeak, http.StatusUnprocessableEntity, CodePasswordTooWeak, "password does not meet the complexity requirements"),
+	newMapping(valueobject.ErrPasswordTooCommon, http.StatusUnprocessableEntity, CodePasswordTooCommon, "password is too common or has appeared in a data breach"),
+	newMapping(domainservice.ErrPasswordReused, http.StatusUnprocessableEntity, CodePasswordReused, "password was used recently, choose a different one"),
+
+	// 两步验证
+	newMapping(domainservice.ErrMFARequired, http.StatusForbidden, CodeMFARequired, "two-factor authentication is required for this account"),
+	newMapping(aggregate.ErrMFAAlreadyEnabled, http.StatusConflict, CodeMFAAlreadyEnabled, "two-factor authentication is already enabled"),
+	newMapping(aggregate.ErrMFANotEnrolled, http.StatusConflict, CodeMFANotEnrolled, "two-factor authentication is not enrolled"),
+	newMapping(aggregate.ErrInvalidMFACode, http.StatusBadRequest, CodeInvalidMFACode, "invalid two-factor authentication code"),
+
+	// 令牌
+	newMapping(port.ErrInvalidActionToken, http.StatusBadRequest, CodeInvalidToken, "invalid or expired token"),
+	newMapping(service.ErrInvalidRefreshToken, http.StatusUnauthorized, CodeInvalidRefreshToken, "invalid or expired refresh token"),
+	newMapping(service.ErrRefreshTokenReused, http.StatusUnauthorized, CodeRefreshTokenReused, "refresh token reuse detected, all sessions revoked"),
+
+	// 查询
+	newMapping(service.ErrInvalidSort, http.StatusBadRequest, CodeInvalidSort, "invalid sort field"),
+	newMapping(service.ErrInvalidCursor, http.StatusBadRequest, CodeInvalidCursor, "invalid cursor"),
+
+	// 头像
+	newMapping(port.ErrUnsupportedImage, http.StatusUnsupportedMediaType, CodeUnsupportedImage, "unsupported image format, expected jpeg, png or gif"),
+	detailed(port.ErrImageTooLarge, http.StatusRequestEntityTooLarge, CodeImageTooLarge, "image is too large"),
+
+	// 删除账户
+	newMapping(aggregate.ErrErasureNotRequested, http.StatusConflict, CodeErasureNotRequested, "account deletion has not been requested"),
+	newMapping(aggregate.ErrErasureNotDue, http.StatusConflict, CodeErasureNotDue, "account deletion grace period has not ended"),
+
+	newMapping(event.ErrConcurrencyConflict, http.StatusConflict, CodeConcurrencyConflict, "the user was modified concurrently, retry the request"),
+}
+
+// internalError 未映射的错误，不向客户端暴露任何细节
+var internalError = errors.ErrInternalError("internal server error")
+
+// ToAppError 将错误映射为 AppError，err 为 nil 时返回 nil
+// 已经是 AppError 的直接返回；命令、查询参数校验失败返回 400；未知错误返回 500
+func ToAppError(err error) *errors.AppError {
+	if err == nil {
+		return nil
+	}
+
+	var appErr *errors.AppError
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+
+	var invalid *bus.ValidationError
+	if errors.As(err, &invalid) {
+		// 命令、查询的校验信息由应用层编写，可以直接展示
+		return errors.NewAppError(http.StatusBadRequest, errors.CodeValidationFailed, invalid.Err.Error(), err)
+	}
+
+	for _, m := range mappings {
+		if !errors.Is(err, m.target) {
+			continue
+		}
+		mapped := m.appErr.WithCause(err)
+		if m.detailed {
+			if detail := domainDetail(err, m.target); detail != "" {
+				mapped.Message = detail
+			}
+		}
+		return mapped
+	}
+
+	return internalError.WithCause(err)
+}
+
+// domainDetail 返回错误链中直接包装 target 的那一层错误信息，
+// 即领域层以 fmt.Errorf("%w: ...", target) 补充的说明，没有时返回空串
+func domainDetail(err, target error) string {
+	for e := err; e != nil; e = unwrap(e) {
+		if unwrap(e) == target {
+			return e.Error()
+		}
+	}
+	return ""
+}
+
+func unwrap(err error) error {
+	u, ok := err.(interface{ Unwrap() error })
+	if !ok {
+		return nil
+	}
+	return u.Unwrap()
+}
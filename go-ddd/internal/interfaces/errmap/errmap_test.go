@@ -0,0 +1,59 @@
+package errmap_test
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	"yiwen/go-ddd/internal/application/bus"
+	domainservice "yiwen/go-ddd/internal/domain/service"
+	"yiwen/go-ddd/internal/domain/valueobject"
+	"yiwen/go-ddd/internal/interfaces/errmap"
+	"yiwen/go-ddd/pkg/errors"
+)
+
+func TestToAppError(t *testing.T) {
+	tooShort := fmt.Errorf("%w: must be at least 12 characters", valueobject.ErrPasswordTooShort)
+
+	tests := []struct {
+		name        string
+		err         error
+		wantStatus  int
+		wantCode    string
+		wantMessage string
+	}{
+		{"domain error", domainservice.ErrUserNotFound, http.StatusNotFound, errmap.CodeUserNotFound, "user not found"},
+		{"wrapped domain error", fmt.Errorf("change user 42: %w", domainservice.ErrUsernameAlreadyExists), http.StatusConflict, errmap.CodeUsernameTaken, "username already exists"},
+		{"domain error wrapped by pkg/errors", errors.Wrap(domainservice.ErrAccountLocked, "login"), http.StatusTooManyRequests, errmap.CodeAccountLocked, "account is temporarily locked, try again later"},
+		{"detailed mapping uses the domain detail", tooShort, http.StatusUnprocessableEntity, errmap.CodePasswordTooShort, "password is too short: must be at least 12 characters"},
+		{"detailed mapping wrapped again keeps the domain detail", fmt.Errorf("register: %w", tooShort), http.StatusUnprocessableEntity, errmap.CodePasswordTooShort, "password is too short: must be at least 12 characters"},
+		{"detailed mapping without detail uses the default message", valueobject.ErrPasswordTooShort, http.StatusUnprocessableEntity, errmap.CodePasswordTooShort, "password is too short"},
+		{"validation error", &bus.ValidationError{Name: "RegisterUserCommand", Err: errors.New("username is required")}, http.StatusBadRequest, errors.CodeValidationFailed, "username is required"},
+		{"app error is returned as is", errors.ErrConflict("already done"), http.StatusConflict, errors.CodeConflict, "already done"},
+		{"unknown error", errors.New("dial tcp 10.0.0.5:3306: password=s3cret refused"), http.StatusInternalServerError, errors.CodeInternalError, "internal server error"},
+		{"wrapped unknown error", fmt.Errorf("save user: %w", errors.New("deadlock on users")), http.StatusInternalServerError, errors.CodeInternalError, "internal server error"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := errmap.ToAppError(tt.err)
+			if got.Status != tt.wantStatus || got.Code != tt.wantCode || got.Message != tt.wantMessage {
+				t.Fatalf("ToAppError = (%d, %q, %q), want (%d, %q, %q)", got.Status, got.Code, got.Message, tt.wantStatus, tt.wantCode, tt.wantMessage)
+			}
+			// 原始错误保留在错误链中用于日志，但不进入响应信息
+			if !errors.Is(got, tt.err) {
+				t.Errorf("cause %v is not kept in the error chain", tt.err)
+			}
+		})
+	}
+
+	if errmap.ToAppError(nil) != nil {
+		t.Fatal("expected nil for a nil error")
+	}
+
+	// 映射结果是副本，修改不会影响后续映射
+	first := errmap.ToAppError(tooShort)
+	if second := errmap.ToAppError(valueobject.ErrPasswordTooShort); second.Message != "password is too short" || strings.Contains(second.Error(), "12 characters") {
+		t.Fatalf("mapping leaked detail from an earlier error: %q (first %q)", second.Error(), first.Message)
+	}
+}
@@ -0,0 +1,92 @@
+package server
+
+import (
+	"context"
+	"log"
+	"net/http"
+	"runtime/debug"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"yiwen/go-ddd/internal/interfaces/api/middleware"
+	"yiwen/go-ddd/internal/interfaces/errmap"
+	"yiwen/go-ddd/pkg/errors"
+)
+
+type principalKey struct{}
+
+// PrincipalFromContext 返回认证拦截器写入上下文的当前用户
+func PrincipalFromContext(ctx context.Context) (*middleware.Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*middleware.Principal)
+	return principal, ok
+}
+
+// Auth 认证拦截器，与 HTTP 的 JWTAuth.AuthMiddleware 对应
+// public 之外的方法要求 metadata 中的 authorization 为 "Bearer <访问令牌>"，
+// 令牌的校验、吊销检查和用户状态检查都由 JWTAuth 完成
+func Auth(jwtAuth *middleware.JWTAuth, public map[string]bool) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		if public[info.FullMethod] {
+			return handler(ctx, req)
+		}
+
+		var authHeader string
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get("authorization"); len(values) > 0 {
+				authHeader = values[0]
+			}
+		}
+
+		principal, err := jwtAuth.Authenticate(ctx, authHeader)
+		if err != nil {
+			return nil, err
+		}
+		return handler(context.WithValue(ctx, principalKey{}, principal), req)
+	}
+}
+
+// Errors 将处理器返回的错误转换为 gRPC 状态，与 HTTP 的 middleware.ErrorHandler 对应
+// 错误先经 errmap 映射为 AppError；5xx 错误记录完整的错误链，状态中只包含通用信息
+func Errors() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		resp, err := handler(ctx, req)
+		if err == nil {
+			return resp, nil
+		}
+		if _, ok := status.FromError(err); ok {
+			return nil, err
+		}
+
+		appErr := errmap.ToAppError(err)
+		if appErr.Status >= http.StatusInternalServerError {
+			log.Printf("%s: %v", info.FullMethod, err)
+		}
+		return nil, toStatus(appErr).Err()
+	}
+}
+
+// Recovery 处理器 panic 时返回 Internal
+func Recovery() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		defer func() {
+			if recovered := recover(); recovered != nil {
+				log.Printf("%s: panic: %v\n%s", info.FullMethod, recovered, debug.Stack())
+				err = toStatus(errors.ErrInternalError("internal server error")).Err()
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// Logging 记录每次调用的方法、状态码和耗时
+func Logging() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		resp, err := handler(ctx, req)
+		log.Printf("[GRPC] %-16s | %13v | %s", status.Code(err), time.Since(start), info.FullMethod)
+		return resp, err
+	}
+}
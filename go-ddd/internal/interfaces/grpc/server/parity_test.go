@@ -0,0 +1,517 @@
+package server_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"yiwen/go-ddd/internal/application/bus"
+	"yiwen/go-ddd/internal/application/dto"
+	appservice "yiwen/go-ddd/internal/application/service"
+	"yiwen/go-ddd/internal/domain/aggregate"
+	domainservice "yiwen/go-ddd/internal/domain/service"
+	"yiwen/go-ddd/internal/domain/valueobject"
+	"yiwen/go-ddd/internal/infrastructure/auth"
+	"yiwen/go-ddd/internal/infrastructure/messaging"
+	"yiwen/go-ddd/internal/infrastructure/persistence/memory"
+	"yiwen/go-ddd/internal/interfaces/api/handler"
+	"yiwen/go-ddd/internal/interfaces/api/middleware"
+	"yiwen/go-ddd/internal/interfaces/api/router"
+	"yiwen/go-ddd/internal/interfaces/grpc/server"
+	"yiwen/go-ddd/internal/interfaces/grpc/userv1"
+)
+
+// 同一套应用服务同时通过 HTTP 和 gRPC 暴露，对同一请求两种协议应得到相同的结果：
+// 成功时返回相同的数据，失败时错误码相同（HTTP 的 code 与 gRPC ErrorInfo.reason），
+// 校验失败的字段相同，状态码按 server.Code 的规则对应
+
+// stack 基于内存存储的完整服务
+type stack struct {
+	http       *httptest.Server
+	grpc       userv1.UserServiceClient
+	users      *memory.UserRepository
+	projection *appservice.UserProjection
+}
+
+func newStack(t *testing.T) *stack {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	ctx := context.Background()
+
+	users := memory.NewUserRepository()
+	uow := memory.NewUnitOfWork()
+	refreshTokens := memory.NewRefreshTokenRepository()
+	views := memory.NewUserReadModel()
+
+	hasher, err := valueobject.NewPasswordHasher("bcrypt", 4, valueobject.Argon2Params{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	passwords := domainservice.NewPasswordService(valueobject.PasswordPolicy{MinLength: 8}, hasher)
+	loginGuard := domainservice.NewLoginGuard(memory.NewLoginAttemptRepository(), domainservice.LoginThrottlePolicy{}, domainservice.LoginThrottlePolicy{})
+	mfaPolicy := domainservice.NewMFAPolicy(false)
+
+	eventBus := messaging.NewEventBus()
+	userService := appservice.NewUserApplicationService(users, uow, domainservice.NewUserDomainService(users, passwords), passwords, loginGuard, eventBus)
+	authService := appservice.NewAuthApplicationService(refreshTokens, memory.NewRevokedTokenRepository(), uow, userService, mfaPolicy, time.Hour)
+	projection := appservice.NewUserProjection(users, views)
+	eventBus.SubscribeAll(projection)
+
+	commands := bus.NewCommandBus(bus.Validation(), bus.Transactional(uow, eventBus))
+	queries := bus.NewQueryBus(bus.Validation())
+	userService.RegisterCommandHandlers(commands)
+	appservice.NewUserQueryService(views).RegisterQueryHandlers(queries)
+
+	cipher, err := auth.NewAESGCMCipher(bytes.Repeat([]byte{1}, 32))
+	if err != nil {
+		t.Fatal(err)
+	}
+	mfaService := appservice.NewMFAApplicationService(users, userService, loginGuard, mfaPolicy, cipher, auth.NewHMACActionTokenSigner("test"),
+		appservice.MFAOptions{Issuer: "go-ddd", ChallengeTTL: time.Minute, RecoveryCodes: 8})
+
+	jwtAuth := middleware.NewJWTAuth(auth.NewHMACKeyProvider("test"), []string{"HS256"}, time.Hour, "go-ddd")
+	jwtAuth.SetStatusChecker(userService)
+	jwtAuth.SetRevocationChecker(authService)
+	policy := domainservice.NewDefaultPolicyEngine()
+
+	// 只覆盖用户接口，其余处理器不会被调用
+	userHandler := handler.NewUserHandler(userService, authService, mfaService, commands, queries, jwtAuth)
+	engine, err := router.NewRouter(userHandler, nil, nil, nil, nil, nil, jwtAuth, middleware.NewAuthorizer(policy)).Setup(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	httpServer := httptest.NewServer(engine)
+	t.Cleanup(httpServer.Close)
+
+	lis := bufconn.Listen(1 << 20)
+	grpcServer := server.New(server.NewUserServer(userService, authService, mfaService, commands, queries, jwtAuth, policy), jwtAuth, server.Options{})
+	go grpcServer.Serve(lis)
+	t.Cleanup(grpcServer.Stop)
+
+	conn, err := grpc.DialContext(ctx, "bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+
+	return &stack{http: httpServer, grpc: userv1.NewUserServiceClient(conn), users: users, projection: projection}
+}
+
+// outcome 一次调用的结果，用于比较两种协议
+type outcome struct {
+	ok     bool
+	status int        // HTTP 状态码
+	code   codes.Code // gRPC 状态码
+	reason string     // 错误码
+	params []string   // 校验失败的字段
+}
+
+func (o outcome) String() string {
+	if o.ok {
+		return "ok"
+	}
+	return fmt.Sprintf("%d/%s %s %v", o.status, o.code, o.reason, o.params)
+}
+
+// call 发送 HTTP 请求，成功时把 data 解码到 out
+func (s *stack) call(t *testing.T, method, path, token string, body, out any) outcome {
+	t.Helper()
+	var reader *bytes.Reader
+	if body != nil {
+		raw, _ := json.Marshal(body)
+		reader = bytes.NewReader(raw)
+	} else {
+		reader = bytes.NewReader(nil)
+	}
+	req, _ := http.NewRequest(method, s.http.URL+path, reader)
+	req.Header.Set("Content-Type", "application/json")
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		var problem middleware.Problem
+		if err := json.NewDecoder(resp.Body).Decode(&problem); err != nil {
+			t.Fatal(err)
+		}
+		o := outcome{status: resp.StatusCode, reason: problem.Code}
+		for _, p := range problem.InvalidParams {
+			o.params = append(o.params, p.Name)
+		}
+		return o
+	}
+
+	envelope := struct {
+		Data json.RawMessage `json:"data"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&envelope); err != nil {
+		t.Fatal(err)
+	}
+	if out != nil {
+		if err := json.Unmarshal(envelope.Data, out); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return outcome{ok: true, status: resp.StatusCode}
+}
+
+// rpcOutcome 将 gRPC 调用的错误转换为 outcome
+func rpcOutcome(err error) outcome {
+	if err == nil {
+		return outcome{ok: true}
+	}
+	st := status.Convert(err)
+	o := outcome{code: st.Code()}
+	for _, d := range st.Details() {
+		switch d := d.(type) {
+		case *errdetails.ErrorInfo:
+			o.reason = d.Reason
+		case *errdetails.BadRequest:
+			for _, v := range d.FieldViolations {
+				o.params = append(o.params, v.Field)
+			}
+		}
+	}
+	return o
+}
+
+func withToken(token string) context.Context {
+	ctx := context.Background()
+	if token == "" {
+		return ctx
+	}
+	return metadata.AppendToOutgoingContext(ctx, "authorization", "Bearer "+token)
+}
+
+// fromProto 将 gRPC 返回的用户转换为 HTTP 返回的 DTO，便于直接比较
+func fromProto(u *userv1.User) dto.UserDTO {
+	user := dto.UserDTO{
+		ID:            u.Id,
+		UUID:          u.Uuid,
+		Username:      u.Username,
+		Email:         u.Email,
+		Nickname:      u.Nickname,
+		Avatar:        u.Avatar,
+		Status:        int(u.Status),
+		Role:          u.Role,
+		CreatedAt:     u.CreatedAt.AsTime(),
+		EmailVerified: u.EmailVerified,
+		MFAEnabled:    u.MfaEnabled,
+	}
+	if u.LockedUntil != nil {
+		lockedUntil := u.LockedUntil.AsTime()
+		user.LockedUntil = &lockedUntil
+	}
+	return user
+}
+
+func sameUser(a, b dto.UserDTO) bool {
+	return a.CreatedAt.Equal(b.CreatedAt) && fmt.Sprint(withoutTime(a)) == fmt.Sprint(withoutTime(b))
+}
+
+func withoutTime(u dto.UserDTO) dto.UserDTO {
+	u.CreatedAt = time.Time{}
+	return u
+}
+
+// register 通过 HTTP 注册并验证邮箱，admin 为 true 时提升为管理员
+func (s *stack) register(t *testing.T, username string, admin bool) dto.UserDTO {
+	t.Helper()
+	var user dto.UserDTO
+	body := dto.RegisterRequest{Username: username, Email: username + "@example.com", Password: username + "-password"}
+	if o := s.call(t, http.MethodPost, "/api/v1/users/register", "", body, &user); !o.ok {
+		t.Fatalf("register %s: %s", username, o)
+	}
+
+	ctx := context.Background()
+	agg, err := s.users.FindAggregateByID(ctx, user.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	agg.VerifyEmail()
+	if admin {
+		agg.PromoteToAdmin()
+	}
+	s.save(t, agg)
+	return user
+}
+
+func (s *stack) save(t *testing.T, agg *aggregate.UserAggregate) {
+	t.Helper()
+	if err := s.users.SaveAggregate(context.Background(), agg); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.projection.Rebuild(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func (s *stack) login(t *testing.T, username, password string) string {
+	t.Helper()
+	var resp dto.LoginResponse
+	if o := s.call(t, http.MethodPost, "/api/v1/users/login", "", dto.LoginRequest{Username: username, Password: password}, &resp); !o.ok {
+		t.Fatalf("login %s: %s", username, o)
+	}
+	return resp.Token
+}
+
+func TestErrorParity(t *testing.T) {
+	s := newStack(t)
+	alice := s.register(t, "alice", false)
+	bob := s.register(t, "bob", false)
+	s.register(t, "admin", true)
+	if o := s.call(t, http.MethodPost, "/api/v1/users/register", "", dto.RegisterRequest{Username: "carol", Email: "carol@example.com", Password: "carol-password"}, nil); !o.ok {
+		t.Fatalf("register carol: %s", o)
+	}
+	aliceToken := s.login(t, "alice", "alice-password")
+	adminToken := s.login(t, "admin", "admin-password")
+	long := strings.Repeat("x", 51)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		token      string
+		body       any
+		rpc        func(ctx context.Context) error
+		wantStatus int
+		wantCode   codes.Code
+		wantReason string
+	}{
+		{
+			name: "username taken", method: http.MethodPost, path: "/api/v1/users/register",
+			body: dto.RegisterRequest{Username: "alice", Email: "other@example.com", Password: "password-1"},
+			rpc: func(ctx context.Context) error {
+				_, err := s.grpc.Register(ctx, &userv1.RegisterRequest{Username: "alice", Email: "other@example.com", Password: "password-1"})
+				return err
+			},
+			wantStatus: http.StatusConflict, wantCode: codes.AlreadyExists, wantReason: "username_taken",
+		},
+		{
+			name: "invalid registration", method: http.MethodPost, path: "/api/v1/users/register",
+			body: dto.RegisterRequest{Username: "ab", Email: "not-an-email", Password: "password-1"},
+			rpc: func(ctx context.Context) error {
+				_, err := s.grpc.Register(ctx, &userv1.RegisterRequest{Username: "ab", Email: "not-an-email", Password: "password-1"})
+				return err
+			},
+			wantStatus: http.StatusBadRequest, wantCode: codes.InvalidArgument, wantReason: "validation_failed",
+		},
+		{
+			name: "password too short", method: http.MethodPost, path: "/api/v1/users/register",
+			body: dto.RegisterRequest{Username: "dave", Email: "dave@example.com", Password: "short"},
+			rpc: func(ctx context.Context) error {
+				_, err := s.grpc.Register(ctx, &userv1.RegisterRequest{Username: "dave", Email: "dave@example.com", Password: "short"})
+				return err
+			},
+			wantStatus: http.StatusUnprocessableEntity, wantCode: codes.InvalidArgument, wantReason: "password_too_short",
+		},
+		{
+			name: "wrong password", method: http.MethodPost, path: "/api/v1/users/login",
+			body: dto.LoginRequest{Username: "alice", Password: "wrong-password"},
+			rpc: func(ctx context.Context) error {
+				_, err := s.grpc.Login(ctx, &userv1.LoginRequest{Username: "alice", Password: "wrong-password"})
+				return err
+			},
+			wantStatus: http.StatusUnauthorized, wantCode: codes.Unauthenticated, wantReason: "invalid_credentials",
+		},
+		{
+			name: "email not verified", method: http.MethodPost, path: "/api/v1/users/login",
+			body: dto.LoginRequest{Username: "carol", Password: "carol-password"},
+			rpc: func(ctx context.Context) error {
+				_, err := s.grpc.Login(ctx, &userv1.LoginRequest{Username: "carol", Password: "carol-password"})
+				return err
+			},
+			wantStatus: http.StatusForbidden, wantCode: codes.PermissionDenied, wantReason: "email_not_verified",
+		},
+		{
+			name: "missing token", method: http.MethodGet, path: fmt.Sprintf("/api/v1/users/%d", alice.ID),
+			rpc: func(ctx context.Context) error {
+				_, err := s.grpc.GetUser(ctx, &userv1.GetUserRequest{Id: alice.ID})
+				return err
+			},
+			wantStatus: http.StatusUnauthorized, wantCode: codes.Unauthenticated, wantReason: "missing_access_token",
+		},
+		{
+			name: "invalid token", method: http.MethodGet, path: fmt.Sprintf("/api/v1/users/%d", alice.ID), token: "not-a-token",
+			rpc: func(ctx context.Context) error {
+				_, err := s.grpc.GetUser(withToken("not-a-token"), &userv1.GetUserRequest{Id: alice.ID})
+				return err
+			},
+			wantStatus: http.StatusUnauthorized, wantCode: codes.Unauthenticated, wantReason: "invalid_access_token",
+		},
+		{
+			name: "user not found", method: http.MethodGet, path: "/api/v1/users/9999", token: adminToken,
+			rpc: func(ctx context.Context) error {
+				_, err := s.grpc.GetUser(withToken(adminToken), &userv1.GetUserRequest{Id: 9999})
+				return err
+			},
+			wantStatus: http.StatusNotFound, wantCode: codes.NotFound, wantReason: "user_not_found",
+		},
+		{
+			name: "list as user", method: http.MethodGet, path: "/api/v1/users", token: aliceToken,
+			rpc: func(ctx context.Context) error {
+				_, err := s.grpc.ListUsers(withToken(aliceToken), &userv1.ListUsersRequest{})
+				return err
+			},
+			wantStatus: http.StatusForbidden, wantCode: codes.PermissionDenied, wantReason: "permission_denied",
+		},
+		{
+			name: "invalid list parameters", method: http.MethodGet, path: "/api/v1/users?page_size=1000&sort=email", token: adminToken,
+			rpc: func(ctx context.Context) error {
+				_, err := s.grpc.ListUsers(withToken(adminToken), &userv1.ListUsersRequest{PageSize: 1000, Sort: "email"})
+				return err
+			},
+			wantStatus: http.StatusBadRequest, wantCode: codes.InvalidArgument, wantReason: "validation_failed",
+		},
+		{
+			name: "update other user", method: http.MethodPut, path: fmt.Sprintf("/api/v1/users/%d", bob.ID), token: aliceToken,
+			body: dto.UpdateProfileRequest{Nickname: "Bobby"},
+			rpc: func(ctx context.Context) error {
+				_, err := s.grpc.UpdateProfile(withToken(aliceToken), &userv1.UpdateProfileRequest{Id: bob.ID, Nickname: "Bobby"})
+				return err
+			},
+			wantStatus: http.StatusForbidden, wantCode: codes.PermissionDenied, wantReason: "permission_denied",
+		},
+		{
+			name: "nickname too long", method: http.MethodPut, path: fmt.Sprintf("/api/v1/users/%d", alice.ID), token: aliceToken,
+			body: dto.UpdateProfileRequest{Nickname: long},
+			rpc: func(ctx context.Context) error {
+				_, err := s.grpc.UpdateProfile(withToken(aliceToken), &userv1.UpdateProfileRequest{Id: alice.ID, Nickname: long})
+				return err
+			},
+			wantStatus: http.StatusBadRequest, wantCode: codes.InvalidArgument, wantReason: "validation_failed",
+		},
+		{
+			name: "change password of other user", method: http.MethodPost, path: fmt.Sprintf("/api/v1/users/%d/password", bob.ID), token: adminToken,
+			body: dto.ChangePasswordRequest{OldPassword: "bob-password", NewPassword: "new-password"},
+			rpc: func(ctx context.Context) error {
+				_, err := s.grpc.ChangePassword(withToken(adminToken), &userv1.ChangePasswordRequest{Id: bob.ID, OldPassword: "bob-password", NewPassword: "new-password"})
+				return err
+			},
+			wantStatus: http.StatusForbidden, wantCode: codes.PermissionDenied, wantReason: "permission_denied",
+		},
+		{
+			name: "missing new password", method: http.MethodPost, path: fmt.Sprintf("/api/v1/users/%d/password", alice.ID), token: aliceToken,
+			body: dto.ChangePasswordRequest{OldPassword: "alice-password"},
+			rpc: func(ctx context.Context) error {
+				_, err := s.grpc.ChangePassword(withToken(aliceToken), &userv1.ChangePasswordRequest{Id: alice.ID, OldPassword: "alice-password"})
+				return err
+			},
+			wantStatus: http.StatusBadRequest, wantCode: codes.InvalidArgument, wantReason: "validation_failed",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			viaHTTP := s.call(t, tt.method, tt.path, tt.token, tt.body, nil)
+			viaGRPC := rpcOutcome(tt.rpc(context.Background()))
+
+			if viaHTTP.ok || viaHTTP.status != tt.wantStatus || viaHTTP.reason != tt.wantReason {
+				t.Fatalf("http: expected %d %s, got %s", tt.wantStatus, tt.wantReason, viaHTTP)
+			}
+			if viaGRPC.ok || viaGRPC.code != tt.wantCode || viaGRPC.reason != tt.wantReason {
+				t.Fatalf("grpc: expected %s %s, got %s", tt.wantCode, tt.wantReason, viaGRPC)
+			}
+			if fmt.Sprint(viaHTTP.params) != fmt.Sprint(viaGRPC.params) {
+				t.Fatalf("invalid params differ: http %v, grpc %v", viaHTTP.params, viaGRPC.params)
+			}
+		})
+	}
+}
+
+func TestResultParity(t *testing.T) {
+	s := newStack(t)
+	alice := s.register(t, "alice", false)
+	s.register(t, "admin", true)
+	ctx := context.Background()
+
+	// gRPC 注册的用户与 HTTP 注册的用户字段一致
+	bob, err := s.grpc.Register(ctx, &userv1.RegisterRequest{Username: "bob", Email: "bob@example.com", Password: "bob-password", Nickname: "Bob"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bob.Username != "bob" || bob.Email != "bob@example.com" || bob.Nickname != "Bob" || bob.Role != alice.Role || bob.Status != int32(alice.Status) || bob.EmailVerified {
+		t.Fatalf("unexpected registered user %+v, http registration returned %+v", bob, alice)
+	}
+
+	// 两种协议签发的访问令牌可以互换使用
+	login, err := s.grpc.Login(ctx, &userv1.LoginRequest{Username: "alice", Password: "alice-password"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if login.Token == "" || login.RefreshToken == "" || login.MfaChallenge != nil || login.User.GetId() != alice.ID {
+		t.Fatalf("unexpected login response %+v", login)
+	}
+	httpToken := s.login(t, "alice", "alice-password")
+
+	var viaHTTP dto.UserDTO
+	if o := s.call(t, http.MethodGet, fmt.Sprintf("/api/v1/users/%d", alice.ID), login.Token, nil, &viaHTTP); !o.ok {
+		t.Fatalf("http get with grpc token: %s", o)
+	}
+	viaGRPC, err := s.grpc.GetUser(withToken(httpToken), &userv1.GetUserRequest{Id: alice.ID})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !sameUser(viaHTTP, fromProto(viaGRPC)) {
+		t.Fatalf("get user differs: http %+v, grpc %+v", viaHTTP, fromProto(viaGRPC))
+	}
+
+	// 资料更新后两边读到的结果一致
+	updated, err := s.grpc.UpdateProfile(withToken(httpToken), &userv1.UpdateProfileRequest{Id: alice.ID, Nickname: "Alice"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if o := s.call(t, http.MethodGet, fmt.Sprintf("/api/v1/users/%d", alice.ID), httpToken, nil, &viaHTTP); !o.ok {
+		t.Fatal(o)
+	}
+	if viaHTTP.Nickname != "Alice" || !sameUser(viaHTTP, fromProto(updated)) {
+		t.Fatalf("update differs: http %+v, grpc %+v", viaHTTP, fromProto(updated))
+	}
+
+	// 列表的过滤、排序、分页结果一致
+	adminToken := s.login(t, "admin", "admin-password")
+	var page dto.UserListDTO
+	if o := s.call(t, http.MethodGet, "/api/v1/users?role=user&sort=username&page_size=1", adminToken, nil, &page); !o.ok {
+		t.Fatal(o)
+	}
+	list, err := s.grpc.ListUsers(withToken(adminToken), &userv1.ListUsersRequest{Role: "user", Sort: "username", PageSize: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if page.Total != 2 || list.Total != page.Total || len(list.Items) != 1 || len(page.Items) != 1 ||
+		!sameUser(page.Items[0], fromProto(list.Items[0])) || list.NextCursor != page.NextCursor || page.Items[0].Username != "alice" {
+		t.Fatalf("list differs: http %+v, grpc %+v", page, list)
+	}
+
+	// gRPC 修改密码后 HTTP 使用新密码登录
+	if _, err := s.grpc.ChangePassword(withToken(httpToken), &userv1.ChangePasswordRequest{Id: alice.ID, OldPassword: "alice-password", NewPassword: "alice-new-password"}); err != nil {
+		t.Fatal(err)
+	}
+	if o := s.call(t, http.MethodPost, "/api/v1/users/login", "", dto.LoginRequest{Username: "alice", Password: "alice-password"}, nil); o.reason != "invalid_credentials" {
+		t.Fatalf("expected old password to be rejected, got %s", o)
+	}
+	s.login(t, "alice", "alice-new-password")
+}
@@ -0,0 +1,40 @@
+// Package server gRPC 接口层
+// 与 interfaces/api（HTTP）并列，调用相同的命令总线、查询总线和应用服务；
+// 认证复用 JWTAuth，授权复用领域层的授权策略，错误经 errmap 映射后转换为 gRPC 状态，
+// 因此两种协议对同一请求的结果、错误码和权限规则保持一致
+package server
+
+import (
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+
+	"yiwen/go-ddd/internal/interfaces/api/middleware"
+	"yiwen/go-ddd/internal/interfaces/grpc/userv1"
+)
+
+// publicMethods 无需认证的方法
+var publicMethods = map[string]bool{
+	userv1.UserService_Register_FullMethodName: true,
+	userv1.UserService_Login_FullMethodName:    true,
+}
+
+// Options gRPC 服务配置
+type Options struct {
+	Reflection bool // 注册服务反射，便于 grpcurl 等工具调试
+}
+
+// New 创建 gRPC 服务器并注册用户服务
+// 拦截器由外到内：日志、panic 恢复、错误映射、认证
+func New(users *UserServer, jwtAuth *middleware.JWTAuth, opts Options) *grpc.Server {
+	s := grpc.NewServer(grpc.ChainUnaryInterceptor(
+		Logging(),
+		Recovery(),
+		Errors(),
+		Auth(jwtAuth, publicMethods),
+	))
+	userv1.RegisterUserServiceServer(s, users)
+	if opts.Reflection {
+		reflection.Register(s)
+	}
+	return s
+}
@@ -0,0 +1,85 @@
+package server
+
+import (
+	"net/http"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/runtime/protoiface"
+	"google.golang.org/protobuf/types/known/durationpb"
+
+	domainservice "yiwen/go-ddd/internal/domain/service"
+	"yiwen/go-ddd/internal/interfaces/errmap"
+	"yiwen/go-ddd/pkg/errors"
+)
+
+// ErrorDomain 错误详情 google.rpc.ErrorInfo 中的 domain
+const ErrorDomain = "go-ddd"
+
+// statusCodes HTTP 状态码对应的 gRPC 状态码
+var statusCodes = map[int]codes.Code{
+	http.StatusBadRequest:            codes.InvalidArgument,
+	http.StatusUnauthorized:          codes.Unauthenticated,
+	http.StatusForbidden:             codes.PermissionDenied,
+	http.StatusNotFound:              codes.NotFound,
+	http.StatusConflict:              codes.FailedPrecondition,
+	http.StatusRequestEntityTooLarge: codes.InvalidArgument,
+	http.StatusUnsupportedMediaType:  codes.InvalidArgument,
+	http.StatusUnprocessableEntity:   codes.InvalidArgument,
+	http.StatusTooManyRequests:       codes.ResourceExhausted,
+	http.StatusNotImplemented:        codes.Unimplemented,
+	http.StatusServiceUnavailable:    codes.Unavailable,
+	http.StatusGatewayTimeout:        codes.DeadlineExceeded,
+}
+
+// errorCodes 同一 HTTP 状态码下语义不同的错误，按错误码单独指定
+// 409 默认为 FailedPrecondition（当前状态不允许），唯一性冲突和并发冲突另有对应的状态码
+var errorCodes = map[string]codes.Code{
+	errmap.CodeUsernameTaken:       codes.AlreadyExists,
+	errmap.CodeEmailTaken:          codes.AlreadyExists,
+	errmap.CodeConcurrencyConflict: codes.Aborted,
+}
+
+// Code 返回 AppError 对应的 gRPC 状态码
+func Code(appErr *errors.AppError) codes.Code {
+	if code, ok := errorCodes[appErr.Code]; ok {
+		return code
+	}
+	if code, ok := statusCodes[appErr.Status]; ok {
+		return code
+	}
+	if appErr.Status >= http.StatusInternalServerError {
+		return codes.Internal
+	}
+	return codes.Unknown
+}
+
+// toStatus 将 AppError 转换为 gRPC 状态
+// 错误码放在 ErrorInfo.reason 中，参数校验失败的字段放在 BadRequest 中，
+// 登录处于退避或锁定中时附带 RetryInfo（对应 HTTP 的 Retry-After）
+func toStatus(appErr *errors.AppError) *status.Status {
+	st := status.New(Code(appErr), appErr.Message)
+
+	info := &errdetails.ErrorInfo{Reason: appErr.Code, Domain: ErrorDomain}
+	details := []protoiface.MessageV1{info}
+	if len(appErr.InvalidParams) > 0 {
+		badRequest := &errdetails.BadRequest{}
+		for _, p := range appErr.InvalidParams {
+			badRequest.FieldViolations = append(badRequest.FieldViolations, &errdetails.BadRequest_FieldViolation{
+				Field:       p.Name,
+				Description: p.Reason,
+			})
+		}
+		details = append(details, badRequest)
+	}
+	var blocked *domainservice.LoginBlockedError
+	if errors.As(appErr, &blocked) {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(blocked.RetryAfter)})
+	}
+
+	if withDetails, err := st.WithDetails(details...); err == nil {
+		st = withDetails
+	}
+	return st
+}
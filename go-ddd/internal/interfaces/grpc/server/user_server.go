@@ -0,0 +1,254 @@
+package server
+
+import (
+	"context"
+	"net"
+
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"yiwen/go-ddd/internal/application/bus"
+	"yiwen/go-ddd/internal/application/command"
+	"yiwen/go-ddd/internal/application/dto"
+	"yiwen/go-ddd/internal/application/query"
+	"yiwen/go-ddd/internal/application/service"
+	"yiwen/go-ddd/internal/domain/valueobject"
+	"yiwen/go-ddd/internal/interfaces/api/middleware"
+	"yiwen/go-ddd/internal/interfaces/grpc/userv1"
+)
+
+// UserServer 用户 gRPC 服务
+// 与 handler.UserHandler 一一对应：请求转换为与 HTTP 相同的命令、查询，
+// 资源级权限在调用用例之前由授权策略判定（对应路由中的 Authorizer.Require）
+type UserServer struct {
+	userv1.UnimplementedUserServiceServer
+
+	userService *service.UserApplicationService
+	authService *service.AuthApplicationService
+	mfaService  *service.MFAApplicationService
+	commands    *bus.Bus
+	queries     *bus.Bus
+	jwtAuth     *middleware.JWTAuth
+	policy      middleware.PolicyAuthorizer
+}
+
+// NewUserServer 创建用户 gRPC 服务
+func NewUserServer(userService *service.UserApplicationService, authService *service.AuthApplicationService, mfaService *service.MFAApplicationService, commands, queries *bus.Bus, jwtAuth *middleware.JWTAuth, policy middleware.PolicyAuthorizer) *UserServer {
+	return &UserServer{
+		userService: userService,
+		authService: authService,
+		mfaService:  mfaService,
+		commands:    commands,
+		queries:     queries,
+		jwtAuth:     jwtAuth,
+		policy:      policy,
+	}
+}
+
+// Register 用户注册
+func (s *UserServer) Register(ctx context.Context, req *userv1.RegisterRequest) (*userv1.User, error) {
+	body := dto.RegisterRequest{
+		Username: req.GetUsername(),
+		Email:    req.GetEmail(),
+		Password: req.GetPassword(),
+		Nickname: req.GetNickname(),
+	}
+	if err := validateRequest(&body); err != nil {
+		return nil, err
+	}
+
+	cmd := command.NewRegisterUserCommand(body.Username, body.Email, body.Password, body.Nickname)
+	user, err := bus.Dispatch[*dto.UserDTO](ctx, s.commands, cmd)
+	if err != nil {
+		return nil, err
+	}
+	return toUser(user), nil
+}
+
+// Login 用户登录
+// 启用（或策略要求启用）两步验证时只返回两步验证令牌，第二步通过 HTTP 接口完成
+func (s *UserServer) Login(ctx context.Context, req *userv1.LoginRequest) (*userv1.LoginResponse, error) {
+	body := dto.LoginRequest{Username: req.GetUsername(), Password: req.GetPassword()}
+	if err := validateRequest(&body); err != nil {
+		return nil, err
+	}
+
+	user, err := s.userService.Login(ctx, query.NewLoginQuery(body.Username, body.Password, clientIP(ctx)))
+	if err != nil {
+		return nil, err
+	}
+
+	challenge, err := s.mfaService.LoginChallenge(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if challenge != nil {
+		return &userv1.LoginResponse{MfaChallenge: &userv1.MFAChallenge{
+			EnrollmentRequired: challenge.EnrollmentRequired,
+			MfaToken:           challenge.MFAToken,
+			ExpiresAt:          challenge.ExpiresAt,
+		}}, nil
+	}
+
+	token, expiresAt, err := s.jwtAuth.GenerateToken(user.ID, user.Username, user.Role)
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, refreshExpiresAt, err := s.authService.IssueRefreshToken(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &userv1.LoginResponse{
+		Token:            token,
+		ExpiresAt:        expiresAt,
+		RefreshToken:     refreshToken,
+		RefreshExpiresAt: refreshExpiresAt.Unix(),
+		User:             toUser(user),
+	}, nil
+}
+
+// GetUser 获取用户信息
+func (s *UserServer) GetUser(ctx context.Context, req *userv1.GetUserRequest) (*userv1.User, error) {
+	if err := s.authorize(ctx, valueobject.PermissionUserRead, valueobject.NewUserResource(req.GetId())); err != nil {
+		return nil, err
+	}
+
+	user, err := bus.Dispatch[*dto.UserDTO](ctx, s.queries, query.NewGetUserByIDQuery(req.GetId()))
+	if err != nil {
+		return nil, err
+	}
+	return toUser(user), nil
+}
+
+// ListUsers 获取用户列表
+func (s *UserServer) ListUsers(ctx context.Context, req *userv1.ListUsersRequest) (*userv1.ListUsersResponse, error) {
+	if err := s.authorize(ctx, valueobject.PermissionUserList, valueobject.Resource{}); err != nil {
+		return nil, err
+	}
+
+	body := dto.ListUsersRequest{
+		PaginationRequest: dto.PaginationRequest{Page: int(req.GetPage()), PageSize: int(req.GetPageSize())},
+		Status:            int(req.GetStatus()),
+		Role:              req.GetRole(),
+		Keyword:           req.GetQ(),
+		Sort:              req.GetSort(),
+		Order:             req.GetOrder(),
+		Cursor:            req.GetCursor(),
+	}
+	if err := validateRequest(&body); err != nil {
+		return nil, err
+	}
+
+	q := query.NewListUsersQuery(body.GetOffset(), body.GetLimit())
+	q.Status = body.Status
+	q.Role = body.Role
+	if req.GetCreatedFrom() != nil {
+		createdFrom := req.GetCreatedFrom().AsTime()
+		q.CreatedFrom = &createdFrom
+	}
+	if req.GetCreatedBefore() != nil {
+		createdBefore := req.GetCreatedBefore().AsTime()
+		q.CreatedBefore = &createdBefore
+	}
+	q.Keyword = body.Keyword
+	if body.Sort != "" {
+		q.SortBy = body.Sort
+	}
+	q.Desc = body.IsDesc()
+	q.Cursor = body.Cursor
+
+	result, err := bus.Dispatch[*dto.UserListDTO](ctx, s.queries, q)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &userv1.ListUsersResponse{Total: result.Total, NextCursor: result.NextCursor}
+	for i := range result.Items {
+		resp.Items = append(resp.Items, toUser(&result.Items[i]))
+	}
+	return resp, nil
+}
+
+// UpdateProfile 更新用户资料
+func (s *UserServer) UpdateProfile(ctx context.Context, req *userv1.UpdateProfileRequest) (*userv1.User, error) {
+	if err := s.authorize(ctx, valueobject.PermissionUserUpdate, valueobject.NewUserResource(req.GetId())); err != nil {
+		return nil, err
+	}
+
+	body := dto.UpdateProfileRequest{Nickname: req.GetNickname(), Avatar: req.GetAvatar()}
+	if err := validateRequest(&body); err != nil {
+		return nil, err
+	}
+
+	cmd := command.NewUpdateProfileCommand(req.GetId(), body.Nickname, body.Avatar)
+	user, err := bus.Dispatch[*dto.UserDTO](ctx, s.commands, cmd)
+	if err != nil {
+		return nil, err
+	}
+	return toUser(user), nil
+}
+
+// ChangePassword 修改密码
+func (s *UserServer) ChangePassword(ctx context.Context, req *userv1.ChangePasswordRequest) (*emptypb.Empty, error) {
+	if err := s.authorize(ctx, valueobject.PermissionUserChangePassword, valueobject.NewUserResource(req.GetId())); err != nil {
+		return nil, err
+	}
+
+	body := dto.ChangePasswordRequest{OldPassword: req.GetOldPassword(), NewPassword: req.GetNewPassword()}
+	if err := validateRequest(&body); err != nil {
+		return nil, err
+	}
+
+	cmd := command.NewChangePasswordCommand(req.GetId(), body.OldPassword, body.NewPassword)
+	if err := bus.Send(ctx, s.commands, cmd); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+// authorize 要求当前用户对资源拥有指定权限，resource 为零值时表示集合类资源
+func (s *UserServer) authorize(ctx context.Context, permission valueobject.Permission, resource valueobject.Resource) error {
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok {
+		return errUnauthenticated
+	}
+	subject := valueobject.Subject{UserID: principal.UserID, Role: principal.Role}
+	return s.policy.Authorize(subject, permission, resource)
+}
+
+// clientIP 返回对端地址，用于登录按 IP 退避
+// gRPC 服务不信任客户端提供的转发头，部署在代理之后时所有请求会共用代理的地址
+func clientIP(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+// toUser 将用户 DTO 转换为 proto 消息
+func toUser(user *dto.UserDTO) *userv1.User {
+	u := &userv1.User{
+		Id:            user.ID,
+		Uuid:          user.UUID,
+		Username:      user.Username,
+		Email:         user.Email,
+		Nickname:      user.Nickname,
+		Avatar:        user.Avatar,
+		Status:        int32(user.Status),
+		Role:          user.Role,
+		CreatedAt:     timestamppb.New(user.CreatedAt),
+		EmailVerified: user.EmailVerified,
+		MfaEnabled:    user.MFAEnabled,
+	}
+	if user.LockedUntil != nil {
+		u.LockedUntil = timestamppb.New(*user.LockedUntil)
+	}
+	return u
+}
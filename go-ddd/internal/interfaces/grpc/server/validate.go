@@ -0,0 +1,63 @@
+package server
+
+import (
+	"errors"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+
+	apperrors "yiwen/go-ddd/pkg/errors"
+)
+
+// 接口层自身的错误，领域层、应用层的错误由 errmap 映射
+var (
+	errUnauthenticated = apperrors.ErrUnauthorizedError("unauthorized")
+	errInvalidRequest  = apperrors.NewAppError(http.StatusBadRequest, apperrors.CodeBadRequest, "invalid request", nil)
+)
+
+// requestValidator 按 dto 中的 binding 标签校验请求，规则与 HTTP 接口绑定参数时相同
+// 字段以 json、form 标签中的名字报告，与 proto 中的字段名一致
+var requestValidator = newRequestValidator()
+
+func newRequestValidator() *validator.Validate {
+	v := validator.New()
+	v.SetTagName("binding")
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		for _, tag := range []string{"json", "form"} {
+			name, _, _ := strings.Cut(field.Tag.Get(tag), ",")
+			if name == "-" {
+				return ""
+			}
+			if name != "" {
+				return name
+			}
+		}
+		return field.Name
+	})
+	return v
+}
+
+// validateRequest 校验请求，失败时返回 400 并逐个列出校验失败的字段
+func validateRequest(req any) error {
+	err := requestValidator.Struct(req)
+	if err == nil {
+		return nil
+	}
+
+	var invalid validator.ValidationErrors
+	if !errors.As(err, &invalid) {
+		return errInvalidRequest.WithCause(err)
+	}
+
+	appErr := apperrors.NewAppError(http.StatusBadRequest, apperrors.CodeValidationFailed, "request parameters are invalid", err)
+	for _, fe := range invalid {
+		reason := "failed on " + fe.Tag()
+		if fe.Param() != "" {
+			reason += "=" + fe.Param()
+		}
+		appErr.InvalidParams = append(appErr.InvalidParams, apperrors.InvalidParam{Name: fe.Field(), Reason: reason})
+	}
+	return appErr
+}
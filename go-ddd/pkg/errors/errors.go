@@ -3,24 +3,36 @@ package errors
 import (
 	"errors"
 	"fmt"
+	"net/http"
 )
 
 // 通用错误定义
 var (
-	ErrNotFound       = errors.New("resource not found")
-	ErrInvalidInput   = errors.New("invalid input")
-	ErrUnauthorized   = errors.New("unauthorized")
-	ErrForbidden      = errors.New("forbidden")
-	ErrInternal       = errors.New("internal error")
-	ErrAlreadyExists  = errors.New("resource already exists")
-	ErrValidation     = errors.New("validation error")
+	ErrNotFound      = errors.New("resource not found")
+	ErrInvalidInput  = errors.New("invalid input")
+	ErrUnauthorized  = errors.New("unauthorized")
+	ErrForbidden     = errors.New("forbidden")
+	ErrInternal      = errors.New("internal error")
+	ErrAlreadyExists = errors.New("resource already exists")
+	ErrValidation    = errors.New("validation error")
 )
 
 // AppError 应用错误
+// 领域层、应用层的错误在接口层被映射为 AppError，再统一渲染为 HTTP 或其他协议的错误响应
+// Code 是稳定的错误码，客户端据此判断错误类型；Message 可以直接展示给用户，不包含内部细节
 type AppError struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
-	Err     error  `json:"-"`
+	Status  int    `json:"status"`  // HTTP 状态码
+	Code    string `json:"code"`    // 稳定的错误码，如 user_not_found
+	Message string `json:"message"` // 安全的错误信息
+	Err     error  `json:"-"`       // 原始错误，只用于日志
+
+	InvalidParams []InvalidParam `json:"invalid_params,omitempty"` // 参数校验失败的字段
+}
+
+// InvalidParam 校验失败的请求参数
+type InvalidParam struct {
+	Name   string `json:"name"`
+	Reason string `json:"reason"`
 }
 
 // Error 实现error接口
@@ -36,9 +48,30 @@ func (e *AppError) Unwrap() error {
 	return e.Err
 }
 
+// Is 错误码相同即视为同一种错误，便于用预定义的 AppError 判断
+func (e *AppError) Is(target error) bool {
+	t, ok := target.(*AppError)
+	return ok && t.Code == e.Code
+}
+
+// WithCause 返回带有原始错误的副本，预定义的 AppError 本身不会被修改
+func (e *AppError) WithCause(err error) *AppError {
+	c := *e
+	c.Err = err
+	return &c
+}
+
+// WithMessage 返回替换了错误信息的副本
+func (e *AppError) WithMessage(message string) *AppError {
+	c := *e
+	c.Message = message
+	return &c
+}
+
 // NewAppError 创建应用错误
-func NewAppError(code int, message string, err error) *AppError {
+func NewAppError(status int, code, message string, err error) *AppError {
 	return &AppError{
+		Status:  status,
 		Code:    code,
 		Message: message,
 		Err:     err,
@@ -81,38 +114,39 @@ func Errorf(format string, args ...interface{}) error {
 	return fmt.Errorf(format, args...)
 }
 
-// HTTP错误码
+// 通用错误码，业务相关的错误码由错误映射定义
 const (
-	CodeSuccess         = 0
-	CodeBadRequest      = 400
-	CodeUnauthorized    = 401
-	CodeForbidden       = 403
-	CodeNotFound        = 404
-	CodeConflict        = 409
-	CodeInternalError   = 500
+	CodeBadRequest       = "bad_request"
+	CodeValidationFailed = "validation_failed"
+	CodeUnauthorized     = "unauthorized"
+	CodeForbidden        = "forbidden"
+	CodeNotFound         = "not_found"
+	CodeConflict         = "conflict"
+	CodeTooManyRequests  = "too_many_requests"
+	CodeInternalError    = "internal_error"
 )
 
 // 预定义应用错误
 func ErrBadRequest(message string) *AppError {
-	return NewAppError(CodeBadRequest, message, nil)
+	return NewAppError(http.StatusBadRequest, CodeBadRequest, message, nil)
 }
 
 func ErrUnauthorizedError(message string) *AppError {
-	return NewAppError(CodeUnauthorized, message, nil)
+	return NewAppError(http.StatusUnauthorized, CodeUnauthorized, message, nil)
 }
 
 func ErrForbiddenError(message string) *AppError {
-	return NewAppError(CodeForbidden, message, nil)
+	return NewAppError(http.StatusForbidden, CodeForbidden, message, nil)
 }
 
 func ErrNotFoundError(message string) *AppError {
-	return NewAppError(CodeNotFound, message, nil)
+	return NewAppError(http.StatusNotFound, CodeNotFound, message, nil)
 }
 
 func ErrConflict(message string) *AppError {
-	return NewAppError(CodeConflict, message, nil)
+	return NewAppError(http.StatusConflict, CodeConflict, message, nil)
 }
 
 func ErrInternalError(message string) *AppError {
-	return NewAppError(CodeInternalError, message, nil)
+	return NewAppError(http.StatusInternalServerError, CodeInternalError, message, nil)
 }
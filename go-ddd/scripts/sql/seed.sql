@@ -0,0 +1,32 @@
+-- =====================================================
+-- DDD 用户管理系统 - 测试数据
+-- 在 migrate up 之后执行：mysql -uroot -p < scripts/sql/seed.sql
+-- =====================================================
+
+USE go_ddd;
+
+-- =====================================================
+-- 插入测试管理员账户
+-- 密码: Admin123 (bcrypt加密)
+-- =====================================================
+INSERT INTO users (uuid, username, email, password_hash, nickname, status, role, email_verified_at)
+VALUES (
+    UUID(),
+    'admin',
+    'admin@example.com',
+    '$2a$10$N9qo8uLOickgx2ZMRZoMyeIjZAgcfl7p92ldGxad68LJZdL17lhWy',
+    'Administrator',
+    1,
+    'admin',
+    NOW()
+) ON DUPLICATE KEY UPDATE updated_at = CURRENT_TIMESTAMP;
+
+-- =====================================================
+-- 说明
+-- =====================================================
+--
+-- 测试账户：
+--    - 用户名: admin
+--    - 密码: Admin123
+--    - 注意: 生产环境请删除或修改此账户
+--
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"reflect"
+	"strings"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// redactedKeys 包含这些关键字的配置项会被隐藏
+var redactedKeys = []string{"password", "secret", "token"}
+
+// runConfigPrint 打印合并了配置文件、环境变量和默认值之后的最终配置
+func runConfigPrint(a *app, args []string) error {
+	fs := a.newFlagSet("config print")
+	redacted := fs.Bool("redacted", true, "隐藏密码等敏感配置")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+
+	values := configToMap(reflect.ValueOf(a.cfg).Elem(), *redacted)
+	if a.format == "json" {
+		return a.render(values, nil, nil)
+	}
+
+	enc := yaml.NewEncoder(a.out)
+	enc.SetIndent(2)
+	defer enc.Close()
+	return enc.Encode(values)
+}
+
+// configToMap 按 mapstructure 标签把配置结构体转换为 map，键名与配置文件一致
+func configToMap(v reflect.Value, redacted bool) map[string]interface{} {
+	result := make(map[string]interface{})
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		key := field.Tag.Get("mapstructure")
+		if key == "" {
+			key = strings.ToLower(field.Name)
+		}
+
+		value := v.Field(i)
+		switch {
+		case value.Kind() == reflect.Struct:
+			result[key] = configToMap(value, redacted)
+		case redacted && isSensitive(key) && !value.IsZero():
+			result[key] = "******"
+		default:
+			result[key] = value.Interface()
+		}
+	}
+	return result
+}
+
+// isSensitive 检查配置项是否为敏感信息
+func isSensitive(key string) bool {
+	for _, k := range redactedKeys {
+		if strings.Contains(key, k) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"example/simple-gin/internal/repository"
+)
+
+// dataAdmin 数据管理操作，由模拟数据库 repository.DB 实现
+type dataAdmin interface {
+	Seed(fixture *repository.Fixture) error
+	Reset() error
+}
+
+var _ dataAdmin = (*repository.DB)(nil)
+
+// runDBMigrate 把数据文件升级到当前版本，不需要初始化容器
+func runDBMigrate(a *app, args []string) error {
+	if a.cfg.DB.DataFile == "" {
+		return a.renderMessage(map[string]interface{}{"migrated": false}, "database.data_file is not set, nothing to migrate")
+	}
+
+	_, statErr := os.Stat(a.cfg.DB.DataFile)
+	created := errors.Is(statErr, os.ErrNotExist)
+
+	from, to, err := repository.MigrateDataFile(a.cfg.DB.DataFile)
+	if err != nil {
+		return err
+	}
+	result := map[string]interface{}{"data_file": a.cfg.DB.DataFile, "from": from, "to": to, "created": created}
+	if created {
+		return a.renderMessage(result, "created %s (version %d)", a.cfg.DB.DataFile, to)
+	}
+	if from == to {
+		return a.renderMessage(result, "%s is up to date (version %d)", a.cfg.DB.DataFile, to)
+	}
+	return a.renderMessage(result, "migrated %s from version %d to %d", a.cfg.DB.DataFile, from, to)
+}
+
+// runDBSeed 加载种子数据，追加到已有数据之后
+func runDBSeed(a *app, args []string) error {
+	fs := a.newFlagSet("db seed")
+	file := fs.String("file", a.cfg.DB.SeedFile, "种子数据文件（YAML），为空时使用内置的种子数据")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+
+	fixture, err := repository.LoadFixture(*file)
+	if err != nil {
+		return err
+	}
+	admin, err := a.dataAdmin()
+	if err != nil {
+		return err
+	}
+
+	source := *file
+	if source == "" {
+		source = repository.BuiltinFixtureName
+	}
+	if err := admin.Seed(fixture); err != nil {
+		return err
+	}
+	return a.renderMessage(map[string]int{"users": len(fixture.Users), "products": len(fixture.Products)},
+		"seeded %d users and %d products from %s", len(fixture.Users), len(fixture.Products), source)
+}
+
+// runDBReset 清空所有用户和产品，需要 -yes 确认
+func runDBReset(a *app, args []string) error {
+	fs := a.newFlagSet("db reset")
+	yes := fs.Bool("yes", false, "确认清空数据")
+	seed := fs.Bool("seed", false, "清空后重新加载种子数据")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+	if !*yes {
+		return errors.New("db reset deletes all users and products, pass -yes to confirm")
+	}
+
+	var fixture *repository.Fixture
+	if *seed {
+		var err error
+		if fixture, err = repository.LoadFixture(a.cfg.DB.SeedFile); err != nil {
+			return err
+		}
+	}
+
+	admin, err := a.dataAdmin()
+	if err != nil {
+		return err
+	}
+	if err := admin.Reset(); err != nil {
+		return err
+	}
+	if fixture != nil {
+		if err := admin.Seed(fixture); err != nil {
+			return err
+		}
+	}
+	return a.renderMessage(map[string]bool{"reset": true, "seeded": fixture != nil}, "database reset")
+}
+
+// dataAdmin 获取数据管理接口
+func (a *app) dataAdmin() (dataAdmin, error) {
+	c, err := a.getWritableContainer()
+	if err != nil {
+		return nil, err
+	}
+	admin, ok := c.DB.(dataAdmin)
+	if !ok {
+		return nil, fmt.Errorf("database %T does not support seed/reset", c.DB)
+	}
+	return admin, nil
+}
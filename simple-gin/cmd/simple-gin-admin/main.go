@@ -0,0 +1,191 @@
+// Package main simple-gin 管理命令行工具
+//
+// 与 API 服务使用相同的配置（config.LoadConfig）和依赖注入容器（container.Container），
+// 运维人员不需要手写 curl 命令就可以管理数据。
+//
+// 用法：
+//
+//	simple-gin-admin [全局参数] <命令> <子命令> [参数]
+//
+//	users    list | create | delete
+//	products import | export | adjust-stock
+//	db       migrate | seed | reset
+//	config   print
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+
+	"example/simple-gin/internal/config"
+	"example/simple-gin/internal/container"
+)
+
+// command 一个子命令
+type command struct {
+	usage string
+	run   func(a *app, args []string) error
+}
+
+// commands 所有命令，按 命令 → 子命令 组织
+var commands = map[string]map[string]command{
+	"users": {
+		"list":   {"列出所有用户", runUsersList},
+		"create": {"创建用户：-name -email -phone", runUsersCreate},
+		"delete": {"删除用户：<id>", runUsersDelete},
+	},
+	"products": {
+		"import":       {"从 JSON/CSV 导入产品：-file -format", runProductsImport},
+		"export":       {"导出产品为 JSON/CSV：-file -format", runProductsExport},
+		"adjust-stock": {"调整库存：<id> <delta>，delta 为负数表示减少", runProductsAdjustStock},
+	},
+	"db": {
+		"migrate": {"把数据文件升级到当前版本", runDBMigrate},
+		"seed":    {"加载种子数据：-file", runDBSeed},
+		"reset":   {"清空所有用户和产品：-yes -seed", runDBReset},
+	},
+	"config": {
+		"print": {"打印当前配置：-redacted", runConfigPrint},
+	},
+}
+
+// errUsage 参数错误，打印用法后退出
+var errUsage = errors.New("usage error")
+
+// app 命令执行时的上下文
+type app struct {
+	cfg    *config.Config
+	out    io.Writer
+	errOut io.Writer
+	format string // table 或 json
+
+	container *container.Container
+}
+
+// errNoDataFile 未配置数据文件时修改只存在于本进程的内存中，命令退出后即丢失
+var errNoDataFile = errors.New("database.data_file is not set, changes would be lost when the command exits; set database.data_file or pass -data-file")
+
+// getContainer 按需创建容器，db migrate、config print 等命令不需要初始化数据库
+func (a *app) getContainer() (*container.Container, error) {
+	if a.container == nil {
+		c, err := container.NewContainer(a.cfg)
+		if err != nil {
+			return nil, err
+		}
+		a.container = c
+	}
+	return a.container, nil
+}
+
+// getWritableContainer 获取用于修改数据的容器，未配置数据文件时返回 errNoDataFile
+func (a *app) getWritableContainer() (*container.Container, error) {
+	if a.cfg.DB.DataFile == "" {
+		return nil, errNoDataFile
+	}
+	return a.getContainer()
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run 解析参数并执行命令，返回进程退出码
+func run(args []string, stdout, stderr io.Writer) int {
+	fs := flag.NewFlagSet("simple-gin-admin", flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	format := fs.String("o", "table", "输出格式：table 或 json")
+	dataFile := fs.String("data-file", "", "覆盖 database.data_file")
+	seedFile := fs.String("seed-file", "", "覆盖 database.seed_file")
+	verbose := fs.Bool("v", false, "输出详细日志")
+	fs.Usage = func() { printUsage(fs, stderr) }
+
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+	if *format != "table" && *format != "json" {
+		fmt.Fprintf(stderr, "invalid output format: %s\n", *format)
+		return 2
+	}
+
+	rest := fs.Args()
+	if len(rest) < 2 {
+		fs.Usage()
+		return 2
+	}
+	cmd, ok := commands[rest[0]][rest[1]]
+	if !ok {
+		fmt.Fprintf(stderr, "unknown command: %s\n\n", strings.Join(rest[:2], " "))
+		fs.Usage()
+		return 2
+	}
+
+	// 日志写到 stderr，stdout 只输出命令结果，便于管道处理
+	level := slog.LevelWarn
+	if *verbose {
+		level = slog.LevelDebug
+	}
+	slog.SetDefault(slog.New(slog.NewTextHandler(stderr, &slog.HandlerOptions{Level: level})))
+
+	cfg := config.LoadConfig()
+	if *dataFile != "" {
+		cfg.DB.DataFile = *dataFile
+	}
+	if *seedFile != "" {
+		cfg.DB.SeedFile = *seedFile
+	}
+
+	a := &app{cfg: cfg, out: stdout, errOut: stderr, format: *format}
+	defer func() {
+		if a.container != nil {
+			a.container.Close()
+		}
+	}()
+
+	if err := cmd.run(a, rest[2:]); err != nil {
+		if !errors.Is(err, errUsage) && !errors.Is(err, flag.ErrHelp) {
+			fmt.Fprintf(stderr, "error: %v\n", err)
+		}
+		return 1
+	}
+	return 0
+}
+
+// printUsage 打印所有命令
+func printUsage(fs *flag.FlagSet, w io.Writer) {
+	fmt.Fprintln(w, "Usage: simple-gin-admin [flags] <command> <subcommand> [args]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		subs := make([]string, 0, len(commands[name]))
+		for sub := range commands[name] {
+			subs = append(subs, sub)
+		}
+		sort.Strings(subs)
+		for _, sub := range subs {
+			fmt.Fprintf(w, "  %-24s %s\n", name+" "+sub, commands[name][sub].usage)
+		}
+	}
+
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Flags:")
+	fs.PrintDefaults()
+}
+
+// newFlagSet 创建子命令参数解析器，错误信息输出到 stderr
+func (a *app) newFlagSet(name string) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(a.errOut)
+	return fs
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"example/simple-gin/internal/config"
+	"example/simple-gin/internal/container"
+	"example/simple-gin/internal/model"
+	"example/simple-gin/internal/service"
+)
+
+// runAdmin 使用临时数据文件执行一条命令
+func runAdmin(t *testing.T, dataFile string, args ...string) (string, int) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+	base := []string{"-data-file", dataFile, "-seed-file", "../../configs/seed.yaml"}
+	code := run(append(base, args...), &stdout, &stderr)
+	if code != 0 {
+		t.Logf("stderr: %s", stderr.String())
+	}
+	return stdout.String(), code
+}
+
+func TestAdminCommands(t *testing.T) {
+	dataFile := filepath.Join(t.TempDir(), "data.json")
+
+	// 首次启动没有数据文件，加载种子数据
+	out, code := runAdmin(t, dataFile, "-o", "json", "users", "list")
+	if code != 0 {
+		t.Fatalf("users list exited with %d", code)
+	}
+	var users []map[string]interface{}
+	if err := json.Unmarshal([]byte(out), &users); err != nil || len(users) != 2 {
+		t.Fatalf("Expected 2 seeded users, got %s", out)
+	}
+
+	tests := []struct {
+		name     string
+		args     []string
+		wantCode int
+		contains string
+	}{
+		{"create user", []string{"users", "create", "-name", "王五", "-email", "wangwu@example.com", "-phone", "13800138002"}, 0, "wangwu@example.com"},
+		{"create invalid user", []string{"users", "create", "-name", "王五", "-email", "bad", "-phone", "13800138002"}, 1, ""},
+		{"delete user", []string{"users", "delete", "1"}, 0, "deleted user 1"},
+		{"delete missing user", []string{"users", "delete", "1"}, 1, ""},
+		{"reduce stock", []string{"products", "adjust-stock", "1", "-10"}, 0, "40"},
+		{"increase stock", []string{"products", "adjust-stock", "1", "5"}, 0, "45"},
+		{"reduce beyond stock", []string{"products", "adjust-stock", "1", "-100"}, 1, ""},
+		{"export csv", []string{"products", "export", "-format", "csv"}, 0, "1,iPhone 15,5999,45,Electronics"},
+		{"reset without confirm", []string{"db", "reset"}, 1, ""},
+		{"unknown command", []string{"orders", "list"}, 2, ""},
+		{"invalid output format", []string{"-o", "xml", "users", "list"}, 2, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, code := runAdmin(t, dataFile, tt.args...)
+			if code != tt.wantCode {
+				t.Fatalf("Expected exit code %d, got %d", tt.wantCode, code)
+			}
+			if !strings.Contains(out, tt.contains) {
+				t.Errorf("Expected output to contain %q, got %q", tt.contains, out)
+			}
+		})
+	}
+
+	// 修改已写入数据文件，新进程可以读到
+	out, _ = runAdmin(t, dataFile, "users", "list")
+	if strings.Contains(out, "zhangsan@example.com") || !strings.Contains(out, "wangwu@example.com") {
+		t.Errorf("Expected persisted changes, got %q", out)
+	}
+}
+
+func TestAdminProductsImport(t *testing.T) {
+	dir := t.TempDir()
+	dataFile := filepath.Join(dir, "data.json")
+	csvFile := filepath.Join(dir, "products.csv")
+	os.WriteFile(csvFile, []byte("name,price,stock,category\niPad,3999,10,Electronics\nKindle,999,5,Books\n"), 0o644)
+
+	if _, code := runAdmin(t, dataFile, "db", "reset", "-yes"); code != 0 {
+		t.Fatalf("db reset exited with %d", code)
+	}
+	if out, code := runAdmin(t, dataFile, "products", "import", "-file", csvFile); code != 0 || !strings.Contains(out, "imported 2 products") {
+		t.Fatalf("products import exited with %d: %q", code, out)
+	}
+
+	out, _ := runAdmin(t, dataFile, "products", "export")
+	var products []map[string]interface{}
+	if err := json.Unmarshal([]byte(out), &products); err != nil || len(products) != 2 {
+		t.Fatalf("Expected 2 products, got %s", out)
+	}
+	if products[0]["id"] != float64(1) || products[1]["name"] != "Kindle" {
+		t.Errorf("Expected ids to restart from 1 after reset, got %v", products)
+	}
+
+	// 任一行校验失败时报错
+	os.WriteFile(csvFile, []byte("name,price,stock,category\nBad,0,1,Books\n"), 0o644)
+	if _, code := runAdmin(t, dataFile, "products", "import", "-file", csvFile); code != 1 {
+		t.Errorf("Expected import of invalid product to fail, got exit code %d", code)
+	}
+}
+
+func TestAdminMigrateAndConfig(t *testing.T) {
+	dataFile := filepath.Join(t.TempDir(), "data.json")
+
+	// 版本 0 的文件：没有 version 和下一个 ID
+	os.WriteFile(dataFile, []byte(`{"users":[{"id":7,"name":"张三","email":"a@example.com","phone":"13800138000"}],"products":[]}`), 0o644)
+	if _, code := runAdmin(t, dataFile, "users", "list"); code != 1 {
+		t.Fatalf("Expected outdated data file to be rejected, got exit code %d", code)
+	}
+
+	out, code := runAdmin(t, dataFile, "db", "migrate")
+	if code != 0 || !strings.Contains(out, "from version 0 to 1") {
+		t.Fatalf("db migrate exited with %d: %q", code, out)
+	}
+	out, _ = runAdmin(t, dataFile, "-o", "json", "users", "create", "-name", "李四", "-email", "b@example.com", "-phone", "13800138001")
+	if !strings.Contains(out, `"id": 8`) {
+		t.Errorf("Expected next user id 8 after migration, got %s", out)
+	}
+
+	out, _ = runAdmin(t, dataFile, "-o", "json", "config", "print")
+	var cfg map[string]map[string]interface{}
+	if err := json.Unmarshal([]byte(out), &cfg); err != nil {
+		t.Fatalf("Failed to parse config: %v", err)
+	}
+	if cfg["database"]["password"] != "******" {
+		t.Errorf("Expected password to be redacted, got %v", cfg["database"]["password"])
+	}
+	if cfg["database"]["data_file"] != dataFile {
+		t.Errorf("Expected data_file %s, got %v", dataFile, cfg["database"]["data_file"])
+	}
+}
+
+func TestAdminSeedFile(t *testing.T) {
+	dir := t.TempDir()
+
+	// 未指定种子文件时使用内置的种子数据，与工作目录无关
+	var stdout, stderr bytes.Buffer
+	args := []string{"-data-file", filepath.Join(dir, "builtin.json"), "-o", "json", "users", "list"}
+	if code := run(args, &stdout, &stderr); code != 0 {
+		t.Fatalf("users list exited with %d: %s", code, stderr.String())
+	}
+	var users []map[string]interface{}
+	if err := json.Unmarshal(stdout.Bytes(), &users); err != nil || len(users) != 2 {
+		t.Fatalf("Expected 2 built-in seed users, got %s", stdout.String())
+	}
+
+	// 显式指定的种子文件不存在时报错
+	stdout.Reset()
+	stderr.Reset()
+	args = []string{"-data-file", filepath.Join(dir, "missing.json"), "-seed-file", filepath.Join(dir, "missing.yaml"), "users", "list"}
+	if code := run(args, &stdout, &stderr); code != 1 || !strings.Contains(stderr.String(), "missing.yaml") {
+		t.Fatalf("Expected missing seed file to fail, got exit code %d: %s", code, stderr.String())
+	}
+}
+
+func TestAdminRequiresDataFile(t *testing.T) {
+	// 未配置数据文件时修改命令会在退出时丢失，必须失败而不是报告成功
+	tests := [][]string{
+		{"users", "create", "-name", "王五", "-email", "wangwu@example.com", "-phone", "13800138002"},
+		{"users", "delete", "1"},
+		{"products", "import", "-file", "products.json"},
+		{"products", "adjust-stock", "1", "5"},
+		{"db", "seed"},
+		{"db", "reset", "-yes"},
+	}
+	for _, args := range tests {
+		t.Run(strings.Join(args[:2], " "), func(t *testing.T) {
+			if args[1] == "import" {
+				file := filepath.Join(t.TempDir(), "products.json")
+				os.WriteFile(file, []byte(`[{"name":"iPad","price":3999,"stock":10,"category":"Electronics"}]`), 0o644)
+				args[3] = file
+			}
+			var stdout, stderr bytes.Buffer
+			if code := run(args, &stdout, &stderr); code != 1 {
+				t.Fatalf("Expected exit code 1, got %d: %s", code, stdout.String())
+			}
+			if !strings.Contains(stderr.String(), "database.data_file is not set") {
+				t.Errorf("Expected data file error, got %q", stderr.String())
+			}
+		})
+	}
+
+	// 只读命令不需要数据文件
+	var stdout, stderr bytes.Buffer
+	if code := run([]string{"users", "list"}, &stdout, &stderr); code != 0 {
+		t.Fatalf("users list exited with %d: %s", code, stderr.String())
+	}
+}
+
+// newServer 模拟运行中的 API 服务：与命令行使用同一个数据文件的容器
+func newServer(t *testing.T, dataFile string) *container.Container {
+	t.Helper()
+	c, err := container.NewContainer(&config.Config{DB: config.DatabaseConfig{DataFile: dataFile}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func TestAdminWhileServerRunning(t *testing.T) {
+	ctx := context.Background()
+	dataFile := filepath.Join(t.TempDir(), "data.json")
+	server := newServer(t, dataFile)
+
+	// 命令行在服务运行期间写入，服务读取时可以看到
+	if _, code := runAdmin(t, dataFile, "users", "create", "-name", "王五", "-email", "wangwu@example.com", "-phone", "13800138002"); code != 0 {
+		t.Fatalf("users create exited with %d", code)
+	}
+	users, err := server.UserService.GetUsers(ctx)
+	if err != nil || len(users) != 3 {
+		t.Fatalf("Expected the server to see 3 users, got %d (%v)", len(users), err)
+	}
+
+	// 服务随后的写入不会覆盖命令行的修改，ID 也不会冲突
+	created, err := server.UserService.CreateUser(ctx, &model.CreateUserRequest{Name: "赵六", Email: "zhaoliu@example.com", Phone: "13800138003"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created.ID != 4 {
+		t.Errorf("Expected the server to use the next id 4, got %d", created.ID)
+	}
+	out, _ := runAdmin(t, dataFile, "users", "list")
+	if !strings.Contains(out, "wangwu@example.com") || !strings.Contains(out, "zhaoliu@example.com") {
+		t.Errorf("Expected both changes to be persisted, got %q", out)
+	}
+}
+
+func TestPersistErrorIsReturned(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "data")
+	server := newServer(t, filepath.Join(dir, "data.json"))
+
+	// 数据目录被替换为普通文件，之后的写入都会失败
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(dir, nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := server.UserService.CreateUser(context.Background(), &model.CreateUserRequest{Name: "王五", Email: "wangwu@example.com", Phone: "13800138002"})
+	if !errors.Is(err, service.ErrPersist) {
+		t.Fatalf("Expected ErrPersist, got %v", err)
+	}
+}
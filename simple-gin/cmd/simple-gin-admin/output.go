@@ -0,0 +1,33 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+)
+
+// render 按输出格式打印结果：json 序列化 value，table 打印表头和各行
+func (a *app) render(value interface{}, header []string, rows [][]string) error {
+	if a.format == "json" {
+		enc := json.NewEncoder(a.out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(value)
+	}
+
+	w := tabwriter.NewWriter(a.out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, strings.Join(header, "\t"))
+	for _, row := range rows {
+		fmt.Fprintln(w, strings.Join(row, "\t"))
+	}
+	return w.Flush()
+}
+
+// renderMessage 打印操作结果：json 输出 value，table 输出一行提示
+func (a *app) renderMessage(value interface{}, format string, args ...interface{}) error {
+	if a.format == "json" {
+		return a.render(value, nil, nil)
+	}
+	_, err := fmt.Fprintf(a.out, format+"\n", args...)
+	return err
+}
@@ -0,0 +1,217 @@
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"example/simple-gin/internal/model"
+)
+
+// productCSVHeader 导入导出 CSV 的表头，导入时 id 列可省略
+var productCSVHeader = []string{"id", "name", "price", "stock", "category"}
+
+// runProductsImport 从 JSON 数组或 CSV 导入产品，逐条校验，任一条失败则停止
+func runProductsImport(a *app, args []string) error {
+	fs := a.newFlagSet("products import")
+	file := fs.String("file", "-", "输入文件，- 表示标准输入")
+	format := fs.String("format", "", "json 或 csv，默认按文件扩展名判断")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+
+	r, closeFn, err := openInput(*file)
+	if err != nil {
+		return err
+	}
+	defer closeFn()
+
+	var reqs []*model.CreateProductRequest
+	switch fileFormat(*file, *format) {
+	case "csv":
+		reqs, err = readProductsCSV(r)
+	default:
+		err = json.NewDecoder(r).Decode(&reqs)
+	}
+	if err != nil {
+		return fmt.Errorf("parse products: %w", err)
+	}
+
+	c, err := a.getWritableContainer()
+	if err != nil {
+		return err
+	}
+
+	imported := make([]*model.Product, 0, len(reqs))
+	for i, req := range reqs {
+		product, err := c.ProductService.CreateProduct(context.Background(), req)
+		if err != nil {
+			return fmt.Errorf("product #%d (%s): %w, %d imported before failure", i+1, req.Name, err, len(imported))
+		}
+		imported = append(imported, product)
+	}
+
+	return a.renderMessage(imported, "imported %d products", len(imported))
+}
+
+// runProductsExport 按 ID 顺序导出所有产品
+func runProductsExport(a *app, args []string) error {
+	fs := a.newFlagSet("products export")
+	file := fs.String("file", "-", "输出文件，- 表示标准输出")
+	format := fs.String("format", "", "json 或 csv，默认按文件扩展名判断")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+
+	c, err := a.getContainer()
+	if err != nil {
+		return err
+	}
+	products, err := c.ProductService.GetProducts(context.Background())
+	if err != nil {
+		return err
+	}
+	sort.Slice(products, func(i, j int) bool { return products[i].ID < products[j].ID })
+
+	w := a.out
+	if *file != "-" {
+		f, err := os.Create(*file)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	switch fileFormat(*file, *format) {
+	case "csv":
+		cw := csv.NewWriter(w)
+		cw.Write(productCSVHeader)
+		for _, p := range products {
+			cw.Write([]string{
+				strconv.Itoa(p.ID),
+				p.Name,
+				strconv.FormatFloat(p.Price, 'f', -1, 64),
+				strconv.Itoa(p.Stock),
+				p.Category,
+			})
+		}
+		cw.Flush()
+		return cw.Error()
+	default:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(products)
+	}
+}
+
+// runProductsAdjustStock 调整库存，减少时使用原子扣减，库存不足会失败
+func runProductsAdjustStock(a *app, args []string) error {
+	id, err := parseID(args)
+	if err != nil {
+		return err
+	}
+	if len(args) < 2 {
+		return fmt.Errorf("missing delta")
+	}
+	delta, err := strconv.Atoi(args[1])
+	if err != nil || delta == 0 {
+		return fmt.Errorf("invalid delta: %s", args[1])
+	}
+
+	c, err := a.getWritableContainer()
+	if err != nil {
+		return err
+	}
+	ctx := context.Background()
+
+	if delta < 0 {
+		err = c.ProductService.ReduceStock(ctx, id, -delta)
+	} else {
+		var product *model.Product
+		if product, err = c.ProductService.GetProductByID(ctx, id); err == nil {
+			_, err = c.ProductService.UpdateProduct(ctx, id, &model.UpdateProductRequest{Stock: product.Stock + delta})
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	product, err := c.ProductService.GetProductByID(ctx, id)
+	if err != nil {
+		return err
+	}
+	return a.render(product, []string{"ID", "NAME", "STOCK"}, [][]string{
+		{strconv.Itoa(product.ID), product.Name, strconv.Itoa(product.Stock)},
+	})
+}
+
+// readProductsCSV 读取 CSV，第一行为表头，按列名取值
+func readProductsCSV(r io.Reader) ([]*model.CreateProductRequest, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, nil
+	}
+
+	columns := make(map[string]int)
+	for i, name := range records[0] {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, name := range []string{"name", "price", "stock", "category"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("missing column: %s", name)
+		}
+	}
+
+	reqs := make([]*model.CreateProductRequest, 0, len(records)-1)
+	for line, record := range records[1:] {
+		price, err := strconv.ParseFloat(record[columns["price"]], 64)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid price: %w", line+2, err)
+		}
+		stock, err := strconv.Atoi(record[columns["stock"]])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid stock: %w", line+2, err)
+		}
+		reqs = append(reqs, &model.CreateProductRequest{
+			Name:     record[columns["name"]],
+			Price:    price,
+			Stock:    stock,
+			Category: record[columns["category"]],
+		})
+	}
+	return reqs, nil
+}
+
+// openInput 打开输入文件，- 表示标准输入
+func openInput(path string) (io.Reader, func() error, error) {
+	if path == "-" {
+		return os.Stdin, func() error { return nil }, nil
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, f.Close, nil
+}
+
+// fileFormat 确定文件格式：显式指定优先，否则按扩展名，默认 json
+func fileFormat(path, format string) string {
+	if format != "" {
+		return strings.ToLower(format)
+	}
+	if strings.EqualFold(filepath.Ext(path), ".csv") {
+		return "csv"
+	}
+	return "json"
+}
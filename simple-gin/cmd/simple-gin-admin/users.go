@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"example/simple-gin/internal/model"
+)
+
+// runUsersList 列出所有用户
+func runUsersList(a *app, args []string) error {
+	c, err := a.getContainer()
+	if err != nil {
+		return err
+	}
+
+	users, err := c.UserService.GetUsers(context.Background())
+	if err != nil {
+		return err
+	}
+	sort.Slice(users, func(i, j int) bool { return users[i].ID < users[j].ID })
+
+	rows := make([][]string, 0, len(users))
+	for _, u := range users {
+		rows = append(rows, []string{strconv.Itoa(u.ID), u.Name, u.Email, u.Phone, u.CreatedAt.Format(time.DateTime)})
+	}
+	return a.render(users, []string{"ID", "NAME", "EMAIL", "PHONE", "CREATED_AT"}, rows)
+}
+
+// runUsersCreate 创建用户，与 API 使用相同的校验规则
+func runUsersCreate(a *app, args []string) error {
+	fs := a.newFlagSet("users create")
+	req := &model.CreateUserRequest{}
+	fs.StringVar(&req.Name, "name", "", "用户名（必填）")
+	fs.StringVar(&req.Email, "email", "", "邮箱（必填）")
+	fs.StringVar(&req.Phone, "phone", "", "手机号（必填）")
+	if err := fs.Parse(args); err != nil {
+		return errUsage
+	}
+
+	c, err := a.getWritableContainer()
+	if err != nil {
+		return err
+	}
+
+	user, err := c.UserService.CreateUser(context.Background(), req)
+	if err != nil {
+		return err
+	}
+	return a.render(user, []string{"ID", "NAME", "EMAIL", "PHONE"}, [][]string{
+		{strconv.Itoa(user.ID), user.Name, user.Email, user.Phone},
+	})
+}
+
+// runUsersDelete 删除用户
+func runUsersDelete(a *app, args []string) error {
+	id, err := parseID(args)
+	if err != nil {
+		return err
+	}
+
+	c, err := a.getWritableContainer()
+	if err != nil {
+		return err
+	}
+
+	if err := c.UserService.DeleteUser(context.Background(), id); err != nil {
+		return err
+	}
+	return a.renderMessage(map[string]int{"deleted": id}, "deleted user %d", id)
+}
+
+// parseID 解析第一个位置参数为 ID
+func parseID(args []string) (int, error) {
+	if len(args) < 1 {
+		return 0, fmt.Errorf("missing id")
+	}
+	id, err := strconv.Atoi(args[0])
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid id: %s", args[0])
+	}
+	return id, nil
+}
@@ -55,9 +55,10 @@ func main() {
 
 	// 6. 设置路由
 	routerCfg := &router.RouterConfig{
-		EnableSwagger: cfg.Swagger.Enabled,
+		EnableSwagger:   cfg.Swagger.Enabled,
+		StreamHeartbeat: cfg.Stream.GetHeartbeatInterval(),
 	}
-	router.SetupRoutes(r, c.UserService, c.ProductService, routerCfg)
+	router.SetupRoutes(r, c.UserService, c.ProductService, c.WebhookService, c.InventoryStream, routerCfg)
 
 	// 7. 启动服务器
 	if cfg.Swagger.Enabled {
@@ -70,6 +71,7 @@ func main() {
 	}
 	if err := r.Run(cfg.Server.GetServerAddr()); err != nil {
 		slog.Error("failed to start server", "error", err)
+		c.Close()
 		os.Exit(1)
 	}
 }
@@ -0,0 +1,10 @@
+// Package configs 内置的默认配置资源
+package configs
+
+import _ "embed"
+
+// Seed 内置的种子数据（seed.yaml），未配置 database.seed_file 时使用，
+// 编译进程序后从任何目录启动都能加载
+//
+//go:embed seed.yaml
+var Seed []byte
@@ -3,6 +3,7 @@ package config
 import (
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/spf13/viper"
 )
@@ -15,6 +16,8 @@ type Config struct {
 	Logger     LoggerConfig     `mapstructure:"logger"`
 	Cache      CacheConfig      `mapstructure:"cache"`
 	Middleware MiddlewareConfig `mapstructure:"middleware"`
+	Webhook    WebhookConfig    `mapstructure:"webhook"`
+	Stream     StreamConfig     `mapstructure:"stream"`
 }
 
 // SwaggerConfig Swagger 文档配置
@@ -40,6 +43,8 @@ type DatabaseConfig struct {
 	MaxConnections  int    `mapstructure:"max_connections"`
 	IdleConnections int    `mapstructure:"idle_connections"`
 	MaxIdleTime     int    `mapstructure:"max_idle_time"` // 秒数
+	SeedFile        string `mapstructure:"seed_file"`     // 种子数据（YAML），没有数据文件时加载，为空时使用内置的种子数据
+	DataFile        string `mapstructure:"data_file"`     // 模拟数据库的数据文件（JSON），为空时只保存在内存中
 }
 
 // LoggerConfig 日志配置
@@ -69,6 +74,52 @@ type CORSConfig struct {
 	AllowedHeaders []string `mapstructure:"allowed_headers"`
 }
 
+// WebhookConfig Webhook 投递配置
+type WebhookConfig struct {
+	Workers           int    `mapstructure:"workers"`
+	QueueSize         int    `mapstructure:"queue_size"`
+	MaxRetries        int    `mapstructure:"max_retries"`
+	InitialBackoff    string `mapstructure:"initial_backoff"`
+	MaxBackoff        string `mapstructure:"max_backoff"`
+	Timeout           string `mapstructure:"timeout"`
+	StockLowThreshold int    `mapstructure:"stock_low_threshold"` // 库存低于该值时发布 product.stock_low
+}
+
+// GetInitialBackoff 获取首次重试等待时间
+func (c *WebhookConfig) GetInitialBackoff() time.Duration {
+	return parseDuration(c.InitialBackoff)
+}
+
+// GetMaxBackoff 获取重试等待时间上限
+func (c *WebhookConfig) GetMaxBackoff() time.Duration {
+	return parseDuration(c.MaxBackoff)
+}
+
+// GetTimeout 获取单次投递超时时间
+func (c *WebhookConfig) GetTimeout() time.Duration {
+	return parseDuration(c.Timeout)
+}
+
+// StreamConfig 实时库存推送配置
+type StreamConfig struct {
+	BufferSize        int    `mapstructure:"buffer_size"`        // 保留用于 Last-Event-ID 续传的最近事件数
+	HeartbeatInterval string `mapstructure:"heartbeat_interval"` // 心跳间隔
+}
+
+// GetHeartbeatInterval 获取心跳间隔
+func (c *StreamConfig) GetHeartbeatInterval() time.Duration {
+	return parseDuration(c.HeartbeatInterval)
+}
+
+// parseDuration 解析时长字符串，格式错误时返回 0（由调用方使用默认值）
+func parseDuration(s string) time.Duration {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0
+	}
+	return d
+}
+
 // LoadConfig 从配置文件加载配置（使用 Viper）
 // 优先级：环境变量 > 配置文件 > 默认值
 // 根据 APP_ENV 环境变量加载不同配置：
@@ -102,27 +153,27 @@ func LoadConfig() *Config {
 		if _, ok := err.(viper.ConfigFileNotFoundError); ok {
 			// 如果指定了环境但配置文件不存在，回退到默认 config.yaml
 			if env != "" {
-				fmt.Printf("Config file '%s.yaml' not found, falling back to 'config.yaml'\n", configName)
+				fmt.Fprintf(os.Stderr, "Config file '%s.yaml' not found, falling back to 'config.yaml'\n", configName)
 				v.SetConfigName("config")
 				if err := v.ReadInConfig(); err != nil {
 					if _, ok := err.(viper.ConfigFileNotFoundError); ok {
-						fmt.Println("Config file 'config.yaml' not found, using defaults and environment variables")
+						fmt.Fprintln(os.Stderr, "Config file 'config.yaml' not found, using defaults and environment variables")
 					} else {
-						fmt.Printf("Error reading config file: %v\n", err)
+						fmt.Fprintf(os.Stderr, "Error reading config file: %v\n", err)
 						os.Exit(1)
 					}
 				} else {
-					fmt.Printf("Config file loaded: %s (fallback)\n", v.ConfigFileUsed())
+					fmt.Fprintf(os.Stderr, "Config file loaded: %s (fallback)\n", v.ConfigFileUsed())
 				}
 			} else {
-				fmt.Println("Config file 'config.yaml' not found, using defaults and environment variables")
+				fmt.Fprintln(os.Stderr, "Config file 'config.yaml' not found, using defaults and environment variables")
 			}
 		} else {
-			fmt.Printf("Error reading config file: %v\n", err)
+			fmt.Fprintf(os.Stderr, "Error reading config file: %v\n", err)
 			os.Exit(1)
 		}
 	} else {
-		fmt.Printf("Config file loaded: %s (env: %s)\n", v.ConfigFileUsed(), env)
+		fmt.Fprintf(os.Stderr, "Config file loaded: %s (env: %s)\n", v.ConfigFileUsed(), env)
 	}
 
 	// 设置所有默认值（覆盖缺失的配置项）
@@ -131,17 +182,17 @@ func LoadConfig() *Config {
 	// 反序列化为结构体
 	cfg := &Config{}
 	if err := v.Unmarshal(cfg); err != nil {
-		fmt.Printf("Error unmarshaling config: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Error unmarshaling config: %v\n", err)
 		os.Exit(1)
 	}
 
 	// 验证配置
 	if err := cfg.Validate(); err != nil {
-		fmt.Printf("Config validation failed: %v\n", err)
+		fmt.Fprintf(os.Stderr, "Config validation failed: %v\n", err)
 		os.Exit(1)
 	}
 
-	fmt.Println("Config loaded successfully")
+	fmt.Fprintln(os.Stderr, "Config loaded successfully")
 	return cfg
 }
 
@@ -165,6 +216,8 @@ func setDefaultsWithViper(v *viper.Viper) {
 	v.SetDefault("database.max_connections", 10)
 	v.SetDefault("database.idle_connections", 5)
 	v.SetDefault("database.max_idle_time", 300)
+	v.SetDefault("database.seed_file", "")
+	v.SetDefault("database.data_file", "")
 
 	// Logger
 	v.SetDefault("logger.level", "info")
@@ -181,6 +234,19 @@ func setDefaultsWithViper(v *viper.Viper) {
 	v.SetDefault("middleware.cors.allowed_origins", []string{"*"})
 	v.SetDefault("middleware.cors.allowed_methods", []string{"GET", "POST", "PUT", "DELETE"})
 	v.SetDefault("middleware.cors.allowed_headers", []string{"*"})
+
+	// Webhook
+	v.SetDefault("webhook.workers", 4)
+	v.SetDefault("webhook.queue_size", 1000)
+	v.SetDefault("webhook.max_retries", 5)
+	v.SetDefault("webhook.initial_backoff", "1s")
+	v.SetDefault("webhook.max_backoff", "1m")
+	v.SetDefault("webhook.timeout", "10s")
+	v.SetDefault("webhook.stock_low_threshold", 10)
+
+	// Stream
+	v.SetDefault("stream.buffer_size", 1000)
+	v.SetDefault("stream.heartbeat_interval", "15s")
 }
 
 // Validate 验证配置的合法性
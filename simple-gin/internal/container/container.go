@@ -16,15 +16,20 @@ type Container struct {
 	Config *config.Config
 
 	// Database
-	DB service.Database
+	DB           service.Database
+	WebhookStore service.WebhookStore
 
 	// Services
-	UserService    service.UserService
-	ProductService service.ProductService
+	UserService     service.UserService
+	ProductService  service.ProductService
+	WebhookService  service.WebhookService
+	InventoryStream service.InventoryStream
 
 	// Handlers
 	UserHandler    *handler.UserHandler
 	ProductHandler *handler.ProductHandler
+	WebhookHandler *handler.WebhookHandler
+	StreamHandler  *handler.StreamHandler
 
 	// Middleware (如果需要注入)
 	// 可以在这里添加中间件、日志系统等
@@ -58,14 +63,29 @@ func (c *Container) initDatabase() error {
 		return err
 	}
 	c.DB = db
+	c.WebhookStore = db
 	slog.Debug("database layer initialized")
 	return nil
 }
 
 // initServices 初始化服务层
 func (c *Container) initServices() {
-	c.UserService = service.NewUserService(c.DB)
-	c.ProductService = service.NewProductService(c.DB)
+	webhookCfg := c.Config.Webhook
+	c.WebhookService = service.NewWebhookService(c.WebhookStore, service.WebhookOptions{
+		Workers:        webhookCfg.Workers,
+		QueueSize:      webhookCfg.QueueSize,
+		MaxRetries:     webhookCfg.MaxRetries,
+		InitialBackoff: webhookCfg.GetInitialBackoff(),
+		MaxBackoff:     webhookCfg.GetMaxBackoff(),
+		Timeout:        webhookCfg.GetTimeout(),
+	})
+
+	c.InventoryStream = service.NewInventoryStream(c.Config.Stream.BufferSize)
+
+	// 用户和产品服务发布的事件同时交给 WebhookService 投递和 InventoryStream 推送
+	events := service.NewMultiPublisher(c.WebhookService, c.InventoryStream)
+	c.UserService = service.NewUserService(c.DB, events)
+	c.ProductService = service.NewProductService(c.DB, events, webhookCfg.StockLowThreshold)
 	slog.Debug("service layer initialized")
 }
 
@@ -73,6 +93,8 @@ func (c *Container) initServices() {
 func (c *Container) initHandlers() {
 	c.UserHandler = handler.NewUserHandler(c.UserService)
 	c.ProductHandler = handler.NewProductHandler(c.ProductService)
+	c.WebhookHandler = handler.NewWebhookHandler(c.WebhookService)
+	c.StreamHandler = handler.NewStreamHandler(c.InventoryStream, c.Config.Stream.GetHeartbeatInterval())
 	slog.Debug("handler layer initialized")
 }
 
@@ -84,8 +106,15 @@ func (c *Container) GetMiddlewares() []func(*interface{}) {
 	return nil
 }
 
-// 如果需要关闭资源（如数据库连接），可以添加 Close 方法
-// func (c *Container) Close() error {
-//     // 关闭数据库连接等
-//     return nil
-// }
+// Close 释放容器持有的资源
+// 断开所有实时推送订阅，停止 Webhook 投递协程并等待进行中的投递结束
+func (c *Container) Close() error {
+	if c.InventoryStream != nil {
+		c.InventoryStream.Close()
+	}
+	if c.WebhookService != nil {
+		c.WebhookService.Close()
+	}
+	slog.Debug("container closed")
+	return nil
+}
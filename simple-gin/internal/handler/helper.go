@@ -2,8 +2,12 @@ package handler
 
 import (
 	"context"
+	"errors"
 	"time"
 
+	"example/simple-gin/internal/service"
+	"example/simple-gin/pkg/response"
+
 	"github.com/gin-gonic/gin"
 )
 
@@ -16,3 +20,13 @@ func createContextWithTimeout(c *gin.Context, timeout time.Duration) (context.Co
 	// 创建具有超时的context，如果Gin context更早被取消也会传播
 	return context.WithTimeout(ctx, timeout)
 }
+
+// respondServiceError 返回写操作的错误：数据没有写入存储时返回 500，
+// 其他错误（参数校验、记录不存在等）按 fallback 返回
+func respondServiceError(c *gin.Context, err error, fallback func(c *gin.Context, message string)) {
+	if errors.Is(err, service.ErrPersist) {
+		response.InternalError(c, err.Error())
+		return
+	}
+	fallback(c, err.Error())
+}
@@ -105,7 +105,7 @@ func (h *ProductHandler) CreateProduct(c *gin.Context) {
 	product, err := h.productService.CreateProduct(ctx, &req)
 	if err != nil {
 		slog.Error("error creating product", "error", err)
-		response.BadRequest(c, err.Error())
+		respondServiceError(c, err, response.BadRequest)
 		return
 	}
 
@@ -145,7 +145,7 @@ func (h *ProductHandler) UpdateProduct(c *gin.Context) {
 	product, err := h.productService.UpdateProduct(ctx, id, &req)
 	if err != nil {
 		slog.Error("error updating product", "id", id, "error", err)
-		response.NotFound(c, err.Error())
+		respondServiceError(c, err, response.NotFound)
 		return
 	}
 
@@ -178,7 +178,7 @@ func (h *ProductHandler) DeleteProduct(c *gin.Context) {
 	err = h.productService.DeleteProduct(ctx, id)
 	if err != nil {
 		slog.Error("error deleting product", "id", id, "error", err)
-		response.NotFound(c, err.Error())
+		respondServiceError(c, err, response.NotFound)
 		return
 	}
 
@@ -217,7 +217,7 @@ func (h *ProductHandler) ReduceStock(c *gin.Context) {
 	err = h.productService.ReduceStock(ctx, id, req.Quantity)
 	if err != nil {
 		slog.Error("error reducing stock", "id", id, "error", err)
-		response.BadRequest(c, err.Error())
+		respondServiceError(c, err, response.BadRequest)
 		return
 	}
 
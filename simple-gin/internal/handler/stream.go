@@ -0,0 +1,229 @@
+package handler
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"example/simple-gin/internal/model"
+	"example/simple-gin/internal/service"
+	"example/simple-gin/pkg/response"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// StreamHandler 实时库存推送处理器
+type StreamHandler struct {
+	stream    service.InventoryStream
+	heartbeat time.Duration
+	upgrader  websocket.Upgrader
+}
+
+// NewStreamHandler 创建实时库存推送处理器实例
+// heartbeat 为心跳间隔，<= 0 时使用默认值 15s
+func NewStreamHandler(stream service.InventoryStream, heartbeat time.Duration) *StreamHandler {
+	if heartbeat <= 0 {
+		heartbeat = 15 * time.Second
+	}
+	return &StreamHandler{
+		stream:    stream,
+		heartbeat: heartbeat,
+		upgrader: websocket.Upgrader{
+			// 与 CORSMiddleware 保持一致，允许任意来源
+			CheckOrigin: func(r *http.Request) bool { return true },
+		},
+	}
+}
+
+// StreamProducts godoc
+//
+//	@Summary		订阅库存变化（SSE）
+//	@Description	以 Server-Sent Events 推送产品的创建、库存和价格变化。断线重连时携带 Last-Event-ID 头（或 last_event_id 参数）可补发缓冲区内错过的事件；超出缓冲区时先推送 stream.reset 事件
+//	@Tags			products
+//	@Produce		text/event-stream
+//	@Param			product_id		query		[]int	false	"只推送指定产品，可重复"	collectionFormat(multi)
+//	@Param			category		query		string	false	"只推送指定分类"
+//	@Param			last_event_id	query		int		false	"从该事件之后续传"
+//	@Param			Last-Event-ID	header		int		false	"从该事件之后续传（EventSource 自动携带）"
+//	@Success		200				{object}	model.InventoryEvent
+//	@Failure		400				{object}	response.Response
+//	@Failure		503				{object}	response.Response
+//	@Router			/api/v1/products/stream [get]
+func (h *StreamHandler) StreamProducts(c *gin.Context) {
+	filter, lastEventID, err := parseStreamParams(c)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	sub, replay, err := h.stream.Subscribe(filter, lastEventID)
+	if err != nil {
+		response.Error(c, http.StatusServiceUnavailable, 503, err.Error())
+		return
+	}
+	defer sub.Close()
+
+	w := c.Writer
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no") // 禁止 Nginx 缓冲
+	w.WriteHeader(http.StatusOK)
+
+	// 建议客户端断线 3 秒后重连
+	fmt.Fprint(w, "retry: 3000\n\n")
+	for _, event := range replay {
+		if err := writeSSE(w, event); err != nil {
+			return
+		}
+	}
+	w.Flush()
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case event, ok := <-sub.Events():
+			if !ok {
+				return
+			}
+			if err := writeSSE(w, event); err != nil {
+				return
+			}
+			w.Flush()
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+			w.Flush()
+		}
+	}
+}
+
+// StreamProductsWebSocket godoc
+//
+//	@Summary		订阅库存变化（WebSocket）
+//	@Description	与 SSE 接口相同的事件，以 JSON 文本消息推送；心跳使用 WebSocket Ping 帧。续传使用 last_event_id 参数
+//	@Tags			products
+//	@Param			product_id		query	[]int	false	"只推送指定产品，可重复"	collectionFormat(multi)
+//	@Param			category		query	string	false	"只推送指定分类"
+//	@Param			last_event_id	query	int		false	"从该事件之后续传"
+//	@Success		101				"Switching Protocols"
+//	@Failure		400				{object}	response.Response
+//	@Failure		503				{object}	response.Response
+//	@Router			/api/v1/products/ws [get]
+func (h *StreamHandler) StreamProductsWebSocket(c *gin.Context) {
+	filter, lastEventID, err := parseStreamParams(c)
+	if err != nil {
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	sub, replay, err := h.stream.Subscribe(filter, lastEventID)
+	if err != nil {
+		response.Error(c, http.StatusServiceUnavailable, 503, err.Error())
+		return
+	}
+	defer sub.Close()
+
+	conn, err := h.upgrader.Upgrade(c.Writer, c.Request, nil)
+	if err != nil {
+		// Upgrade 失败时已经写入了错误响应
+		slog.Warn("websocket upgrade failed", "error", err)
+		return
+	}
+	defer conn.Close()
+
+	// 读协程：处理 Pong 和关闭帧，客户端断开时通知写循环退出
+	closed := make(chan struct{})
+	conn.SetReadDeadline(time.Now().Add(2 * h.heartbeat))
+	conn.SetPongHandler(func(string) error {
+		return conn.SetReadDeadline(time.Now().Add(2 * h.heartbeat))
+	})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	for _, event := range replay {
+		if err := conn.WriteJSON(event); err != nil {
+			return
+		}
+	}
+
+	ticker := time.NewTicker(h.heartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-closed:
+			return
+		case event, ok := <-sub.Events():
+			if !ok {
+				conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, "stream closed"))
+				return
+			}
+			if err := conn.WriteJSON(event); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(h.heartbeat)); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// parseStreamParams 解析过滤条件和续传位置
+func parseStreamParams(c *gin.Context) (model.StreamFilter, int64, error) {
+	var filter model.StreamFilter
+
+	for _, raw := range c.QueryArray("product_id") {
+		for _, part := range strings.Split(raw, ",") {
+			id, err := strconv.Atoi(strings.TrimSpace(part))
+			if err != nil || id <= 0 {
+				return filter, 0, fmt.Errorf("invalid product_id: %s", part)
+			}
+			filter.ProductIDs = append(filter.ProductIDs, id)
+		}
+	}
+	filter.Category = c.Query("category")
+
+	lastEventIDStr := c.GetHeader("Last-Event-ID")
+	if lastEventIDStr == "" {
+		lastEventIDStr = c.Query("last_event_id")
+	}
+
+	var lastEventID int64
+	if lastEventIDStr != "" {
+		var err error
+		lastEventID, err = strconv.ParseInt(lastEventIDStr, 10, 64)
+		if err != nil || lastEventID < 0 {
+			return filter, 0, fmt.Errorf("invalid last event id: %s", lastEventIDStr)
+		}
+	}
+
+	return filter, lastEventID, nil
+}
+
+// writeSSE 按 SSE 格式写出一个事件
+func writeSSE(w gin.ResponseWriter, event *model.InventoryEvent) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
@@ -105,7 +105,7 @@ func (h *UserHandler) CreateUser(c *gin.Context) {
 	user, err := h.userService.CreateUser(ctx, &req)
 	if err != nil {
 		slog.Error("error creating user", "error", err)
-		response.BadRequest(c, err.Error())
+		respondServiceError(c, err, response.BadRequest)
 		return
 	}
 
@@ -145,7 +145,7 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 	user, err := h.userService.UpdateUser(ctx, id, &req)
 	if err != nil {
 		slog.Error("error updating user", "id", id, "error", err)
-		response.NotFound(c, err.Error())
+		respondServiceError(c, err, response.NotFound)
 		return
 	}
 
@@ -178,7 +178,7 @@ func (h *UserHandler) DeleteUser(c *gin.Context) {
 	err = h.userService.DeleteUser(ctx, id)
 	if err != nil {
 		slog.Error("error deleting user", "id", id, "error", err)
-		response.NotFound(c, err.Error())
+		respondServiceError(c, err, response.NotFound)
 		return
 	}
 
@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"log/slog"
+	"strconv"
+	"time"
+
+	"example/simple-gin/internal/model"
+	"example/simple-gin/internal/service"
+	"example/simple-gin/pkg/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// WebhookHandler Webhook 处理器
+type WebhookHandler struct {
+	webhookService service.WebhookService
+}
+
+// NewWebhookHandler 创建 Webhook 处理器实例
+func NewWebhookHandler(webhookService service.WebhookService) *WebhookHandler {
+	return &WebhookHandler{
+		webhookService: webhookService,
+	}
+}
+
+// GetWebhooks godoc
+//
+//	@Summary		获取所有 Webhook
+//	@Description	获取已注册的 Webhook 列表（不含签名密钥）
+//	@Tags			webhooks
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	response.Response{data=[]model.Webhook}
+//	@Failure		500	{object}	response.Response
+//	@Router			/api/v1/webhooks [get]
+func (h *WebhookHandler) GetWebhooks(c *gin.Context) {
+	ctx, cancel := createContextWithTimeout(c, 5*time.Second)
+	defer cancel()
+
+	webhooks, err := h.webhookService.GetWebhooks(ctx)
+	if err != nil {
+		slog.Error("error getting webhooks", "error", err)
+		response.InternalError(c, "failed to get webhooks: "+err.Error())
+		return
+	}
+
+	response.Success(c, webhooks)
+}
+
+// GetWebhook godoc
+//
+//	@Summary		获取单个 Webhook
+//	@Description	根据ID获取 Webhook 详情（不含签名密钥）
+//	@Tags			webhooks
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		int	true	"Webhook ID"
+//	@Success		200	{object}	response.Response{data=model.Webhook}
+//	@Failure		400	{object}	response.Response
+//	@Failure		404	{object}	response.Response
+//	@Router			/api/v1/webhooks/{id} [get]
+func (h *WebhookHandler) GetWebhook(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		response.BadRequest(c, "invalid webhook id")
+		return
+	}
+
+	ctx, cancel := createContextWithTimeout(c, 5*time.Second)
+	defer cancel()
+
+	webhook, err := h.webhookService.GetWebhookByID(ctx, id)
+	if err != nil {
+		slog.Error("error getting webhook", "id", id, "error", err)
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	response.Success(c, webhook)
+}
+
+// CreateWebhook godoc
+//
+//	@Summary		注册 Webhook
+//	@Description	订阅一个或多个事件（"*" 表示全部），未提供 secret 时自动生成。每次投递都带有 X-Webhook-Signature 签名头
+//	@Tags			webhooks
+//	@Accept			json
+//	@Produce		json
+//	@Param			webhook	body		model.CreateWebhookRequest	true	"Webhook 信息"
+//	@Success		201		{object}	response.Response{data=model.Webhook}
+//	@Failure		400		{object}	response.Response
+//	@Router			/api/v1/webhooks [post]
+func (h *WebhookHandler) CreateWebhook(c *gin.Context) {
+	var req model.CreateWebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(c, "invalid request body: "+err.Error())
+		return
+	}
+
+	ctx, cancel := createContextWithTimeout(c, 5*time.Second)
+	defer cancel()
+
+	webhook, err := h.webhookService.CreateWebhook(ctx, &req)
+	if err != nil {
+		slog.Error("error creating webhook", "error", err)
+		response.BadRequest(c, err.Error())
+		return
+	}
+
+	response.Created(c, webhook)
+}
+
+// DeleteWebhook godoc
+//
+//	@Summary		删除 Webhook
+//	@Description	根据ID删除 Webhook
+//	@Tags			webhooks
+//	@Accept			json
+//	@Produce		json
+//	@Param			id	path		int	true	"Webhook ID"
+//	@Success		200	{object}	response.Response
+//	@Failure		400	{object}	response.Response
+//	@Failure		404	{object}	response.Response
+//	@Router			/api/v1/webhooks/{id} [delete]
+func (h *WebhookHandler) DeleteWebhook(c *gin.Context) {
+	idStr := c.Param("id")
+	id, err := strconv.Atoi(idStr)
+	if err != nil {
+		response.BadRequest(c, "invalid webhook id")
+		return
+	}
+
+	ctx, cancel := createContextWithTimeout(c, 5*time.Second)
+	defer cancel()
+
+	err = h.webhookService.DeleteWebhook(ctx, id)
+	if err != nil {
+		slog.Error("error deleting webhook", "id", id, "error", err)
+		response.NotFound(c, err.Error())
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// GetDeadLetters godoc
+//
+//	@Summary		获取死信列表
+//	@Description	获取重试耗尽后仍投递失败的事件
+//	@Tags			webhooks
+//	@Accept			json
+//	@Produce		json
+//	@Success		200	{object}	response.Response{data=[]model.DeadLetter}
+//	@Failure		500	{object}	response.Response
+//	@Router			/api/v1/webhooks/dead-letters [get]
+func (h *WebhookHandler) GetDeadLetters(c *gin.Context) {
+	ctx, cancel := createContextWithTimeout(c, 5*time.Second)
+	defer cancel()
+
+	deadLetters, err := h.webhookService.GetDeadLetters(ctx)
+	if err != nil {
+		slog.Error("error getting dead letters", "error", err)
+		response.InternalError(c, "failed to get dead letters: "+err.Error())
+		return
+	}
+
+	response.Success(c, deadLetters)
+}
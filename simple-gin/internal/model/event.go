@@ -0,0 +1,65 @@
+package model
+
+import "time"
+
+// 事件类型
+const (
+	EventUserCreated     = "user.created"
+	EventUserUpdated     = "user.updated"
+	EventUserDeleted     = "user.deleted"
+	EventProductCreated  = "product.created"
+	EventProductUpdated  = "product.updated"
+	EventProductDeleted  = "product.deleted"
+	EventProductStockLow = "product.stock_low"
+
+	// EventWildcard 订阅所有事件
+	EventWildcard = "*"
+)
+
+// EventTypes 所有可订阅的事件类型
+var EventTypes = []string{
+	EventUserCreated,
+	EventUserUpdated,
+	EventUserDeleted,
+	EventProductCreated,
+	EventProductUpdated,
+	EventProductDeleted,
+	EventProductStockLow,
+}
+
+// Event 领域事件
+type Event struct {
+	ID         string      `json:"id" example:"9f86d081884c7d65"`
+	Type       string      `json:"type" example:"product.created"`
+	OccurredAt time.Time   `json:"occurred_at"`
+	Data       interface{} `json:"data"`
+}
+
+// ProductDeletedData 产品删除事件数据
+// 序列化后只有 id，Webhook 的载荷与 user.deleted 一致；
+// Product 为删除前的快照，只供进程内的订阅者（实时库存推送）按分类过滤和展示
+type ProductDeletedData struct {
+	ID      int     `json:"id" example:"1"`
+	Product Product `json:"-"`
+}
+
+// StockLowData 库存不足事件数据
+type StockLowData struct {
+	ProductID int    `json:"product_id" example:"1"`
+	Name      string `json:"name" example:"iPhone 15"`
+	Stock     int    `json:"stock" example:"5"`
+	Threshold int    `json:"threshold" example:"10"`
+}
+
+// IsValidEventType 检查事件类型是否可订阅
+func IsValidEventType(eventType string) bool {
+	if eventType == EventWildcard {
+		return true
+	}
+	for _, t := range EventTypes {
+		if t == eventType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,49 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// StreamEventReset 请求的 Last-Event-ID 已超出缓冲区范围，客户端应重新拉取全量数据
+const StreamEventReset = "stream.reset"
+
+// InventoryEvent 实时库存推送事件
+type InventoryEvent struct {
+	ID         int64     `json:"id" example:"42"`
+	Type       string    `json:"type" example:"product.updated"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Product    *Product  `json:"product,omitempty"`
+}
+
+// StreamFilter 库存推送过滤条件，字段为空表示不过滤
+type StreamFilter struct {
+	ProductIDs []int
+	Category   string
+}
+
+// Match 检查事件是否满足过滤条件，重置事件总是推送
+func (f StreamFilter) Match(event *InventoryEvent) bool {
+	if event.Product == nil {
+		return true
+	}
+
+	if len(f.ProductIDs) > 0 {
+		matched := false
+		for _, id := range f.ProductIDs {
+			if id == event.Product.ID {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if f.Category != "" && !strings.EqualFold(f.Category, event.Product.Category) {
+		return false
+	}
+
+	return true
+}
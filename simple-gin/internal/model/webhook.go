@@ -0,0 +1,40 @@
+package model
+
+import "time"
+
+// Webhook Webhook 订阅模型
+type Webhook struct {
+	ID        int       `json:"id" example:"1"`
+	URL       string    `json:"url" example:"https://example.com/hooks/inventory"`
+	Events    []string  `json:"events" example:"product.created,product.stock_low"`
+	Secret    string    `json:"secret,omitempty" example:"3f7a1c9e5b2d4f6a8c0e1b3d5f7a9c2e"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// Subscribes 检查 Webhook 是否订阅了指定事件
+func (w *Webhook) Subscribes(eventType string) bool {
+	for _, e := range w.Events {
+		if e == EventWildcard || e == eventType {
+			return true
+		}
+	}
+	return false
+}
+
+// CreateWebhookRequest 创建 Webhook 请求体
+type CreateWebhookRequest struct {
+	URL    string   `json:"url" binding:"required,url" example:"https://example.com/hooks/inventory"`
+	Events []string `json:"events" binding:"required,min=1" example:"product.created,product.stock_low"`
+	Secret string   `json:"secret" example:"my-signing-secret"`
+}
+
+// DeadLetter 投递失败的 Webhook 记录
+type DeadLetter struct {
+	ID        int       `json:"id" example:"1"`
+	WebhookID int       `json:"webhook_id" example:"1"`
+	URL       string    `json:"url" example:"https://example.com/hooks/inventory"`
+	Event     Event     `json:"event"`
+	Attempts  int       `json:"attempts" example:"4"`
+	LastError string    `json:"last_error" example:"unexpected status code: 500"`
+	FailedAt  time.Time `json:"failed_at"`
+}
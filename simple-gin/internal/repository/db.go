@@ -4,84 +4,74 @@ import (
 	"example/simple-gin/internal/config"
 	"example/simple-gin/internal/model"
 	"example/simple-gin/internal/service"
+	"os"
 	"sync"
 	"time"
 )
 
-// 编译时验证 DB 实现了 service.Database 接口
-var _ service.Database = (*DB)(nil)
+// 编译时验证 DB 实现了 service.Database 和 service.WebhookStore 接口
+var (
+	_ service.Database     = (*DB)(nil)
+	_ service.WebhookStore = (*DB)(nil)
+)
 
 // DB 模拟数据库结构
 type DB struct {
-	users     map[int]*model.User
-	products  map[int]*model.Product
-	userID    int
-	productID int
-	mu        sync.RWMutex
+	users        map[int]*model.User
+	products     map[int]*model.Product
+	webhooks     map[int]*model.Webhook
+	deadLetters  []*model.DeadLetter
+	userID       int
+	productID    int
+	webhookID    int
+	deadLetterID int
+	dataFile     string      // 为空时只保存在内存中
+	fileInfo     os.FileInfo // 最近一次加载或写入的数据文件，用于发现其他进程的修改
+	mu           sync.RWMutex
 }
 
 var db *DB
 
 // Init 初始化数据库连接（模拟）
+// 配置了 database.data_file 且文件存在时从文件加载数据，否则加载 database.seed_file 种子数据，
+// seed_file 为空时使用内置的种子数据
 func Init(cfg *config.Config) (*DB, error) {
 	// 这里模拟数据库连接
 	// 实际项目中会连接真实数据库（PostgreSQL, MySQL等）
-	db = &DB{
-		users:     make(map[int]*model.User),
-		products:  make(map[int]*model.Product),
-		userID:    1,
-		productID: 1,
+	d := NewDB()
+
+	// 在数据文件锁内加载已有数据，没有数据文件时才写入种子数据，
+	// 避免与同时启动的进程各自写入一份
+	d.dataFile = cfg.DB.DataFile
+	if err := d.write(func() (bool, error) {
+		if d.fileInfo != nil {
+			return false, nil
+		}
+		fixture, err := LoadFixture(cfg.DB.SeedFile)
+		if err != nil {
+			return false, err
+		}
+		d.seed(fixture)
+		return true, nil
+	}); err != nil {
+		return nil, err
 	}
 
-	// 初始化一些模拟数据
-	db.seedData()
-
+	db = d
 	return db, nil
 }
 
-// seedData 初始化模拟数据
-func (d *DB) seedData() {
-	now := time.Now()
-
-	// 初始化用户数据
-	d.users[1] = &model.User{
-		ID:        1,
-		Name:      "张三",
-		Email:     "zhangsan@example.com",
-		Phone:     "13800138000",
-		CreatedAt: now,
-		UpdatedAt: now,
-	}
-
-	d.users[2] = &model.User{
-		ID:        2,
-		Name:      "李四",
-		Email:     "lisi@example.com",
-		Phone:     "13800138001",
-		CreatedAt: now,
-		UpdatedAt: now,
-	}
-
-	d.userID = 3
-
-	// 初始化产品数据
-	d.products[1] = &model.Product{
-		ID:       1,
-		Name:     "iPhone 15",
-		Price:    5999,
-		Stock:    50,
-		Category: "Electronics",
+// NewDB 创建一个空的内存数据库（不含模拟数据），主要用于测试
+func NewDB() *DB {
+	return &DB{
+		users:        make(map[int]*model.User),
+		products:     make(map[int]*model.Product),
+		webhooks:     make(map[int]*model.Webhook),
+		userID:       1,
+		productID:    1,
+		webhookID:    1,
+		deadLetterID: 1,
 	}
-
-	d.products[2] = &model.Product{
-		ID:       2,
-		Name:     "MacBook Pro",
-		Price:    12999,
-		Stock:    30,
-		Category: "Electronics",
-	}
-
-	d.productID = 3
 }
 
 // GetDB 获取数据库实例
@@ -90,31 +80,44 @@ func GetDB() *DB {
 }
 
 // ======== User Operations ========
+// 所有方法都返回副本，调用方修改返回值不会影响存储的数据，也不会与其他请求产生数据竞争
 
 // GetUser 获取单个用户
 func (d *DB) GetUser(id int) *model.User {
+	d.refresh()
 	d.mu.RLock()
 	defer d.mu.RUnlock()
-	return d.users[id]
+	return copyUser(d.users[id])
 }
 
 // GetAllUsers 获取所有用户
 func (d *DB) GetAllUsers() []*model.User {
+	d.refresh()
 	d.mu.RLock()
 	defer d.mu.RUnlock()
 
 	var users []*model.User
 	for _, user := range d.users {
-		users = append(users, user)
+		users = append(users, copyUser(user))
 	}
 	return users
 }
 
 // CreateUser 创建用户
-func (d *DB) CreateUser(req *model.CreateUserRequest) *model.User {
-	d.mu.Lock()
-	defer d.mu.Unlock()
+func (d *DB) CreateUser(req *model.CreateUserRequest) (*model.User, error) {
+	var user *model.User
+	err := d.write(func() (bool, error) {
+		user = copyUser(d.insertUser(req))
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return user, nil
+}
 
+// insertUser 写入新用户，调用方需持有写锁
+func (d *DB) insertUser(req *model.CreateUserRequest) *model.User {
 	user := &model.User{
 		ID:        d.userID,
 		Name:      req.Name,
@@ -130,10 +133,20 @@ func (d *DB) CreateUser(req *model.CreateUserRequest) *model.User {
 }
 
 // UpdateUser 更新用户
-func (d *DB) UpdateUser(id int, req *model.UpdateUserRequest) *model.User {
-	d.mu.Lock()
-	defer d.mu.Unlock()
+func (d *DB) UpdateUser(id int, req *model.UpdateUserRequest) (*model.User, error) {
+	var updated *model.User
+	err := d.write(func() (bool, error) {
+		updated = copyUser(d.updateUser(id, req))
+		return updated != nil, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
 
+// updateUser 修改已有用户，不存在时返回 nil，调用方需持有写锁
+func (d *DB) updateUser(id int, req *model.UpdateUserRequest) *model.User {
 	user, exists := d.users[id]
 	if !exists {
 		return nil
@@ -149,48 +162,63 @@ func (d *DB) UpdateUser(id int, req *model.UpdateUserRequest) *model.User {
 		user.Phone = req.Phone
 	}
 	user.UpdatedAt = time.Now()
-
 	return user
 }
 
 // DeleteUser 删除用户
-func (d *DB) DeleteUser(id int) bool {
-	d.mu.Lock()
-	defer d.mu.Unlock()
-
-	if _, exists := d.users[id]; exists {
-		delete(d.users, id)
-		return true
+func (d *DB) DeleteUser(id int) (bool, error) {
+	var deleted bool
+	err := d.write(func() (bool, error) {
+		if _, deleted = d.users[id]; deleted {
+			delete(d.users, id)
+		}
+		return deleted, nil
+	})
+	if err != nil {
+		return false, err
 	}
-	return false
+	return deleted, nil
 }
 
 // ======== Product Operations ========
+// 与用户相同，所有方法都返回副本
 
 // GetProduct 获取单个产品
 func (d *DB) GetProduct(id int) *model.Product {
+	d.refresh()
 	d.mu.RLock()
 	defer d.mu.RUnlock()
-	return d.products[id]
+	return copyProduct(d.products[id])
 }
 
 // GetAllProducts 获取所有产品
 func (d *DB) GetAllProducts() []*model.Product {
+	d.refresh()
 	d.mu.RLock()
 	defer d.mu.RUnlock()
 
 	var products []*model.Product
 	for _, product := range d.products {
-		products = append(products, product)
+		products = append(products, copyProduct(product))
 	}
 	return products
 }
 
 // CreateProduct 创建产品
-func (d *DB) CreateProduct(req *model.CreateProductRequest) *model.Product {
-	d.mu.Lock()
-	defer d.mu.Unlock()
+func (d *DB) CreateProduct(req *model.CreateProductRequest) (*model.Product, error) {
+	var product *model.Product
+	err := d.write(func() (bool, error) {
+		product = copyProduct(d.insertProduct(req))
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return product, nil
+}
 
+// insertProduct 写入新产品，调用方需持有写锁
+func (d *DB) insertProduct(req *model.CreateProductRequest) *model.Product {
 	product := &model.Product{
 		ID:       d.productID,
 		Name:     req.Name,
@@ -205,10 +233,20 @@ func (d *DB) CreateProduct(req *model.CreateProductRequest) *model.Product {
 }
 
 // UpdateProduct 更新产品
-func (d *DB) UpdateProduct(id int, req *model.UpdateProductRequest) *model.Product {
-	d.mu.Lock()
-	defer d.mu.Unlock()
+func (d *DB) UpdateProduct(id int, req *model.UpdateProductRequest) (*model.Product, error) {
+	var updated *model.Product
+	err := d.write(func() (bool, error) {
+		updated = copyProduct(d.updateProduct(id, req))
+		return updated != nil, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return updated, nil
+}
 
+// updateProduct 修改已有产品，不存在时返回 nil，调用方需持有写锁
+func (d *DB) updateProduct(id int, req *model.UpdateProductRequest) *model.Product {
 	product, exists := d.products[id]
 	if !exists {
 		return nil
@@ -226,18 +264,61 @@ func (d *DB) UpdateProduct(id int, req *model.UpdateProductRequest) *model.Produ
 	if req.Category != "" {
 		product.Category = req.Category
 	}
-
 	return product
 }
 
 // DeleteProduct 删除产品
-func (d *DB) DeleteProduct(id int) bool {
-	d.mu.Lock()
-	defer d.mu.Unlock()
+func (d *DB) DeleteProduct(id int) (bool, error) {
+	var deleted bool
+	err := d.write(func() (bool, error) {
+		if _, deleted = d.products[id]; deleted {
+			delete(d.products, id)
+		}
+		return deleted, nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return deleted, nil
+}
 
-	if _, exists := d.products[id]; exists {
-		delete(d.products, id)
-		return true
+// ReduceStock 原子地减少产品库存，返回减少后的产品
+// 检查库存和扣减在同一把锁内完成，并发扣减不会让库存变为负数
+func (d *DB) ReduceStock(id, quantity int) (*model.Product, error) {
+	var reduced *model.Product
+	err := d.write(func() (bool, error) {
+		product, exists := d.products[id]
+		if !exists {
+			return false, service.ErrProductNotFound
+		}
+		if product.Stock < quantity {
+			return false, service.ErrInsufficientStock
+		}
+
+		product.Stock -= quantity
+		reduced = copyProduct(product)
+		return true, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return reduced, nil
+}
+
+// copyUser 返回用户副本，nil 返回 nil
+func copyUser(user *model.User) *model.User {
+	if user == nil {
+		return nil
+	}
+	c := *user
+	return &c
+}
+
+// copyProduct 返回产品副本，nil 返回 nil
+func copyProduct(product *model.Product) *model.Product {
+	if product == nil {
+		return nil
 	}
-	return false
+	c := *product
+	return &c
 }
@@ -0,0 +1,8 @@
+//go:build !unix
+
+package repository
+
+// lockFile 其他平台不支持 flock，不加锁：服务运行期间用 simple-gin-admin 修改数据可能丢失
+func lockFile(path string) (func(), error) {
+	return func() {}, nil
+}
@@ -0,0 +1,247 @@
+package repository
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"example/simple-gin/internal/model"
+	"example/simple-gin/internal/service"
+)
+
+// dataFileVersion 当前数据文件格式版本
+const dataFileVersion = 1
+
+// ErrDataFileOutdated 数据文件版本过旧，需要先执行 simple-gin-admin db migrate
+var ErrDataFileOutdated = errors.New("data file is outdated, run `simple-gin-admin db migrate`")
+
+// dataFile 数据文件内容（JSON）
+// 只持久化用户和产品，Webhook 和死信记录仍然只保存在内存中
+type dataFile struct {
+	Version       int              `json:"version"`
+	NextUserID    int              `json:"next_user_id"`
+	NextProductID int              `json:"next_product_id"`
+	Users         []*model.User    `json:"users"`
+	Products      []*model.Product `json:"products"`
+}
+
+// migrations 数据文件迁移，migrations[v] 把版本 v 升级到 v+1
+var migrations = map[int]func(f *dataFile){
+	// 版本 0：手写或旧版导出的文件，只有 users 和 products，需要补上下一个 ID
+	0: func(f *dataFile) {
+		f.NextUserID, f.NextProductID = 1, 1
+		for _, u := range f.Users {
+			f.NextUserID = max(f.NextUserID, u.ID+1)
+		}
+		for _, p := range f.Products {
+			f.NextProductID = max(f.NextProductID, p.ID+1)
+		}
+	},
+}
+
+// MigrateDataFile 把数据文件升级到当前版本
+// 文件不存在时创建一个空的数据文件，返回迁移前后的版本
+// 与服务和其他命令的写入使用同一个数据文件锁
+func MigrateDataFile(path string) (from, to int, err error) {
+	unlock, err := lockFile(path + ".lock")
+	if err != nil {
+		return 0, 0, err
+	}
+	defer unlock()
+
+	f, err := readDataFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		f = &dataFile{Version: dataFileVersion, NextUserID: 1, NextProductID: 1}
+		return 0, dataFileVersion, writeDataFile(path, f)
+	}
+	if err != nil {
+		return 0, 0, err
+	}
+
+	from = f.Version
+	if from > dataFileVersion {
+		return from, from, fmt.Errorf("data file version %d is newer than supported version %d", from, dataFileVersion)
+	}
+	for f.Version < dataFileVersion {
+		migrations[f.Version](f)
+		f.Version++
+	}
+	if from == f.Version {
+		return from, f.Version, nil
+	}
+
+	slog.Info("data file migrated", "path", path, "from", from, "to", f.Version)
+	return from, f.Version, writeDataFile(path, f)
+}
+
+// write 执行一次修改并写入数据文件，fn 返回是否有修改
+//
+// 服务和 simple-gin-admin 可能同时使用同一个数据文件：修改在数据文件锁（<data_file>.lock）内进行，
+// 修改前先重新加载其他进程写入的数据，保证不会覆盖它们的修改。
+// 写入失败时返回包装了 service.ErrPersist 的错误，内存中的修改在下次读写时被数据文件的内容替换
+func (d *DB) write(fn func() (bool, error)) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.dataFile == "" {
+		_, err := fn()
+		return err
+	}
+
+	unlock, err := lockFile(d.dataFile + ".lock")
+	if err != nil {
+		return fmt.Errorf("%w: %w", service.ErrPersist, err)
+	}
+	defer unlock()
+
+	if err := d.reload(); err != nil {
+		return fmt.Errorf("%w: %w", service.ErrPersist, err)
+	}
+	changed, err := fn()
+	if err != nil || !changed {
+		return err
+	}
+	if err := d.persist(); err != nil {
+		d.fileInfo = nil
+		return fmt.Errorf("%w: %w", service.ErrPersist, err)
+	}
+	return nil
+}
+
+// refresh 数据文件被其他进程替换后重新加载，读操作前调用，
+// 服务运行期间 simple-gin-admin 写入的修改可以立即读到
+func (d *DB) refresh() {
+	if d.dataFile == "" {
+		return
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if err := d.reload(); err != nil {
+		slog.Error("error reloading data file", "path", d.dataFile, "error", err)
+	}
+}
+
+// reload 数据文件在最近一次加载或写入之后被替换时重新加载，调用方需持有写锁
+// 数据文件总是整体替换（writeDataFile），通过文件是否仍是同一个判断是否被修改；文件不存在时保留内存中的数据
+func (d *DB) reload() error {
+	info, err := os.Stat(d.dataFile)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if d.fileInfo != nil && os.SameFile(d.fileInfo, info) {
+		return nil
+	}
+
+	file, err := os.Open(d.dataFile)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+	if info, err = file.Stat(); err != nil {
+		return err
+	}
+	f, err := decodeDataFile(file, d.dataFile)
+	if err != nil {
+		return err
+	}
+	if f.Version != dataFileVersion {
+		return fmt.Errorf("%s (version %d, want %d): %w", d.dataFile, f.Version, dataFileVersion, ErrDataFileOutdated)
+	}
+
+	d.users = make(map[int]*model.User, len(f.Users))
+	for _, u := range f.Users {
+		d.users[u.ID] = u
+	}
+	d.products = make(map[int]*model.Product, len(f.Products))
+	for _, p := range f.Products {
+		d.products[p.ID] = p
+	}
+	d.userID = f.NextUserID
+	d.productID = f.NextProductID
+	d.fileInfo = info
+	return nil
+}
+
+// persist 把当前数据写入数据文件，调用方需持有写锁和数据文件锁
+func (d *DB) persist() error {
+	f := &dataFile{
+		Version:       dataFileVersion,
+		NextUserID:    d.userID,
+		NextProductID: d.productID,
+		Users:         make([]*model.User, 0, len(d.users)),
+		Products:      make([]*model.Product, 0, len(d.products)),
+	}
+	for _, u := range d.users {
+		f.Users = append(f.Users, u)
+	}
+	for _, p := range d.products {
+		f.Products = append(f.Products, p)
+	}
+	// 按 ID 排序，让文件内容稳定，便于比较
+	sort.Slice(f.Users, func(i, j int) bool { return f.Users[i].ID < f.Users[j].ID })
+	sort.Slice(f.Products, func(i, j int) bool { return f.Products[i].ID < f.Products[j].ID })
+
+	if err := writeDataFile(d.dataFile, f); err != nil {
+		return err
+	}
+	info, err := os.Stat(d.dataFile)
+	if err != nil {
+		return err
+	}
+	d.fileInfo = info
+	return nil
+}
+
+// readDataFile 读取数据文件
+func readDataFile(path string) (*dataFile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return decodeDataFile(file, path)
+}
+
+// decodeDataFile 解析数据文件内容，path 用于错误信息
+func decodeDataFile(r io.Reader, path string) (*dataFile, error) {
+	var f dataFile
+	if err := json.NewDecoder(r).Decode(&f); err != nil {
+		return nil, fmt.Errorf("parse data file %s: %w", path, err)
+	}
+	return &f, nil
+}
+
+// writeDataFile 先写临时文件再重命名，避免写到一半时进程退出导致文件损坏
+func writeDataFile(path string, f *dataFile) error {
+	data, err := json.MarshalIndent(f, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return err
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -0,0 +1,87 @@
+package repository
+
+import (
+	"fmt"
+	"os"
+
+	"example/simple-gin/configs"
+	"example/simple-gin/internal/model"
+
+	"go.yaml.in/yaml/v3"
+)
+
+// Fixture 种子数据
+type Fixture struct {
+	Users    []FixtureUser    `yaml:"users"`
+	Products []FixtureProduct `yaml:"products"`
+}
+
+// FixtureUser 种子用户
+type FixtureUser struct {
+	Name  string `yaml:"name"`
+	Email string `yaml:"email"`
+	Phone string `yaml:"phone"`
+}
+
+// FixtureProduct 种子产品
+type FixtureProduct struct {
+	Name     string  `yaml:"name"`
+	Price    float64 `yaml:"price"`
+	Stock    int     `yaml:"stock"`
+	Category string  `yaml:"category"`
+}
+
+// BuiltinFixtureName 内置种子数据在日志和提示信息中的名称
+const BuiltinFixtureName = "built-in seed"
+
+// LoadFixture 从 YAML 文件加载种子数据，path 为空时使用内置的种子数据
+// 显式指定的文件不存在时返回错误
+func LoadFixture(path string) (*Fixture, error) {
+	if path == "" {
+		return parseFixture(configs.Seed, BuiltinFixtureName)
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read seed file: %w", err)
+	}
+	return parseFixture(data, path)
+}
+
+// parseFixture 解析 YAML 种子数据，name 用于错误信息
+func parseFixture(data []byte, name string) (*Fixture, error) {
+	var fixture Fixture
+	if err := yaml.Unmarshal(data, &fixture); err != nil {
+		return nil, fmt.Errorf("parse seed file %s: %w", name, err)
+	}
+	return &fixture, nil
+}
+
+// Seed 写入种子数据，已有数据保留，新数据使用新的 ID
+func (d *DB) Seed(fixture *Fixture) error {
+	return d.write(func() (bool, error) {
+		d.seed(fixture)
+		return true, nil
+	})
+}
+
+// seed 写入种子数据，调用方需持有写锁
+func (d *DB) seed(fixture *Fixture) {
+	for _, u := range fixture.Users {
+		d.insertUser(&model.CreateUserRequest{Name: u.Name, Email: u.Email, Phone: u.Phone})
+	}
+	for _, p := range fixture.Products {
+		d.insertProduct(&model.CreateProductRequest{Name: p.Name, Price: p.Price, Stock: p.Stock, Category: p.Category})
+	}
+}
+
+// Reset 清空所有用户和产品，ID 从 1 重新开始
+func (d *DB) Reset() error {
+	return d.write(func() (bool, error) {
+		d.users = make(map[int]*model.User)
+		d.products = make(map[int]*model.Product)
+		d.userID = 1
+		d.productID = 1
+		return true, nil
+	})
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"time"
+
+	"example/simple-gin/internal/model"
+)
+
+// ======== Webhook Operations ========
+
+// GetWebhook 获取单个 Webhook
+func (d *DB) GetWebhook(id int) *model.Webhook {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.webhooks[id]
+}
+
+// GetAllWebhooks 获取所有 Webhook
+func (d *DB) GetAllWebhooks() []*model.Webhook {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	var webhooks []*model.Webhook
+	for _, webhook := range d.webhooks {
+		webhooks = append(webhooks, webhook)
+	}
+	return webhooks
+}
+
+// CreateWebhook 创建 Webhook
+func (d *DB) CreateWebhook(req *model.CreateWebhookRequest, secret string) *model.Webhook {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	webhook := &model.Webhook{
+		ID:        d.webhookID,
+		URL:       req.URL,
+		Events:    append([]string(nil), req.Events...),
+		Secret:    secret,
+		CreatedAt: time.Now(),
+	}
+
+	d.webhooks[d.webhookID] = webhook
+	d.webhookID++
+	return webhook
+}
+
+// DeleteWebhook 删除 Webhook
+func (d *DB) DeleteWebhook(id int) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if _, exists := d.webhooks[id]; exists {
+		delete(d.webhooks, id)
+		return true
+	}
+	return false
+}
+
+// AddDeadLetter 记录投递失败的事件
+func (d *DB) AddDeadLetter(deadLetter *model.DeadLetter) *model.DeadLetter {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	deadLetter.ID = d.deadLetterID
+	d.deadLetters = append(d.deadLetters, deadLetter)
+	d.deadLetterID++
+	return deadLetter
+}
+
+// GetAllDeadLetters 获取所有死信记录（按失败时间先后排列）
+func (d *DB) GetAllDeadLetters() []*model.DeadLetter {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+
+	deadLetters := make([]*model.DeadLetter, len(d.deadLetters))
+	copy(deadLetters, d.deadLetters)
+	return deadLetters
+}
@@ -1,6 +1,8 @@
 package router
 
 import (
+	"time"
+
 	"example/simple-gin/internal/handler"
 	"example/simple-gin/internal/middleware"
 	"example/simple-gin/internal/service"
@@ -15,11 +17,12 @@ import (
 
 // RouterConfig 路由配置选项
 type RouterConfig struct {
-	EnableSwagger bool
+	EnableSwagger   bool
+	StreamHeartbeat time.Duration // 实时推送心跳间隔，0 表示使用默认值
 }
 
 // SetupRoutes 设置所有路由
-func SetupRoutes(router *gin.Engine, userService service.UserService, productService service.ProductService, cfg *RouterConfig) {
+func SetupRoutes(router *gin.Engine, userService service.UserService, productService service.ProductService, webhookService service.WebhookService, inventoryStream service.InventoryStream, cfg *RouterConfig) {
 	// 应用中间件
 	router.Use(middleware.LoggingMiddleware())
 	router.Use(middleware.RecoveryMiddleware())
@@ -39,6 +42,13 @@ func SetupRoutes(router *gin.Engine, userService service.UserService, productSer
 	// 创建处理器实例
 	userHandler := handler.NewUserHandler(userService)
 	productHandler := handler.NewProductHandler(productService)
+	webhookHandler := handler.NewWebhookHandler(webhookService)
+
+	var streamHeartbeat time.Duration
+	if cfg != nil {
+		streamHeartbeat = cfg.StreamHeartbeat
+	}
+	streamHandler := handler.NewStreamHandler(inventoryStream, streamHeartbeat)
 
 	// API v1 路由组
 	v1 := router.Group("/api/v1")
@@ -58,10 +68,22 @@ func SetupRoutes(router *gin.Engine, userService service.UserService, productSer
 		{
 			products.GET("", productHandler.GetProducts)
 			products.POST("", productHandler.CreateProduct)
+			products.GET("/stream", streamHandler.StreamProducts)
+			products.GET("/ws", streamHandler.StreamProductsWebSocket)
 			products.GET("/:id", productHandler.GetProduct)
 			products.PUT("/:id", productHandler.UpdateProduct)
 			products.DELETE("/:id", productHandler.DeleteProduct)
 			products.POST("/:id/reduce-stock", productHandler.ReduceStock)
 		}
+
+		// Webhook 相关路由
+		webhooks := v1.Group("/webhooks")
+		{
+			webhooks.GET("", webhookHandler.GetWebhooks)
+			webhooks.POST("", webhookHandler.CreateWebhook)
+			webhooks.GET("/dead-letters", webhookHandler.GetDeadLetters)
+			webhooks.GET("/:id", webhookHandler.GetWebhook)
+			webhooks.DELETE("/:id", webhookHandler.DeleteWebhook)
+		}
 	}
 }
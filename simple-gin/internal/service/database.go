@@ -1,21 +1,49 @@
 package service
 
-import "example/simple-gin/internal/model"
+import (
+	"errors"
+
+	"example/simple-gin/internal/model"
+)
+
+// Database 实现返回的错误
+var (
+	ErrProductNotFound   = errors.New("product not found")
+	ErrInsufficientStock = errors.New("insufficient stock")
+	// ErrPersist 修改没有写入存储，调用方应当视为失败
+	ErrPersist = errors.New("failed to persist data")
+)
 
 // Database 数据库接口定义
 // Service层通过这个接口与Database层交互，实现依赖倒置
+// 写操作在修改没有写入存储时返回包装了 ErrPersist 的错误；
+// 更新、删除的记录不存在时返回 nil / false，不返回错误
 type Database interface {
 	// User operations
 	GetUser(id int) *model.User
 	GetAllUsers() []*model.User
-	CreateUser(req *model.CreateUserRequest) *model.User
-	UpdateUser(id int, req *model.UpdateUserRequest) *model.User
-	DeleteUser(id int) bool
+	CreateUser(req *model.CreateUserRequest) (*model.User, error)
+	UpdateUser(id int, req *model.UpdateUserRequest) (*model.User, error)
+	DeleteUser(id int) (bool, error)
 
 	// Product operations
 	GetProduct(id int) *model.Product
 	GetAllProducts() []*model.Product
-	CreateProduct(req *model.CreateProductRequest) *model.Product
-	UpdateProduct(id int, req *model.UpdateProductRequest) *model.Product
-	DeleteProduct(id int) bool
+	CreateProduct(req *model.CreateProductRequest) (*model.Product, error)
+	UpdateProduct(id int, req *model.UpdateProductRequest) (*model.Product, error)
+	DeleteProduct(id int) (bool, error)
+	// ReduceStock 原子地检查并减少库存，返回减少后的产品
+	// 产品不存在返回 ErrProductNotFound，库存不足返回 ErrInsufficientStock
+	ReduceStock(id, quantity int) (*model.Product, error)
+}
+
+// WebhookStore Webhook 订阅与死信存储接口
+type WebhookStore interface {
+	GetWebhook(id int) *model.Webhook
+	GetAllWebhooks() []*model.Webhook
+	CreateWebhook(req *model.CreateWebhookRequest, secret string) *model.Webhook
+	DeleteWebhook(id int) bool
+
+	AddDeadLetter(deadLetter *model.DeadLetter) *model.DeadLetter
+	GetAllDeadLetters() []*model.DeadLetter
 }
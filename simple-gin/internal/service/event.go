@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"example/simple-gin/internal/model"
+	"example/simple-gin/pkg/utils"
+)
+
+// EventPublisher 事件发布接口
+// Service 层在数据变更后通过这个接口发布事件，不关心事件最终被谁消费
+type EventPublisher interface {
+	Publish(ctx context.Context, event *model.Event)
+}
+
+// NewEvent 创建事件
+func NewEvent(eventType string, data interface{}) *model.Event {
+	return &model.Event{
+		ID:         utils.GenerateID(16),
+		Type:       eventType,
+		OccurredAt: time.Now(),
+		Data:       data,
+	}
+}
+
+// multiPublisher 把事件依次交给多个发布器
+type multiPublisher []EventPublisher
+
+// NewMultiPublisher 组合多个发布器，忽略其中的 nil
+func NewMultiPublisher(publishers ...EventPublisher) EventPublisher {
+	var m multiPublisher
+	for _, p := range publishers {
+		if p != nil {
+			m = append(m, p)
+		}
+	}
+	return m
+}
+
+func (m multiPublisher) Publish(ctx context.Context, event *model.Event) {
+	for _, p := range m {
+		p.Publish(ctx, event)
+	}
+}
+
+// noopPublisher 不做任何事的发布器，未配置事件消费者时使用
+type noopPublisher struct{}
+
+func (noopPublisher) Publish(context.Context, *model.Event) {}
+
+// orNoopPublisher 保证 Service 持有的发布器永远不为 nil
+func orNoopPublisher(p EventPublisher) EventPublisher {
+	if p == nil {
+		return noopPublisher{}
+	}
+	return p
+}
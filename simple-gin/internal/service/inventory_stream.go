@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"example/simple-gin/internal/model"
+)
+
+// ErrStreamClosed 推送服务已关闭
+var ErrStreamClosed = errors.New("inventory stream closed")
+
+// InventoryStream 实时库存推送服务接口定义
+// 同时实现 EventPublisher，把产品事件转换为库存事件广播给所有订阅者
+type InventoryStream interface {
+	EventPublisher
+
+	// Subscribe 订阅库存事件
+	// lastEventID > 0 时先返回缓冲区中 ID 更大的事件，用于断线续传
+	Subscribe(filter model.StreamFilter, lastEventID int64) (*StreamSubscription, []*model.InventoryEvent, error)
+	// Close 关闭推送服务，断开所有订阅者
+	Close()
+}
+
+// StreamSubscription 一个库存事件订阅
+// 消费过慢导致缓冲区写满时订阅会被服务端关闭，客户端可携带 Last-Event-ID 重连续传
+type StreamSubscription struct {
+	events chan *model.InventoryEvent
+	filter model.StreamFilter
+	stream *inventoryStream
+	once   sync.Once
+}
+
+// Events 返回事件通道，订阅关闭后通道被关闭
+func (s *StreamSubscription) Events() <-chan *model.InventoryEvent {
+	return s.events
+}
+
+// Close 取消订阅
+func (s *StreamSubscription) Close() {
+	s.stream.unsubscribe(s)
+}
+
+// closeChannel 关闭事件通道（只执行一次）
+func (s *StreamSubscription) closeChannel() {
+	s.once.Do(func() {
+		close(s.events)
+	})
+}
+
+// inventoryStream 实时库存推送服务实现
+type inventoryStream struct {
+	mu          sync.Mutex
+	buffer      []*model.InventoryEvent // 最近的事件，按 ID 递增
+	bufferSize  int
+	nextID      int64
+	subscribers map[*StreamSubscription]struct{}
+	closed      bool
+}
+
+// subscriberBuffer 每个订阅者的待发送事件数
+const subscriberBuffer = 64
+
+// NewInventoryStream 创建实时库存推送服务
+// bufferSize 为保留用于续传的最近事件数，<= 0 时使用默认值 1000
+func NewInventoryStream(bufferSize int) InventoryStream {
+	if bufferSize <= 0 {
+		bufferSize = 1000
+	}
+	return &inventoryStream{
+		buffer:      make([]*model.InventoryEvent, 0, bufferSize),
+		bufferSize:  bufferSize,
+		nextID:      1,
+		subscribers: make(map[*StreamSubscription]struct{}),
+	}
+}
+
+// Publish 只处理携带产品数据的产品事件，其余事件忽略；删除事件推送删除前的快照
+func (s *inventoryStream) Publish(ctx context.Context, event *model.Event) {
+	if event == nil {
+		return
+	}
+
+	switch event.Type {
+	case model.EventProductCreated, model.EventProductUpdated, model.EventProductDeleted:
+	default:
+		return
+	}
+
+	var product model.Product
+	switch data := event.Data.(type) {
+	case model.Product:
+		product = data
+	case model.ProductDeletedData:
+		product = data.Product
+	default:
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return
+	}
+
+	inventoryEvent := &model.InventoryEvent{
+		ID:         s.nextID,
+		Type:       event.Type,
+		OccurredAt: event.OccurredAt,
+		Product:    &product,
+	}
+	s.nextID++
+
+	if len(s.buffer) == s.bufferSize {
+		// 丢弃最旧的事件，复用底层数组避免无限增长
+		copy(s.buffer, s.buffer[1:])
+		s.buffer = s.buffer[:len(s.buffer)-1]
+	}
+	s.buffer = append(s.buffer, inventoryEvent)
+
+	for sub := range s.subscribers {
+		if !sub.filter.Match(inventoryEvent) {
+			continue
+		}
+		select {
+		case sub.events <- inventoryEvent:
+		default:
+			slog.Warn("stream subscriber too slow, disconnecting", "event_id", inventoryEvent.ID)
+			delete(s.subscribers, sub)
+			sub.closeChannel()
+		}
+	}
+}
+
+// Subscribe 实现订阅库存事件
+func (s *inventoryStream) Subscribe(filter model.StreamFilter, lastEventID int64) (*StreamSubscription, []*model.InventoryEvent, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return nil, nil, ErrStreamClosed
+	}
+
+	sub := &StreamSubscription{
+		events: make(chan *model.InventoryEvent, subscriberBuffer),
+		filter: filter,
+		stream: s,
+	}
+	s.subscribers[sub] = struct{}{}
+
+	// 在同一把锁内计算补发事件，保证补发与实时推送之间没有遗漏
+	replay := make([]*model.InventoryEvent, 0)
+	if lastEventID > 0 && lastEventID < s.nextID-1 {
+		oldest := s.nextID
+		if len(s.buffer) > 0 {
+			oldest = s.buffer[0].ID
+		}
+		if lastEventID+1 < oldest {
+			replay = append(replay, &model.InventoryEvent{
+				ID:         oldest - 1,
+				Type:       model.StreamEventReset,
+				OccurredAt: time.Now(),
+			})
+		}
+		for _, event := range s.buffer {
+			if event.ID > lastEventID && filter.Match(event) {
+				replay = append(replay, event)
+			}
+		}
+	}
+
+	slog.Debug("stream subscribed", "last_event_id", lastEventID, "replay", len(replay), "subscribers", len(s.subscribers))
+	return sub, replay, nil
+}
+
+// unsubscribe 移除订阅
+func (s *inventoryStream) unsubscribe(sub *StreamSubscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.subscribers, sub)
+	sub.closeChannel()
+}
+
+// Close 实现关闭推送服务
+func (s *inventoryStream) Close() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.closed {
+		return
+	}
+	s.closed = true
+	for sub := range s.subscribers {
+		sub.closeChannel()
+	}
+	s.subscribers = make(map[*StreamSubscription]struct{})
+}
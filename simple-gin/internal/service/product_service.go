@@ -27,13 +27,19 @@ type ProductService interface {
 
 // productService 产品服务实现
 type productService struct {
-	db Database
+	db                Database
+	events            EventPublisher
+	stockLowThreshold int
 }
 
 // NewProductService 创建产品服务实例
-func NewProductService(db Database) ProductService {
+// events 可以为 nil，此时不发布任何事件
+// 库存从阈值及以上降到阈值以下时发布 product.stock_low 事件，阈值 <= 0 表示不检查
+func NewProductService(db Database, events EventPublisher, stockLowThreshold int) ProductService {
 	return &productService{
-		db: db,
+		db:                db,
+		events:            orNoopPublisher(events),
+		stockLowThreshold: stockLowThreshold,
 	}
 }
 
@@ -110,7 +116,12 @@ func (s *productService) CreateProduct(ctx context.Context, req *model.CreatePro
 	}
 
 	slog.Info("creating product", "name", req.Name)
-	product := s.db.CreateProduct(req)
+	product, err := s.db.CreateProduct(req)
+	if err != nil {
+		return nil, err
+	}
+	s.events.Publish(ctx, NewEvent(model.EventProductCreated, *product))
+	s.checkStockLow(ctx, product, -1)
 
 	return product, nil
 }
@@ -140,7 +151,16 @@ func (s *productService) UpdateProduct(ctx context.Context, id int, req *model.U
 	}
 
 	slog.Info("updating product", "id", id)
-	product := s.db.UpdateProduct(id, req)
+	oldStock := existingProduct.Stock
+	product, err := s.db.UpdateProduct(id, req)
+	if err != nil {
+		return nil, err
+	}
+	if product == nil {
+		return nil, errors.New("product not found")
+	}
+	s.events.Publish(ctx, NewEvent(model.EventProductUpdated, *product))
+	s.checkStockLow(ctx, product, oldStock)
 
 	return product, nil
 }
@@ -158,10 +178,22 @@ func (s *productService) DeleteProduct(ctx context.Context, id int) error {
 		return errors.New("invalid product id")
 	}
 
+	// 删除前保留快照：Webhook 只收到 id，实时库存推送需要分类等完整信息
+	product := s.db.GetProduct(id)
+	if product == nil {
+		return errors.New("product not found")
+	}
+	snapshot := *product
+
 	slog.Info("deleting product", "id", id)
-	if !s.db.DeleteProduct(id) {
+	deleted, err := s.db.DeleteProduct(id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
 		return errors.New("product not found")
 	}
+	s.events.Publish(ctx, NewEvent(model.EventProductDeleted, model.ProductDeletedData{ID: id, Product: snapshot}))
 
 	return nil
 }
@@ -183,17 +215,34 @@ func (s *productService) ReduceStock(ctx context.Context, id, quantity int) erro
 		return errors.New("quantity must be greater than 0")
 	}
 
-	product := s.db.GetProduct(id)
-	if product == nil {
-		return errors.New("product not found")
-	}
-
-	if product.Stock < quantity {
-		return errors.New("insufficient stock")
+	slog.Info("reducing stock", "id", id, "quantity", quantity)
+	product, err := s.db.ReduceStock(id, quantity)
+	if err != nil {
+		return err
 	}
 
-	slog.Info("reducing stock", "id", id, "quantity", quantity)
-	product.Stock -= quantity
+	oldStock := product.Stock + quantity
+	s.events.Publish(ctx, NewEvent(model.EventProductUpdated, *product))
+	s.checkStockLow(ctx, product, oldStock)
 
 	return nil
 }
+
+// checkStockLow 库存从阈值及以上跌破阈值时发布 product.stock_low 事件
+// oldStock < 0 表示新建产品，此时只要低于阈值就发布
+func (s *productService) checkStockLow(ctx context.Context, product *model.Product, oldStock int) {
+	if s.stockLowThreshold <= 0 || product.Stock >= s.stockLowThreshold {
+		return
+	}
+	if oldStock >= 0 && oldStock < s.stockLowThreshold {
+		return // 已经处于低库存状态，避免重复通知
+	}
+
+	slog.Warn("product stock low", "id", product.ID, "stock", product.Stock, "threshold", s.stockLowThreshold)
+	s.events.Publish(ctx, NewEvent(model.EventProductStockLow, model.StockLowData{
+		ProductID: product.ID,
+		Name:      product.Name,
+		Stock:     product.Stock,
+		Threshold: s.stockLowThreshold,
+	}))
+}
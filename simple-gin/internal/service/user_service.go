@@ -25,13 +25,16 @@ type UserService interface {
 
 // userService 用户服务实现
 type userService struct {
-	db Database
+	db     Database
+	events EventPublisher
 }
 
 // NewUserService 创建用户服务实例
-func NewUserService(db Database) UserService {
+// events 可以为 nil，此时不发布任何事件
+func NewUserService(db Database, events EventPublisher) UserService {
 	return &userService{
-		db: db,
+		db:     db,
+		events: orNoopPublisher(events),
 	}
 }
 
@@ -104,7 +107,11 @@ func (s *userService) CreateUser(ctx context.Context, req *model.CreateUserReque
 	}
 
 	slog.Info("creating user", "email", req.Email)
-	user := s.db.CreateUser(req)
+	user, err := s.db.CreateUser(req)
+	if err != nil {
+		return nil, err
+	}
+	s.events.Publish(ctx, NewEvent(model.EventUserCreated, *user))
 
 	return user, nil
 }
@@ -138,7 +145,14 @@ func (s *userService) UpdateUser(ctx context.Context, id int, req *model.UpdateU
 	}
 
 	slog.Info("updating user", "id", id)
-	user := s.db.UpdateUser(id, req)
+	user, err := s.db.UpdateUser(id, req)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, errors.New("user not found")
+	}
+	s.events.Publish(ctx, NewEvent(model.EventUserUpdated, *user))
 
 	return user, nil
 }
@@ -157,9 +171,14 @@ func (s *userService) DeleteUser(ctx context.Context, id int) error {
 	}
 
 	slog.Info("deleting user", "id", id)
-	if !s.db.DeleteUser(id) {
+	deleted, err := s.db.DeleteUser(id)
+	if err != nil {
+		return err
+	}
+	if !deleted {
 		return errors.New("user not found")
 	}
+	s.events.Publish(ctx, NewEvent(model.EventUserDeleted, map[string]int{"id": id}))
 
 	return nil
 }
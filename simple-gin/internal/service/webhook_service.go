@@ -0,0 +1,391 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"example/simple-gin/internal/model"
+	"example/simple-gin/pkg/signature"
+	"example/simple-gin/pkg/utils"
+)
+
+// Webhook 投递请求头
+const (
+	HeaderWebhookID        = "X-Webhook-ID"
+	HeaderWebhookEvent     = "X-Webhook-Event"
+	HeaderWebhookDelivery  = "X-Webhook-Delivery"
+	HeaderWebhookTimestamp = "X-Webhook-Timestamp"
+	HeaderWebhookSignature = "X-Webhook-Signature"
+)
+
+// WebhookService Webhook 服务接口定义
+// 同时实现 EventPublisher，接收 Service 层发布的事件并异步投递给订阅者
+type WebhookService interface {
+	EventPublisher
+
+	// GetWebhooks 获取所有 Webhook
+	GetWebhooks(ctx context.Context) ([]*model.Webhook, error)
+	// GetWebhookByID 根据ID获取 Webhook
+	GetWebhookByID(ctx context.Context, id int) (*model.Webhook, error)
+	// CreateWebhook 注册 Webhook
+	CreateWebhook(ctx context.Context, req *model.CreateWebhookRequest) (*model.Webhook, error)
+	// DeleteWebhook 删除 Webhook
+	DeleteWebhook(ctx context.Context, id int) error
+	// GetDeadLetters 获取投递失败的记录
+	GetDeadLetters(ctx context.Context) ([]*model.DeadLetter, error)
+	// Close 停止投递协程，等待进行中的投递结束
+	Close()
+}
+
+// WebhookOptions Webhook 投递选项
+type WebhookOptions struct {
+	Workers        int           // 投递协程数
+	QueueSize      int           // 待投递队列长度
+	MaxRetries     int           // 首次失败后的最大重试次数
+	InitialBackoff time.Duration // 首次重试前的等待时间，之后指数增长
+	MaxBackoff     time.Duration // 重试等待时间上限
+	Timeout        time.Duration // 单次 HTTP 请求超时
+}
+
+// withDefaults 为未设置的选项填充默认值
+func (o WebhookOptions) withDefaults() WebhookOptions {
+	if o.Workers <= 0 {
+		o.Workers = 4
+	}
+	if o.QueueSize <= 0 {
+		o.QueueSize = 1000
+	}
+	if o.MaxRetries < 0 {
+		o.MaxRetries = 0
+	}
+	if o.InitialBackoff <= 0 {
+		o.InitialBackoff = time.Second
+	}
+	if o.MaxBackoff <= 0 {
+		o.MaxBackoff = time.Minute
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = 10 * time.Second
+	}
+	return o
+}
+
+// delivery 一次待投递的任务
+type delivery struct {
+	webhook model.Webhook
+	event   model.Event
+	body    []byte
+}
+
+// webhookService Webhook 服务实现
+type webhookService struct {
+	store  WebhookStore
+	opts   WebhookOptions
+	client *http.Client
+
+	queue  chan *delivery
+	done   chan struct{}
+	wg     sync.WaitGroup
+	mu     sync.RWMutex
+	closed bool
+}
+
+// NewWebhookService 创建 Webhook 服务实例并启动投递协程
+func NewWebhookService(store WebhookStore, opts WebhookOptions) WebhookService {
+	opts = opts.withDefaults()
+	s := &webhookService{
+		store:  store,
+		opts:   opts,
+		client: &http.Client{Timeout: opts.Timeout},
+		queue:  make(chan *delivery, opts.QueueSize),
+		done:   make(chan struct{}),
+	}
+
+	for i := 0; i < opts.Workers; i++ {
+		s.wg.Add(1)
+		go s.worker()
+	}
+
+	return s
+}
+
+// GetWebhooks 实现获取所有 Webhook
+func (s *webhookService) GetWebhooks(ctx context.Context) ([]*model.Webhook, error) {
+	select {
+	case <-ctx.Done():
+		slog.Warn("GetWebhooks request cancelled", "error", ctx.Err())
+		return nil, ctx.Err()
+	default:
+	}
+
+	webhooks := make([]*model.Webhook, 0)
+	for _, webhook := range s.store.GetAllWebhooks() {
+		webhooks = append(webhooks, withoutSecret(webhook))
+	}
+
+	return webhooks, nil
+}
+
+// GetWebhookByID 实现根据ID获取 Webhook
+func (s *webhookService) GetWebhookByID(ctx context.Context, id int) (*model.Webhook, error) {
+	select {
+	case <-ctx.Done():
+		slog.Warn("GetWebhookByID request cancelled", "error", ctx.Err())
+		return nil, ctx.Err()
+	default:
+	}
+
+	if id <= 0 {
+		return nil, errors.New("invalid webhook id")
+	}
+
+	webhook := s.store.GetWebhook(id)
+	if webhook == nil {
+		return nil, errors.New("webhook not found")
+	}
+
+	return withoutSecret(webhook), nil
+}
+
+// CreateWebhook 实现注册 Webhook
+// 未提供签名密钥时自动生成，密钥只在创建时返回一次
+func (s *webhookService) CreateWebhook(ctx context.Context, req *model.CreateWebhookRequest) (*model.Webhook, error) {
+	select {
+	case <-ctx.Done():
+		slog.Warn("CreateWebhook request cancelled", "error", ctx.Err())
+		return nil, ctx.Err()
+	default:
+	}
+
+	if req == nil {
+		return nil, errors.New("invalid request")
+	}
+
+	if len(req.Events) == 0 {
+		return nil, errors.New("at least one event is required")
+	}
+
+	for _, eventType := range req.Events {
+		if !model.IsValidEventType(eventType) {
+			return nil, fmt.Errorf("unknown event type: %s", eventType)
+		}
+	}
+
+	secret := req.Secret
+	if secret == "" {
+		secret = utils.GenerateID(32)
+	}
+
+	slog.Info("creating webhook", "url", req.URL, "events", req.Events)
+	webhook := s.store.CreateWebhook(req, secret)
+
+	created := *webhook
+	return &created, nil
+}
+
+// DeleteWebhook 实现删除 Webhook
+func (s *webhookService) DeleteWebhook(ctx context.Context, id int) error {
+	select {
+	case <-ctx.Done():
+		slog.Warn("DeleteWebhook request cancelled", "error", ctx.Err())
+		return ctx.Err()
+	default:
+	}
+
+	if id <= 0 {
+		return errors.New("invalid webhook id")
+	}
+
+	slog.Info("deleting webhook", "id", id)
+	if !s.store.DeleteWebhook(id) {
+		return errors.New("webhook not found")
+	}
+
+	return nil
+}
+
+// GetDeadLetters 实现获取投递失败的记录
+func (s *webhookService) GetDeadLetters(ctx context.Context) ([]*model.DeadLetter, error) {
+	select {
+	case <-ctx.Done():
+		slog.Warn("GetDeadLetters request cancelled", "error", ctx.Err())
+		return nil, ctx.Err()
+	default:
+	}
+
+	deadLetters := s.store.GetAllDeadLetters()
+	if deadLetters == nil {
+		deadLetters = make([]*model.DeadLetter, 0)
+	}
+
+	return deadLetters, nil
+}
+
+// Publish 把事件放入所有订阅者的投递队列
+// 发布不会阻塞调用方：队列已满时直接记入死信
+func (s *webhookService) Publish(ctx context.Context, event *model.Event) {
+	if event == nil {
+		return
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.closed {
+		return
+	}
+
+	var body []byte
+	for _, webhook := range s.store.GetAllWebhooks() {
+		if !webhook.Subscribes(event.Type) {
+			continue
+		}
+
+		if body == nil {
+			var err error
+			if body, err = json.Marshal(event); err != nil {
+				slog.Error("error marshaling event", "type", event.Type, "error", err)
+				return
+			}
+		}
+
+		d := &delivery{webhook: *webhook, event: *event, body: body}
+		select {
+		case s.queue <- d:
+		default:
+			slog.Warn("webhook queue full", "webhook_id", webhook.ID, "event", event.Type)
+			s.deadLetter(d, 0, errors.New("delivery queue full"))
+		}
+	}
+}
+
+// Close 停止接收新事件，等待队列中的事件投递完毕
+func (s *webhookService) Close() {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return
+	}
+	s.closed = true
+	close(s.done)
+	close(s.queue)
+	s.mu.Unlock()
+
+	s.wg.Wait()
+}
+
+// worker 投递协程
+func (s *webhookService) worker() {
+	defer s.wg.Done()
+	for d := range s.queue {
+		s.deliver(d)
+	}
+}
+
+// deliver 投递一次事件，失败时按指数退避重试，最终失败记入死信
+func (s *webhookService) deliver(d *delivery) {
+	var lastErr error
+	attempts := 0
+
+	for attempt := 0; attempt <= s.opts.MaxRetries; attempt++ {
+		if attempt > 0 && !s.wait(s.backoff(attempt)) {
+			break
+		}
+
+		attempts++
+		if lastErr = s.send(d); lastErr == nil {
+			slog.Debug("webhook delivered", "webhook_id", d.webhook.ID, "event", d.event.Type, "attempts", attempts)
+			return
+		}
+
+		slog.Warn("webhook delivery failed",
+			"webhook_id", d.webhook.ID,
+			"event", d.event.Type,
+			"attempt", attempts,
+			"error", lastErr,
+		)
+	}
+
+	s.deadLetter(d, attempts, lastErr)
+}
+
+// send 发送一次 HTTP 请求，2xx 视为成功
+func (s *webhookService) send(d *delivery) error {
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequest(http.MethodPost, d.webhook.URL, bytes.NewReader(d.body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(HeaderWebhookID, strconv.Itoa(d.webhook.ID))
+	req.Header.Set(HeaderWebhookEvent, d.event.Type)
+	req.Header.Set(HeaderWebhookDelivery, d.event.ID)
+	req.Header.Set(HeaderWebhookTimestamp, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(HeaderWebhookSignature, signature.Sign(d.webhook.Secret, timestamp, d.body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// backoff 计算第 n 次重试前的等待时间：InitialBackoff * 2^(n-1)，不超过 MaxBackoff
+func (s *webhookService) backoff(retry int) time.Duration {
+	d := s.opts.InitialBackoff
+	for i := 1; i < retry; i++ {
+		d *= 2
+		if d >= s.opts.MaxBackoff {
+			return s.opts.MaxBackoff
+		}
+	}
+	return d
+}
+
+// wait 等待指定时间，服务关闭时提前返回 false
+func (s *webhookService) wait(d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-s.done:
+		return false
+	}
+}
+
+// deadLetter 记录投递失败的事件
+func (s *webhookService) deadLetter(d *delivery, attempts int, err error) {
+	slog.Error("webhook moved to dead letters", "webhook_id", d.webhook.ID, "event", d.event.Type, "error", err)
+	s.store.AddDeadLetter(&model.DeadLetter{
+		WebhookID: d.webhook.ID,
+		URL:       d.webhook.URL,
+		Event:     d.event,
+		Attempts:  attempts,
+		LastError: err.Error(),
+		FailedAt:  time.Now(),
+	})
+}
+
+// withoutSecret 返回隐藏签名密钥的副本
+func withoutSecret(webhook *model.Webhook) *model.Webhook {
+	w := *webhook
+	w.Secret = ""
+	return &w
+}
@@ -0,0 +1,234 @@
+// Package client 提供 simple-gin API 的类型化 Go 客户端
+// 这个包可以被其他项目导入使用
+//
+// 每个接口对应一个方法，请求和响应都是强类型结构体。服务端返回的统一响应
+// （response.Response）会被解包：成功时把 data 解码到返回值，失败时返回 *APIError。
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"example/simple-gin/pkg/utils"
+)
+
+// HeaderRequestID 请求ID头，与服务端 RequestIDMiddleware 一致
+const HeaderRequestID = "X-Request-ID"
+
+// Options 客户端选项
+type Options struct {
+	HTTPClient   *http.Client  // 自定义 HTTP 客户端，为空时使用默认客户端
+	Timeout      time.Duration // 单次请求超时（每次重试单独计时）
+	MaxRetries   int           // 幂等请求（GET/PUT/DELETE）失败后的最大重试次数
+	RetryBackoff time.Duration // 首次重试前的等待时间，之后指数增长
+	MaxBackoff   time.Duration // 重试等待时间上限
+}
+
+// withDefaults 为未设置的选项填充默认值
+func (o Options) withDefaults() Options {
+	if o.HTTPClient == nil {
+		o.HTTPClient = &http.Client{}
+	}
+	if o.Timeout <= 0 {
+		o.Timeout = 10 * time.Second
+	}
+	if o.MaxRetries < 0 {
+		o.MaxRetries = 0
+	}
+	if o.RetryBackoff <= 0 {
+		o.RetryBackoff = 100 * time.Millisecond
+	}
+	if o.MaxBackoff <= 0 {
+		o.MaxBackoff = 2 * time.Second
+	}
+	return o
+}
+
+// Client simple-gin API 客户端
+type Client struct {
+	baseURL string
+	opts    Options
+}
+
+// NewClient 创建客户端，baseURL 形如 http://localhost:8080
+func NewClient(baseURL string, opts Options) *Client {
+	return &Client{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		opts:    opts.withDefaults(),
+	}
+}
+
+// requestIDKey context 中请求ID的键
+type requestIDKey struct{}
+
+// WithRequestID 返回携带请求ID的 context，客户端会把它放在 X-Request-ID 头中
+// 在服务端处理请求时传入上游的请求ID，即可把调用链串起来
+func WithRequestID(ctx context.Context, requestID string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestID)
+}
+
+// RequestIDFromContext 获取 context 中的请求ID
+func RequestIDFromContext(ctx context.Context) string {
+	requestID, _ := ctx.Value(requestIDKey{}).(string)
+	return requestID
+}
+
+// envelope 服务端统一响应结构
+type envelope struct {
+	Code    int             `json:"code"`
+	Message string          `json:"msg"`
+	Data    json.RawMessage `json:"data,omitempty"`
+}
+
+// do 发送请求并解包统一响应，out 为空时忽略 data
+// context 中没有请求ID时生成一个，所有重试共用同一个请求ID
+func (c *Client) do(ctx context.Context, route Route, path string, body interface{}, out interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return fmt.Errorf("simple-gin: marshal request: %w", err)
+		}
+	}
+
+	requestID := c.requestID(ctx)
+
+	retries := 0
+	if isIdempotent(route.Method) {
+		retries = c.opts.MaxRetries
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= retries; attempt++ {
+		if attempt > 0 {
+			if err := sleep(ctx, c.backoff(attempt)); err != nil {
+				return err
+			}
+		}
+
+		lastErr = c.send(ctx, route.Method, path, payload, requestID, out)
+		if lastErr == nil || !retryable(ctx, lastErr) {
+			return lastErr
+		}
+	}
+	return lastErr
+}
+
+// send 发送一次请求
+func (c *Client) send(ctx context.Context, method, path string, payload []byte, requestID string, out interface{}) error {
+	ctx, cancel := context.WithTimeout(ctx, c.opts.Timeout)
+	defer cancel()
+
+	var body io.Reader
+	if payload != nil {
+		body = bytes.NewReader(payload)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set(HeaderRequestID, requestID)
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.opts.HTTPClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		return readAPIError(resp, requestID)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+
+	var env envelope
+	if err := json.Unmarshal(data, &env); err != nil {
+		return &decodeError{err: err}
+	}
+	if env.Code != 0 {
+		return &APIError{StatusCode: resp.StatusCode, Code: env.Code, Message: env.Message, RequestID: requestID}
+	}
+
+	if out != nil && len(env.Data) > 0 {
+		if err := json.Unmarshal(env.Data, out); err != nil {
+			return &decodeError{err: err}
+		}
+	}
+	return nil
+}
+
+// backoff 计算第 n 次重试前的等待时间：RetryBackoff * 2^(n-1)，不超过 MaxBackoff
+func (c *Client) backoff(retry int) time.Duration {
+	d := c.opts.RetryBackoff
+	for i := 1; i < retry; i++ {
+		d *= 2
+		if d >= c.opts.MaxBackoff {
+			return c.opts.MaxBackoff
+		}
+	}
+	return d
+}
+
+// isIdempotent POST 请求（创建、减少库存）重试可能重复执行，不自动重试
+func isIdempotent(method string) bool {
+	return method == http.MethodGet || method == http.MethodPut || method == http.MethodDelete
+}
+
+// retryable 网络错误、单次超时以及 5xx/429 可以重试，调用方取消或超时则不再重试
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.Temporary()
+	}
+	var decodeErr *decodeError
+	return !errors.As(err, &decodeErr)
+}
+
+// decodeError 响应格式错误，重试也不会成功
+type decodeError struct {
+	err error
+}
+
+func (e *decodeError) Error() string {
+	return "simple-gin: decode response: " + e.err.Error()
+}
+
+func (e *decodeError) Unwrap() error {
+	return e.err
+}
+
+// newRequestID 生成请求ID
+func newRequestID() string {
+	return utils.GenerateID(16)
+}
+
+// sleep 等待指定时间，context 结束时提前返回
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,294 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestRoutesMatchSwagger 客户端路由必须与 docs/swagger.json 完全一致
+func TestRoutesMatchSwagger(t *testing.T) {
+	data, err := os.ReadFile("../../docs/swagger.json")
+	if err != nil {
+		t.Fatalf("Failed to read swagger spec: %v", err)
+	}
+
+	var spec struct {
+		BasePath string                                `json:"basePath"`
+		Paths    map[string]map[string]json.RawMessage `json:"paths"`
+	}
+	if err := json.Unmarshal(data, &spec); err != nil {
+		t.Fatalf("Failed to parse swagger spec: %v", err)
+	}
+
+	swaggerRoutes := make(map[string]bool)
+	for path, methods := range spec.Paths {
+		for method := range methods {
+			fullPath := strings.TrimRight(spec.BasePath, "/") + path
+			swaggerRoutes[Route{strings.ToUpper(method), fullPath}.String()] = true
+		}
+	}
+
+	clientRoutes := make(map[string]bool)
+	for _, route := range Routes() {
+		clientRoutes[route.String()] = true
+	}
+
+	var missing, extra []string
+	for route := range swaggerRoutes {
+		if !clientRoutes[route] {
+			missing = append(missing, route)
+		}
+	}
+	for route := range clientRoutes {
+		if !swaggerRoutes[route] {
+			extra = append(extra, route)
+		}
+	}
+	sort.Strings(missing)
+	sort.Strings(extra)
+
+	if len(missing) > 0 {
+		t.Errorf("Routes in swagger.json but not in client: %v", missing)
+	}
+	if len(extra) > 0 {
+		t.Errorf("Routes in client but not in swagger.json: %v", extra)
+	}
+}
+
+func TestRouteExpand(t *testing.T) {
+	tests := []struct {
+		name   string
+		route  Route
+		params []interface{}
+		want   string
+	}{
+		{"no params", routeGetUsers, nil, "/api/v1/users"},
+		{"one param", routeGetUser, []interface{}{42}, "/api/v1/users/42"},
+		{"param in middle", routeReduceStock, []interface{}{7}, "/api/v1/products/7/reduce-stock"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.route.expand(tt.params...); got != tt.want {
+				t.Errorf("expand() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	tests := []struct {
+		name        string
+		status      int
+		body        string
+		wantCode    int
+		wantMessage string
+		notFound    bool
+	}{
+		{"not found", 404, `{"code":404,"msg":"user not found"}`, 404, "user not found", true},
+		{"bad request", 400, `{"code":400,"msg":"invalid user id"}`, 400, "invalid user id", false},
+		{"non-envelope body", 502, `<html>Bad Gateway</html>`, 502, "Bad Gateway", false},
+		{"error code with 200", 200, `{"code":1001,"msg":"business error"}`, 1001, "business error", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(tt.status)
+				fmt.Fprint(w, tt.body)
+			}))
+			defer server.Close()
+
+			c := NewClient(server.URL, Options{})
+			_, err := c.GetUser(context.Background(), 1)
+
+			apiErr, ok := err.(*APIError)
+			if !ok {
+				t.Fatalf("Expected *APIError, got %T: %v", err, err)
+			}
+			if apiErr.StatusCode != tt.status || apiErr.Code != tt.wantCode || apiErr.Message != tt.wantMessage {
+				t.Errorf("Got %+v, want status %d code %d message %q", apiErr, tt.status, tt.wantCode, tt.wantMessage)
+			}
+			if IsNotFound(err) != tt.notFound {
+				t.Errorf("IsNotFound() = %v, want %v", IsNotFound(err), tt.notFound)
+			}
+		})
+	}
+}
+
+func TestClientDecodesEnvelope(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v1/products" {
+			t.Errorf("Unexpected request %s %s", r.Method, r.URL.Path)
+		}
+		var req CreateProductRequest
+		json.NewDecoder(r.Body).Decode(&req)
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"code": 0,
+			"msg":  "created successfully",
+			"data": Product{ID: 3, Name: req.Name, Price: req.Price, Stock: req.Stock, Category: req.Category},
+		})
+	}))
+	defer server.Close()
+
+	c := NewClient(server.URL, Options{})
+	product, err := c.CreateProduct(context.Background(), CreateProductRequest{Name: "iPad", Price: 3999, Stock: 10, Category: "Electronics"})
+	if err != nil {
+		t.Fatalf("CreateProduct() error = %v", err)
+	}
+	if product.ID != 3 || product.Name != "iPad" {
+		t.Errorf("Unexpected product %+v", product)
+	}
+}
+
+func TestClientRetry(t *testing.T) {
+	tests := []struct {
+		name         string
+		call         func(c *Client) error
+		failures     int
+		wantAttempts int
+		wantErr      bool
+	}{
+		{
+			name:         "GET retried until success",
+			call:         func(c *Client) error { _, err := c.GetProducts(context.Background()); return err },
+			failures:     2,
+			wantAttempts: 3,
+		},
+		{
+			name:         "GET gives up after max retries",
+			call:         func(c *Client) error { _, err := c.GetProducts(context.Background()); return err },
+			failures:     10,
+			wantAttempts: 4,
+			wantErr:      true,
+		},
+		{
+			name:         "POST not retried",
+			call:         func(c *Client) error { return c.ReduceStock(context.Background(), 1, 1) },
+			failures:     1,
+			wantAttempts: 1,
+			wantErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			attempts := 0
+			requestIDs := make(map[string]bool)
+
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				mu.Lock()
+				attempts++
+				n := attempts
+				requestIDs[r.Header.Get(HeaderRequestID)] = true
+				mu.Unlock()
+
+				if n <= tt.failures {
+					w.WriteHeader(http.StatusServiceUnavailable)
+					fmt.Fprint(w, `{"code":503,"msg":"unavailable"}`)
+					return
+				}
+				fmt.Fprint(w, `{"code":0,"msg":"success","data":[]}`)
+			}))
+			defer server.Close()
+
+			c := NewClient(server.URL, Options{MaxRetries: 3, RetryBackoff: time.Millisecond})
+			err := tt.call(c)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if attempts != tt.wantAttempts {
+				t.Errorf("attempts = %d, want %d", attempts, tt.wantAttempts)
+			}
+			if len(requestIDs) != 1 {
+				t.Errorf("Expected all attempts to share one request ID, got %v", requestIDs)
+			}
+		})
+	}
+}
+
+func TestClientTimeout(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}))
+	defer server.Close()
+
+	c := NewClient(server.URL, Options{Timeout: 20 * time.Millisecond})
+	start := time.Now()
+	if _, err := c.GetUsers(context.Background()); err == nil {
+		t.Fatal("Expected timeout error")
+	}
+	if elapsed := time.Since(start); elapsed > 500*time.Millisecond {
+		t.Errorf("Expected request to time out quickly, took %v", elapsed)
+	}
+}
+
+func TestClientRequestIDPropagation(t *testing.T) {
+	var got string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get(HeaderRequestID)
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprint(w, `{"code":404,"msg":"webhook not found"}`)
+	}))
+	defer server.Close()
+
+	c := NewClient(server.URL, Options{})
+	ctx := WithRequestID(context.Background(), "upstream-123")
+	_, err := c.GetWebhook(ctx, 9)
+
+	if got != "upstream-123" {
+		t.Errorf("Expected request ID upstream-123, got %q", got)
+	}
+	if apiErr, ok := err.(*APIError); !ok || apiErr.RequestID != "upstream-123" {
+		t.Errorf("Expected error to carry request ID, got %v", err)
+	}
+}
+
+func TestStreamProducts(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("category") != "Electronics" || r.Header.Get("Last-Event-ID") != "5" {
+			t.Errorf("Unexpected stream request %s, Last-Event-ID %q", r.URL, r.Header.Get("Last-Event-ID"))
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, "retry: 3000\n\n")
+		fmt.Fprint(w, ": heartbeat\n\n")
+		fmt.Fprint(w, "id: 6\nevent: product.updated\ndata: {\"id\":6,\"type\":\"product.updated\",\"product\":{\"id\":1,\"stock\":9}}\n\n")
+	}))
+	defer server.Close()
+
+	c := NewClient(server.URL, Options{})
+	stream, err := c.StreamProducts(context.Background(), StreamOptions{Category: "Electronics", LastEventID: 5})
+	if err != nil {
+		t.Fatalf("StreamProducts() error = %v", err)
+	}
+	defer stream.Close()
+
+	event, err := stream.Next()
+	if err != nil {
+		t.Fatalf("Next() error = %v", err)
+	}
+	if event.ID != 6 || event.Product == nil || event.Product.Stock != 9 {
+		t.Errorf("Unexpected event %+v", event)
+	}
+	if stream.LastEventID() != 6 {
+		t.Errorf("LastEventID() = %d, want 6", stream.LastEventID())
+	}
+	if _, err := stream.Next(); err == nil {
+		t.Error("Expected error after stream ends")
+	}
+}
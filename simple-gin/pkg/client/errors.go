@@ -0,0 +1,59 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// APIError 服务端返回的错误响应
+type APIError struct {
+	StatusCode int    // HTTP 状态码
+	Code       int    // 响应体中的业务码
+	Message    string // 响应体中的错误信息
+	RequestID  string // 本次请求的 X-Request-ID
+}
+
+// Error 实现 error 接口
+func (e *APIError) Error() string {
+	if e.RequestID != "" {
+		return fmt.Sprintf("simple-gin: %d %s (request_id=%s)", e.StatusCode, e.Message, e.RequestID)
+	}
+	return fmt.Sprintf("simple-gin: %d %s", e.StatusCode, e.Message)
+}
+
+// Temporary 是否为可重试的错误（5xx 或 429）
+func (e *APIError) Temporary() bool {
+	return e.StatusCode >= 500 || e.StatusCode == http.StatusTooManyRequests
+}
+
+// IsNotFound 检查错误是否为 404
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsBadRequest 检查错误是否为 400
+func IsBadRequest(err error) bool {
+	return hasStatus(err, http.StatusBadRequest)
+}
+
+// hasStatus 检查错误链中是否有指定状态码的 APIError
+func hasStatus(err error, status int) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == status
+}
+
+// readAPIError 把错误响应解码为 APIError
+// 响应体不是统一格式时（如网关返回的 502 页面）使用状态码文本作为错误信息
+func readAPIError(resp *http.Response, requestID string) error {
+	apiErr := &APIError{StatusCode: resp.StatusCode, Code: resp.StatusCode, Message: http.StatusText(resp.StatusCode), RequestID: requestID}
+
+	var env envelope
+	if data, err := io.ReadAll(resp.Body); err == nil && json.Unmarshal(data, &env) == nil && env.Message != "" {
+		apiErr.Code = env.Code
+		apiErr.Message = env.Message
+	}
+	return apiErr
+}
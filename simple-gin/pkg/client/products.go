@@ -0,0 +1,48 @@
+package client
+
+import "context"
+
+// GetProducts 获取所有产品
+func (c *Client) GetProducts(ctx context.Context) ([]Product, error) {
+	var products []Product
+	err := c.do(ctx, routeGetProducts, routeGetProducts.expand(), nil, &products)
+	return products, err
+}
+
+// GetProduct 根据ID获取产品
+func (c *Client) GetProduct(ctx context.Context, id int) (*Product, error) {
+	var product Product
+	if err := c.do(ctx, routeGetProduct, routeGetProduct.expand(id), nil, &product); err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+// CreateProduct 创建产品
+func (c *Client) CreateProduct(ctx context.Context, req CreateProductRequest) (*Product, error) {
+	var product Product
+	if err := c.do(ctx, routeCreateProduct, routeCreateProduct.expand(), req, &product); err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+// UpdateProduct 更新产品
+func (c *Client) UpdateProduct(ctx context.Context, id int, req UpdateProductRequest) (*Product, error) {
+	var product Product
+	if err := c.do(ctx, routeUpdateProduct, routeUpdateProduct.expand(id), req, &product); err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+// DeleteProduct 删除产品
+func (c *Client) DeleteProduct(ctx context.Context, id int) error {
+	return c.do(ctx, routeDeleteProduct, routeDeleteProduct.expand(id), nil, nil)
+}
+
+// ReduceStock 减少库存
+// 该请求不是幂等的，失败时不会自动重试
+func (c *Client) ReduceStock(ctx context.Context, id int, quantity int) error {
+	return c.do(ctx, routeReduceStock, routeReduceStock.expand(id), ReduceStockRequest{Quantity: quantity}, nil)
+}
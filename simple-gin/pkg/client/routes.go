@@ -0,0 +1,66 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Route 一个 API 路由，Path 使用 Swagger 的 {param} 占位符
+type Route struct {
+	Method string
+	Path   string
+}
+
+// 客户端封装的全部路由
+// 新增接口时在这里登记，client_test 会与 docs/swagger.json 对比，防止两边不一致
+var (
+	routeGetUsers   = Route{"GET", "/api/v1/users"}
+	routeCreateUser = Route{"POST", "/api/v1/users"}
+	routeGetUser    = Route{"GET", "/api/v1/users/{id}"}
+	routeUpdateUser = Route{"PUT", "/api/v1/users/{id}"}
+	routeDeleteUser = Route{"DELETE", "/api/v1/users/{id}"}
+
+	routeGetProducts    = Route{"GET", "/api/v1/products"}
+	routeCreateProduct  = Route{"POST", "/api/v1/products"}
+	routeGetProduct     = Route{"GET", "/api/v1/products/{id}"}
+	routeUpdateProduct  = Route{"PUT", "/api/v1/products/{id}"}
+	routeDeleteProduct  = Route{"DELETE", "/api/v1/products/{id}"}
+	routeReduceStock    = Route{"POST", "/api/v1/products/{id}/reduce-stock"}
+	routeStreamProducts = Route{"GET", "/api/v1/products/stream"}
+	routeWatchProducts  = Route{"GET", "/api/v1/products/ws"}
+
+	routeGetWebhooks    = Route{"GET", "/api/v1/webhooks"}
+	routeCreateWebhook  = Route{"POST", "/api/v1/webhooks"}
+	routeGetWebhook     = Route{"GET", "/api/v1/webhooks/{id}"}
+	routeDeleteWebhook  = Route{"DELETE", "/api/v1/webhooks/{id}"}
+	routeGetDeadLetters = Route{"GET", "/api/v1/webhooks/dead-letters"}
+)
+
+// Routes 返回客户端封装的全部路由
+func Routes() []Route {
+	return []Route{
+		routeGetUsers, routeCreateUser, routeGetUser, routeUpdateUser, routeDeleteUser,
+		routeGetProducts, routeCreateProduct, routeGetProduct, routeUpdateProduct, routeDeleteProduct,
+		routeReduceStock, routeStreamProducts, routeWatchProducts,
+		routeGetWebhooks, routeCreateWebhook, routeGetWebhook, routeDeleteWebhook, routeGetDeadLetters,
+	}
+}
+
+// String 返回 "METHOD /path" 形式
+func (r Route) String() string {
+	return r.Method + " " + r.Path
+}
+
+// expand 依次用参数替换路径中的占位符
+func (r Route) expand(params ...interface{}) string {
+	path := r.Path
+	for _, p := range params {
+		start := strings.Index(path, "{")
+		end := strings.Index(path, "}")
+		if start < 0 || end < start {
+			break
+		}
+		path = path[:start] + fmt.Sprint(p) + path[end+1:]
+	}
+	return path
+}
@@ -0,0 +1,164 @@
+package client
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/gorilla/websocket"
+)
+
+// EventStream 库存事件订阅
+// 连接断开后可以用 LastEventID 作为 StreamOptions.LastEventID 重新订阅续传
+type EventStream struct {
+	next        func() (*InventoryEvent, error)
+	close       func() error
+	lastEventID int64
+}
+
+// Next 阻塞读取下一个事件，连接断开时返回错误（正常结束为 io.EOF）
+func (s *EventStream) Next() (*InventoryEvent, error) {
+	event, err := s.next()
+	if err != nil {
+		return nil, err
+	}
+	s.lastEventID = event.ID
+	return event, nil
+}
+
+// LastEventID 最后收到的事件ID
+func (s *EventStream) LastEventID() int64 {
+	return s.lastEventID
+}
+
+// Close 关闭订阅
+func (s *EventStream) Close() error {
+	return s.close()
+}
+
+// StreamProducts 通过 SSE 订阅库存变化
+// 订阅是长连接，不受 Options.Timeout 限制，通过 ctx 或 Close 结束
+func (c *Client) StreamProducts(ctx context.Context, opts StreamOptions) (*EventStream, error) {
+	req, err := http.NewRequestWithContext(ctx, routeStreamProducts.Method, c.baseURL+routeStreamProducts.expand()+streamQuery(opts), nil)
+	if err != nil {
+		return nil, err
+	}
+	requestID := c.requestID(ctx)
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set(HeaderRequestID, requestID)
+	if opts.LastEventID > 0 {
+		req.Header.Set("Last-Event-ID", strconv.FormatInt(opts.LastEventID, 10))
+	}
+
+	resp, err := c.opts.HTTPClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, readAPIError(resp, requestID)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	return &EventStream{
+		next:        func() (*InventoryEvent, error) { return readSSEEvent(reader) },
+		close:       resp.Body.Close,
+		lastEventID: opts.LastEventID,
+	}, nil
+}
+
+// WatchProducts 通过 WebSocket 订阅库存变化
+func (c *Client) WatchProducts(ctx context.Context, opts StreamOptions) (*EventStream, error) {
+	query := streamQuery(opts)
+	if opts.LastEventID > 0 {
+		if query == "" {
+			query = "?"
+		} else {
+			query += "&"
+		}
+		query += "last_event_id=" + strconv.FormatInt(opts.LastEventID, 10)
+	}
+	wsURL := "ws" + strings.TrimPrefix(c.baseURL, "http") + routeWatchProducts.expand() + query
+
+	requestID := c.requestID(ctx)
+	header := http.Header{}
+	header.Set(HeaderRequestID, requestID)
+
+	conn, resp, err := websocket.DefaultDialer.DialContext(ctx, wsURL, header)
+	if err != nil {
+		if resp != nil && resp.StatusCode != http.StatusSwitchingProtocols {
+			defer resp.Body.Close()
+			return nil, readAPIError(resp, requestID)
+		}
+		return nil, err
+	}
+
+	return &EventStream{
+		next: func() (*InventoryEvent, error) {
+			var event InventoryEvent
+			if err := conn.ReadJSON(&event); err != nil {
+				if websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway) {
+					return nil, io.EOF
+				}
+				return nil, err
+			}
+			return &event, nil
+		},
+		close:       conn.Close,
+		lastEventID: opts.LastEventID,
+	}, nil
+}
+
+// requestID 获取 context 中的请求ID，没有时生成一个
+func (c *Client) requestID(ctx context.Context) string {
+	if requestID := RequestIDFromContext(ctx); requestID != "" {
+		return requestID
+	}
+	return newRequestID()
+}
+
+// streamQuery 构造过滤参数，不含续传位置
+func streamQuery(opts StreamOptions) string {
+	values := url.Values{}
+	for _, id := range opts.ProductIDs {
+		values.Add("product_id", strconv.Itoa(id))
+	}
+	if opts.Category != "" {
+		values.Set("category", opts.Category)
+	}
+	if len(values) == 0 {
+		return ""
+	}
+	return "?" + values.Encode()
+}
+
+// readSSEEvent 读取下一个 SSE 事件，跳过 retry 字段和心跳注释
+func readSSEEvent(reader *bufio.Reader) (*InventoryEvent, error) {
+	var data string
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return nil, err
+		}
+		line = strings.TrimRight(line, "\r\n")
+
+		switch {
+		case line == "":
+			if data == "" {
+				continue
+			}
+			var event InventoryEvent
+			if err := json.Unmarshal([]byte(data), &event); err != nil {
+				return nil, &decodeError{err: err}
+			}
+			return &event, nil
+		case strings.HasPrefix(line, "data:"):
+			data = strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " ")
+		}
+	}
+}
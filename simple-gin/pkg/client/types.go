@@ -0,0 +1,116 @@
+package client
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// 这些类型与服务端 internal/model 的 JSON 结构保持一致
+// 客户端不能导入 internal 包，因此单独定义一份
+
+// User 用户
+type User struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	Email     string    `json:"email"`
+	Phone     string    `json:"phone"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// CreateUserRequest 创建用户请求体
+type CreateUserRequest struct {
+	Name  string `json:"name"`
+	Email string `json:"email"`
+	Phone string `json:"phone"`
+}
+
+// UpdateUserRequest 更新用户请求体，空字段表示不修改
+type UpdateUserRequest struct {
+	Name  string `json:"name,omitempty"`
+	Email string `json:"email,omitempty"`
+	Phone string `json:"phone,omitempty"`
+}
+
+// Product 产品
+type Product struct {
+	ID       int     `json:"id"`
+	Name     string  `json:"name"`
+	Price    float64 `json:"price"`
+	Stock    int     `json:"stock"`
+	Category string  `json:"category"`
+}
+
+// CreateProductRequest 创建产品请求体
+type CreateProductRequest struct {
+	Name     string  `json:"name"`
+	Price    float64 `json:"price"`
+	Stock    int     `json:"stock"`
+	Category string  `json:"category"`
+}
+
+// UpdateProductRequest 更新产品请求体
+type UpdateProductRequest struct {
+	Name     string  `json:"name"`
+	Price    float64 `json:"price"`
+	Stock    int     `json:"stock"`
+	Category string  `json:"category"`
+}
+
+// ReduceStockRequest 减少库存请求体
+type ReduceStockRequest struct {
+	Quantity int `json:"quantity"`
+}
+
+// Webhook Webhook 订阅，Secret 只在创建时返回
+type Webhook struct {
+	ID        int       `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreateWebhookRequest 创建 Webhook 请求体
+type CreateWebhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Secret string   `json:"secret,omitempty"`
+}
+
+// Event 领域事件，Data 的结构取决于事件类型
+type Event struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	OccurredAt time.Time       `json:"occurred_at"`
+	Data       json.RawMessage `json:"data"`
+}
+
+// DeadLetter 投递失败的 Webhook 记录
+type DeadLetter struct {
+	ID        int       `json:"id"`
+	WebhookID int       `json:"webhook_id"`
+	URL       string    `json:"url"`
+	Event     Event     `json:"event"`
+	Attempts  int       `json:"attempts"`
+	LastError string    `json:"last_error"`
+	FailedAt  time.Time `json:"failed_at"`
+}
+
+// InventoryEvent 实时库存推送事件
+type InventoryEvent struct {
+	ID         int64     `json:"id"`
+	Type       string    `json:"type"`
+	OccurredAt time.Time `json:"occurred_at"`
+	Product    *Product  `json:"product,omitempty"`
+}
+
+// StreamEventReset 续传位置已超出服务端缓冲区，应重新拉取全量数据
+const StreamEventReset = "stream.reset"
+
+// StreamOptions 库存推送订阅选项，字段为空表示不过滤
+type StreamOptions struct {
+	ProductIDs  []int
+	Category    string
+	LastEventID int64 // 从该事件之后续传
+}
@@ -0,0 +1,42 @@
+package client
+
+import "context"
+
+// GetUsers 获取所有用户
+func (c *Client) GetUsers(ctx context.Context) ([]User, error) {
+	var users []User
+	err := c.do(ctx, routeGetUsers, routeGetUsers.expand(), nil, &users)
+	return users, err
+}
+
+// GetUser 根据ID获取用户
+func (c *Client) GetUser(ctx context.Context, id int) (*User, error) {
+	var user User
+	if err := c.do(ctx, routeGetUser, routeGetUser.expand(id), nil, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// CreateUser 创建用户
+func (c *Client) CreateUser(ctx context.Context, req CreateUserRequest) (*User, error) {
+	var user User
+	if err := c.do(ctx, routeCreateUser, routeCreateUser.expand(), req, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// UpdateUser 更新用户
+func (c *Client) UpdateUser(ctx context.Context, id int, req UpdateUserRequest) (*User, error) {
+	var user User
+	if err := c.do(ctx, routeUpdateUser, routeUpdateUser.expand(id), req, &user); err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+// DeleteUser 删除用户
+func (c *Client) DeleteUser(ctx context.Context, id int) error {
+	return c.do(ctx, routeDeleteUser, routeDeleteUser.expand(id), nil, nil)
+}
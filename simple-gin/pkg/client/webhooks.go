@@ -0,0 +1,40 @@
+package client
+
+import "context"
+
+// GetWebhooks 获取所有 Webhook（不含签名密钥）
+func (c *Client) GetWebhooks(ctx context.Context) ([]Webhook, error) {
+	var webhooks []Webhook
+	err := c.do(ctx, routeGetWebhooks, routeGetWebhooks.expand(), nil, &webhooks)
+	return webhooks, err
+}
+
+// GetWebhook 根据ID获取 Webhook（不含签名密钥）
+func (c *Client) GetWebhook(ctx context.Context, id int) (*Webhook, error) {
+	var webhook Webhook
+	if err := c.do(ctx, routeGetWebhook, routeGetWebhook.expand(id), nil, &webhook); err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// CreateWebhook 注册 Webhook，返回值中的 Secret 只在这里出现一次
+func (c *Client) CreateWebhook(ctx context.Context, req CreateWebhookRequest) (*Webhook, error) {
+	var webhook Webhook
+	if err := c.do(ctx, routeCreateWebhook, routeCreateWebhook.expand(), req, &webhook); err != nil {
+		return nil, err
+	}
+	return &webhook, nil
+}
+
+// DeleteWebhook 删除 Webhook
+func (c *Client) DeleteWebhook(ctx context.Context, id int) error {
+	return c.do(ctx, routeDeleteWebhook, routeDeleteWebhook.expand(id), nil, nil)
+}
+
+// GetDeadLetters 获取投递失败的记录
+func (c *Client) GetDeadLetters(ctx context.Context) ([]DeadLetter, error) {
+	var deadLetters []DeadLetter
+	err := c.do(ctx, routeGetDeadLetters, routeGetDeadLetters.expand(), nil, &deadLetters)
+	return deadLetters, err
+}
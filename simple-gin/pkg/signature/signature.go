@@ -0,0 +1,33 @@
+// Package signature 提供 Webhook 负载的 HMAC 签名与校验
+// 接收方可以导入这个包来验证请求确实来自 simple-gin
+package signature
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"strings"
+)
+
+// Prefix 签名头的前缀，标识签名算法
+const Prefix = "sha256="
+
+// Sign 使用 HMAC-SHA256 对 "时间戳.负载" 进行签名
+// 把时间戳纳入签名可以防止重放攻击
+func Sign(secret string, timestamp int64, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+	return Prefix + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Verify 校验签名是否匹配（常量时间比较）
+func Verify(secret string, timestamp int64, payload []byte, signature string) bool {
+	if !strings.HasPrefix(signature, Prefix) {
+		return false
+	}
+	expected := Sign(secret, timestamp, payload)
+	return hmac.Equal([]byte(expected), []byte(signature))
+}
@@ -0,0 +1,39 @@
+package signature
+
+import "testing"
+
+func TestSignAndVerify(t *testing.T) {
+	payload := []byte(`{"type":"product.created"}`)
+	sig := Sign("secret", 1700000000, payload)
+
+	tests := []struct {
+		name      string
+		secret    string
+		timestamp int64
+		payload   []byte
+		signature string
+		want      bool
+	}{
+		{"valid signature", "secret", 1700000000, payload, sig, true},
+		{"wrong secret", "other", 1700000000, payload, sig, false},
+		{"wrong timestamp", "secret", 1700000001, payload, sig, false},
+		{"tampered payload", "secret", 1700000000, []byte(`{"type":"user.deleted"}`), sig, false},
+		{"missing prefix", "secret", 1700000000, payload, sig[len(Prefix):], false},
+		{"empty signature", "secret", 1700000000, payload, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Verify(tt.secret, tt.timestamp, tt.payload, tt.signature); got != tt.want {
+				t.Errorf("Verify() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func BenchmarkSign(b *testing.B) {
+	payload := []byte(`{"type":"product.created","data":{"id":1}}`)
+	for i := 0; i < b.N; i++ {
+		Sign("secret", 1700000000, payload)
+	}
+}
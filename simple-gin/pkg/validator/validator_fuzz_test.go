@@ -0,0 +1,84 @@
+package validator
+
+import (
+	"strings"
+	"testing"
+	"unicode"
+)
+
+// 运行方式：go test -fuzz=FuzzIsValidEmail ./pkg/validator/
+
+func FuzzIsValidEmail(f *testing.F) {
+	for _, seed := range []string{"test@example.com", "a.b+c@mail.example.co", "testexample.com", "test@", "", "test @example.com", "@@", "a@b.c"} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, email string) {
+		if !IsValidEmail(email) {
+			return
+		}
+		if strings.Count(email, "@") != 1 {
+			t.Errorf("IsValidEmail(%q) accepted address without exactly one @", email)
+		}
+		if strings.ContainsFunc(email, unicode.IsSpace) {
+			t.Errorf("IsValidEmail(%q) accepted address with whitespace", email)
+		}
+		local, domain, _ := strings.Cut(email, "@")
+		if local == "" || !strings.Contains(domain, ".") {
+			t.Errorf("IsValidEmail(%q) accepted address with empty local part or dotless domain", email)
+		}
+	})
+}
+
+func FuzzIsValidPhone(f *testing.F) {
+	for _, seed := range []string{"13800138000", "12800138000", "1380013800", "138001380001", "", "1380013800a", "１３８００１３８０００"} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, phone string) {
+		if !IsValidPhone(phone) {
+			return
+		}
+		if len(phone) != 11 || phone[0] != '1' || phone[1] < '3' || phone[1] > '9' {
+			t.Errorf("IsValidPhone(%q) accepted invalid prefix or length", phone)
+		}
+		for _, r := range phone {
+			if r < '0' || r > '9' {
+				t.Errorf("IsValidPhone(%q) accepted non-ASCII-digit %q", phone, r)
+			}
+		}
+	})
+}
+
+func FuzzIsNotEmpty(f *testing.F) {
+	for _, seed := range []string{"hello", "", "   ", "\t\n", "　"} {
+		f.Add(seed)
+	}
+
+	f.Fuzz(func(t *testing.T, s string) {
+		if got, want := IsNotEmpty(s), strings.TrimSpace(s) != ""; got != want {
+			t.Errorf("IsNotEmpty(%q) = %v, want %v", s, got, want)
+		}
+		// 前后追加空白不影响结果
+		if IsNotEmpty(" "+s+"\t") != IsNotEmpty(s) {
+			t.Errorf("IsNotEmpty(%q) changed after padding with whitespace", s)
+		}
+	})
+}
+
+func FuzzLengthBetween(f *testing.F) {
+	f.Add("hello", 1, 10)
+	f.Add("", 0, 0)
+	f.Add("你好", 2, 4)
+	f.Add("abc", 5, 1)
+
+	f.Fuzz(func(t *testing.T, s string, min, max int) {
+		got := LengthBetween(s, min, max)
+		if got != (MinLength(s, min) && MaxLength(s, max)) {
+			t.Errorf("LengthBetween(%q, %d, %d) = %v, inconsistent with MinLength/MaxLength", s, min, max, got)
+		}
+		if min > max && got {
+			t.Errorf("LengthBetween(%q, %d, %d) = true for empty range", s, min, max)
+		}
+	})
+}
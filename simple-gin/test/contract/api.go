@@ -0,0 +1,158 @@
+package contract
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"example/simple-gin/internal/model"
+	"example/simple-gin/internal/repository"
+	"example/simple-gin/internal/router"
+	"example/simple-gin/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// apiFixture 每个 API 用例开始前写入的数据
+type apiFixture struct {
+	userID    int
+	productID int
+}
+
+// apiCase 一个 API 契约用例，path 中的 {user} 和 {product} 会被替换为 fixture 的 ID
+type apiCase struct {
+	name       string
+	method     string
+	path       string
+	body       string
+	wantStatus int
+	wantCode   int
+	check      func(t *testing.T, data json.RawMessage)
+}
+
+var apiCases = []apiCase{
+	// 用户
+	{name: "list users", method: "GET", path: "/api/v1/users", wantStatus: 200, check: wantLen(1)},
+	{name: "get user", method: "GET", path: "/api/v1/users/{user}", wantStatus: 200, check: wantField("name", "张三")},
+	{name: "get missing user", method: "GET", path: "/api/v1/users/999", wantStatus: 404, wantCode: 404},
+	{name: "get user with invalid id", method: "GET", path: "/api/v1/users/abc", wantStatus: 400, wantCode: 400},
+	{name: "create user", method: "POST", path: "/api/v1/users", body: `{"name":"李四","email":"lisi@example.com","phone":"13900139000"}`, wantStatus: 201, check: wantField("email", "lisi@example.com")},
+	{name: "create user with invalid email", method: "POST", path: "/api/v1/users", body: `{"name":"李四","email":"lisi","phone":"13900139000"}`, wantStatus: 400, wantCode: 400},
+	{name: "create user with missing fields", method: "POST", path: "/api/v1/users", body: `{"name":"李四"}`, wantStatus: 400, wantCode: 400},
+	{name: "create user with malformed json", method: "POST", path: "/api/v1/users", body: `{"name":`, wantStatus: 400, wantCode: 400},
+	{name: "update user", method: "PUT", path: "/api/v1/users/{user}", body: `{"name":"张三丰"}`, wantStatus: 200, check: wantField("name", "张三丰")},
+	{name: "delete user", method: "DELETE", path: "/api/v1/users/{user}", wantStatus: 200},
+	{name: "delete missing user", method: "DELETE", path: "/api/v1/users/999", wantStatus: 404, wantCode: 404},
+
+	// 产品
+	{name: "list products", method: "GET", path: "/api/v1/products", wantStatus: 200, check: wantLen(1)},
+	{name: "get product", method: "GET", path: "/api/v1/products/{product}", wantStatus: 200, check: wantField("stock", float64(5))},
+	{name: "get missing product", method: "GET", path: "/api/v1/products/999", wantStatus: 404, wantCode: 404},
+	{name: "create product", method: "POST", path: "/api/v1/products", body: `{"name":"iPad","price":3999,"stock":10,"category":"Electronics"}`, wantStatus: 201, check: wantField("name", "iPad")},
+	{name: "create product with zero price", method: "POST", path: "/api/v1/products", body: `{"name":"iPad","price":0,"stock":10,"category":"Electronics"}`, wantStatus: 400, wantCode: 400},
+	{name: "create product with negative stock", method: "POST", path: "/api/v1/products", body: `{"name":"iPad","price":3999,"stock":-1,"category":"Electronics"}`, wantStatus: 400, wantCode: 400},
+	{name: "update product", method: "PUT", path: "/api/v1/products/{product}", body: `{"price":4999,"stock":8}`, wantStatus: 200, check: wantField("price", float64(4999))},
+	{name: "delete product", method: "DELETE", path: "/api/v1/products/{product}", wantStatus: 200},
+	{name: "reduce stock", method: "POST", path: "/api/v1/products/{product}/reduce-stock", body: `{"quantity":5}`, wantStatus: 200},
+	{name: "reduce stock beyond available", method: "POST", path: "/api/v1/products/{product}/reduce-stock", body: `{"quantity":6}`, wantStatus: 400, wantCode: 400},
+	{name: "reduce stock of missing product", method: "POST", path: "/api/v1/products/999/reduce-stock", body: `{"quantity":1}`, wantStatus: 400, wantCode: 400},
+	{name: "reduce stock with zero quantity", method: "POST", path: "/api/v1/products/{product}/reduce-stock", body: `{"quantity":0}`, wantStatus: 400, wantCode: 400},
+}
+
+// runAPIContract 通过 HTTP 接口验证状态码和统一响应格式
+func runAPIContract(t *testing.T, newDB Factory) {
+	for _, tc := range apiCases {
+		t.Run(tc.name, func(t *testing.T) {
+			db := newDB(t)
+			fixture := apiFixture{
+				userID:    must(db.CreateUser(&model.CreateUserRequest{Name: "张三", Email: "zhangsan@example.com", Phone: "13800138000"})).ID,
+				productID: must(db.CreateProduct(&model.CreateProductRequest{Name: "iPhone", Price: 5999, Stock: 5, Category: "Electronics"})).ID,
+			}
+			r := newRouter(t, db)
+
+			path := strings.NewReplacer(
+				"{user}", fmt.Sprint(fixture.userID),
+				"{product}", fmt.Sprint(fixture.productID),
+			).Replace(tc.path)
+
+			req, _ := http.NewRequest(tc.method, path, bytes.NewBufferString(tc.body))
+			req.Header.Set("Content-Type", "application/json")
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+
+			if w.Code != tc.wantStatus {
+				t.Fatalf("Expected status %d, got %d: %s", tc.wantStatus, w.Code, w.Body.String())
+			}
+
+			var resp struct {
+				Code    *int            `json:"code"`
+				Message string          `json:"msg"`
+				Data    json.RawMessage `json:"data"`
+			}
+			if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+				t.Fatalf("Response is not a JSON envelope: %v", err)
+			}
+			if resp.Code == nil || *resp.Code != tc.wantCode {
+				t.Errorf("Expected code %d, got %v", tc.wantCode, resp.Code)
+			}
+			if resp.Message == "" {
+				t.Error("Expected non-empty msg")
+			}
+			if tc.check != nil {
+				tc.check(t, resp.Data)
+			}
+		})
+	}
+}
+
+// newRouter 使用指定数据库创建完整路由，不依赖全局状态
+func newRouter(t *testing.T, db service.Database) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+
+	webhooks := service.NewWebhookService(repository.NewDB(), service.WebhookOptions{})
+	stream := service.NewInventoryStream(0)
+	t.Cleanup(func() {
+		stream.Close()
+		webhooks.Close()
+	})
+
+	events := service.NewMultiPublisher(webhooks, stream)
+	r := gin.New()
+	router.SetupRoutes(r,
+		service.NewUserService(db, events),
+		service.NewProductService(db, events, 0),
+		webhooks,
+		stream,
+		&router.RouterConfig{},
+	)
+	return r
+}
+
+// wantLen 检查 data 是长度为 n 的数组
+func wantLen(n int) func(t *testing.T, data json.RawMessage) {
+	return func(t *testing.T, data json.RawMessage) {
+		t.Helper()
+		var items []json.RawMessage
+		if err := json.Unmarshal(data, &items); err != nil || len(items) != n {
+			t.Errorf("Expected data to be an array of %d items, got %s", n, data)
+		}
+	}
+}
+
+// wantField 检查 data 对象的字段值
+func wantField(key string, want interface{}) func(t *testing.T, data json.RawMessage) {
+	return func(t *testing.T, data json.RawMessage) {
+		t.Helper()
+		var obj map[string]interface{}
+		if err := json.Unmarshal(data, &obj); err != nil {
+			t.Fatalf("Expected data to be an object, got %s", data)
+		}
+		if obj[key] != want {
+			t.Errorf("Expected data.%s = %v, got %v", key, want, obj[key])
+		}
+	}
+}
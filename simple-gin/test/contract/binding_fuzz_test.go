@@ -0,0 +1,89 @@
+package contract
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"example/simple-gin/internal/repository"
+)
+
+// 运行方式：go test -fuzz=FuzzCreateProductBinding ./test/contract/
+// 任意请求体都不能导致 5xx 或 panic，成功创建的数据必须满足校验规则
+
+// fuzzPost 发送任意请求体并解析统一响应
+func fuzzPost(t *testing.T, path string, body []byte) (int, map[string]interface{}) {
+	r := newRouter(t, repository.NewDB())
+
+	req, _ := http.NewRequest("POST", path, bytes.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var resp map[string]interface{}
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Response is not JSON (status %d): %q", w.Code, w.Body.String())
+	}
+	return w.Code, resp
+}
+
+func FuzzCreateProductBinding(f *testing.F) {
+	for _, seed := range []string{
+		`{"name":"iPad","price":3999,"stock":10,"category":"Electronics"}`,
+		`{"name":"iPad","price":-1,"stock":10,"category":"Electronics"}`,
+		`{"name":" ","price":1,"stock":1,"category":" "}`,
+		`{"name":"iPad","price":1e309,"stock":10,"category":"Electronics"}`,
+		`{"name":"iPad","price":"3999","stock":10.5,"category":"Electronics"}`,
+		`{"stock":9223372036854775808}`,
+		`[]`, `null`, `{`, ``,
+	} {
+		f.Add([]byte(seed))
+	}
+
+	f.Fuzz(func(t *testing.T, body []byte) {
+		code, resp := fuzzPost(t, "/api/v1/products", body)
+
+		switch code {
+		case http.StatusBadRequest:
+		case http.StatusCreated:
+			data, _ := resp["data"].(map[string]interface{})
+			price, _ := data["price"].(float64)
+			stock, _ := data["stock"].(float64)
+			if price <= 0 || stock < 0 || data["name"] == "" || data["category"] == "" {
+				t.Errorf("Created invalid product %v from %q", data, body)
+			}
+		default:
+			t.Errorf("Unexpected status %d for body %q", code, body)
+		}
+	})
+}
+
+func FuzzCreateUserBinding(f *testing.F) {
+	for _, seed := range []string{
+		`{"name":"张三","email":"zhangsan@example.com","phone":"13800138000"}`,
+		`{"name":"张三","email":"zhangsan","phone":"13800138000"}`,
+		`{"name":"张三","email":"zhangsan@example.com","phone":"138"}`,
+		`{"name":1,"email":true,"phone":null}`,
+		`{"name":"\u0000","email":"a@b.cc","phone":"13800138000"}`,
+		`[]`, `null`, `{`, ``,
+	} {
+		f.Add([]byte(seed))
+	}
+
+	f.Fuzz(func(t *testing.T, body []byte) {
+		code, resp := fuzzPost(t, "/api/v1/users", body)
+
+		switch code {
+		case http.StatusBadRequest:
+		case http.StatusCreated:
+			data, _ := resp["data"].(map[string]interface{})
+			if data["name"] == "" || data["email"] == "" || data["phone"] == "" {
+				t.Errorf("Created invalid user %v from %q", data, body)
+			}
+		default:
+			t.Errorf("Unexpected status %d for body %q", code, body)
+		}
+	})
+}
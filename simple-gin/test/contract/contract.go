@@ -0,0 +1,36 @@
+// Package contract 提供与具体存储实现无关的契约测试
+//
+// 任何 service.Database 实现（内存、PostgreSQL 等）都应该通过 RunDatabaseContract：
+//
+//	func TestPostgresContract(t *testing.T) {
+//		contract.RunDatabaseContract(t, func(t *testing.T) service.Database {
+//			return newEmptyPostgresDB(t)
+//		})
+//	}
+package contract
+
+import (
+	"testing"
+
+	"example/simple-gin/internal/service"
+)
+
+// Factory 创建一个空的数据库实例，每个子测试调用一次，保证测试之间互不影响
+type Factory func(t *testing.T) service.Database
+
+// RunDatabaseContract 运行全部契约测试
+func RunDatabaseContract(t *testing.T, newDB Factory) {
+	t.Run("Users", func(t *testing.T) { runUserContract(t, newDB) })
+	t.Run("Products", func(t *testing.T) { runProductContract(t, newDB) })
+	t.Run("Properties", func(t *testing.T) { runPropertyContract(t, newDB) })
+	t.Run("API", func(t *testing.T) { runAPIContract(t, newDB) })
+}
+
+// must 写操作返回错误时 panic（测试随之失败），否则返回写操作的结果
+// 契约中的写操作都不应该失败，用法与 template.Must 相同
+func must[T any](v T, err error) T {
+	if err != nil {
+		panic(err)
+	}
+	return v
+}
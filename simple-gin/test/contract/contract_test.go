@@ -0,0 +1,15 @@
+package contract
+
+import (
+	"testing"
+
+	"example/simple-gin/internal/repository"
+	"example/simple-gin/internal/service"
+)
+
+// TestMemoryDatabaseContract 内存数据库必须满足全部契约
+func TestMemoryDatabaseContract(t *testing.T) {
+	RunDatabaseContract(t, func(t *testing.T) service.Database {
+		return repository.NewDB()
+	})
+}
@@ -0,0 +1,146 @@
+package contract
+
+import (
+	"errors"
+	"testing"
+
+	"example/simple-gin/internal/model"
+	"example/simple-gin/internal/service"
+)
+
+// runUserContract 用户 CRUD 契约
+func runUserContract(t *testing.T, newDB Factory) {
+	t.Run("create assigns distinct ids", func(t *testing.T) {
+		db := newDB(t)
+		a := must(db.CreateUser(&model.CreateUserRequest{Name: "张三", Email: "a@example.com", Phone: "13800138000"}))
+		b := must(db.CreateUser(&model.CreateUserRequest{Name: "李四", Email: "b@example.com", Phone: "13800138001"}))
+		if a.ID <= 0 || b.ID <= 0 || a.ID == b.ID {
+			t.Errorf("Expected distinct positive ids, got %d and %d", a.ID, b.ID)
+		}
+		if got := len(db.GetAllUsers()); got != 2 {
+			t.Errorf("GetAllUsers() returned %d users, want 2", got)
+		}
+	})
+
+	tests := []struct {
+		name string
+		req  model.UpdateUserRequest
+		want model.User
+	}{
+		{"update name only", model.UpdateUserRequest{Name: "王五"}, model.User{Name: "王五", Email: "a@example.com", Phone: "13800138000"}},
+		{"update email only", model.UpdateUserRequest{Email: "new@example.com"}, model.User{Name: "张三", Email: "new@example.com", Phone: "13800138000"}},
+		{"update all", model.UpdateUserRequest{Name: "赵六", Email: "z@example.com", Phone: "13900139000"}, model.User{Name: "赵六", Email: "z@example.com", Phone: "13900139000"}},
+		{"empty update keeps fields", model.UpdateUserRequest{}, model.User{Name: "张三", Email: "a@example.com", Phone: "13800138000"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newDB(t)
+			created := must(db.CreateUser(&model.CreateUserRequest{Name: "张三", Email: "a@example.com", Phone: "13800138000"}))
+
+			updated := must(db.UpdateUser(created.ID, &tt.req))
+			if updated == nil {
+				t.Fatal("UpdateUser() returned nil")
+			}
+			got := db.GetUser(created.ID)
+			if got.Name != tt.want.Name || got.Email != tt.want.Email || got.Phone != tt.want.Phone {
+				t.Errorf("GetUser() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+
+	t.Run("missing user", func(t *testing.T) {
+		db := newDB(t)
+		if db.GetUser(999) != nil {
+			t.Error("GetUser() of missing id should return nil")
+		}
+		if must(db.UpdateUser(999, &model.UpdateUserRequest{Name: "x"})) != nil {
+			t.Error("UpdateUser() of missing id should return nil")
+		}
+		if must(db.DeleteUser(999)) {
+			t.Error("DeleteUser() of missing id should return false")
+		}
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		db := newDB(t)
+		created := must(db.CreateUser(&model.CreateUserRequest{Name: "张三", Email: "a@example.com", Phone: "13800138000"}))
+		if !must(db.DeleteUser(created.ID)) {
+			t.Fatal("DeleteUser() returned false")
+		}
+		if db.GetUser(created.ID) != nil {
+			t.Error("Deleted user is still returned")
+		}
+		if must(db.DeleteUser(created.ID)) {
+			t.Error("Deleting twice should return false")
+		}
+	})
+}
+
+// runProductContract 产品 CRUD 和库存契约
+func runProductContract(t *testing.T, newDB Factory) {
+	t.Run("returned values are copies", func(t *testing.T) {
+		db := newDB(t)
+		created := must(db.CreateProduct(&model.CreateProductRequest{Name: "iPhone", Price: 5999, Stock: 10, Category: "Electronics"}))
+		created.Stock = 0
+
+		fetched := db.GetProduct(created.ID)
+		fetched.Name = "changed"
+
+		if got := db.GetProduct(created.ID); got.Stock != 10 || got.Name != "iPhone" {
+			t.Errorf("Mutating returned product changed stored data: %+v", got)
+		}
+	})
+
+	tests := []struct {
+		name      string
+		stock     int
+		quantity  int
+		wantStock int
+		wantErr   error
+	}{
+		{"reduce part", 10, 3, 7, nil},
+		{"reduce all", 10, 10, 0, nil},
+		{"insufficient", 10, 11, 10, service.ErrInsufficientStock},
+		{"empty stock", 0, 1, 0, service.ErrInsufficientStock},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db := newDB(t)
+			created := must(db.CreateProduct(&model.CreateProductRequest{Name: "iPhone", Price: 5999, Stock: tt.stock, Category: "Electronics"}))
+
+			product, err := db.ReduceStock(created.ID, tt.quantity)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ReduceStock() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && product.Stock != tt.wantStock {
+				t.Errorf("ReduceStock() returned stock %d, want %d", product.Stock, tt.wantStock)
+			}
+			if got := db.GetProduct(created.ID).Stock; got != tt.wantStock {
+				t.Errorf("Stored stock = %d, want %d", got, tt.wantStock)
+			}
+		})
+	}
+
+	t.Run("reduce missing product", func(t *testing.T) {
+		db := newDB(t)
+		if _, err := db.ReduceStock(999, 1); !errors.Is(err, service.ErrProductNotFound) {
+			t.Errorf("ReduceStock() error = %v, want %v", err, service.ErrProductNotFound)
+		}
+	})
+
+	t.Run("update and delete", func(t *testing.T) {
+		db := newDB(t)
+		created := must(db.CreateProduct(&model.CreateProductRequest{Name: "iPhone", Price: 5999, Stock: 10, Category: "Electronics"}))
+
+		updated := must(db.UpdateProduct(created.ID, &model.UpdateProductRequest{Price: 4999, Stock: 20}))
+		if updated == nil || updated.Price != 4999 || updated.Stock != 20 || updated.Name != "iPhone" {
+			t.Errorf("UpdateProduct() = %+v", updated)
+		}
+		if !must(db.DeleteProduct(created.ID)) || db.GetProduct(created.ID) != nil {
+			t.Error("Product should be deleted")
+		}
+		if must(db.UpdateProduct(created.ID, &model.UpdateProductRequest{Price: 1})) != nil {
+			t.Error("UpdateProduct() of deleted product should return nil")
+		}
+	})
+}
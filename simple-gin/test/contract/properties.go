@@ -0,0 +1,128 @@
+package contract
+
+import (
+	"context"
+	"math/rand"
+	"reflect"
+	"sync"
+	"testing"
+	"testing/quick"
+
+	"example/simple-gin/internal/model"
+	"example/simple-gin/internal/service"
+)
+
+// quickConfig 属性测试配置，固定随机种子便于复现失败用例
+func quickConfig(maxCount int) *quick.Config {
+	return &quick.Config{
+		MaxCount: maxCount,
+		Rand:     rand.New(rand.NewSource(1)),
+	}
+}
+
+// stockScenario 并发扣减库存的随机场景
+type stockScenario struct {
+	Stock      int
+	Quantities []int
+}
+
+// Generate 实现 quick.Generator：初始库存 0~100，10~50 个并发请求，每次扣减 1~10
+func (stockScenario) Generate(r *rand.Rand, size int) reflect.Value {
+	s := stockScenario{
+		Stock:      r.Intn(101),
+		Quantities: make([]int, 10+r.Intn(41)),
+	}
+	for i := range s.Quantities {
+		s.Quantities[i] = 1 + r.Intn(10)
+	}
+	return reflect.ValueOf(s)
+}
+
+// runPropertyContract 基于 testing/quick 的属性测试
+func runPropertyContract(t *testing.T, newDB Factory) {
+	t.Run("create then get returns an equal user", func(t *testing.T) {
+		db := newDB(t)
+		property := func(name, email, phone string) bool {
+			created := must(db.CreateUser(&model.CreateUserRequest{Name: name, Email: email, Phone: phone}))
+			got := db.GetUser(created.ID)
+			return got != nil &&
+				got.ID == created.ID &&
+				got.Name == name && got.Email == email && got.Phone == phone &&
+				got.CreatedAt.Equal(created.CreatedAt)
+		}
+		if err := quick.Check(property, quickConfig(200)); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("create then get returns an equal product", func(t *testing.T) {
+		db := newDB(t)
+		property := func(name, category string, price float64, stock uint16) bool {
+			req := &model.CreateProductRequest{Name: name, Price: price, Stock: int(stock), Category: category}
+			created := must(db.CreateProduct(req))
+			got := db.GetProduct(created.ID)
+			return got != nil && *got == *created &&
+				got.Name == name && got.Price == price && got.Stock == int(stock) && got.Category == category
+		}
+		if err := quick.Check(property, quickConfig(200)); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("update only changes non-empty fields", func(t *testing.T) {
+		db := newDB(t)
+		property := func(name, email string) bool {
+			created := must(db.CreateUser(&model.CreateUserRequest{Name: "张三", Email: "a@example.com", Phone: "13800138000"}))
+			must(db.UpdateUser(created.ID, &model.UpdateUserRequest{Name: name, Email: email}))
+			got := db.GetUser(created.ID)
+
+			wantName, wantEmail := name, email
+			if name == "" {
+				wantName = created.Name
+			}
+			if email == "" {
+				wantEmail = created.Email
+			}
+			return got.Name == wantName && got.Email == wantEmail && got.Phone == created.Phone
+		}
+		if err := quick.Check(property, quickConfig(200)); err != nil {
+			t.Error(err)
+		}
+	})
+
+	t.Run("stock never goes negative under concurrent ReduceStock", func(t *testing.T) {
+		property := func(s stockScenario) bool {
+			db := newDB(t)
+			products := service.NewProductService(db, nil, 0)
+			created := must(db.CreateProduct(&model.CreateProductRequest{Name: "iPhone", Price: 5999, Stock: s.Stock, Category: "Electronics"}))
+
+			var (
+				wg      sync.WaitGroup
+				mu      sync.Mutex
+				reduced int
+			)
+			for _, quantity := range s.Quantities {
+				wg.Add(1)
+				go func(quantity int) {
+					defer wg.Done()
+					if err := products.ReduceStock(context.Background(), created.ID, quantity); err == nil {
+						mu.Lock()
+						reduced += quantity
+						mu.Unlock()
+					}
+				}(quantity)
+			}
+			wg.Wait()
+
+			final := db.GetProduct(created.ID).Stock
+			if final < 0 || final != s.Stock-reduced {
+				t.Logf("initial %d, reduced %d, final %d", s.Stock, reduced, final)
+				return false
+			}
+			return true
+		}
+		if err := quick.Check(property, quickConfig(100)); err != nil {
+			t.Error(err)
+		}
+	})
+}
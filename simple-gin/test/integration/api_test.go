@@ -16,31 +16,41 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-// setupTestRouter 创建测试用的路由
-func setupTestRouter() *gin.Engine {
-	gin.SetMode(gin.TestMode)
-
-	// 使用测试配置
-	cfg := &config.Config{
+// newTestConfig 创建测试配置
+func newTestConfig() *config.Config {
+	return &config.Config{
 		Server: config.ServerConfig{
 			Port: 8080,
 			Mode: "debug",
 		},
 		DB: config.DatabaseConfig{
-			Host: "localhost",
-			Port: 5432,
+			Host:     "localhost",
+			Port:     5432,
+			SeedFile: "../../configs/seed.yaml", // 与开发环境使用相同的种子数据
 		},
 	}
+}
+
+// setupTestRouter 创建测试用的路由
+func setupTestRouter() *gin.Engine {
+	r, _ := setupTestRouterWithConfig(newTestConfig())
+	return r
+}
+
+// setupTestRouterWithConfig 使用指定配置创建测试用的路由，同时返回容器以便释放资源
+func setupTestRouterWithConfig(cfg *config.Config) (*gin.Engine, *container.Container) {
+	gin.SetMode(gin.TestMode)
 
 	c, _ := container.NewContainer(cfg)
 
 	r := gin.New()
 	routerCfg := &router.RouterConfig{
-		EnableSwagger: true, // 测试环境启用 Swagger
+		EnableSwagger:   true, // 测试环境启用 Swagger
+		StreamHeartbeat: cfg.Stream.GetHeartbeatInterval(),
 	}
-	router.SetupRoutes(r, c.UserService, c.ProductService, routerCfg)
+	router.SetupRoutes(r, c.UserService, c.ProductService, c.WebhookService, c.InventoryStream, routerCfg)
 
-	return r
+	return r, c
 }
 
 // TestPingEndpoint 测试健康检查接口
@@ -0,0 +1,68 @@
+package integration
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+
+	"example/simple-gin/pkg/client"
+)
+
+// TestClientAgainstServer 使用类型化客户端调用真实路由
+func TestClientAgainstServer(t *testing.T) {
+	r, c := setupTestRouterWithConfig(newTestConfig())
+	server := httptest.NewServer(r)
+	t.Cleanup(func() {
+		c.Close()
+		server.Close()
+	})
+
+	ctx := context.Background()
+	api := client.NewClient(server.URL, client.Options{})
+
+	user, err := api.CreateUser(ctx, client.CreateUserRequest{Name: "赵六", Email: "zhaoliu@example.com", Phone: "13800138003"})
+	if err != nil {
+		t.Fatalf("CreateUser() error = %v", err)
+	}
+
+	updated, err := api.UpdateUser(ctx, user.ID, client.UpdateUserRequest{Name: "赵六（已更新）"})
+	if err != nil {
+		t.Fatalf("UpdateUser() error = %v", err)
+	}
+	if updated.Name != "赵六（已更新）" || updated.Email != user.Email {
+		t.Errorf("Unexpected updated user %+v", updated)
+	}
+
+	if err := api.DeleteUser(ctx, user.ID); err != nil {
+		t.Fatalf("DeleteUser() error = %v", err)
+	}
+	if _, err := api.GetUser(ctx, user.ID); !client.IsNotFound(err) {
+		t.Errorf("Expected not found error, got %v", err)
+	}
+
+	stream, err := api.StreamProducts(ctx, client.StreamOptions{ProductIDs: []int{1}})
+	if err != nil {
+		t.Fatalf("StreamProducts() error = %v", err)
+	}
+	defer stream.Close()
+
+	if err := api.ReduceStock(ctx, 1, 3); err != nil {
+		t.Fatalf("ReduceStock() error = %v", err)
+	}
+	product, err := api.GetProduct(ctx, 1)
+	if err != nil {
+		t.Fatalf("GetProduct() error = %v", err)
+	}
+
+	event, err := stream.Next()
+	if err != nil {
+		t.Fatalf("Next() error = %v", err)
+	}
+	if event.Type != "product.updated" || event.Product.Stock != product.Stock {
+		t.Errorf("Unexpected event %+v, product %+v", event, product)
+	}
+
+	if err := api.ReduceStock(ctx, 1, 0); !client.IsBadRequest(err) {
+		t.Errorf("Expected bad request for zero quantity, got %v", err)
+	}
+}
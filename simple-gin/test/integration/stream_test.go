@@ -0,0 +1,297 @@
+package integration
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"example/simple-gin/internal/config"
+	"example/simple-gin/internal/container"
+	"example/simple-gin/internal/model"
+
+	"github.com/gorilla/websocket"
+)
+
+// sseFrame 一个 SSE 帧，comment 为 ":" 开头的注释行（心跳）
+type sseFrame struct {
+	id      string
+	event   string
+	data    string
+	comment string
+}
+
+// streamTestServer 启动真实 HTTP 服务，SSE 和 WebSocket 需要真正的连接
+func streamTestServer(t *testing.T, heartbeat string) (*httptest.Server, *container.Container) {
+	t.Helper()
+
+	cfg := newTestConfig()
+	cfg.Stream = config.StreamConfig{
+		BufferSize:        100,
+		HeartbeatInterval: heartbeat,
+	}
+	r, c := setupTestRouterWithConfig(cfg)
+	server := httptest.NewServer(r)
+	t.Cleanup(func() {
+		// 先关闭推送服务断开长连接，否则 server.Close 会一直等待
+		c.Close()
+		server.Close()
+	})
+	return server, c
+}
+
+// readSSE 在后台解析 SSE 响应体，每解析出一帧就发送到通道
+func readSSE(resp *http.Response) <-chan sseFrame {
+	frames := make(chan sseFrame, 16)
+	go func() {
+		defer close(frames)
+		scanner := bufio.NewScanner(resp.Body)
+		var frame sseFrame
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case line == "":
+				if frame != (sseFrame{}) {
+					frames <- frame
+				}
+				frame = sseFrame{}
+			case strings.HasPrefix(line, ":"):
+				frame.comment = strings.TrimSpace(line[1:])
+			case strings.HasPrefix(line, "id: "):
+				frame.id = line[len("id: "):]
+			case strings.HasPrefix(line, "event: "):
+				frame.event = line[len("event: "):]
+			case strings.HasPrefix(line, "data: "):
+				frame.data = line[len("data: "):]
+			}
+		}
+	}()
+	return frames
+}
+
+// nextEvent 读取下一个带事件类型的帧，跳过 retry 和心跳
+func nextEvent(t *testing.T, frames <-chan sseFrame) sseFrame {
+	t.Helper()
+
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case frame, ok := <-frames:
+			if !ok {
+				t.Fatal("Stream closed before receiving event")
+			}
+			if frame.event != "" {
+				return frame
+			}
+		case <-timeout:
+			t.Fatal("Timed out waiting for stream event")
+		}
+	}
+}
+
+// openStream 打开 SSE 连接
+func openStream(t *testing.T, url, lastEventID string) *http.Response {
+	t.Helper()
+
+	req, _ := http.NewRequest("GET", url, nil)
+	if lastEventID != "" {
+		req.Header.Set("Last-Event-ID", lastEventID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to open stream: %v", err)
+	}
+	t.Cleanup(func() { resp.Body.Close() })
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Expected Content-Type text/event-stream, got %s", ct)
+	}
+	return resp
+}
+
+// postProduct 通过真实 HTTP 创建产品
+func postProduct(t *testing.T, server *httptest.Server, name, category string, stock int) {
+	t.Helper()
+
+	data, _ := json.Marshal(map[string]interface{}{
+		"name":     name,
+		"price":    99.9,
+		"stock":    stock,
+		"category": category,
+	})
+	resp, err := http.Post(server.URL+"/api/v1/products", "application/json", bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to create product: %v", err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d", resp.StatusCode)
+	}
+}
+
+// TestProductStreamFilter 测试 SSE 推送及分类过滤
+func TestProductStreamFilter(t *testing.T) {
+	server, _ := streamTestServer(t, "1m")
+
+	resp := openStream(t, server.URL+"/api/v1/products/stream?category=electronics", "")
+	frames := readSSE(resp)
+
+	postProduct(t, server, "Novel", "Books", 100)
+	postProduct(t, server, "Keyboard", "Electronics", 100)
+
+	frame := nextEvent(t, frames)
+	if frame.event != model.EventProductCreated {
+		t.Errorf("Expected event %s, got %s", model.EventProductCreated, frame.event)
+	}
+	if frame.id != "2" {
+		t.Errorf("Expected event id 2 (Books event filtered out), got %s", frame.id)
+	}
+
+	var event model.InventoryEvent
+	if err := json.Unmarshal([]byte(frame.data), &event); err != nil {
+		t.Fatalf("Failed to parse event data: %v", err)
+	}
+	if event.Product == nil || event.Product.Name != "Keyboard" {
+		t.Errorf("Expected Keyboard product, got %+v", event.Product)
+	}
+}
+
+// TestProductStreamDeleted 测试删除事件推送删除前的快照，仍可按分类过滤
+func TestProductStreamDeleted(t *testing.T) {
+	server, c := streamTestServer(t, "1m")
+
+	postProduct(t, server, "Lamp", "Furniture", 100)
+	resp := openStream(t, server.URL+"/api/v1/products/stream?category=furniture", "")
+	frames := readSSE(resp)
+
+	var id int
+	for _, p := range c.DB.GetAllProducts() {
+		if p.Name == "Lamp" {
+			id = p.ID
+		}
+	}
+	req, _ := http.NewRequest("DELETE", fmt.Sprintf("%s/api/v1/products/%d", server.URL, id), nil)
+	deleted, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("Failed to delete product: %v", err)
+	}
+	deleted.Body.Close()
+
+	frame := nextEvent(t, frames)
+	if frame.event != model.EventProductDeleted {
+		t.Fatalf("Expected event %s, got %s", model.EventProductDeleted, frame.event)
+	}
+	var event model.InventoryEvent
+	if err := json.Unmarshal([]byte(frame.data), &event); err != nil {
+		t.Fatalf("Failed to parse event data: %v", err)
+	}
+	if event.Product == nil || event.Product.ID != id || event.Product.Name != "Lamp" {
+		t.Errorf("Expected snapshot of the deleted Lamp, got %+v", event.Product)
+	}
+}
+
+// TestProductStreamResume 测试携带 Last-Event-ID 重连时补发错过的事件
+func TestProductStreamResume(t *testing.T) {
+	server, _ := streamTestServer(t, "1m")
+
+	postProduct(t, server, "Mouse", "Electronics", 100)
+	postProduct(t, server, "Monitor", "Electronics", 100)
+	postProduct(t, server, "Desk", "Furniture", 100)
+
+	resp := openStream(t, server.URL+"/api/v1/products/stream", "1")
+	frames := readSSE(resp)
+
+	for _, want := range []string{"2", "3"} {
+		frame := nextEvent(t, frames)
+		if frame.id != want {
+			t.Errorf("Expected replayed event id %s, got %s", want, frame.id)
+		}
+	}
+
+	// 补发之后继续接收实时事件
+	postProduct(t, server, "Chair", "Furniture", 100)
+	if frame := nextEvent(t, frames); frame.id != "4" {
+		t.Errorf("Expected live event id 4, got %s", frame.id)
+	}
+}
+
+// TestProductStreamInvalidParams 测试非法参数
+func TestProductStreamInvalidParams(t *testing.T) {
+	server, _ := streamTestServer(t, "1m")
+
+	for _, query := range []string{"product_id=abc", "last_event_id=-1"} {
+		resp, err := http.Get(server.URL + "/api/v1/products/stream?" + query)
+		if err != nil {
+			t.Fatalf("Request failed: %v", err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", query, resp.StatusCode)
+		}
+	}
+}
+
+// TestProductStreamHeartbeat 测试空闲连接的心跳
+func TestProductStreamHeartbeat(t *testing.T) {
+	server, _ := streamTestServer(t, "20ms")
+
+	resp := openStream(t, server.URL+"/api/v1/products/stream", "")
+	frames := readSSE(resp)
+
+	timeout := time.After(2 * time.Second)
+	for {
+		select {
+		case frame := <-frames:
+			if frame.comment == "heartbeat" {
+				return
+			}
+		case <-timeout:
+			t.Fatal("Timed out waiting for heartbeat")
+		}
+	}
+}
+
+// TestProductWebSocket 测试 WebSocket 推送及产品过滤
+func TestProductWebSocket(t *testing.T) {
+	server, _ := streamTestServer(t, "1m")
+
+	url := "ws" + strings.TrimPrefix(server.URL, "http") + "/api/v1/products/ws?product_id=2"
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("Failed to dial websocket: %v", err)
+	}
+	defer conn.Close()
+
+	// 分别减少产品 1 和产品 2 的库存，只应收到产品 2 的事件
+	data, _ := json.Marshal(map[string]interface{}{"quantity": 1})
+	resp, err := http.Post(server.URL+"/api/v1/products/1/reduce-stock", "application/json", bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to reduce stock: %v", err)
+	}
+	resp.Body.Close()
+	resp, err = http.Post(server.URL+"/api/v1/products/2/reduce-stock", "application/json", bytes.NewReader(data))
+	if err != nil {
+		t.Fatalf("Failed to reduce stock: %v", err)
+	}
+	resp.Body.Close()
+
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var event model.InventoryEvent
+	if err := conn.ReadJSON(&event); err != nil {
+		t.Fatalf("Failed to read websocket message: %v", err)
+	}
+	if event.Type != model.EventProductUpdated {
+		t.Errorf("Expected event %s, got %s", model.EventProductUpdated, event.Type)
+	}
+	if event.Product == nil || event.Product.ID != 2 {
+		t.Errorf("Expected product 2, got %+v", event.Product)
+	}
+}
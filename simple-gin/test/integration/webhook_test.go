@@ -0,0 +1,261 @@
+package integration
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"example/simple-gin/internal/config"
+	"example/simple-gin/internal/service"
+	"example/simple-gin/pkg/signature"
+
+	"github.com/gin-gonic/gin"
+)
+
+// webhookTestConfig 使用很短的退避时间，让重试测试快速结束
+func webhookTestConfig() *config.Config {
+	cfg := newTestConfig()
+	cfg.Webhook = config.WebhookConfig{
+		Workers:           2,
+		MaxRetries:        2,
+		InitialBackoff:    "10ms",
+		MaxBackoff:        "50ms",
+		Timeout:           "1s",
+		StockLowThreshold: 10,
+	}
+	return cfg
+}
+
+// receivedWebhook 接收端收到的一次投递
+type receivedWebhook struct {
+	header http.Header
+	body   []byte
+}
+
+// doJSON 发送 JSON 请求并解析统一响应
+func doJSON(t *testing.T, r *gin.Engine, method, path string, payload interface{}) (int, map[string]interface{}) {
+	t.Helper()
+
+	var body io.Reader
+	if payload != nil {
+		data, _ := json.Marshal(payload)
+		body = bytes.NewBuffer(data)
+	}
+
+	req, _ := http.NewRequest(method, path, body)
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	var resp map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &resp)
+	return w.Code, resp
+}
+
+// TestWebhookDelivery 测试事件投递及签名
+func TestWebhookDelivery(t *testing.T) {
+	received := make(chan receivedWebhook, 10)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		received <- receivedWebhook{header: req.Header.Clone(), body: body}
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	r, c := setupTestRouterWithConfig(webhookTestConfig())
+	defer c.Close()
+
+	code, resp := doJSON(t, r, "POST", "/api/v1/webhooks", map[string]interface{}{
+		"url":    receiver.URL,
+		"events": []string{"product.created", "product.stock_low"},
+		"secret": "test-secret",
+	})
+	if code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d", code)
+	}
+	if resp["data"].(map[string]interface{})["secret"] != "test-secret" {
+		t.Errorf("Expected secret to be returned on creation")
+	}
+
+	// 库存低于阈值的新产品会同时触发 product.created 和 product.stock_low
+	code, _ = doJSON(t, r, "POST", "/api/v1/products", map[string]interface{}{
+		"name":     "AirPods",
+		"price":    1299,
+		"stock":    5,
+		"category": "Electronics",
+	})
+	if code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d", code)
+	}
+
+	seen := make(map[string]bool)
+	for len(seen) < 2 {
+		select {
+		case got := <-received:
+			eventType := got.header.Get(service.HeaderWebhookEvent)
+			seen[eventType] = true
+
+			timestamp, _ := strconv.ParseInt(got.header.Get(service.HeaderWebhookTimestamp), 10, 64)
+			if !signature.Verify("test-secret", timestamp, got.body, got.header.Get(service.HeaderWebhookSignature)) {
+				t.Errorf("Invalid signature for event %s", eventType)
+			}
+
+			var event map[string]interface{}
+			json.Unmarshal(got.body, &event)
+			if event["type"] != eventType {
+				t.Errorf("Expected body type %s, got %v", eventType, event["type"])
+			}
+		case <-time.After(2 * time.Second):
+			t.Fatalf("Timed out waiting for webhooks, got %v", seen)
+		}
+	}
+
+	if !seen["product.created"] || !seen["product.stock_low"] {
+		t.Errorf("Expected product.created and product.stock_low, got %v", seen)
+	}
+}
+
+// TestWebhookDeadLetter 测试重试耗尽后进入死信列表
+func TestWebhookDeadLetter(t *testing.T) {
+	attempts := make(chan struct{}, 10)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		attempts <- struct{}{}
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	defer receiver.Close()
+
+	r, c := setupTestRouterWithConfig(webhookTestConfig())
+	defer c.Close()
+
+	code, _ := doJSON(t, r, "POST", "/api/v1/webhooks", map[string]interface{}{
+		"url":    receiver.URL,
+		"events": []string{"*"},
+	})
+	if code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d", code)
+	}
+
+	code, _ = doJSON(t, r, "DELETE", "/api/v1/users/1", nil)
+	if code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", code)
+	}
+
+	deadline := time.After(2 * time.Second)
+	for {
+		_, resp := doJSON(t, r, "GET", "/api/v1/webhooks/dead-letters", nil)
+		deadLetters := resp["data"].([]interface{})
+		if len(deadLetters) == 1 {
+			dl := deadLetters[0].(map[string]interface{})
+			if dl["attempts"].(float64) != 3 {
+				t.Errorf("Expected 3 attempts (1 + 2 retries), got %v", dl["attempts"])
+			}
+			if dl["event"].(map[string]interface{})["type"] != "user.deleted" {
+				t.Errorf("Expected user.deleted event, got %v", dl["event"])
+			}
+			break
+		}
+
+		select {
+		case <-deadline:
+			t.Fatal("Timed out waiting for dead letter")
+		case <-time.After(20 * time.Millisecond):
+		}
+	}
+
+	if len(attempts) != 3 {
+		t.Errorf("Expected receiver to be called 3 times, got %d", len(attempts))
+	}
+}
+
+// TestCreateWebhookInvalidEvent 测试订阅未知事件
+func TestCreateWebhookInvalidEvent(t *testing.T) {
+	r, c := setupTestRouterWithConfig(webhookTestConfig())
+	defer c.Close()
+
+	code, resp := doJSON(t, r, "POST", "/api/v1/webhooks", map[string]interface{}{
+		"url":    "https://example.com/hook",
+		"events": []string{"order.created"},
+	})
+	if code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", code)
+	}
+	if resp["msg"] != "unknown event type: order.created" {
+		t.Errorf("Expected unknown event message, got %v", resp["msg"])
+	}
+
+	// 列表中不应暴露签名密钥
+	code, _ = doJSON(t, r, "POST", "/api/v1/webhooks", map[string]interface{}{
+		"url":    "https://example.com/hook",
+		"events": []string{"user.created"},
+	})
+	if code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d", code)
+	}
+	_, resp = doJSON(t, r, "GET", "/api/v1/webhooks", nil)
+	for _, item := range resp["data"].([]interface{}) {
+		if _, ok := item.(map[string]interface{})["secret"]; ok {
+			t.Error("Expected secret to be hidden in webhook list")
+		}
+	}
+}
+
+// TestWebhookProductDeletedPayload 测试删除产品的 Webhook 只携带产品 ID
+func TestWebhookProductDeletedPayload(t *testing.T) {
+	received := make(chan []byte, 10)
+	receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := io.ReadAll(req.Body)
+		received <- body
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer receiver.Close()
+
+	r, c := setupTestRouterWithConfig(webhookTestConfig())
+	defer c.Close()
+
+	code, _ := doJSON(t, r, "POST", "/api/v1/webhooks", map[string]interface{}{
+		"url":    receiver.URL,
+		"events": []string{"product.deleted"},
+	})
+	if code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d", code)
+	}
+
+	code, resp := doJSON(t, r, "POST", "/api/v1/products", map[string]interface{}{
+		"name":     "Webcam",
+		"price":    399,
+		"stock":    50,
+		"category": "Electronics",
+	})
+	if code != http.StatusCreated {
+		t.Fatalf("Expected status 201, got %d", code)
+	}
+	id := int(resp["data"].(map[string]interface{})["id"].(float64))
+
+	if code, _ := doJSON(t, r, "DELETE", "/api/v1/products/"+strconv.Itoa(id), nil); code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", code)
+	}
+
+	select {
+	case body := <-received:
+		var event struct {
+			Type string                 `json:"type"`
+			Data map[string]interface{} `json:"data"`
+		}
+		if err := json.Unmarshal(body, &event); err != nil {
+			t.Fatalf("Failed to parse webhook body: %v", err)
+		}
+		if event.Type != "product.deleted" {
+			t.Errorf("Expected product.deleted, got %s", event.Type)
+		}
+		if len(event.Data) != 1 || event.Data["id"] != float64(id) {
+			t.Errorf("Expected data {\"id\": %d}, got %v", id, event.Data)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Timed out waiting for product.deleted webhook")
+	}
+}